@@ -28,17 +28,20 @@ var (
 	_ DDLNode = &AlterTableStmt{}
 	_ DDLNode = &AlterSequenceStmt{}
 	_ DDLNode = &AlterPlacementPolicyStmt{}
+	_ DDLNode = &AlterResourceGroupStmt{}
 	_ DDLNode = &CreateDatabaseStmt{}
 	_ DDLNode = &CreateIndexStmt{}
 	_ DDLNode = &CreateTableStmt{}
 	_ DDLNode = &CreateViewStmt{}
 	_ DDLNode = &CreateSequenceStmt{}
 	_ DDLNode = &CreatePlacementPolicyStmt{}
+	_ DDLNode = &CreateResourceGroupStmt{}
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
 	_ DDLNode = &DropSequenceStmt{}
 	_ DDLNode = &DropPlacementPolicyStmt{}
+	_ DDLNode = &DropResourceGroupStmt{}
 	_ DDLNode = &RenameTableStmt{}
 	_ DDLNode = &TruncateTableStmt{}
 	_ DDLNode = &RepairTableStmt{}
@@ -1996,6 +1999,7 @@ const (
 	TableOptionTableCheckSum
 	TableOptionUnion
 	TableOptionEncryption
+	TableOptionWithSystemVersioning
 	TableOptionPlacementPrimaryRegion       = TableOptionType(PlacementOptionPrimaryRegion)
 	TableOptionPlacementRegions             = TableOptionType(PlacementOptionRegions)
 	TableOptionPlacementFollowerCount       = TableOptionType(PlacementOptionFollowerCount)
@@ -2273,6 +2277,8 @@ func (n *TableOption) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord("ENCRYPTION ")
 		ctx.WritePlain("= ")
 		ctx.WriteString(n.StrValue)
+	case TableOptionWithSystemVersioning:
+		ctx.WriteKeyWord("WITH SYSTEM VERSIONING")
 	case TableOptionPlacementPrimaryRegion, TableOptionPlacementRegions, TableOptionPlacementFollowerCount, TableOptionPlacementLeaderConstraints, TableOptionPlacementLearnerCount, TableOptionPlacementVoterCount, TableOptionPlacementSchedule, TableOptionPlacementConstraints, TableOptionPlacementFollowerConstraints, TableOptionPlacementVoterConstraints, TableOptionPlacementLearnerConstraints, TableOptionPlacementPolicy:
 		placementOpt := PlacementOption{
 			Tp:        PlacementOptionType(n.Tp),
@@ -3977,6 +3983,154 @@ func (n *AlterPlacementPolicyStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ResourceGroupOptionType is the type for ResourceGroupOption.
+type ResourceGroupOptionType int
+
+// ResourceGroupOption types.
+const (
+	ResourceGroupOptionGroupType ResourceGroupOptionType = iota + 1
+	ResourceGroupOptionVCPU
+	ResourceGroupOptionThreadPriority
+	ResourceGroupOptionEnable
+	ResourceGroupOptionDisable
+)
+
+// ResourceGroupOption is used for parsing resource group option.
+type ResourceGroupOption struct {
+	Tp        ResourceGroupOptionType
+	GroupType model.ResourceGroupType
+	VCPUs     []model.VCPURange
+	IntValue  int64
+}
+
+// Restore implements Node interface.
+func (n *ResourceGroupOption) Restore(ctx *format.RestoreCtx) error {
+	switch n.Tp {
+	case ResourceGroupOptionGroupType:
+		ctx.WriteKeyWord("TYPE ")
+		ctx.WritePlain("= ")
+		ctx.WriteKeyWord(n.GroupType.String())
+	case ResourceGroupOptionVCPU:
+		ctx.WriteKeyWord("VCPU ")
+		ctx.WritePlain("= ")
+		for i, vcpu := range n.VCPUs {
+			if i != 0 {
+				ctx.WritePlain(",")
+			}
+			ctx.WritePlain(vcpu.String())
+		}
+	case ResourceGroupOptionThreadPriority:
+		ctx.WriteKeyWord("THREAD_PRIORITY ")
+		ctx.WritePlain("= ")
+		ctx.WritePlainf("%d", n.IntValue)
+	case ResourceGroupOptionEnable:
+		ctx.WriteKeyWord("ENABLE")
+	case ResourceGroupOptionDisable:
+		ctx.WriteKeyWord("DISABLE")
+	default:
+		return errors.Errorf("invalid ResourceGroupOption: %d", n.Tp)
+	}
+	return nil
+}
+
+// CreateResourceGroupStmt is a statement to create a resource group.
+// See https://dev.mysql.com/doc/refman/8.0/en/create-resource-group.html
+type CreateResourceGroupStmt struct {
+	ddlNode
+
+	ResourceGroupName    model.CIStr
+	ResourceGroupOptions []*ResourceGroupOption
+}
+
+// Restore implements Node interface.
+func (n *CreateResourceGroupStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE RESOURCE GROUP ")
+	ctx.WriteName(n.ResourceGroupName.O)
+	for i, option := range n.ResourceGroupOptions {
+		ctx.WritePlain(" ")
+		if err := option.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while splicing CreateResourceGroupStmt ResourceGroupOption: [%v]", i)
+		}
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateResourceGroupStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateResourceGroupStmt)
+	return v.Leave(n)
+}
+
+// AlterResourceGroupStmt is a statement to alter resource group option.
+// See https://dev.mysql.com/doc/refman/8.0/en/alter-resource-group.html
+type AlterResourceGroupStmt struct {
+	ddlNode
+
+	ResourceGroupName    model.CIStr
+	ResourceGroupOptions []*ResourceGroupOption
+	// Force is true if the threads assigned to a disabled group should be moved to the default group.
+	Force bool
+}
+
+// Restore implements Node interface.
+func (n *AlterResourceGroupStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ALTER RESOURCE GROUP ")
+	ctx.WriteName(n.ResourceGroupName.O)
+	for i, option := range n.ResourceGroupOptions {
+		ctx.WritePlain(" ")
+		if err := option.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while splicing AlterResourceGroupStmt ResourceGroupOption: [%v]", i)
+		}
+	}
+	if n.Force {
+		ctx.WriteKeyWord(" FORCE")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *AlterResourceGroupStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*AlterResourceGroupStmt)
+	return v.Leave(n)
+}
+
+// DropResourceGroupStmt is a statement to drop a resource group.
+// See https://dev.mysql.com/doc/refman/8.0/en/drop-resource-group.html
+type DropResourceGroupStmt struct {
+	ddlNode
+
+	ResourceGroupName model.CIStr
+	Force             bool
+}
+
+// Restore implements Restore interface.
+func (n *DropResourceGroupStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP RESOURCE GROUP ")
+	ctx.WriteName(n.ResourceGroupName.O)
+	if n.Force {
+		ctx.WriteKeyWord(" FORCE")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *DropResourceGroupStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropResourceGroupStmt)
+	return v.Leave(n)
+}
+
 // AlterSequenceStmt is a statement to alter sequence option.
 type AlterSequenceStmt struct {
 	ddlNode
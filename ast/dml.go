@@ -40,6 +40,7 @@ var (
 	_ Node = &GroupByClause{}
 	_ Node = &HavingClause{}
 	_ Node = &AsOfClause{}
+	_ Node = &SystemTimeClause{}
 	_ Node = &Join{}
 	_ Node = &Limit{}
 	_ Node = &OnCondition{}
@@ -275,6 +276,8 @@ type TableName struct {
 	TableSample    *TableSample
 	// AS OF is used to see the data as it was at a specific point in time.
 	AsOf *AsOfClause
+	// SystemTime is the FOR SYSTEM_TIME clause used to query system-versioned tables.
+	SystemTime *SystemTimeClause
 }
 
 func (*TableName) resultSet() {}
@@ -315,6 +318,16 @@ func (n *TableName) restorePartitions(ctx *format.RestoreCtx) {
 	}
 }
 
+func (n *TableName) restoreSystemTime(ctx *format.RestoreCtx) error {
+	if n.SystemTime != nil {
+		ctx.WritePlain(" ")
+		if err := n.SystemTime.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while splicing TableName.SystemTime")
+		}
+	}
+	return nil
+}
+
 func (n *TableName) restoreIndexHints(ctx *format.RestoreCtx) error {
 	for _, value := range n.IndexHints {
 		ctx.WritePlain(" ")
@@ -328,6 +341,9 @@ func (n *TableName) restoreIndexHints(ctx *format.RestoreCtx) error {
 func (n *TableName) Restore(ctx *format.RestoreCtx) error {
 	n.restoreName(ctx)
 	n.restorePartitions(ctx)
+	if err := n.restoreSystemTime(ctx); err != nil {
+		return err
+	}
 	if err := n.restoreIndexHints(ctx); err != nil {
 		return err
 	}
@@ -436,6 +452,13 @@ func (n *TableName) Accept(v Visitor) (Node, bool) {
 		}
 		n.AsOf = newNode.(*AsOfClause)
 	}
+	if n.SystemTime != nil {
+		newNode, ok := n.SystemTime.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.SystemTime = newNode.(*SystemTimeClause)
+	}
 	return v.Leave(n)
 }
 
@@ -537,6 +560,9 @@ func (n *TableSource) Restore(ctx *format.RestoreCtx) error {
 
 		tn.restoreName(ctx)
 		tn.restorePartitions(ctx)
+		if err := tn.restoreSystemTime(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore TableSource.Source.(*TableName).SystemTime")
+		}
 
 		if asName := n.AsName.String(); asName != "" {
 			ctx.WriteKeyWord(" AS ")
@@ -3493,3 +3519,105 @@ func (n *AsOfClause) Accept(v Visitor) (Node, bool) {
 	n.TsExpr = node.(ExprNode)
 	return v.Leave(n)
 }
+
+// SystemTimeType is the type of FOR SYSTEM_TIME clause.
+type SystemTimeType int
+
+// FOR SYSTEM_TIME clause types.
+const (
+	SystemTimeAsOf SystemTimeType = iota + 1
+	SystemTimeBetween
+	SystemTimeFromTo
+	SystemTimeAll
+)
+
+// SystemTimeUnit is the unit of a point in FOR SYSTEM_TIME clause.
+type SystemTimeUnit int
+
+// FOR SYSTEM_TIME point units.
+const (
+	SystemTimeUnitDefault SystemTimeUnit = iota
+	SystemTimeUnitTimestamp
+	SystemTimeUnitTransaction
+)
+
+func (u SystemTimeUnit) restore(ctx *format.RestoreCtx) {
+	switch u {
+	case SystemTimeUnitTimestamp:
+		ctx.WriteKeyWord("TIMESTAMP ")
+	case SystemTimeUnitTransaction:
+		ctx.WriteKeyWord("TRANSACTION ")
+	}
+}
+
+// SystemTimeClause is the FOR SYSTEM_TIME clause used to query the history of a system-versioned table.
+// See https://mariadb.com/kb/en/system-versioned-tables/#querying-historical-data
+type SystemTimeClause struct {
+	node
+
+	Tp SystemTimeType
+	// Start is the point of AS OF, or the start point of BETWEEN and FROM.
+	Start     ExprNode
+	StartUnit SystemTimeUnit
+	End       ExprNode
+	EndUnit   SystemTimeUnit
+}
+
+// Restore implements Node interface.
+func (n *SystemTimeClause) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("FOR SYSTEM_TIME ")
+	switch n.Tp {
+	case SystemTimeAll:
+		ctx.WriteKeyWord("ALL")
+		return nil
+	case SystemTimeAsOf:
+		ctx.WriteKeyWord("AS OF ")
+	case SystemTimeBetween:
+		ctx.WriteKeyWord("BETWEEN ")
+	case SystemTimeFromTo:
+		ctx.WriteKeyWord("FROM ")
+	default:
+		return errors.Errorf("Unsupported SystemTimeClause.Tp %d", n.Tp)
+	}
+	n.StartUnit.restore(ctx)
+	if err := n.Start.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore SystemTimeClause.Start")
+	}
+	if n.Tp == SystemTimeAsOf {
+		return nil
+	}
+	if n.Tp == SystemTimeBetween {
+		ctx.WriteKeyWord(" AND ")
+	} else {
+		ctx.WriteKeyWord(" TO ")
+	}
+	n.EndUnit.restore(ctx)
+	if err := n.End.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore SystemTimeClause.End")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *SystemTimeClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SystemTimeClause)
+	if n.Start != nil {
+		node, ok := n.Start.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Start = node.(ExprNode)
+	}
+	if n.End != nil {
+		node, ok := n.End.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.End = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
@@ -32,6 +32,8 @@ var (
 	_ StmtNode = &AlterUserStmt{}
 	_ StmtNode = &BeginStmt{}
 	_ StmtNode = &BinlogStmt{}
+	_ StmtNode = &ChangeReplicationFilterStmt{}
+	_ StmtNode = &ChangeReplicationSourceStmt{}
 	_ StmtNode = &CommitStmt{}
 	_ StmtNode = &CreateUserStmt{}
 	_ StmtNode = &DeallocateStmt{}
@@ -44,6 +46,7 @@ var (
 	_ StmtNode = &SetPwdStmt{}
 	_ StmtNode = &SetRoleStmt{}
 	_ StmtNode = &SetDefaultRoleStmt{}
+	_ StmtNode = &SetResourceGroupStmt{}
 	_ StmtNode = &SetStmt{}
 	_ StmtNode = &UseStmt{}
 	_ StmtNode = &FlushStmt{}
@@ -52,6 +55,12 @@ var (
 	_ StmtNode = &DropBindingStmt{}
 	_ StmtNode = &ShutdownStmt{}
 	_ StmtNode = &RestartStmt{}
+	_ StmtNode = &ResetMasterStmt{}
+	_ StmtNode = &ResetReplicaStmt{}
+	_ StmtNode = &StartReplicaStmt{}
+	_ StmtNode = &StopReplicaStmt{}
+	_ StmtNode = &SignalStmt{}
+	_ StmtNode = &GetDiagnosticsStmt{}
 	_ StmtNode = &RenameUserStmt{}
 	_ StmtNode = &HelpStmt{}
 	_ StmtNode = &PlanRecreatorStmt{}
@@ -97,25 +106,66 @@ type AuthOption struct {
 	AuthString   string
 	HashString   string
 	AuthPlugin   string
+	// RandomPassword is true for IDENTIFIED ... BY RANDOM PASSWORD.
+	RandomPassword bool
+	// ReplaceString is the current password given by the REPLACE clause of ALTER USER.
+	ReplaceString         string
+	RetainCurrentPassword bool
+	// DiscardOldPassword is true for ALTER USER ... DISCARD OLD PASSWORD, no other field is set then.
+	DiscardOldPassword bool
+	// Factors are the additional authentication factors given by AND IDENTIFIED ...
+	Factors []*AuthOption
 }
 
 // Restore implements Node interface.
 func (n *AuthOption) Restore(ctx *format.RestoreCtx) error {
+	if n.DiscardOldPassword {
+		ctx.WriteKeyWord("DISCARD OLD PASSWORD")
+		return nil
+	}
 	ctx.WriteKeyWord("IDENTIFIED")
 	if n.AuthPlugin != "" {
 		ctx.WriteKeyWord(" WITH ")
 		ctx.WriteString(n.AuthPlugin)
 	}
-	if n.ByAuthString {
+	if n.RandomPassword {
+		ctx.WriteKeyWord(" BY RANDOM PASSWORD")
+	} else if n.ByAuthString {
 		ctx.WriteKeyWord(" BY ")
 		ctx.WriteString(n.AuthString)
 	} else if n.HashString != "" {
 		ctx.WriteKeyWord(" AS ")
 		ctx.WriteString(n.HashString)
 	}
+	if n.ReplaceString != "" {
+		ctx.WriteKeyWord(" REPLACE ")
+		ctx.WriteString(n.ReplaceString)
+	}
+	if n.RetainCurrentPassword {
+		ctx.WriteKeyWord(" RETAIN CURRENT PASSWORD")
+	}
+	for i, factor := range n.Factors {
+		ctx.WriteKeyWord(" AND ")
+		if err := factor.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore AuthOption.Factors[%d]", i)
+		}
+	}
 	return nil
 }
 
+// hasSecret reports whether the option or any of its factors contains a password or hash.
+func (n *AuthOption) hasSecret() bool {
+	if len(n.AuthString) > 0 || len(n.HashString) > 0 || len(n.ReplaceString) > 0 {
+		return true
+	}
+	for _, factor := range n.Factors {
+		if factor.hasSecret() {
+			return true
+		}
+	}
+	return false
+}
+
 // TraceStmt is a statement to trace what sql actually does at background.
 type TraceStmt struct {
 	stmtNode
@@ -1004,6 +1054,41 @@ func (n *SetPwdStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SetResourceGroupStmt is a statement to assign threads to a resource group.
+// See https://dev.mysql.com/doc/refman/8.0/en/set-resource-group.html
+type SetResourceGroupStmt struct {
+	stmtNode
+
+	ResourceGroupName model.CIStr
+	// ThreadIDs is empty if the statement applies to the current thread.
+	ThreadIDs []uint64
+}
+
+// Restore implements Node interface.
+func (n *SetResourceGroupStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SET RESOURCE GROUP ")
+	ctx.WriteName(n.ResourceGroupName.O)
+	for i, id := range n.ThreadIDs {
+		if i == 0 {
+			ctx.WriteKeyWord(" FOR ")
+		} else {
+			ctx.WritePlain(", ")
+		}
+		ctx.WritePlainf("%d", id)
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *SetResourceGroupStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SetResourceGroupStmt)
+	return v.Leave(n)
+}
+
 type ChangeStmt struct {
 	stmtNode
 
@@ -1039,6 +1124,771 @@ func (n *ChangeStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ReplicationOption is an option of the CHANGE REPLICATION SOURCE statement,
+// or an UNTIL / connection option of the START REPLICA statement.
+// If neither Value nor Keyword is set, the value is the ServerIDs list.
+type ReplicationOption struct {
+	// Name is the upper case name of the option, e.g. SOURCE_HOST.
+	Name string
+	// Value is a literal value.
+	Value ExprNode
+	// Keyword is a keyword value such as ON, OFF or STREAM.
+	Keyword string
+	// User is the account value of PRIVILEGE_CHECKS_USER.
+	User *auth.UserIdentity
+	// ServerIDs is the value list of IGNORE_SERVER_IDS.
+	ServerIDs []int64
+	// NoValue is true for the options without a value, such as UNTIL SQL_AFTER_MTS_GAPS.
+	NoValue bool
+}
+
+// Restore implements Node interface.
+func (n *ReplicationOption) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord(n.Name)
+	if n.NoValue {
+		return nil
+	}
+	ctx.WritePlain(" = ")
+	switch {
+	case n.Value != nil:
+		// String values are written without the charset introducer as MySQL doesn't accept it here.
+		if v, ok := n.Value.(ValueExpr); ok {
+			if str, ok := v.GetValue().(string); ok {
+				ctx.WriteString(str)
+				break
+			}
+		}
+		if err := n.Value.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore ReplicationOption.Value")
+		}
+	case n.Keyword != "":
+		ctx.WriteKeyWord(n.Keyword)
+	case n.User != nil:
+		if err := n.User.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore ReplicationOption.User")
+		}
+	default:
+		ctx.WritePlain("(")
+		for i, id := range n.ServerIDs {
+			if i != 0 {
+				ctx.WritePlain(",")
+			}
+			ctx.WritePlainf("%d", id)
+		}
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
+// IsPassword reports whether the option carries a password.
+func (n *ReplicationOption) IsPassword() bool {
+	switch n.Name {
+	case "PASSWORD", "MASTER_PASSWORD", "SOURCE_PASSWORD":
+		return true
+	}
+	return false
+}
+
+// secureReplicationOptions returns a copy of opts with the password values masked.
+func secureReplicationOptions(opts []*ReplicationOption) []*ReplicationOption {
+	secured := make([]*ReplicationOption, 0, len(opts))
+	for _, opt := range opts {
+		if opt.IsPassword() {
+			opt = &ReplicationOption{Name: opt.Name, Value: NewValueExpr("******", "", "")}
+		}
+		secured = append(secured, opt)
+	}
+	return secured
+}
+
+func acceptReplicationOptions(v Visitor, opts []*ReplicationOption) bool {
+	for _, opt := range opts {
+		if opt.Value == nil {
+			continue
+		}
+		node, ok := opt.Value.Accept(v)
+		if !ok {
+			return false
+		}
+		opt.Value = node.(ExprNode)
+	}
+	return true
+}
+
+func restoreReplicationOptions(ctx *format.RestoreCtx, opts []*ReplicationOption, sep string, field string) error {
+	for i, opt := range opts {
+		if i != 0 {
+			ctx.WritePlain(sep)
+		}
+		if err := opt.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore %s[%d]", field, i)
+		}
+	}
+	return nil
+}
+
+func restoreReplicationChannel(ctx *format.RestoreCtx, channel string) {
+	if channel != "" {
+		ctx.WriteKeyWord(" FOR CHANNEL ")
+		ctx.WriteString(channel)
+	}
+}
+
+// ChangeReplicationSourceStmt is a statement to change the replication source of the replica.
+// See https://dev.mysql.com/doc/refman/8.0/en/change-replication-source-to.html
+type ChangeReplicationSourceStmt struct {
+	stmtNode
+
+	// IsMaster is true if the statement is written as CHANGE MASTER TO.
+	IsMaster bool
+	Options  []*ReplicationOption
+	Channel  string
+}
+
+// Restore implements Node interface.
+func (n *ChangeReplicationSourceStmt) Restore(ctx *format.RestoreCtx) error {
+	if n.IsMaster {
+		ctx.WriteKeyWord("CHANGE MASTER TO ")
+	} else {
+		ctx.WriteKeyWord("CHANGE REPLICATION SOURCE TO ")
+	}
+	if err := restoreReplicationOptions(ctx, n.Options, ", ", "ChangeReplicationSourceStmt.Options"); err != nil {
+		return err
+	}
+	restoreReplicationChannel(ctx, n.Channel)
+	return nil
+}
+
+// SecureText implements SensitiveStmtNode
+func (n *ChangeReplicationSourceStmt) SecureText() string {
+	redactedStmt := &ChangeReplicationSourceStmt{
+		IsMaster: n.IsMaster,
+		Options:  secureReplicationOptions(n.Options),
+		Channel:  n.Channel,
+	}
+
+	var sb strings.Builder
+	_ = redactedStmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb))
+	return sb.String()
+}
+
+// Accept implements Node Accept interface.
+func (n *ChangeReplicationSourceStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ChangeReplicationSourceStmt)
+	if !acceptReplicationOptions(v, n.Options) {
+		return n, false
+	}
+	return v.Leave(n)
+}
+
+// ReplicationFilterType is the type of a replication filter rule.
+type ReplicationFilterType int
+
+// Replication filter types.
+const (
+	ReplicateDoDB ReplicationFilterType = iota + 1
+	ReplicateIgnoreDB
+	ReplicateDoTable
+	ReplicateIgnoreTable
+	ReplicateWildDoTable
+	ReplicateWildIgnoreTable
+	ReplicateRewriteDB
+)
+
+// String implements fmt.Stringer interface.
+func (t ReplicationFilterType) String() string {
+	switch t {
+	case ReplicateDoDB:
+		return "REPLICATE_DO_DB"
+	case ReplicateIgnoreDB:
+		return "REPLICATE_IGNORE_DB"
+	case ReplicateDoTable:
+		return "REPLICATE_DO_TABLE"
+	case ReplicateIgnoreTable:
+		return "REPLICATE_IGNORE_TABLE"
+	case ReplicateWildDoTable:
+		return "REPLICATE_WILD_DO_TABLE"
+	case ReplicateWildIgnoreTable:
+		return "REPLICATE_WILD_IGNORE_TABLE"
+	case ReplicateRewriteDB:
+		return "REPLICATE_REWRITE_DB"
+	}
+	return ""
+}
+
+// ReplicationFilterTypeFromName returns the filter type of the name, it's case insensitive.
+// The boolean value is false if the name is not a replication filter.
+func ReplicationFilterTypeFromName(name string) (ReplicationFilterType, bool) {
+	name = strings.ToUpper(name)
+	for t := ReplicateDoDB; t <= ReplicateRewriteDB; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// ReplicationRewrite is a (from_db, to_db) pair of the REPLICATE_REWRITE_DB filter.
+type ReplicationRewrite struct {
+	From model.CIStr
+	To   model.CIStr
+}
+
+// ReplicationFilter is a filter rule of the CHANGE REPLICATION FILTER statement.
+// An empty value list clears the rule.
+type ReplicationFilter struct {
+	Tp ReplicationFilterType
+	// DBNames is used by REPLICATE_DO_DB and REPLICATE_IGNORE_DB.
+	DBNames []model.CIStr
+	// Tables is used by REPLICATE_DO_TABLE and REPLICATE_IGNORE_TABLE.
+	Tables []*TableName
+	// Patterns is used by REPLICATE_WILD_DO_TABLE and REPLICATE_WILD_IGNORE_TABLE.
+	Patterns []string
+	// Rewrites is used by REPLICATE_REWRITE_DB.
+	Rewrites []*ReplicationRewrite
+}
+
+// Restore implements Node interface.
+func (n *ReplicationFilter) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord(n.Tp.String())
+	ctx.WritePlain(" = (")
+	switch n.Tp {
+	case ReplicateDoDB, ReplicateIgnoreDB:
+		for i, name := range n.DBNames {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WriteName(name.O)
+		}
+	case ReplicateDoTable, ReplicateIgnoreTable:
+		for i, table := range n.Tables {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			if err := table.Restore(ctx); err != nil {
+				return errors.Annotatef(err, "An error occurred while restore ReplicationFilter.Tables[%d]", i)
+			}
+		}
+	case ReplicateWildDoTable, ReplicateWildIgnoreTable:
+		for i, pattern := range n.Patterns {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WriteString(pattern)
+		}
+	case ReplicateRewriteDB:
+		for i, rewrite := range n.Rewrites {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WritePlain("(")
+			ctx.WriteName(rewrite.From.O)
+			ctx.WritePlain(", ")
+			ctx.WriteName(rewrite.To.O)
+			ctx.WritePlain(")")
+		}
+	default:
+		return errors.Errorf("Unsupported ReplicationFilter.Tp %d", n.Tp)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// ChangeReplicationFilterStmt is a statement to set the replication filter rules.
+// See https://dev.mysql.com/doc/refman/8.0/en/change-replication-filter.html
+type ChangeReplicationFilterStmt struct {
+	stmtNode
+
+	Filters []*ReplicationFilter
+	Channel string
+}
+
+// Restore implements Node interface.
+func (n *ChangeReplicationFilterStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CHANGE REPLICATION FILTER ")
+	for i, filter := range n.Filters {
+		if i != 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := filter.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore ChangeReplicationFilterStmt.Filters[%d]", i)
+		}
+	}
+	restoreReplicationChannel(ctx, n.Channel)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *ChangeReplicationFilterStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ChangeReplicationFilterStmt)
+	for _, filter := range n.Filters {
+		for i, val := range filter.Tables {
+			node, ok := val.Accept(v)
+			if !ok {
+				return n, false
+			}
+			filter.Tables[i] = node.(*TableName)
+		}
+	}
+	return v.Leave(n)
+}
+
+// ReplicaThreadType is the type of a replication thread.
+type ReplicaThreadType int
+
+// Replication thread types.
+const (
+	ReplicaIOThread ReplicaThreadType = iota + 1
+	ReplicaSQLThread
+)
+
+// String implements fmt.Stringer interface.
+func (t ReplicaThreadType) String() string {
+	switch t {
+	case ReplicaIOThread:
+		return "IO_THREAD"
+	case ReplicaSQLThread:
+		return "SQL_THREAD"
+	}
+	return ""
+}
+
+func restoreReplicaKeyword(ctx *format.RestoreCtx, isSlave bool) {
+	if isSlave {
+		ctx.WriteKeyWord("SLAVE")
+	} else {
+		ctx.WriteKeyWord("REPLICA")
+	}
+}
+
+func restoreReplicaThreadTypes(ctx *format.RestoreCtx, threadTypes []ReplicaThreadType) {
+	for i, tp := range threadTypes {
+		if i != 0 {
+			ctx.WritePlain(",")
+		}
+		ctx.WritePlain(" ")
+		ctx.WriteKeyWord(tp.String())
+	}
+}
+
+// StartReplicaStmt is a statement to start the replication threads.
+// See https://dev.mysql.com/doc/refman/8.0/en/start-replica.html
+type StartReplicaStmt struct {
+	stmtNode
+
+	// IsSlave is true if the statement is written as START SLAVE.
+	IsSlave     bool
+	ThreadTypes []ReplicaThreadType
+	// UntilOptions are the conditions of the UNTIL clause.
+	UntilOptions []*ReplicationOption
+	// ConnOptions are the USER, PASSWORD, DEFAULT_AUTH and PLUGIN_DIR options.
+	ConnOptions []*ReplicationOption
+	Channel     string
+}
+
+// Restore implements Node interface.
+func (n *StartReplicaStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("START ")
+	restoreReplicaKeyword(ctx, n.IsSlave)
+	restoreReplicaThreadTypes(ctx, n.ThreadTypes)
+	if len(n.UntilOptions) != 0 {
+		ctx.WriteKeyWord(" UNTIL ")
+		if err := restoreReplicationOptions(ctx, n.UntilOptions, ", ", "StartReplicaStmt.UntilOptions"); err != nil {
+			return err
+		}
+	}
+	for i, opt := range n.ConnOptions {
+		ctx.WritePlain(" ")
+		if err := opt.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore StartReplicaStmt.ConnOptions[%d]", i)
+		}
+	}
+	restoreReplicationChannel(ctx, n.Channel)
+	return nil
+}
+
+// SecureText implements SensitiveStmtNode
+func (n *StartReplicaStmt) SecureText() string {
+	redactedStmt := *n
+	redactedStmt.ConnOptions = secureReplicationOptions(n.ConnOptions)
+
+	var sb strings.Builder
+	_ = redactedStmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb))
+	return sb.String()
+}
+
+// Accept implements Node Accept interface.
+func (n *StartReplicaStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*StartReplicaStmt)
+	if !acceptReplicationOptions(v, n.UntilOptions) || !acceptReplicationOptions(v, n.ConnOptions) {
+		return n, false
+	}
+	return v.Leave(n)
+}
+
+// StopReplicaStmt is a statement to stop the replication threads.
+// See https://dev.mysql.com/doc/refman/8.0/en/stop-replica.html
+type StopReplicaStmt struct {
+	stmtNode
+
+	// IsSlave is true if the statement is written as STOP SLAVE.
+	IsSlave     bool
+	ThreadTypes []ReplicaThreadType
+	Channel     string
+}
+
+// Restore implements Node interface.
+func (n *StopReplicaStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("STOP ")
+	restoreReplicaKeyword(ctx, n.IsSlave)
+	restoreReplicaThreadTypes(ctx, n.ThreadTypes)
+	restoreReplicationChannel(ctx, n.Channel)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *StopReplicaStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*StopReplicaStmt)
+	return v.Leave(n)
+}
+
+// ResetMasterStmt is a statement to delete all the binary log files.
+// See https://dev.mysql.com/doc/refman/8.0/en/reset-master.html
+type ResetMasterStmt struct {
+	stmtNode
+
+	// BinlogIndex is the number of the first binary log file after reset, 0 means not specified.
+	BinlogIndex uint64
+}
+
+// Restore implements Node interface.
+func (n *ResetMasterStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("RESET MASTER")
+	if n.BinlogIndex != 0 {
+		ctx.WriteKeyWord(" TO ")
+		ctx.WritePlainf("%d", n.BinlogIndex)
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *ResetMasterStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ResetMasterStmt)
+	return v.Leave(n)
+}
+
+// ResetReplicaStmt is a statement to reset the replication position of the replica.
+// See https://dev.mysql.com/doc/refman/8.0/en/reset-replica.html
+type ResetReplicaStmt struct {
+	stmtNode
+
+	// IsSlave is true if the statement is written as RESET SLAVE.
+	IsSlave bool
+	// All is true if the connection parameters are cleared too.
+	All     bool
+	Channel string
+}
+
+// Restore implements Node interface.
+func (n *ResetReplicaStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("RESET ")
+	restoreReplicaKeyword(ctx, n.IsSlave)
+	if n.All {
+		ctx.WriteKeyWord(" ALL")
+	}
+	restoreReplicationChannel(ctx, n.Channel)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *ResetReplicaStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ResetReplicaStmt)
+	return v.Leave(n)
+}
+
+// ConditionInfoItem is the name of a condition information item in the diagnostics area.
+// See https://dev.mysql.com/doc/refman/8.0/en/get-diagnostics.html
+type ConditionInfoItem int
+
+// Condition information items.
+const (
+	ConditionClassOrigin ConditionInfoItem = iota + 1
+	ConditionSubclassOrigin
+	ConditionReturnedSQLState
+	ConditionMessageText
+	ConditionMySQLErrno
+	ConditionConstraintCatalog
+	ConditionConstraintSchema
+	ConditionConstraintName
+	ConditionCatalogName
+	ConditionSchemaName
+	ConditionTableName
+	ConditionColumnName
+	ConditionCursorName
+)
+
+var conditionInfoItemNames = map[ConditionInfoItem]string{
+	ConditionClassOrigin:       "CLASS_ORIGIN",
+	ConditionSubclassOrigin:    "SUBCLASS_ORIGIN",
+	ConditionReturnedSQLState:  "RETURNED_SQLSTATE",
+	ConditionMessageText:       "MESSAGE_TEXT",
+	ConditionMySQLErrno:        "MYSQL_ERRNO",
+	ConditionConstraintCatalog: "CONSTRAINT_CATALOG",
+	ConditionConstraintSchema:  "CONSTRAINT_SCHEMA",
+	ConditionConstraintName:    "CONSTRAINT_NAME",
+	ConditionCatalogName:       "CATALOG_NAME",
+	ConditionSchemaName:        "SCHEMA_NAME",
+	ConditionTableName:         "TABLE_NAME",
+	ConditionColumnName:        "COLUMN_NAME",
+	ConditionCursorName:        "CURSOR_NAME",
+}
+
+// String implements fmt.Stringer interface.
+func (item ConditionInfoItem) String() string {
+	return conditionInfoItemNames[item]
+}
+
+// ConditionInfoItemFromName returns the condition information item with the given name.
+// The second return value is false if the name is unknown.
+func ConditionInfoItemFromName(name string) (ConditionInfoItem, bool) {
+	name = strings.ToUpper(name)
+	for item, n := range conditionInfoItemNames {
+		if n == name {
+			return item, true
+		}
+	}
+	return 0, false
+}
+
+// StatementInfoItem is the name of a statement information item in the diagnostics area.
+type StatementInfoItem int
+
+// Statement information items.
+const (
+	StatementNumber StatementInfoItem = iota + 1
+	StatementRowCount
+)
+
+// String implements fmt.Stringer interface.
+func (item StatementInfoItem) String() string {
+	switch item {
+	case StatementNumber:
+		return "NUMBER"
+	case StatementRowCount:
+		return "ROW_COUNT"
+	}
+	return ""
+}
+
+// StatementInfoItemFromName returns the statement information item with the given name.
+// The second return value is false if the name is unknown.
+func StatementInfoItemFromName(name string) (StatementInfoItem, bool) {
+	switch strings.ToUpper(name) {
+	case "NUMBER":
+		return StatementNumber, true
+	case "ROW_COUNT":
+		return StatementRowCount, true
+	}
+	return 0, false
+}
+
+// SignalInfoItem is a condition information item assignment in SIGNAL or RESIGNAL.
+type SignalInfoItem struct {
+	Name  ConditionInfoItem
+	Value ExprNode
+}
+
+// SignalStmt is a statement to raise or re-raise a condition.
+// See https://dev.mysql.com/doc/refman/8.0/en/signal.html
+// and https://dev.mysql.com/doc/refman/8.0/en/resignal.html
+type SignalStmt struct {
+	stmtNode
+
+	IsResignal bool
+	// SQLState is set if the condition is given as SQLSTATE value.
+	SQLState string
+	// ConditionName is set if the condition is given by a named condition.
+	ConditionName string
+	Infos         []*SignalInfoItem
+}
+
+// Restore implements Node interface.
+func (n *SignalStmt) Restore(ctx *format.RestoreCtx) error {
+	if n.IsResignal {
+		ctx.WriteKeyWord("RESIGNAL")
+	} else {
+		ctx.WriteKeyWord("SIGNAL")
+	}
+	if n.SQLState != "" {
+		ctx.WriteKeyWord(" SQLSTATE ")
+		ctx.WriteString(n.SQLState)
+	} else if n.ConditionName != "" {
+		ctx.WritePlain(" ")
+		ctx.WriteName(n.ConditionName)
+	}
+	for i, info := range n.Infos {
+		if i == 0 {
+			ctx.WriteKeyWord(" SET ")
+		} else {
+			ctx.WritePlain(", ")
+		}
+		ctx.WriteKeyWord(info.Name.String())
+		ctx.WritePlain(" = ")
+		if err := info.Value.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore SignalStmt.Infos[%d]", i)
+		}
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *SignalStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SignalStmt)
+	for _, info := range n.Infos {
+		node, ok := info.Value.Accept(v)
+		if !ok {
+			return n, false
+		}
+		info.Value = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
+// DiagnosticsArea is the diagnostics area read by GET DIAGNOSTICS.
+type DiagnosticsArea int
+
+// Diagnostics areas.
+const (
+	DiagnosticsAreaDefault DiagnosticsArea = iota
+	DiagnosticsAreaCurrent
+	DiagnosticsAreaStacked
+)
+
+// DiagnosticsStatementItem assigns a statement information item to a target.
+type DiagnosticsStatementItem struct {
+	Target ExprNode
+	Name   StatementInfoItem
+}
+
+// DiagnosticsConditionItem assigns a condition information item to a target.
+type DiagnosticsConditionItem struct {
+	Target ExprNode
+	Name   ConditionInfoItem
+}
+
+// GetDiagnosticsStmt is a statement to retrieve information from the diagnostics area.
+// See https://dev.mysql.com/doc/refman/8.0/en/get-diagnostics.html
+type GetDiagnosticsStmt struct {
+	stmtNode
+
+	Area DiagnosticsArea
+	// ConditionNumber is nil when statement information is retrieved.
+	ConditionNumber ExprNode
+	StatementItems  []*DiagnosticsStatementItem
+	ConditionItems  []*DiagnosticsConditionItem
+}
+
+// Restore implements Node interface.
+func (n *GetDiagnosticsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("GET ")
+	switch n.Area {
+	case DiagnosticsAreaCurrent:
+		ctx.WriteKeyWord("CURRENT ")
+	case DiagnosticsAreaStacked:
+		ctx.WriteKeyWord("STACKED ")
+	}
+	ctx.WriteKeyWord("DIAGNOSTICS ")
+	if n.ConditionNumber == nil {
+		for i, item := range n.StatementItems {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			if err := item.Target.Restore(ctx); err != nil {
+				return errors.Annotatef(err, "An error occurred while restore GetDiagnosticsStmt.StatementItems[%d]", i)
+			}
+			ctx.WritePlain(" = ")
+			ctx.WriteKeyWord(item.Name.String())
+		}
+		return nil
+	}
+	ctx.WriteKeyWord("CONDITION ")
+	if err := n.ConditionNumber.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore GetDiagnosticsStmt.ConditionNumber")
+	}
+	for i, item := range n.ConditionItems {
+		if i == 0 {
+			ctx.WritePlain(" ")
+		} else {
+			ctx.WritePlain(", ")
+		}
+		if err := item.Target.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore GetDiagnosticsStmt.ConditionItems[%d]", i)
+		}
+		ctx.WritePlain(" = ")
+		ctx.WriteKeyWord(item.Name.String())
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *GetDiagnosticsStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*GetDiagnosticsStmt)
+	if n.ConditionNumber != nil {
+		node, ok := n.ConditionNumber.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.ConditionNumber = node.(ExprNode)
+	}
+	for _, item := range n.StatementItems {
+		node, ok := item.Target.Accept(v)
+		if !ok {
+			return n, false
+		}
+		item.Target = node.(ExprNode)
+	}
+	for _, item := range n.ConditionItems {
+		node, ok := item.Target.Accept(v)
+		if !ok {
+			return n, false
+		}
+		item.Target = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
 // SetRoleStmtType is the type for FLUSH statement.
 type SetRoleStmtType int
 
@@ -1167,13 +2017,7 @@ func (n *UserSpec) Restore(ctx *format.RestoreCtx) error {
 
 // SecurityString formats the UserSpec without password information.
 func (n *UserSpec) SecurityString() string {
-	withPassword := false
-	if opt := n.AuthOpt; opt != nil {
-		if len(opt.AuthString) > 0 || len(opt.HashString) > 0 {
-			withPassword = true
-		}
-	}
-	if withPassword {
+	if n.AuthOpt != nil && n.AuthOpt.hasSecret() {
 		return fmt.Sprintf("{%s password = ***}", n.User)
 	}
 	return n.User.String()
@@ -1295,6 +2139,16 @@ const (
 	PasswordExpireInterval
 	Lock
 	Unlock
+	FailedLoginAttempts
+	PasswordLockTime
+	PasswordLockTimeUnbounded
+	PasswordHistory
+	PasswordHistoryDefault
+	PasswordReuseInterval
+	PasswordReuseDefault
+	PasswordRequireCurrent
+	PasswordRequireCurrentDefault
+	PasswordRequireCurrentOptional
 )
 
 type PasswordOrLockOption struct {
@@ -1318,23 +2172,81 @@ func (p *PasswordOrLockOption) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord("ACCOUNT LOCK")
 	case Unlock:
 		ctx.WriteKeyWord("ACCOUNT UNLOCK")
+	case FailedLoginAttempts:
+		ctx.WriteKeyWord("FAILED_LOGIN_ATTEMPTS")
+		ctx.WritePlainf(" %d", p.Count)
+	case PasswordLockTime:
+		ctx.WriteKeyWord("PASSWORD_LOCK_TIME")
+		ctx.WritePlainf(" %d", p.Count)
+	case PasswordLockTimeUnbounded:
+		ctx.WriteKeyWord("PASSWORD_LOCK_TIME UNBOUNDED")
+	case PasswordHistory:
+		ctx.WriteKeyWord("PASSWORD HISTORY")
+		ctx.WritePlainf(" %d", p.Count)
+	case PasswordHistoryDefault:
+		ctx.WriteKeyWord("PASSWORD HISTORY DEFAULT")
+	case PasswordReuseInterval:
+		ctx.WriteKeyWord("PASSWORD REUSE INTERVAL")
+		ctx.WritePlainf(" %d", p.Count)
+		ctx.WriteKeyWord(" DAY")
+	case PasswordReuseDefault:
+		ctx.WriteKeyWord("PASSWORD REUSE INTERVAL DEFAULT")
+	case PasswordRequireCurrent:
+		ctx.WriteKeyWord("PASSWORD REQUIRE CURRENT")
+	case PasswordRequireCurrentDefault:
+		ctx.WriteKeyWord("PASSWORD REQUIRE CURRENT DEFAULT")
+	case PasswordRequireCurrentOptional:
+		ctx.WriteKeyWord("PASSWORD REQUIRE CURRENT OPTIONAL")
 	default:
 		return errors.Errorf("Unsupported PasswordOrLockOption.Type %d", p.Type)
 	}
 	return nil
 }
 
+// CommentOrAttributeType is the type of CommentOrAttributeOption.
+type CommentOrAttributeType int
+
+// CommentOrAttributeOption types.
+const (
+	// UserCommentType is the COMMENT clause of a user.
+	UserCommentType CommentOrAttributeType = iota + 1
+	// UserAttributeType is the ATTRIBUTE clause of a user.
+	UserAttributeType
+)
+
+// CommentOrAttributeOption is the COMMENT or ATTRIBUTE clause of CREATE USER and ALTER USER.
+// The ATTRIBUTE value is a JSON object string.
+type CommentOrAttributeOption struct {
+	Type  CommentOrAttributeType
+	Value string
+}
+
+// Restore implements Node interface.
+func (c *CommentOrAttributeOption) Restore(ctx *format.RestoreCtx) error {
+	switch c.Type {
+	case UserCommentType:
+		ctx.WriteKeyWord("COMMENT ")
+	case UserAttributeType:
+		ctx.WriteKeyWord("ATTRIBUTE ")
+	default:
+		return errors.Errorf("Unsupported CommentOrAttributeOption.Type %d", c.Type)
+	}
+	ctx.WriteString(c.Value)
+	return nil
+}
+
 // CreateUserStmt creates user account.
 // See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 type CreateUserStmt struct {
 	stmtNode
 
-	IsCreateRole          bool
-	IfNotExists           bool
-	Specs                 []*UserSpec
-	TLSOptions            []*TLSOption
-	ResourceOptions       []*ResourceOption
-	PasswordOrLockOptions []*PasswordOrLockOption
+	IsCreateRole             bool
+	IfNotExists              bool
+	Specs                    []*UserSpec
+	TLSOptions               []*TLSOption
+	ResourceOptions          []*ResourceOption
+	PasswordOrLockOptions    []*PasswordOrLockOption
+	CommentOrAttributeOption *CommentOrAttributeOption
 }
 
 // Restore implements Node interface.
@@ -1386,6 +2298,13 @@ func (n *CreateUserStmt) Restore(ctx *format.RestoreCtx) error {
 			return errors.Annotatef(err, "An error occurred while restore CreateUserStmt.PasswordOrLockOptions[%d]", i)
 		}
 	}
+
+	if n.CommentOrAttributeOption != nil {
+		ctx.WritePlain(" ")
+		if err := n.CommentOrAttributeOption.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore CreateUserStmt.CommentOrAttributeOption")
+		}
+	}
 	return nil
 }
 
@@ -1415,12 +2334,13 @@ func (n *CreateUserStmt) SecureText() string {
 type AlterUserStmt struct {
 	stmtNode
 
-	IfExists              bool
-	CurrentAuth           *AuthOption
-	Specs                 []*UserSpec
-	TLSOptions            []*TLSOption
-	ResourceOptions       []*ResourceOption
-	PasswordOrLockOptions []*PasswordOrLockOption
+	IfExists                 bool
+	CurrentAuth              *AuthOption
+	Specs                    []*UserSpec
+	TLSOptions               []*TLSOption
+	ResourceOptions          []*ResourceOption
+	PasswordOrLockOptions    []*PasswordOrLockOption
+	CommentOrAttributeOption *CommentOrAttributeOption
 }
 
 // Restore implements Node interface.
@@ -1475,6 +2395,13 @@ func (n *AlterUserStmt) Restore(ctx *format.RestoreCtx) error {
 			return errors.Annotatef(err, "An error occurred while restore AlterUserStmt.PasswordOrLockOptions[%d]", i)
 		}
 	}
+
+	if n.CommentOrAttributeOption != nil {
+		ctx.WritePlain(" ")
+		if err := n.CommentOrAttributeOption.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore AlterUserStmt.CommentOrAttributeOption")
+		}
+	}
 	return nil
 }
 
@@ -151,6 +151,8 @@ func (ts *testMiscSuite) TestSensitiveStatement(c *C) {
 		&ast.CreateUserStmt{},
 		&ast.AlterUserStmt{},
 		&ast.GrantStmt{},
+		&ast.ChangeReplicationSourceStmt{},
+		&ast.StartReplicaStmt{},
 	}
 	for i, stmt := range positive {
 		_, ok := stmt.(ast.SensitiveStmtNode)
@@ -169,6 +171,8 @@ func (ts *testMiscSuite) TestSensitiveStatement(c *C) {
 		&ast.DropTableStmt{},
 		&ast.RenameTableStmt{},
 		&ast.TruncateTableStmt{},
+		&ast.StopReplicaStmt{},
+		&ast.ResetReplicaStmt{},
 	}
 	for _, stmt := range negative {
 		_, ok := stmt.(ast.SensitiveStmtNode)
@@ -329,3 +333,76 @@ func (ts *testMiscSuite) TestBRIESecureText(c *C) {
 		c.Assert(n.SecureText(), Matches, tc.secured, comment)
 	}
 }
+
+func (ts *testMiscSuite) TestReplicationSecureText(c *C) {
+	testCases := []struct {
+		input   string
+		secured string
+	}{
+		{
+			input:   "change replication source to source_host = 'h', source_password = 'secret', source_port = 3306",
+			secured: "CHANGE REPLICATION SOURCE TO SOURCE_HOST = 'h', SOURCE_PASSWORD = '******', SOURCE_PORT = 3306",
+		},
+		{
+			input:   "change master to master_user = 'u', master_password = 'secret' for channel 'c'",
+			secured: "CHANGE MASTER TO MASTER_USER = 'u', MASTER_PASSWORD = '******' FOR CHANNEL 'c'",
+		},
+		{
+			input:   "start replica sql_thread user = 'u' password = 'secret'",
+			secured: "START REPLICA SQL_THREAD USER = 'u' PASSWORD = '******'",
+		},
+	}
+
+	p := parser.New()
+	for _, tc := range testCases {
+		comment := Commentf("input = %s", tc.input)
+		node, err := p.ParseOneStmt(tc.input, "", "")
+		c.Assert(err, IsNil, comment)
+		n, ok := node.(ast.SensitiveStmtNode)
+		c.Assert(ok, IsTrue, comment)
+		c.Assert(n.SecureText(), Equals, tc.secured, comment)
+		c.Assert(n.SecureText(), Not(Matches), ".*secret.*", comment)
+	}
+}
+
+func (ts *testMiscSuite) TestUserSecureText(c *C) {
+	testCases := []struct {
+		input   string
+		secured string
+	}{
+		{
+			input:   "create user u identified by 'secret' and identified with 'authentication_ldap_sasl' as 'secret_hash'",
+			secured: "create user {u@% password = ***}",
+		},
+		{
+			input:   "create user u identified with 'caching_sha2_password' and identified with 'authentication_fido'",
+			secured: "create user u@%",
+		},
+		{
+			input:   "create user u identified by random password",
+			secured: "create user u@%",
+		},
+		{
+			input:   "create user u identified with 'mysql_native_password' and identified by 'secret'",
+			secured: "create user {u@% password = ***}",
+		},
+		{
+			input:   "alter user u identified by random password replace 'secret' retain current password",
+			secured: "alter user {u@% password = ***}",
+		},
+		{
+			input:   "alter user u discard old password",
+			secured: "alter user u@%",
+		},
+	}
+
+	p := parser.New()
+	for _, tc := range testCases {
+		comment := Commentf("input = %s", tc.input)
+		node, err := p.ParseOneStmt(tc.input, "", "")
+		c.Assert(err, IsNil, comment)
+		n, ok := node.(ast.SensitiveStmtNode)
+		c.Assert(ok, IsTrue, comment)
+		c.Assert(n.SecureText(), Equals, tc.secured, comment)
+	}
+}
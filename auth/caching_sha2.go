@@ -34,10 +34,15 @@ package auth
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 )
 
 const (
@@ -46,6 +51,16 @@ const (
 	ITERATION_MULTIPLIER = 1000
 )
 
+// The packets of the caching_sha2_password authentication exchange.
+const (
+	// Sha2RequestPublicKey is sent by the client to request the RSA public key of the server.
+	Sha2RequestPublicKey = 2
+	// Sha2FastAuthSuccess is sent by the server when the fast authentication succeeds.
+	Sha2FastAuthSuccess = 3
+	// Sha2PerformFullAuthentication is sent by the server when the fast authentication can't be used.
+	Sha2PerformFullAuthentication = 4
+)
+
 func b64From24bit(b []byte, n int) []byte {
 	b64t := []byte("./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
 
@@ -203,6 +218,11 @@ func CheckShaPassword(pwhash []byte, password string) (bool, error) {
 }
 
 func NewSha2Password(pwd string) string {
+	return sha256crypt(pwd, newSha2Salt(), 5*ITERATION_MULTIPLIER)
+}
+
+// newSha2Salt generates a random salt of 7-bit characters except '$' and NUL.
+func newSha2Salt() []byte {
 	salt := make([]byte, SALT_LENGTH)
 	rand.Read(salt)
 
@@ -215,6 +235,137 @@ func NewSha2Password(pwd string) string {
 			salt[i] = newval[0] &^ 128
 		}
 	}
+	return salt
+}
+
+// Sha256Hash is an util function to calculate sha256 hash.
+func Sha256Hash(bs []byte) []byte {
+	sum := sha256.Sum256(bs)
+	return sum[:]
+}
+
+// EncodeSha2FastAuthPassword returns SHA256(SHA256(pwd)), which is cached by
+// the server after a full authentication to check the scrambles of the fast
+// authentication.
+func EncodeSha2FastAuthPassword(pwd string) []byte {
+	return Sha256Hash(Sha256Hash([]byte(pwd)))
+}
+
+// CheckSha2ScrambledPassword checks the scrambled password received from the
+// client in the caching_sha2_password fast authentication.
+//   SERVER:  public_seed=create_random_string()
+//            send(public_seed)
+//   CLIENT:  recv(public_seed)
+//            hash_stage1=sha256("password")
+//            hash_stage2=sha256(hash_stage1)
+//            reply=xor(hash_stage1, sha256(hash_stage2,public_seed))
+//            send(reply)
+//   SERVER:  recv(reply)
+//            hash_stage1=xor(reply, sha256(hash_stage2,public_seed))
+//            candidate_hash2=sha256(hash_stage1)
+//            check(candidate_hash2==hash_stage2)
+// hpwd is hash_stage2, see EncodeSha2FastAuthPassword.
+func CheckSha2ScrambledPassword(salt, hpwd, auth []byte) bool {
+	crypt := sha256.New()
+	crypt.Write(hpwd)
+	crypt.Write(salt)
+	hash := crypt.Sum(nil)
+	if len(auth) != len(hash) {
+		return false
+	}
+	for i := range hash {
+		hash[i] ^= auth[i]
+	}
+
+	return bytes.Equal(hpwd, Sha256Hash(hash))
+}
+
+// DecryptSha2Password decrypts the password sent by the client in the
+// caching_sha2_password full authentication over an insecure connection. The
+// client XORs the NUL terminated password with the salt, and encrypts it with
+// the RSA public key of the server using OAEP padding.
+func DecryptSha2Password(key *rsa.PrivateKey, salt, encrypted []byte) (string, error) {
+	if len(salt) == 0 {
+		return "", errors.New("empty salt")
+	}
+	plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, encrypted, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt the password")
+	}
+	for i := range plain {
+		plain[i] ^= salt[i%len(salt)]
+	}
+	return string(TrimNulTerminator(plain)), nil
+}
 
-	return sha256crypt(pwd, salt, 5*ITERATION_MULTIPLIER)
+// TrimNulTerminator trims the NUL terminator of a password sent by the
+// client, e.g. in the full authentication over a secure connection.
+func TrimNulTerminator(pwd []byte) []byte {
+	if n := len(pwd); n > 0 && pwd[n-1] == 0 {
+		return pwd[:n-1]
+	}
+	return pwd
+}
+
+// MarshalPublicKeyPEM encodes the RSA public key of the server in the PEM
+// format which is sent to the client requesting it.
+func MarshalPublicKeyPEM(key *rsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Sha2Cache is the in-memory cache of the caching_sha2_password fast
+// authentication. It caches the hash_stage2 of the accounts which passed a
+// full authentication. It is safe for concurrent use.
+type Sha2Cache struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+// NewSha2Cache creates an empty Sha2Cache.
+func NewSha2Cache() *Sha2Cache {
+	return &Sha2Cache{entries: make(map[string][]byte)}
+}
+
+func sha2CacheKey(user, host string) string {
+	return user + "\x00" + host
+}
+
+// Add caches the password of an account after it passed a full authentication.
+// The host is the host of the account, which may be a pattern.
+func (c *Sha2Cache) Add(user, host, pwd string) {
+	c.mu.Lock()
+	c.entries[sha2CacheKey(user, host)] = EncodeSha2FastAuthPassword(pwd)
+	c.mu.Unlock()
+}
+
+// Remove removes an account from the cache. It should be called when the
+// password of the account is changed, or the account is renamed or dropped.
+func (c *Sha2Cache) Remove(user, host string) {
+	c.mu.Lock()
+	delete(c.entries, sha2CacheKey(user, host))
+	c.mu.Unlock()
+}
+
+// Clear removes all the accounts from the cache, e.g. for FLUSH PRIVILEGES.
+func (c *Sha2Cache) Clear() {
+	c.mu.Lock()
+	c.entries = make(map[string][]byte)
+	c.mu.Unlock()
+}
+
+// CheckScramble checks the scrambled password of the fast authentication.
+// found is false if the account isn't cached, and then the server should send
+// Sha2PerformFullAuthentication to the client.
+func (c *Sha2Cache) CheckScramble(user, host string, salt, auth []byte) (found, ok bool) {
+	c.mu.RLock()
+	hpwd, found := c.entries[sha2CacheKey(user, host)]
+	c.mu.RUnlock()
+	if !found {
+		return false, false
+	}
+	return true, CheckSha2ScrambledPassword(salt, hpwd, auth)
 }
@@ -14,7 +14,12 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 
 	. "github.com/pingcap/check"
 )
@@ -71,3 +76,93 @@ func (s *testAuthSuite) TestNewSha2Password(c *C) {
 		c.Assert(pwhash[r], Not(Equals), 36) // '$'
 	}
 }
+
+// scrambleSha2Password scrambles the password like the client of the
+// caching_sha2_password fast authentication.
+func scrambleSha2Password(salt []byte, pwd string) []byte {
+	stage1 := Sha256Hash([]byte(pwd))
+	scramble := Sha256Hash(append(Sha256Hash(stage1), salt...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
+
+func (s *testAuthSuite) TestCheckSha2Scramble(c *C) {
+	salt := []byte{85, 92, 45, 22, 58, 79, 107, 6, 122, 125, 58, 80, 12, 90, 103, 32, 90, 10, 74, 82}
+	hpwd := EncodeSha2FastAuthPassword("abc")
+	c.Assert(hpwd, DeepEquals, Sha256Hash(Sha256Hash([]byte("abc"))))
+
+	c.Assert(CheckSha2ScrambledPassword(salt, hpwd, scrambleSha2Password(salt, "abc")), IsTrue)
+	c.Assert(CheckSha2ScrambledPassword(salt, hpwd, scrambleSha2Password(salt, "abd")), IsFalse)
+	c.Assert(CheckSha2ScrambledPassword(salt[1:], hpwd, scrambleSha2Password(salt, "abc")), IsFalse)
+	// Do not panic for invalid input.
+	c.Assert(CheckSha2ScrambledPassword(salt, hpwd, []byte("xxyyzz")), IsFalse)
+}
+
+func (s *testAuthSuite) TestDecryptSha2Password(c *C) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, IsNil)
+	salt := []byte("0123456789abcdefghij")
+	for _, pwd := range []string{"", "abc", "a password longer than the salt"} {
+		// The client XORs the NUL terminated password with the salt, and encrypts it.
+		plain := append([]byte(pwd), 0)
+		for i := range plain {
+			plain[i] ^= salt[i%len(salt)]
+		}
+		encrypted, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &key.PublicKey, plain, nil)
+		c.Assert(err, IsNil)
+		decrypted, err := DecryptSha2Password(key, salt, encrypted)
+		c.Assert(err, IsNil)
+		c.Assert(decrypted, Equals, pwd)
+	}
+
+	_, err = DecryptSha2Password(key, salt, []byte("xxyyzz"))
+	c.Assert(err, NotNil)
+	_, err = DecryptSha2Password(key, nil, []byte("xxyyzz"))
+	c.Assert(err, NotNil)
+
+	c.Assert(string(TrimNulTerminator([]byte("abc\x00"))), Equals, "abc")
+	c.Assert(string(TrimNulTerminator([]byte("abc"))), Equals, "abc")
+}
+
+func (s *testAuthSuite) TestMarshalPublicKeyPEM(c *C) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, IsNil)
+	data, err := MarshalPublicKeyPEM(&key.PublicKey)
+	c.Assert(err, IsNil)
+	block, _ := pem.Decode(data)
+	c.Assert(block, NotNil)
+	c.Assert(block.Type, Equals, "PUBLIC KEY")
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	c.Assert(err, IsNil)
+	c.Assert(pub.(*rsa.PublicKey).Equal(&key.PublicKey), IsTrue)
+}
+
+func (s *testAuthSuite) TestSha2Cache(c *C) {
+	cache := NewSha2Cache()
+	salt := []byte("0123456789abcdefghij")
+	found, ok := cache.CheckScramble("u", "%", salt, scrambleSha2Password(salt, "pwd"))
+	c.Assert(found, IsFalse)
+	c.Assert(ok, IsFalse)
+
+	cache.Add("u", "%", "pwd")
+	found, ok = cache.CheckScramble("u", "%", salt, scrambleSha2Password(salt, "pwd"))
+	c.Assert(found, IsTrue)
+	c.Assert(ok, IsTrue)
+	found, ok = cache.CheckScramble("u", "%", salt, scrambleSha2Password(salt, "bad"))
+	c.Assert(found, IsTrue)
+	c.Assert(ok, IsFalse)
+	found, _ = cache.CheckScramble("u", "localhost", salt, scrambleSha2Password(salt, "pwd"))
+	c.Assert(found, IsFalse)
+
+	cache.Add("u", "localhost", "pwd2")
+	cache.Remove("u", "%")
+	found, _ = cache.CheckScramble("u", "%", salt, scrambleSha2Password(salt, "pwd"))
+	c.Assert(found, IsFalse)
+	_, ok = cache.CheckScramble("u", "localhost", salt, scrambleSha2Password(salt, "pwd2"))
+	c.Assert(ok, IsTrue)
+	cache.Clear()
+	found, _ = cache.CheckScramble("u", "localhost", salt, scrambleSha2Password(salt, "pwd2"))
+	c.Assert(found, IsFalse)
+}
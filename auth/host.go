@@ -0,0 +1,246 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/binary"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// See https://dev.mysql.com/doc/refman/8.0/en/account-names.html and
+// https://dev.mysql.com/doc/refman/8.0/en/connection-access.html
+
+const (
+	hostLocalhost = "localhost"
+	// specificityExact is the specificity of the names without wildcards.
+	specificityExact = 128
+)
+
+// MatchHost reports whether the host of a client matches the host part of an
+// account. The host part may be:
+//   - a pattern with the '%' and '_' wildcards, compared case-insensitively,
+//     where '\' escapes a wildcard. An empty host part is the same as '%'.
+//   - an IPv4 address with a netmask, like '10.0.0.0/255.0.0.0', or with a
+//     CIDR prefix length like '10.0.0.0/8' as in MySQL 8.0.23. They only match
+//     the IP addresses.
+//   - 'localhost', which also matches the loopback addresses 127.0.0.1 and ::1.
+func MatchHost(pattern, host string) bool {
+	if pattern == "" || pattern == "%" {
+		return true
+	}
+	if ipNet, _, ok := parseHostNetmask(pattern); ok {
+		ip := net.ParseIP(host)
+		return ip != nil && ipNet.Contains(ip)
+	}
+	if strings.EqualFold(pattern, hostLocalhost) && isLoopback(host) {
+		return true
+	}
+	return matchWildcard(strings.ToLower(pattern), strings.ToLower(host))
+}
+
+func isLoopback(host string) bool {
+	return host == "127.0.0.1" || host == "::1"
+}
+
+// parseHostNetmask parses the host part of an account in the IP/netmask or
+// IP/prefix length format, and reports whether it's the prefix length one.
+// Like MySQL, the IP address must not have bits out of the netmask.
+func parseHostNetmask(pattern string) (ipNet *net.IPNet, cidr bool, ok bool) {
+	slash := strings.IndexByte(pattern, '/')
+	if slash < 0 {
+		return nil, false, false
+	}
+	ip := net.ParseIP(pattern[:slash]).To4()
+	if ip == nil {
+		return nil, false, false
+	}
+	var mask net.IPMask
+	if maskIP := net.ParseIP(pattern[slash+1:]).To4(); maskIP != nil {
+		mask = net.IPMask(maskIP)
+		if ones, bits := mask.Size(); ones == 0 && bits == 0 {
+			return nil, false, false
+		}
+	} else {
+		ones, err := strconv.Atoi(pattern[slash+1:])
+		if err != nil || ones < 0 || ones > 32 {
+			return nil, false, false
+		}
+		mask = net.CIDRMask(ones, 32)
+		cidr = true
+	}
+	if !ip.Equal(ip.Mask(mask)) {
+		return nil, false, false
+	}
+	return &net.IPNet{IP: ip, Mask: mask}, cidr, true
+}
+
+// matchWildcard matches a string against a LIKE pattern.
+func matchWildcard(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for len(pattern) > 0 && pattern[0] == '%' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchWildcard(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '_':
+			if len(s) == 0 {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return len(s) == 0
+}
+
+// nameSpecificity is the weight of a user or host name in the order of the
+// accounts, like get_sort() in MySQL. The names without wildcards weigh the
+// most, then the patterns by the position of their first wildcard, and the
+// empty names or '%' the least.
+func nameSpecificity(name string) uint32 {
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '\\':
+			if i+1 < len(name) {
+				i++
+			}
+		case '%', '_':
+			pos := uint32(i) + 1
+			if !(i == 0 && name == "%") {
+				pos++
+			}
+			if pos > 127 {
+				pos = 127
+			}
+			return pos
+		}
+	}
+	if name == "" {
+		return 0
+	}
+	return specificityExact
+}
+
+// The kinds of the host parts in the order of the accounts.
+const (
+	hostKindPattern = iota
+	hostKindSubnetMask
+	hostKindCIDR
+	hostKindExact
+)
+
+// accountSpecificity is the sort key of an account, like ACL_compare in MySQL
+// 8.0.23. The host parts without wildcards, like IP addresses, come first,
+// then the IP/prefix length ones, then the IP/netmask ones and the patterns
+// last. The IP/prefix length and IP/netmask host parts are ordered by their
+// netmasks, the larger first. The host is compared before the user.
+func accountSpecificity(username, hostname string) uint64 {
+	if ipNet, cidr, ok := parseHostNetmask(hostname); ok {
+		kind := uint64(hostKindSubnetMask)
+		if cidr {
+			kind = hostKindCIDR
+		}
+		mask := binary.BigEndian.Uint32(ipNet.Mask)
+		return kind<<48 | uint64(mask)<<16 | uint64(nameSpecificity(username))
+	}
+	hostSpecificity := nameSpecificity(hostname)
+	kind := uint64(hostKindPattern)
+	if hostSpecificity == specificityExact {
+		kind = hostKindExact
+	}
+	return kind<<48 | uint64(hostSpecificity<<8|nameSpecificity(username))
+}
+
+// MatchAccount reports whether the user, who connects as user.Username from
+// user.Hostname, matches the account. The account's Username is empty for
+// the anonymous account that matches any user, and its Hostname is the host
+// part, see MatchHost.
+func (user *UserIdentity) MatchAccount(account *UserIdentity) bool {
+	if account.Username != "" && account.Username != user.Username {
+		return false
+	}
+	return MatchHost(account.Hostname, user.Hostname)
+}
+
+// MoreSpecific reports whether the account is sorted before the other one in
+// the order MySQL uses to pick the account of a user. The accounts with host
+// parts without wildcards come first, then the ones with IP/prefix length and
+// IP/netmask host parts, then the ones with patterns, see accountSpecificity.
+// Within them, the accounts with more specific host parts come first, then
+// the ones with more specific user names. For example, 'u'@'%' is before the
+// anonymous account on '%' but after the anonymous account on 'localhost'.
+func (user *UserIdentity) MoreSpecific(other *UserIdentity) bool {
+	return accountSpecificity(user.Username, user.Hostname) > accountSpecificity(other.Username, other.Hostname)
+}
+
+// SortAccounts sorts the accounts from the most specific to the least, see
+// MoreSpecific. The accounts which are equally specific keep their order.
+func SortAccounts(accounts []*UserIdentity) {
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].MoreSpecific(accounts[j])
+	})
+}
+
+// FindAccount returns the account which MySQL picks for the user, i.e. the
+// most specific account that the user matches. It returns nil if the user
+// matches no account.
+func (user *UserIdentity) FindAccount(accounts []*UserIdentity) *UserIdentity {
+	var found *UserIdentity
+	for _, account := range accounts {
+		if user.MatchAccount(account) && (found == nil || account.MoreSpecific(found)) {
+			found = account
+		}
+	}
+	return found
+}
+
+// MatchAccount reports whether the role is the account. Unlike the users,
+// the role names aren't patterns: the host parts are compared literally and
+// case-insensitively, and an empty host part is the same as '%'.
+func (role *RoleIdentity) MatchAccount(account *UserIdentity) bool {
+	return role.Username == account.Username && strings.EqualFold(roleHost(role.Hostname), roleHost(account.Hostname))
+}
+
+func roleHost(host string) string {
+	if host == "" {
+		return "%"
+	}
+	return host
+}
+
+// MoreSpecific reports whether the role is sorted before the other one in the
+// order of the accounts, see UserIdentity.MoreSpecific.
+func (role *RoleIdentity) MoreSpecific(other *RoleIdentity) bool {
+	return accountSpecificity(role.Username, roleHost(role.Hostname)) > accountSpecificity(other.Username, roleHost(other.Hostname))
+}
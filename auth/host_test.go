@@ -0,0 +1,138 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	. "github.com/pingcap/check"
+)
+
+func (s *testAuthSuite) TestMatchHost(c *C) {
+	tests := []struct {
+		pattern string
+		host    string
+		match   bool
+	}{
+		{"%", "example.com", true},
+		{"", "example.com", true},
+		{"example.com", "EXAMPLE.com", true},
+		{"example.com", "example.org", false},
+		{"%.example.com", "db.example.com", true},
+		{"%.example.com", "example.com", false},
+		{"db_.example.com", "db1.example.com", true},
+		{"db_.example.com", "db12.example.com", false},
+		{"db\\_.example.com", "db1.example.com", false},
+		{"db\\_.example.com", "db_.example.com", true},
+		{"192.168.1.%", "192.168.1.20", true},
+		{"192.168.1.%", "192.168.2.20", false},
+		{"10.0.0.0/255.0.0.0", "10.1.2.3", true},
+		{"10.0.0.0/255.0.0.0", "11.1.2.3", false},
+		{"10.0.0.0/255.0.0.0", "host.10.0.0.0", false},
+		{"10.0.0.0/8", "10.1.2.3", true},
+		{"10.1.0.0/16", "10.2.0.1", false},
+		{"192.168.0.1/255.255.255.255", "192.168.0.1", true},
+		// The IP must not have bits out of the netmask, and the netmask must be contiguous.
+		{"10.0.0.1/255.0.0.0", "10.0.0.1", false},
+		{"10.0.0.0/255.0.255.0", "10.0.0.1", false},
+		{"10.0.0.0/33", "10.0.0.1", false},
+		{"localhost", "localhost", true},
+		{"localhost", "127.0.0.1", true},
+		{"localhost", "::1", true},
+		{"localhost", "192.168.1.1", false},
+		{"127.0.0.1", "localhost", false},
+	}
+	for _, t := range tests {
+		c.Assert(MatchHost(t.pattern, t.host), Equals, t.match, Commentf("%s, %s", t.pattern, t.host))
+	}
+}
+
+func (s *testAuthSuite) TestMatchAccount(c *C) {
+	user := &UserIdentity{Username: "u", Hostname: "db.example.com"}
+	c.Assert(user.MatchAccount(&UserIdentity{Username: "u", Hostname: "%"}), IsTrue)
+	c.Assert(user.MatchAccount(&UserIdentity{Username: "U", Hostname: "%"}), IsFalse)
+	c.Assert(user.MatchAccount(&UserIdentity{Username: "", Hostname: "%.example.com"}), IsTrue)
+	c.Assert(user.MatchAccount(&UserIdentity{Username: "u", Hostname: "localhost"}), IsFalse)
+
+	role := &RoleIdentity{Username: "r"}
+	c.Assert(role.MatchAccount(&UserIdentity{Username: "r", Hostname: "%"}), IsTrue)
+	c.Assert(role.MatchAccount(&UserIdentity{Username: "r", Hostname: ""}), IsTrue)
+	c.Assert(role.MatchAccount(&UserIdentity{Username: "r", Hostname: "localhost"}), IsFalse)
+	role = &RoleIdentity{Username: "r", Hostname: "LocalHost"}
+	c.Assert(role.MatchAccount(&UserIdentity{Username: "r", Hostname: "localhost"}), IsTrue)
+	c.Assert(role.MatchAccount(&UserIdentity{Username: "r", Hostname: "local%"}), IsFalse)
+}
+
+func (s *testAuthSuite) TestSortAccounts(c *C) {
+	// The order of the accounts follows the examples of
+	// https://dev.mysql.com/doc/refman/8.0/en/connection-access.html
+	accounts := []*UserIdentity{
+		{Username: "", Hostname: "%"},
+		{Username: "root", Hostname: "%"},
+		{Username: "jeffrey", Hostname: "%.example.net"},
+		{Username: "", Hostname: "localhost"},
+		{Username: "root", Hostname: "localhost"},
+		{Username: "u", Hostname: "192.168.1.%"},
+		{Username: "u", Hostname: "10.0.0.0/8"},
+		{Username: "jeffrey", Hostname: "h1.example.net"},
+		{Username: "u", Hostname: ""},
+		{Username: "u", Hostname: "10.1.0.0/255.255.0.0"},
+		{Username: "", Hostname: "10.1.1.0/24"},
+		{Username: "v", Hostname: "10.1.1.0/24"},
+		{Username: "u", Hostname: "10.1.1.1"},
+	}
+	SortAccounts(accounts)
+	var names []string
+	for _, account := range accounts {
+		names = append(names, account.String())
+	}
+	c.Assert(names, DeepEquals, []string{
+		"root@localhost",
+		"jeffrey@h1.example.net",
+		"u@10.1.1.1",
+		"@localhost",
+		"v@10.1.1.0/24",
+		"@10.1.1.0/24",
+		"u@10.0.0.0/8",
+		"u@10.1.0.0/255.255.0.0",
+		"u@192.168.1.%",
+		"jeffrey@%.example.net",
+		"root@%",
+		"@%",
+		"u@",
+	})
+
+	// The anonymous account on localhost is picked before root@%.
+	user := &UserIdentity{Username: "root", Hostname: "localhost"}
+	c.Assert(user.FindAccount(accounts[3:]).String(), Equals, "@localhost")
+	user = &UserIdentity{Username: "jeffrey", Hostname: "h1.example.net"}
+	c.Assert(user.FindAccount(accounts).String(), Equals, "jeffrey@h1.example.net")
+	user = &UserIdentity{Username: "jeffrey", Hostname: "h2.example.net"}
+	c.Assert(user.FindAccount(accounts).String(), Equals, "jeffrey@%.example.net")
+	// The IP addresses are picked before IP/prefix length, and IP/prefix
+	// length before IP/netmask.
+	user = &UserIdentity{Username: "u", Hostname: "10.1.1.1"}
+	c.Assert(user.FindAccount(accounts).String(), Equals, "u@10.1.1.1")
+	user = &UserIdentity{Username: "w", Hostname: "10.1.1.1"}
+	c.Assert(user.FindAccount(accounts).String(), Equals, "@10.1.1.0/24")
+	user = &UserIdentity{Username: "u", Hostname: "10.1.2.1"}
+	c.Assert(user.FindAccount(accounts).String(), Equals, "u@10.0.0.0/8")
+	c.Assert(user.FindAccount(accounts[7:]).String(), Equals, "u@10.1.0.0/255.255.0.0")
+	user = &UserIdentity{Username: "v", Hostname: "example.com"}
+	c.Assert(user.FindAccount(accounts).String(), Equals, "@%")
+	c.Assert(user.FindAccount(accounts[:2]), IsNil)
+
+	roles := []*RoleIdentity{{Username: "r1"}, {Username: "r2", Hostname: "localhost"}}
+	c.Assert(roles[1].MoreSpecific(roles[0]), IsTrue)
+	c.Assert(roles[0].MoreSpecific(roles[1]), IsFalse)
+	c.Assert(roles[0].MoreSpecific(&RoleIdentity{Username: "r3", Hostname: "%"}), IsFalse)
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+)
+
+// Plugin is an authentication plugin of the MySQL protocol. The servers and
+// proxies negotiate the plugins by name and check the authentication data
+// sent by the clients through it.
+type Plugin interface {
+	// Name returns the name of the plugin, like mysql_native_password.
+	Name() string
+	// GenerateAuthString generates the authentication string stored in
+	// mysql.user for the password.
+	GenerateAuthString(pwd string) (string, error)
+	// CheckAuth checks the authentication data sent by the client against the
+	// stored authentication string. salt is the data sent to the client in the
+	// handshake or the auth switch request.
+	CheckAuth(authString string, salt, authData []byte) (bool, error)
+	// RequiresSecureTransport reports whether the client sends the password
+	// in cleartext, so the plugin must only be used over TLS or a socket.
+	RequiresSecureTransport() bool
+	// AuthSwitchData returns the plugin data of the auth switch request for
+	// the salt.
+	AuthSwitchData(salt []byte) []byte
+}
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   = make(map[string]Plugin)
+)
+
+func init() {
+	for _, p := range []Plugin{
+		nativePasswordPlugin{},
+		cachingSha2PasswordPlugin{},
+		sha256PasswordPlugin{},
+		clearPasswordPlugin{},
+	} {
+		mustRegisterPlugin(p)
+	}
+}
+
+func mustRegisterPlugin(p Plugin) {
+	if err := RegisterPlugin(p); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterPlugin registers an authentication plugin. It returns an error if a
+// plugin with the same name is already registered.
+func RegisterPlugin(p Plugin) error {
+	name := strings.ToLower(p.Name())
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if _, ok := plugins[name]; ok {
+		return errors.Errorf("authentication plugin '%s' is already registered", name)
+	}
+	plugins[name] = p
+	return nil
+}
+
+// GetPlugin returns the registered authentication plugin by name.
+func GetPlugin(name string) (Plugin, bool) {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	p, ok := plugins[strings.ToLower(name)]
+	return p, ok
+}
+
+// PluginNames returns the names of the registered authentication plugins in
+// order.
+func PluginNames() []string {
+	pluginsMu.RLock()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	pluginsMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// nulTerminated returns the salt followed by NUL, as the scramble based
+// plugins send it in the auth switch request.
+func nulTerminated(salt []byte) []byte {
+	data := make([]byte, len(salt), len(salt)+1)
+	copy(data, salt)
+	return append(data, 0)
+}
+
+// nativePasswordPlugin is mysql_native_password. The client sends the
+// scrambled password, see CheckScrambledPassword.
+type nativePasswordPlugin struct{}
+
+func (nativePasswordPlugin) Name() string {
+	return mysql.AuthNativePassword
+}
+
+func (nativePasswordPlugin) GenerateAuthString(pwd string) (string, error) {
+	return EncodePassword(pwd), nil
+}
+
+func (nativePasswordPlugin) CheckAuth(authString string, salt, authData []byte) (bool, error) {
+	if authString == "" {
+		return len(authData) == 0, nil
+	}
+	if len(authString) != mysql.PWDHashLen+1 || authString[0] != '*' {
+		return false, errors.Errorf("invalid %s authentication string", mysql.AuthNativePassword)
+	}
+	hpwd, err := DecodePassword(authString)
+	if err != nil {
+		return false, err
+	}
+	return CheckScrambledPassword(salt, hpwd, authData), nil
+}
+
+func (nativePasswordPlugin) RequiresSecureTransport() bool {
+	return false
+}
+
+func (nativePasswordPlugin) AuthSwitchData(salt []byte) []byte {
+	return nulTerminated(salt)
+}
+
+// cachingSha2PasswordPlugin is caching_sha2_password. CheckAuth performs the
+// full authentication, in which the client sends the password in cleartext
+// over TLS or encrypted with RSA, see DecryptSha2Password. The fast
+// authentication is checked with a Sha2Cache.
+type cachingSha2PasswordPlugin struct{}
+
+func (cachingSha2PasswordPlugin) Name() string {
+	return mysql.AuthCachingSha2Password
+}
+
+func (cachingSha2PasswordPlugin) GenerateAuthString(pwd string) (string, error) {
+	if pwd == "" {
+		return "", nil
+	}
+	return NewSha2Password(pwd), nil
+}
+
+func (cachingSha2PasswordPlugin) CheckAuth(authString string, salt, authData []byte) (bool, error) {
+	pwd := TrimNulTerminator(authData)
+	if authString == "" {
+		return len(pwd) == 0, nil
+	}
+	return CheckShaPassword([]byte(authString), string(pwd))
+}
+
+func (cachingSha2PasswordPlugin) RequiresSecureTransport() bool {
+	return false
+}
+
+func (cachingSha2PasswordPlugin) AuthSwitchData(salt []byte) []byte {
+	return nulTerminated(salt)
+}
+
+// sha256PasswordPlugin is sha256_password. The client sends the password in
+// cleartext over TLS or encrypted with RSA like caching_sha2_password. The
+// authentication string is "$5$<salt>$<hash>" of SHA-crypt with 5000 rounds.
+type sha256PasswordPlugin struct{}
+
+const (
+	sha256PasswordPrefix = "$5$"
+	// sha256CryptHashLen is the length of the base64 encoded SHA-crypt hash.
+	sha256CryptHashLen = 43
+)
+
+func (sha256PasswordPlugin) Name() string {
+	return mysql.AuthSHA256Password
+}
+
+func (sha256PasswordPlugin) GenerateAuthString(pwd string) (string, error) {
+	if pwd == "" {
+		return "", nil
+	}
+	return sha256PasswordAuthString(pwd, newSha2Salt()), nil
+}
+
+// sha256PasswordAuthString formats the SHA-crypt hash of the password like
+// sha256_password. The hash is computed like caching_sha2_password's, which
+// formats it as "$A$005$<salt><hash>".
+func sha256PasswordAuthString(pwd string, salt []byte) string {
+	hash := sha256crypt(pwd, salt, 5*ITERATION_MULTIPLIER)
+	return sha256PasswordPrefix + string(salt) + "$" + hash[len(hash)-sha256CryptHashLen:]
+}
+
+func (sha256PasswordPlugin) CheckAuth(authString string, salt, authData []byte) (bool, error) {
+	pwd := TrimNulTerminator(authData)
+	if authString == "" {
+		return len(pwd) == 0, nil
+	}
+	parts := strings.Split(authString, "$")
+	if len(parts) != 4 || parts[1] != "5" || len(parts[2]) == 0 || len(parts[3]) != sha256CryptHashLen {
+		return false, errors.Errorf("invalid %s authentication string", mysql.AuthSHA256Password)
+	}
+	expected := sha256PasswordAuthString(string(pwd), []byte(parts[2]))
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(authString)) == 1, nil
+}
+
+func (sha256PasswordPlugin) RequiresSecureTransport() bool {
+	return false
+}
+
+func (sha256PasswordPlugin) AuthSwitchData(salt []byte) []byte {
+	return nulTerminated(salt)
+}
+
+// clearPasswordPlugin is mysql_clear_password, which is used by the external
+// authentication like LDAP and PAM. The client sends the password in
+// cleartext, and it is checked against a mysql_native_password hash.
+type clearPasswordPlugin struct{}
+
+func (clearPasswordPlugin) Name() string {
+	return mysql.AuthClearPassword
+}
+
+func (clearPasswordPlugin) GenerateAuthString(pwd string) (string, error) {
+	return EncodePassword(pwd), nil
+}
+
+func (clearPasswordPlugin) CheckAuth(authString string, salt, authData []byte) (bool, error) {
+	pwd := TrimNulTerminator(authData)
+	if authString == "" {
+		return len(pwd) == 0, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(EncodePassword(string(pwd))), []byte(authString)) == 1, nil
+}
+
+func (clearPasswordPlugin) RequiresSecureTransport() bool {
+	return true
+}
+
+func (clearPasswordPlugin) AuthSwitchData(salt []byte) []byte {
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/mysql"
+)
+
+func (s *testAuthSuite) TestPluginRegistry(c *C) {
+	c.Assert(PluginNames(), DeepEquals, []string{
+		mysql.AuthCachingSha2Password,
+		mysql.AuthClearPassword,
+		mysql.AuthNativePassword,
+		mysql.AuthSHA256Password,
+	})
+	p, ok := GetPlugin("MYSQL_NATIVE_PASSWORD")
+	c.Assert(ok, IsTrue)
+	c.Assert(p.Name(), Equals, mysql.AuthNativePassword)
+	_, ok = GetPlugin(mysql.AuthSocket)
+	c.Assert(ok, IsFalse)
+	c.Assert(RegisterPlugin(nativePasswordPlugin{}), ErrorMatches, "authentication plugin 'mysql_native_password' is already registered")
+}
+
+func (s *testAuthSuite) TestNativePasswordPlugin(c *C) {
+	p, _ := GetPlugin(mysql.AuthNativePassword)
+	salt := []byte{85, 92, 45, 22, 58, 79, 107, 6, 122, 125, 58, 80, 12, 90, 103, 32, 90, 10, 74, 82}
+	scramble := []byte{24, 180, 183, 225, 166, 6, 81, 102, 70, 248, 199, 143, 91, 204, 169, 9, 161, 171, 203, 33}
+	authString, err := p.GenerateAuthString("abc")
+	c.Assert(err, IsNil)
+	c.Assert(authString, Equals, EncodePassword("abc"))
+
+	ok, err := p.CheckAuth(authString, salt, scramble)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = p.CheckAuth(authString, salt, []byte("xxyyzz"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	ok, err = p.CheckAuth("", salt, nil)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = p.CheckAuth("", salt, scramble)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	_, err = p.CheckAuth("*XYZ", salt, scramble)
+	c.Assert(err, NotNil)
+
+	c.Assert(p.RequiresSecureTransport(), IsFalse)
+	c.Assert(p.AuthSwitchData(salt), DeepEquals, append(append([]byte{}, salt...), 0))
+}
+
+func (s *testAuthSuite) TestCachingSha2PasswordPlugin(c *C) {
+	p, _ := GetPlugin(mysql.AuthCachingSha2Password)
+	authString, err := p.GenerateAuthString("foobar")
+	c.Assert(err, IsNil)
+	c.Assert(authString, HasLen, mysql.SHAPWDHashLen)
+
+	ok, err := p.CheckAuth(authString, nil, []byte("foobar\x00"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = p.CheckAuth(authString, nil, []byte("foobaz"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	authString, err = p.GenerateAuthString("")
+	c.Assert(err, IsNil)
+	c.Assert(authString, Equals, "")
+	ok, err = p.CheckAuth("", nil, []byte{0})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	_, err = p.CheckAuth("$A$", nil, []byte("foobar"))
+	c.Assert(err, NotNil)
+	c.Assert(p.RequiresSecureTransport(), IsFalse)
+}
+
+func (s *testAuthSuite) TestSha256PasswordPlugin(c *C) {
+	p, _ := GetPlugin(mysql.AuthSHA256Password)
+	// The authentication string is the one of SHA-crypt, e.g. `openssl passwd -5`.
+	authString := "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"
+	ok, err := p.CheckAuth(authString, nil, []byte("Hello world!"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = p.CheckAuth(authString, nil, []byte("Hello world"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	authString, err = p.GenerateAuthString("testpwd")
+	c.Assert(err, IsNil)
+	c.Assert(authString, Matches, `\$5\$[^$]{20}\$[./0-9A-Za-z]{43}`)
+	ok, err = p.CheckAuth(authString, nil, []byte("testpwd\x00"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	for _, invalid := range []string{"*23AE809DDACAF96AF0FD78ED04B6A265E05AA257", "$5$salt", "$5$$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"} {
+		_, err = p.CheckAuth(invalid, nil, []byte("Hello world!"))
+		c.Assert(err, NotNil, Commentf("%s", invalid))
+	}
+	c.Assert(p.RequiresSecureTransport(), IsFalse)
+}
+
+func (s *testAuthSuite) TestClearPasswordPlugin(c *C) {
+	p, _ := GetPlugin(mysql.AuthClearPassword)
+	authString, err := p.GenerateAuthString("123")
+	c.Assert(err, IsNil)
+	c.Assert(authString, Equals, "*23AE809DDACAF96AF0FD78ED04B6A265E05AA257")
+	ok, err := p.CheckAuth(authString, nil, []byte("123\x00"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = p.CheckAuth(authString, nil, []byte("1234"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	c.Assert(p.RequiresSecureTransport(), IsTrue)
+	c.Assert(p.AuthSwitchData([]byte("salt")), HasLen, 0)
+}
@@ -57,6 +57,15 @@ var charsetInfos = map[string]*Charset{
 	CharsetASCII:   {CharsetASCII, CollationASCII, make(map[string]*Collation), "US ASCII", 1},
 	CharsetLatin1:  {CharsetLatin1, CollationLatin1, make(map[string]*Collation), "Latin1", 1},
 	CharsetBin:     {CharsetBin, CollationBin, make(map[string]*Collation), "binary", 1},
+	// The following charsets are supported as the charset of the client, see ParserConfig.CharsetClient.
+	CharsetBig5:    {CharsetBig5, CollationBig5Bin, make(map[string]*Collation), "Big5 Traditional Chinese", 2},
+	CharsetCP932:   {CharsetCP932, CollationCP932Bin, make(map[string]*Collation), "SJIS for Windows Japanese", 2},
+	CharsetEUCKR:   {CharsetEUCKR, CollationEUCKRBin, make(map[string]*Collation), "EUC-KR Korean", 2},
+	CharsetGB18030: {CharsetGB18030, CollationGB18030Bin, make(map[string]*Collation), "China National Standard GB18030", 4},
+	CharsetGBK:     {CharsetGBK, CollationGBKBin, make(map[string]*Collation), "GBK Simplified Chinese", 2},
+	CharsetSJIS:    {CharsetSJIS, CollationSJISBin, make(map[string]*Collation), "Shift-JIS Japanese", 2},
+	CharsetUTF16:   {CharsetUTF16, CollationUTF16Bin, make(map[string]*Collation), "UTF-16 Unicode", 4},
+	CharsetUTF16LE: {CharsetUTF16LE, CollationUTF16LEBin, make(map[string]*Collation), "UTF-16LE Unicode", 4},
 }
 
 // All the names supported collations should be in the following table.
@@ -66,6 +75,15 @@ var supportedCollationNames = map[string]struct{}{
 	CollationASCII:   {},
 	CollationLatin1:  {},
 	CollationBin:     {},
+
+	CollationBig5Bin:    {},
+	CollationCP932Bin:   {},
+	CollationEUCKRBin:   {},
+	CollationGB18030Bin: {},
+	CollationGBKBin:     {},
+	CollationSJISBin:    {},
+	CollationUTF16Bin:   {},
+	CollationUTF16LEBin: {},
 }
 
 // GetSupportedCharsets gets descriptions for all charsets supported so far.
@@ -196,7 +214,22 @@ const (
 	// CollationLatin1 is the default collation for CharsetLatin1.
 	CollationLatin1 = "latin1_bin"
 
+	// CollationGBKBin is the default collation for CharsetGBK.
 	CollationGBKBin = "gbk_bin"
+	// CollationBig5Bin is the default collation for CharsetBig5.
+	CollationBig5Bin = "big5_bin"
+	// CollationCP932Bin is the default collation for CharsetCP932.
+	CollationCP932Bin = "cp932_bin"
+	// CollationEUCKRBin is the default collation for CharsetEUCKR.
+	CollationEUCKRBin = "euckr_bin"
+	// CollationGB18030Bin is the default collation for CharsetGB18030.
+	CollationGB18030Bin = "gb18030_bin"
+	// CollationSJISBin is the default collation for CharsetSJIS.
+	CollationSJISBin = "sjis_bin"
+	// CollationUTF16Bin is the default collation for CharsetUTF16.
+	CollationUTF16Bin = "utf16_bin"
+	// CollationUTF16LEBin is the default collation for CharsetUTF16LE.
+	CollationUTF16LEBin = "utf16le_bin"
 
 	CharsetARMSCII8 = "armscii8"
 	CharsetBig5     = "big5"
@@ -238,7 +271,7 @@ const (
 )
 
 var collations = []*Collation{
-	{1, "big5", "big5_chinese_ci", true},
+	{1, "big5", "big5_chinese_ci", false},
 	{2, "latin2", "latin2_czech_cs", false},
 	{3, "dec8", "dec8_swedish_ci", true},
 	{4, "cp850", "cp850_general_ci", true},
@@ -250,12 +283,12 @@ var collations = []*Collation{
 	{10, "swe7", "swe7_swedish_ci", true},
 	{11, "ascii", "ascii_general_ci", false},
 	{12, "ujis", "ujis_japanese_ci", true},
-	{13, "sjis", "sjis_japanese_ci", true},
+	{13, "sjis", "sjis_japanese_ci", false},
 	{14, "cp1251", "cp1251_bulgarian_ci", false},
 	{15, "latin1", "latin1_danish_ci", false},
 	{16, "hebrew", "hebrew_general_ci", true},
 	{18, "tis620", "tis620_thai_ci", true},
-	{19, "euckr", "euckr_korean_ci", true},
+	{19, "euckr", "euckr_korean_ci", false},
 	{20, "latin7", "latin7_estonian_cs", false},
 	{21, "latin2", "latin2_hungarian_ci", false},
 	{22, "koi8u", "koi8u_general_ci", true},
@@ -264,7 +297,7 @@ var collations = []*Collation{
 	{25, "greek", "greek_general_ci", true},
 	{26, "cp1250", "cp1250_general_ci", true},
 	{27, "latin2", "latin2_croatian_ci", false},
-	{28, "gbk", "gbk_chinese_ci", true},
+	{28, "gbk", "gbk_chinese_ci", false},
 	{29, "cp1257", "cp1257_lithuanian_ci", false},
 	{30, "latin5", "latin5_turkish_ci", true},
 	{31, "latin1", "latin1_german2_ci", false},
@@ -290,15 +323,15 @@ var collations = []*Collation{
 	{51, "cp1251", "cp1251_general_ci", true},
 	{52, "cp1251", "cp1251_general_cs", false},
 	{53, "macroman", "macroman_bin", false},
-	{54, "utf16", "utf16_general_ci", true},
-	{55, "utf16", "utf16_bin", false},
-	{56, "utf16le", "utf16le_general_ci", true},
+	{54, "utf16", "utf16_general_ci", false},
+	{55, "utf16", "utf16_bin", true},
+	{56, "utf16le", "utf16le_general_ci", false},
 	{57, "cp1256", "cp1256_general_ci", true},
 	{58, "cp1257", "cp1257_bin", false},
 	{59, "cp1257", "cp1257_general_ci", true},
 	{60, "utf32", "utf32_general_ci", true},
 	{61, "utf32", "utf32_bin", false},
-	{62, "utf16le", "utf16le_bin", false},
+	{62, "utf16le", "utf16le_bin", true},
 	{63, "binary", "binary", true},
 	{64, "armscii8", "armscii8_bin", false},
 	{65, "ascii", "ascii_bin", true},
@@ -319,19 +352,19 @@ var collations = []*Collation{
 	{81, "cp852", "cp852_bin", false},
 	{82, "swe7", "swe7_bin", false},
 	{83, "utf8", "utf8_bin", true},
-	{84, "big5", "big5_bin", false},
-	{85, "euckr", "euckr_bin", false},
+	{84, "big5", "big5_bin", true},
+	{85, "euckr", "euckr_bin", true},
 	{86, "gb2312", "gb2312_bin", false},
-	{87, "gbk", "gbk_bin", false},
-	{88, "sjis", "sjis_bin", false},
+	{87, "gbk", "gbk_bin", true},
+	{88, "sjis", "sjis_bin", true},
 	{89, "tis620", "tis620_bin", false},
 	{90, "ucs2", "ucs2_bin", false},
 	{91, "ujis", "ujis_bin", false},
 	{92, "geostd8", "geostd8_general_ci", true},
 	{93, "geostd8", "geostd8_bin", false},
 	{94, "latin1", "latin1_spanish_ci", false},
-	{95, "cp932", "cp932_japanese_ci", true},
-	{96, "cp932", "cp932_bin", false},
+	{95, "cp932", "cp932_japanese_ci", false},
+	{96, "cp932", "cp932_bin", true},
 	{97, "eucjpms", "eucjpms_japanese_ci", true},
 	{98, "eucjpms", "eucjpms_bin", false},
 	{99, "cp1250", "cp1250_polish_ci", false},
@@ -457,6 +490,9 @@ var collations = []*Collation{
 	{245, "utf8mb4", "utf8mb4_croatian_ci", false},
 	{246, "utf8mb4", "utf8mb4_unicode_520_ci", false},
 	{247, "utf8mb4", "utf8mb4_vietnamese_ci", false},
+	{248, "gb18030", "gb18030_chinese_ci", false},
+	{249, "gb18030", "gb18030_bin", true},
+	{250, "gb18030", "gb18030_unicode_520_ci", false},
 	{255, "utf8mb4", "utf8mb4_0900_ai_ci", false},
 	{2048, "utf8mb4", "utf8mb4_zh_pinyin_tidb_as_cs", false},
 }
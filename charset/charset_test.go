@@ -46,7 +46,9 @@ func (s *testCharsetSuite) TestValidCharset(c *C) {
 		{"utf8mb4", "utf8mb4_bin", true},
 		{"latin1", "latin1_bin", true},
 		{"utf8", "utf8_invalid_ci", false},
-		{"utf16", "utf16_bin", false},
+		{"utf16", "utf16_bin", true},
+		{"utf16", "utf8_bin", false},
+		{"utf32", "utf32_bin", false},
 		{"gb2312", "gb2312_chinese_ci", false},
 		{"UTF8", "UTF8_BIN", true},
 		{"UTF8", "utf8_bin", true},
@@ -98,6 +100,14 @@ func (s *testCharsetSuite) TestGetDefaultCollation(c *C) {
 		{"ascii", "ascii_bin", true},
 		{"binary", "binary", true},
 		{"latin1", "latin1_bin", true},
+		{"big5", "big5_bin", true},
+		{"cp932", "cp932_bin", true},
+		{"euckr", "euckr_bin", true},
+		{"gb18030", "gb18030_bin", true},
+		{"gbk", "gbk_bin", true},
+		{"sjis", "sjis_bin", true},
+		{"utf16", "utf16_bin", true},
+		{"utf16le", "utf16le_bin", true},
 		{"invalid_cs", "", false},
 		{"", "utf8_bin", false},
 	}
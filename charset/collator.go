@@ -0,0 +1,252 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Compare compares two strings under the collation. It returns 0 if a equals
+// b, -1 if a is less than b and 1 if a is greater than b.
+// The strings are UTF-8 encoded. The collations which don't have a comparison
+// rule here are compared like the _bin collations.
+func (c *Collation) Compare(a, b string) int {
+	return getCollator(c.Name).compare(a, b)
+}
+
+// Key returns the sort key of a string under the collation. The sort keys of
+// two strings compare bytewise like the strings compare under the collation,
+// and the sort keys of equal strings are equal.
+func (c *Collation) Key(s string) []byte {
+	return getCollator(c.Name).key(s)
+}
+
+// IsPadSpace reports whether trailing spaces are ignored when the strings are
+// compared under the collation. Only binary and the UCA 9.0.0 based
+// collations are NO PAD.
+func (c *Collation) IsPadSpace() bool {
+	return getCollator(c.Name).padSpace()
+}
+
+// collator implements the comparison rule of collations.
+type collator interface {
+	key(s string) []byte
+	compare(a, b string) int
+	padSpace() bool
+}
+
+var (
+	binCollator        = &binaryCollator{}
+	binPaddingCollator = &binaryCollator{pad: true}
+	generalCICollator  = &weightCollator{weight: generalCIWeight}
+
+	collators = map[string]collator{
+		CollationBin:         binCollator,
+		"utf8_general_ci":    generalCICollator,
+		"utf8mb4_general_ci": generalCICollator,
+		"utf8_unicode_ci":    newUCACollator(true),
+		"utf8mb4_unicode_ci": newUCACollator(true),
+		"utf8mb4_0900_ai_ci": newUCACollator(false),
+		"latin1_swedish_ci":  &weightCollator{weight: latin1SwedishCIWeight},
+		"gbk_chinese_ci":     &weightCollator{weight: gbkChineseCIWeight},
+	}
+)
+
+func getCollator(name string) collator {
+	if c, ok := collators[strings.ToLower(name)]; ok {
+		return c
+	}
+	return binPaddingCollator
+}
+
+func truncateTrailingSpaces(s string) string {
+	return strings.TrimRight(s, " ")
+}
+
+// binaryCollator compares the bytes of the strings. It implements the _bin
+// collations which are PAD SPACE, and binary which is NO PAD.
+type binaryCollator struct {
+	pad bool
+}
+
+func (c *binaryCollator) key(s string) []byte {
+	if c.pad {
+		s = truncateTrailingSpaces(s)
+	}
+	return []byte(s)
+}
+
+func (c *binaryCollator) compare(a, b string) int {
+	if c.pad {
+		a, b = truncateTrailingSpaces(a), truncateTrailingSpaces(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func (c *binaryCollator) padSpace() bool {
+	return c.pad
+}
+
+// weightCollator compares the strings by the weights of their characters. The
+// collations are PAD SPACE.
+type weightCollator struct {
+	// weight appends the weight of a character to the key.
+	weight func(key []byte, r rune) []byte
+}
+
+func (c *weightCollator) key(s string) []byte {
+	s = truncateTrailingSpaces(s)
+	key := make([]byte, 0, len(s)*2)
+	for _, r := range s {
+		key = c.weight(key, r)
+	}
+	return key
+}
+
+func (c *weightCollator) compare(a, b string) int {
+	return bytes.Compare(c.key(a), c.key(b))
+}
+
+func (c *weightCollator) padSpace() bool {
+	return true
+}
+
+// generalCIWeight is the weight of a character in utf8mb4_general_ci: the
+// upper case of the character without diacritics. All the characters outside
+// the BMP weigh the same as U+FFFD.
+func generalCIWeight(key []byte, r rune) []byte {
+	switch {
+	case r > 0xFFFF:
+		r = 0xFFFD
+	case r == 'ß':
+		r = 'S'
+	case r >= utf8.RuneSelf:
+		if d := norm.NFD.PropertiesString(string(r)).Decomposition(); len(d) > 0 {
+			r, _ = utf8.DecodeRune(d)
+		}
+	}
+	r = unicode.ToUpper(r)
+	return append(key, byte(r>>8), byte(r))
+}
+
+// latin1SwedishCISortOrder is the sort order of the latin1 characters in
+// latin1_swedish_ci.
+var latin1SwedishCISortOrder = [256]byte{
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+	16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
+	32, 33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47,
+	48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59, 60, 61, 62, 63,
+	64, 65, 66, 67, 68, 69, 70, 71, 72, 73, 74, 75, 76, 77, 78, 79,
+	80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90, 91, 92, 93, 94, 95,
+	96, 65, 66, 67, 68, 69, 70, 71, 72, 73, 74, 75, 76, 77, 78, 79,
+	80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90, 123, 124, 125, 126, 127,
+	128, 129, 130, 131, 132, 133, 134, 135, 136, 137, 138, 139, 140, 141, 142, 143,
+	144, 145, 146, 147, 148, 149, 150, 151, 152, 153, 154, 155, 156, 157, 158, 159,
+	160, 161, 162, 163, 164, 165, 166, 167, 168, 169, 170, 171, 172, 173, 174, 175,
+	176, 177, 178, 179, 180, 181, 182, 183, 184, 185, 186, 187, 188, 189, 190, 191,
+	65, 65, 65, 65, 92, 91, 92, 67, 69, 69, 69, 69, 73, 73, 73, 73,
+	68, 78, 79, 79, 79, 79, 93, 215, 216, 85, 85, 85, 89, 89, 222, 223,
+	65, 65, 65, 65, 92, 91, 92, 67, 69, 69, 69, 69, 73, 73, 73, 73,
+	68, 78, 79, 79, 79, 79, 93, 247, 216, 85, 85, 85, 89, 89, 222, 255,
+}
+
+// latin1SwedishCIWeight is the weight of a character in latin1_swedish_ci. The
+// characters which aren't in latin1 weigh the same as '?'.
+func latin1SwedishCIWeight(key []byte, r rune) []byte {
+	b, ok := charmap.Windows1252.EncodeRune(r)
+	if !ok {
+		b = '?'
+	}
+	return append(key, latin1SwedishCISortOrder[b])
+}
+
+// gbkChineseCIWeight is the weight of a character in gbk_chinese_ci: the
+// upper case of an ASCII character, or the GBK code of other characters. The
+// characters which aren't in GBK weigh the same as '?'.
+func gbkChineseCIWeight(key []byte, r rune) []byte {
+	if r < utf8.RuneSelf {
+		return append(key, byte(unicode.ToUpper(r)))
+	}
+	var buf [4]byte
+	n := utf8.EncodeRune(buf[:], r)
+	var dst [4]byte
+	nDst, _, err := simplifiedchinese.GBK.NewEncoder().Transform(dst[:], buf[:n], true)
+	if err != nil || nDst != 2 {
+		return append(key, '?')
+	}
+	return append(key, dst[0], dst[1])
+}
+
+// ucaCollator compares the strings by the primary weights of the Unicode
+// Collation Algorithm, so the comparison is case and accent insensitive. The
+// weights come from the CLDR root collation, which may differ from the UCA
+// version of the MySQL collations for some rare characters.
+type ucaCollator struct {
+	// pad is set for the UCA 4.0.0 collations, e.g. utf8mb4_unicode_ci, which
+	// pad spaces and weigh the supplementary characters as U+FFFD.
+	pad bool
+	// The collators aren't safe for concurrent use.
+	pool sync.Pool
+}
+
+func newUCACollator(pad bool) *ucaCollator {
+	c := &ucaCollator{pad: pad}
+	c.pool.New = func() interface{} {
+		return collate.New(language.Und, collate.Loose)
+	}
+	return c
+}
+
+// replaceSupplementary replaces the characters out of the BMP with U+FFFD.
+func replaceSupplementary(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0xFFFF {
+			return utf8.RuneError
+		}
+		return r
+	}, s)
+}
+
+func (c *ucaCollator) key(s string) []byte {
+	if c.pad {
+		s = replaceSupplementary(truncateTrailingSpaces(s))
+	}
+	coll := c.pool.Get().(*collate.Collator)
+	defer c.pool.Put(coll)
+	var buf collate.Buffer
+	return append([]byte{}, coll.KeyFromString(&buf, s)...)
+}
+
+func (c *ucaCollator) compare(a, b string) int {
+	if c.pad {
+		a, b = replaceSupplementary(truncateTrailingSpaces(a)), replaceSupplementary(truncateTrailingSpaces(b))
+	}
+	coll := c.pool.Get().(*collate.Collator)
+	defer c.pool.Put(coll)
+	return coll.CompareString(a, b)
+}
+
+func (c *ucaCollator) padSpace() bool {
+	return c.pad
+}
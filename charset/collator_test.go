@@ -0,0 +1,159 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset_test
+
+import (
+	"bytes"
+	"sort"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/charset"
+)
+
+var _ = Suite(&testCollatorSuite{})
+
+type testCollatorSuite struct {
+}
+
+type compareTestCase struct {
+	a, b   string
+	result int
+}
+
+func testCompare(c *C, name string, tests []compareTestCase) {
+	coll, err := charset.GetCollationByName(name)
+	c.Assert(err, IsNil)
+	for _, t := range tests {
+		cmt := Commentf("%s: %q, %q", name, t.a, t.b)
+		c.Assert(coll.Compare(t.a, t.b), Equals, t.result, cmt)
+		c.Assert(coll.Compare(t.b, t.a), Equals, -t.result, cmt)
+		c.Assert(bytes.Compare(coll.Key(t.a), coll.Key(t.b)), Equals, t.result, cmt)
+	}
+}
+
+func (s *testCollatorSuite) TestBinCollation(c *C) {
+	tests := []compareTestCase{
+		{"a", "a", 0},
+		{"a", "A", 1},
+		{"a", "b", -1},
+		{"é", "e", 1},
+		{"", "", 0},
+	}
+	testCompare(c, "utf8mb4_bin", append(tests,
+		compareTestCase{"a  ", "a", 0},
+		compareTestCase{"", "  ", 0},
+		compareTestCase{"a\t", "a", 1},
+	))
+	testCompare(c, "binary", append(tests,
+		compareTestCase{"a  ", "a", 1},
+		compareTestCase{"", "  ", -1},
+	))
+	testCompare(c, "latin1_bin", []compareTestCase{{"A ", "A", 0}})
+	// The collations which have no comparison rule are compared like the _bin ones.
+	testCompare(c, "utf8mb4_german2_ci", []compareTestCase{{"a ", "a", 0}, {"a", "A", 1}})
+}
+
+func (s *testCollatorSuite) TestGeneralCICollation(c *C) {
+	tests := []compareTestCase{
+		{"a", "A", 0},
+		{"a  ", "A", 0},
+		{"À", "a", 0},
+		{"Ǎ", "a", 0},
+		{"ß", "s", 0},
+		{"ß", "ss", -1},
+		{"æ", "Æ", 0},
+		{"Ø", "O", 1},
+		{"a", "b", -1},
+		{"😃", "😄", 0},
+		{"😃", "�", 0},
+		{"中", "文", -1},
+	}
+	testCompare(c, "utf8mb4_general_ci", tests)
+	testCompare(c, "utf8_general_ci", tests)
+}
+
+func (s *testCollatorSuite) TestUnicodeCICollation(c *C) {
+	tests := []compareTestCase{
+		{"a", "A", 0},
+		{"À", "a", 0},
+		{"ß", "ss", 0},
+		{"æ", "ae", 0},
+		{"ｱ", "ア", 0},
+		{"a", "b", -1},
+		{"Ä", "Z", -1},
+	}
+	// The UCA 4.0.0 collations weigh the supplementary characters as U+FFFD.
+	testCompare(c, "utf8mb4_unicode_ci", append(tests, compareTestCase{"a  ", "A", 0}, compareTestCase{"😃", "😄", 0}, compareTestCase{"😃", "�", 0}))
+	testCompare(c, "utf8_unicode_ci", append(tests, compareTestCase{"a  ", "A", 0}, compareTestCase{"😃", "😄", 0}))
+	testCompare(c, "utf8mb4_0900_ai_ci", append(tests, compareTestCase{"a  ", "A", 1}, compareTestCase{"a", "a ", -1}, compareTestCase{"😃", "😄", -1}))
+}
+
+func (s *testCollatorSuite) TestLatin1SwedishCICollation(c *C) {
+	testCompare(c, "latin1_swedish_ci", []compareTestCase{
+		{"a", "A", 0},
+		{"a  ", "A", 0},
+		{"é", "E", 0},
+		{"ü", "y", 0},
+		{"Ý", "Y", 0},
+		{"ù", "U", 0},
+		{"Ú", "u", 0},
+		{"û", "ü", -1},
+		{"Ù", "V", -1},
+		{"ä", "Æ", 0},
+		{"Å", "Z", 1},
+		{"Å", "Ä", -1},
+		{"Ö", "Ä", 1},
+		// The characters which aren't in latin1 are '?'.
+		{"中", "?", 0},
+	})
+}
+
+func (s *testCollatorSuite) TestGBKChineseCICollation(c *C) {
+	testCompare(c, "gbk_chinese_ci", []compareTestCase{
+		{"a", "A", 0},
+		{"a  ", "A", 0},
+		{"a", "中", -1},
+		// The characters are ordered by pinyin, i.e. their GBK codes.
+		{"丁", "一", -1},
+		{"啊", "阿", -1},
+		{"é", "e", 1},
+		{"😃", "?", 0},
+	})
+}
+
+func (s *testCollatorSuite) TestPadSpace(c *C) {
+	for name, padSpace := range map[string]bool{
+		"utf8mb4_bin":        true,
+		"binary":             false,
+		"utf8mb4_general_ci": true,
+		"utf8mb4_unicode_ci": true,
+		"utf8mb4_0900_ai_ci": false,
+		"latin1_swedish_ci":  true,
+		"gbk_chinese_ci":     true,
+	} {
+		coll, err := charset.GetCollationByName(name)
+		c.Assert(err, IsNil)
+		c.Assert(coll.IsPadSpace(), Equals, padSpace, Commentf("%s", name))
+	}
+}
+
+func (s *testCollatorSuite) TestSortByKey(c *C) {
+	coll, err := charset.GetCollationByName("UTF8MB4_GENERAL_CI")
+	c.Assert(err, IsNil)
+	strs := []string{"b", "Á", "c ", "a", "B", "C"}
+	sort.SliceStable(strs, func(i, j int) bool {
+		return bytes.Compare(coll.Key(strs[i]), coll.Key(strs[j])) < 0
+	})
+	c.Assert(strs, DeepEquals, []string{"Á", "a", "b", "B", "c ", "C"})
+}
@@ -241,11 +241,13 @@ var encodings = map[string]struct {
 	"shift-jis":           {japanese.ShiftJIS, "shift_jis"},
 	"shift_jis":           {japanese.ShiftJIS, "shift_jis"},
 	"sjis":                {japanese.ShiftJIS, "shift_jis"},
+	"cp932":               {japanese.ShiftJIS, "shift_jis"},
 	"windows-31j":         {japanese.ShiftJIS, "shift_jis"},
 	"x-sjis":              {japanese.ShiftJIS, "shift_jis"},
 	"cseuckr":             {korean.EUCKR, "euc-kr"},
 	"csksc56011987":       {korean.EUCKR, "euc-kr"},
 	"euc-kr":              {korean.EUCKR, "euc-kr"},
+	"euckr":               {korean.EUCKR, "euc-kr"},
 	"iso-ir-149":          {korean.EUCKR, "euc-kr"},
 	"korean":              {korean.EUCKR, "euc-kr"},
 	"ks_c_5601-1987":      {korean.EUCKR, "euc-kr"},
@@ -258,8 +260,10 @@ var encodings = map[string]struct {
 	"iso-2022-cn":         {encoding.Replacement, "replacement"},
 	"iso-2022-cn-ext":     {encoding.Replacement, "replacement"},
 	"utf-16be":            {unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "utf-16be"},
+	"utf16":               {unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "utf-16be"}, // MySQL utf16 is big-endian.
 	"utf-16":              {unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "utf-16le"},
 	"utf-16le":            {unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "utf-16le"},
+	"utf16le":             {unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "utf-16le"},
 	"x-user-defined":      {charmap.XUserDefined, "x-user-defined"},
 }
 
@@ -280,6 +284,58 @@ var encodingNextCharacterLength = map[string]func([]byte) int{
 		}
 		return 2
 	},
+	// https://en.wikipedia.org/wiki/GB_18030#Mapping
+	"gb18030": func(bs []byte) int {
+		if len(bs) == 0 || bs[0] < 0x81 || bs[0] > 0xfe {
+			return 1
+		}
+		// The second byte of a four-byte sequence is a digit.
+		if len(bs) > 1 && bs[1] >= 0x30 && bs[1] <= 0x39 {
+			return 4
+		}
+		return 2
+	},
+	// https://en.wikipedia.org/wiki/Big5#Organization
+	"big5": func(bs []byte) int {
+		if len(bs) == 0 || bs[0] < 0x81 || bs[0] > 0xfe {
+			return 1
+		}
+		return 2
+	},
+	// https://en.wikipedia.org/wiki/Shift_JIS#Shift_JIS_byte_map
+	"shift_jis": func(bs []byte) int {
+		if len(bs) == 0 {
+			return 1
+		}
+		// 0xA1-0xDF are the single-byte half-width katakana.
+		if (bs[0] >= 0x81 && bs[0] <= 0x9f) || (bs[0] >= 0xe0 && bs[0] <= 0xfc) {
+			return 2
+		}
+		return 1
+	},
+	// https://en.wikipedia.org/wiki/Extended_Unix_Code#EUC-KR
+	"euc-kr": func(bs []byte) int {
+		if len(bs) == 0 || bs[0] < 0x81 || bs[0] > 0xfe {
+			return 1
+		}
+		return 2
+	},
+	"windows-1252": func(bs []byte) int {
+		return 1
+	},
+	// A character is a code unit of 2 bytes, or a surrogate pair of 4 bytes.
+	"utf-16be": func(bs []byte) int {
+		if len(bs) >= 4 && bs[0] >= 0xd8 && bs[0] <= 0xdb && bs[2] >= 0xdc && bs[2] <= 0xdf {
+			return 4
+		}
+		return 2
+	},
+	"utf-16le": func(bs []byte) int {
+		if len(bs) >= 4 && bs[1] >= 0xd8 && bs[1] <= 0xdb && bs[3] >= 0xdc && bs[3] <= 0xdf {
+			return 4
+		}
+		return 2
+	},
 	"utf-8": func(bs []byte) int {
 		if len(bs) == 0 || bs[0] < 0x80 {
 			return 1
@@ -74,3 +74,53 @@ func (s *testEncodingSuite) TestEncoding(c *C) {
 		c.Assert(string(result), Equals, tc.result, Commentf("%v", tc))
 	}
 }
+
+func (s *testEncodingSuite) TestClientEncodings(c *C) {
+	tests := []struct {
+		label   string
+		name    string
+		utf8Str string
+		// invalid is a string which can't be decoded, and result is its decoded string.
+		invalid string
+		result  string
+	}{
+		{"big5", "big5", "中文測試abc", "a\xff\x40b", "a?@b"},
+		{"sjis", "shift_jis", "日本語ﾃｽﾄabc", "a\x81\x20b", "a?b"},
+		{"cp932", "shift_jis", "日本語テスト", "\x85\x40", "?"},
+		{"euckr", "euc-kr", "한국어abc", "a\xa1\x20b", "a?b"},
+		{"gb18030", "gb18030", "中文𠀀€abc", "a\x81\x30\x81b", "a?"},
+		{"latin1", "windows-1252", "Ünïcödé€", "", ""},
+		{"utf16", "utf-16be", "中文𠀀abc", "\xd8\x00\x00a", "?a"},
+		{"utf16le", "utf-16le", "中文𠀀abc", "\x00\xd8a\x00", "?a"},
+	}
+	for _, tc := range tests {
+		cmt := Commentf("%v", tc)
+		enc := charset.NewEncoding(tc.label)
+		c.Assert(enc.Name(), Equals, tc.name, cmt)
+		c.Assert(enc.Enabled(), IsTrue, cmt)
+
+		encoded, err := enc.Encode(nil, []byte(tc.utf8Str))
+		c.Assert(err, IsNil, cmt)
+		result, err := enc.Decode(nil, encoded)
+		c.Assert(err, IsNil, cmt)
+		c.Assert(string(result), Equals, tc.utf8Str, cmt)
+
+		// The characters are decoded one by one.
+		charLength := charset.FindNextCharacterLength(tc.name)
+		c.Assert(charLength, NotNil, cmt)
+		var n int
+		for len(encoded) > 0 {
+			l := charLength(encoded)
+			c.Assert(l <= len(encoded), IsTrue, cmt)
+			encoded = encoded[l:]
+			n++
+		}
+		c.Assert(n, Equals, len([]rune(tc.utf8Str)), cmt)
+
+		if tc.invalid != "" {
+			result, err = enc.Decode(nil, []byte(tc.invalid))
+			c.Assert(err, NotNil, cmt)
+			c.Assert(string(result), Equals, tc.result, cmt)
+		}
+	}
+}
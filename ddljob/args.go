@@ -0,0 +1,344 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ddljob reads the DDL jobs of TiDB, whose arguments are saved as
+// positional JSON arrays in model.Job.RawArgs. It also exports the jobs as
+// records with a stable JSON encoding, and rebuilds their statements.
+package ddljob
+
+import (
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+)
+
+// CreateSchemaArgs is the arguments of ActionCreateSchema.
+type CreateSchemaArgs struct {
+	DBInfo *model.DBInfo `arg:"0"`
+}
+
+// ModifySchemaCharsetAndCollateArgs is the arguments of ActionModifySchemaCharsetAndCollate.
+type ModifySchemaCharsetAndCollateArgs struct {
+	ToCharset string `arg:"0"`
+	ToCollate string `arg:"1"`
+}
+
+// ModifySchemaDefaultPlacementArgs is the arguments of ActionModifySchemaDefaultPlacement.
+type ModifySchemaDefaultPlacementArgs struct {
+	PolicyRef           *model.PolicyRefInfo     `arg:"0"`
+	DirectPlacementOpts *model.PlacementSettings `arg:"1"`
+}
+
+// DropSchemaArgs is the arguments of ActionDropSchema.
+type DropSchemaArgs struct {
+	// TableIDs are the IDs of the dropped tables, which are set when the job is done.
+	TableIDs []int64 `arg:"0"`
+}
+
+// CreateTableArgs is the arguments of ActionCreateTable, ActionCreateSequence
+// and ActionRepairTable.
+type CreateTableArgs struct {
+	TableInfo *model.TableInfo `arg:"0"`
+}
+
+// CreateViewArgs is the arguments of ActionCreateView.
+type CreateViewArgs struct {
+	TableInfo *model.TableInfo `arg:"0"`
+	OrReplace bool             `arg:"1"`
+	// OldViewTableID is the ID of the replaced view.
+	OldViewTableID int64 `arg:"2"`
+}
+
+// DropTableArgs is the arguments of ActionDropTable, ActionDropView and
+// ActionDropSequence. They are set when the job is done.
+type DropTableArgs struct {
+	StartKey     []byte  `arg:"0"`
+	PartitionIDs []int64 `arg:"1"`
+}
+
+// TruncateTableArgs is the arguments of ActionTruncateTable.
+type TruncateTableArgs struct {
+	NewTableID int64 `arg:"0"`
+}
+
+// RecoverTableArgs is the arguments of ActionRecoverTable.
+type RecoverTableArgs struct {
+	TableInfo  *model.TableInfo `arg:"0"`
+	AutoIncID  int64            `arg:"1"`
+	DropJobID  int64            `arg:"2"`
+	SnapshotTS uint64           `arg:"3"`
+	CheckFlag  int64            `arg:"4"`
+	AutoRandID int64            `arg:"5,since=2"`
+}
+
+// RenameTableArgs is the arguments of ActionRenameTable.
+type RenameTableArgs struct {
+	OldSchemaID  int64       `arg:"0"`
+	NewTableName model.CIStr `arg:"1"`
+}
+
+// RenameTablesArgs is the arguments of ActionRenameTables.
+type RenameTablesArgs struct {
+	OldSchemaIDs   []int64        `arg:"0"`
+	NewSchemaIDs   []int64        `arg:"1"`
+	NewTableNames  []*model.CIStr `arg:"2"`
+	TableIDs       []int64        `arg:"3"`
+	OldSchemaNames []*model.CIStr `arg:"4,since=2"`
+}
+
+// AddColumnArgs is the arguments of ActionAddColumn.
+type AddColumnArgs struct {
+	Column      *model.ColumnInfo   `arg:"0"`
+	Position    *ast.ColumnPosition `arg:"1"`
+	Offset      int                 `arg:"2"`
+	IfNotExists bool                `arg:"3,since=2"`
+}
+
+// AddColumnsArgs is the arguments of ActionAddColumns.
+type AddColumnsArgs struct {
+	Columns     []*model.ColumnInfo   `arg:"0"`
+	Positions   []*ast.ColumnPosition `arg:"1"`
+	Offsets     []int                 `arg:"2"`
+	IfNotExists []bool                `arg:"3"`
+}
+
+// DropColumnArgs is the arguments of ActionDropColumn.
+type DropColumnArgs struct {
+	ColumnName model.CIStr `arg:"0"`
+	IfExists   bool        `arg:"1,since=2"`
+}
+
+// DropColumnsArgs is the arguments of ActionDropColumns.
+type DropColumnsArgs struct {
+	ColumnNames []model.CIStr `arg:"0"`
+	IfExists    []bool        `arg:"1"`
+}
+
+// ModifyColumnArgs is the arguments of ActionModifyColumn.
+type ModifyColumnArgs struct {
+	Column        *model.ColumnInfo   `arg:"0"`
+	OldColumnName *model.CIStr        `arg:"1"`
+	Position      *ast.ColumnPosition `arg:"2"`
+	// ModifyColumnType is mysql.TypeNull if the column is changed from NULL to NOT NULL.
+	ModifyColumnType      byte   `arg:"3,since=2"`
+	UpdatedAutoRandomBits uint64 `arg:"4,since=3"`
+}
+
+// SetDefaultValueArgs is the arguments of ActionSetDefaultValue.
+type SetDefaultValueArgs struct {
+	Column *model.ColumnInfo `arg:"0"`
+}
+
+// AddIndexArgs is the arguments of ActionAddIndex.
+type AddIndexArgs struct {
+	Unique                  bool                          `arg:"0"`
+	IndexName               model.CIStr                   `arg:"1"`
+	IndexPartSpecifications []*ast.IndexPartSpecification `arg:"2"`
+	IndexOption             *ast.IndexOption              `arg:"3"`
+	// HiddenCols are the hidden columns of the expression index.
+	HiddenCols []*model.ColumnInfo `arg:"4,since=2"`
+	Global     bool                `arg:"5,since=3"`
+}
+
+// AddPrimaryKeyArgs is the arguments of ActionAddPrimaryKey.
+type AddPrimaryKeyArgs struct {
+	Unique                  bool                          `arg:"0"`
+	IndexName               model.CIStr                   `arg:"1"`
+	IndexPartSpecifications []*ast.IndexPartSpecification `arg:"2"`
+	IndexOption             *ast.IndexOption              `arg:"3"`
+	SQLMode                 mysql.SQLMode                 `arg:"4"`
+	// The argument 5 is reserved for the warnings, which are always null.
+	Global bool `arg:"6,since=2"`
+}
+
+// DropIndexArgs is the arguments of ActionDropIndex and ActionDropPrimaryKey.
+type DropIndexArgs struct {
+	IndexName model.CIStr `arg:"0"`
+	IfExists  bool        `arg:"1,since=2"`
+}
+
+// DropIndexesArgs is the arguments of ActionDropIndexes.
+type DropIndexesArgs struct {
+	IndexNames []model.CIStr `arg:"0"`
+	IfExists   []bool        `arg:"1"`
+}
+
+// RenameIndexArgs is the arguments of ActionRenameIndex.
+type RenameIndexArgs struct {
+	From model.CIStr `arg:"0"`
+	To   model.CIStr `arg:"1"`
+}
+
+// AlterIndexVisibilityArgs is the arguments of ActionAlterIndexVisibility.
+type AlterIndexVisibilityArgs struct {
+	IndexName model.CIStr `arg:"0"`
+	Invisible bool        `arg:"1"`
+}
+
+// AddForeignKeyArgs is the arguments of ActionAddForeignKey.
+type AddForeignKeyArgs struct {
+	FKInfo *model.FKInfo `arg:"0"`
+}
+
+// DropForeignKeyArgs is the arguments of ActionDropForeignKey.
+type DropForeignKeyArgs struct {
+	FKName model.CIStr `arg:"0"`
+}
+
+// RebaseAutoIDArgs is the arguments of ActionRebaseAutoID and ActionRebaseAutoRandomBase.
+type RebaseAutoIDArgs struct {
+	NewBase int64 `arg:"0"`
+	Force   bool  `arg:"1,since=2"`
+}
+
+// ShardRowIDArgs is the arguments of ActionShardRowID.
+type ShardRowIDArgs struct {
+	ShardRowIDBits uint64 `arg:"0"`
+}
+
+// ModifyTableCommentArgs is the arguments of ActionModifyTableComment.
+type ModifyTableCommentArgs struct {
+	Comment string `arg:"0"`
+}
+
+// ModifyTableAutoIDCacheArgs is the arguments of ActionModifyTableAutoIdCache.
+type ModifyTableAutoIDCacheArgs struct {
+	NewCache int64 `arg:"0"`
+}
+
+// ModifyTableCharsetAndCollateArgs is the arguments of ActionModifyTableCharsetAndCollate.
+type ModifyTableCharsetAndCollateArgs struct {
+	ToCharset string `arg:"0"`
+	ToCollate string `arg:"1"`
+	// NeedsOverwriteCols is true for ALTER TABLE ... CONVERT TO CHARACTER SET.
+	NeedsOverwriteCols bool `arg:"2,since=2"`
+}
+
+// AddTablePartitionArgs is the arguments of ActionAddTablePartition.
+type AddTablePartitionArgs struct {
+	PartInfo *model.PartitionInfo `arg:"0"`
+}
+
+// DropTablePartitionArgs is the arguments of ActionDropTablePartition. They
+// are replaced by DroppedTablePartitionArgs when the job is done.
+type DropTablePartitionArgs struct {
+	PartitionNames []string `arg:"0"`
+}
+
+// DroppedTablePartitionArgs is the arguments of a done ActionDropTablePartition
+// job, whose data is deleted in the background.
+type DroppedTablePartitionArgs struct {
+	PhysicalTableIDs []int64 `arg:"0"`
+}
+
+// TruncateTablePartitionArgs is the arguments of ActionTruncateTablePartition.
+type TruncateTablePartitionArgs struct {
+	PartitionIDs []int64 `arg:"0"`
+}
+
+// ExchangeTablePartitionArgs is the arguments of ActionExchangeTablePartition.
+// The job belongs to the non-partitioned table.
+type ExchangeTablePartitionArgs struct {
+	PartitionID              int64  `arg:"0"`
+	PartitionedTableSchemaID int64  `arg:"1"`
+	PartitionedTableID       int64  `arg:"2"`
+	PartitionName            string `arg:"3"`
+	WithValidation           bool   `arg:"4"`
+}
+
+// SetTiFlashReplicaArgs is the arguments of ActionSetTiFlashReplica.
+type SetTiFlashReplicaArgs struct {
+	Replica *ast.TiFlashReplicaSpec `arg:"0"`
+}
+
+// UpdateTiFlashReplicaStatusArgs is the arguments of ActionUpdateTiFlashReplicaStatus.
+type UpdateTiFlashReplicaStatusArgs struct {
+	Available  bool  `arg:"0"`
+	PhysicalID int64 `arg:"1"`
+}
+
+// AlterSequenceArgs is the arguments of ActionAlterSequence.
+type AlterSequenceArgs struct {
+	Ident   ast.Ident             `arg:"0"`
+	Options []*ast.SequenceOption `arg:"1"`
+}
+
+// PlacementPolicyArgs is the arguments of ActionCreatePlacementPolicy and
+// ActionAlterPlacementPolicy.
+type PlacementPolicyArgs struct {
+	Policy *model.PolicyInfo `arg:"0"`
+}
+
+// DropPlacementPolicyArgs is the arguments of ActionDropPlacementPolicy.
+type DropPlacementPolicyArgs struct {
+	PolicyName model.CIStr `arg:"0"`
+}
+
+// doneArgs returns the typed arguments of the done jobs whose arguments are
+// replaced when they are done.
+var doneArgs = map[model.ActionType]func() interface{}{
+	model.ActionDropTablePartition: func() interface{} { return &DroppedTablePartitionArgs{} },
+}
+
+func init() {
+	for tp, newArgs := range map[model.ActionType]func() interface{}{
+		model.ActionCreateSchema:                  func() interface{} { return &CreateSchemaArgs{} },
+		model.ActionModifySchemaCharsetAndCollate: func() interface{} { return &ModifySchemaCharsetAndCollateArgs{} },
+		model.ActionModifySchemaDefaultPlacement:  func() interface{} { return &ModifySchemaDefaultPlacementArgs{} },
+		model.ActionDropSchema:                    func() interface{} { return &DropSchemaArgs{} },
+		model.ActionCreateTable:                   func() interface{} { return &CreateTableArgs{} },
+		model.ActionCreateSequence:                func() interface{} { return &CreateTableArgs{} },
+		model.ActionRepairTable:                   func() interface{} { return &CreateTableArgs{} },
+		model.ActionCreateView:                    func() interface{} { return &CreateViewArgs{} },
+		model.ActionDropTable:                     func() interface{} { return &DropTableArgs{} },
+		model.ActionDropView:                      func() interface{} { return &DropTableArgs{} },
+		model.ActionDropSequence:                  func() interface{} { return &DropTableArgs{} },
+		model.ActionTruncateTable:                 func() interface{} { return &TruncateTableArgs{} },
+		model.ActionRecoverTable:                  func() interface{} { return &RecoverTableArgs{} },
+		model.ActionRenameTable:                   func() interface{} { return &RenameTableArgs{} },
+		model.ActionRenameTables:                  func() interface{} { return &RenameTablesArgs{} },
+		model.ActionAddColumn:                     func() interface{} { return &AddColumnArgs{} },
+		model.ActionAddColumns:                    func() interface{} { return &AddColumnsArgs{} },
+		model.ActionDropColumn:                    func() interface{} { return &DropColumnArgs{} },
+		model.ActionDropColumns:                   func() interface{} { return &DropColumnsArgs{} },
+		model.ActionModifyColumn:                  func() interface{} { return &ModifyColumnArgs{} },
+		model.ActionSetDefaultValue:               func() interface{} { return &SetDefaultValueArgs{} },
+		model.ActionAddIndex:                      func() interface{} { return &AddIndexArgs{} },
+		model.ActionAddPrimaryKey:                 func() interface{} { return &AddPrimaryKeyArgs{} },
+		model.ActionDropIndex:                     func() interface{} { return &DropIndexArgs{} },
+		model.ActionDropPrimaryKey:                func() interface{} { return &DropIndexArgs{} },
+		model.ActionDropIndexes:                   func() interface{} { return &DropIndexesArgs{} },
+		model.ActionRenameIndex:                   func() interface{} { return &RenameIndexArgs{} },
+		model.ActionAlterIndexVisibility:          func() interface{} { return &AlterIndexVisibilityArgs{} },
+		model.ActionAddForeignKey:                 func() interface{} { return &AddForeignKeyArgs{} },
+		model.ActionDropForeignKey:                func() interface{} { return &DropForeignKeyArgs{} },
+		model.ActionRebaseAutoID:                  func() interface{} { return &RebaseAutoIDArgs{} },
+		model.ActionRebaseAutoRandomBase:          func() interface{} { return &RebaseAutoIDArgs{} },
+		model.ActionShardRowID:                    func() interface{} { return &ShardRowIDArgs{} },
+		model.ActionModifyTableComment:            func() interface{} { return &ModifyTableCommentArgs{} },
+		model.ActionModifyTableAutoIdCache:        func() interface{} { return &ModifyTableAutoIDCacheArgs{} },
+		model.ActionModifyTableCharsetAndCollate:  func() interface{} { return &ModifyTableCharsetAndCollateArgs{} },
+		model.ActionAddTablePartition:             func() interface{} { return &AddTablePartitionArgs{} },
+		model.ActionDropTablePartition:            func() interface{} { return &DropTablePartitionArgs{} },
+		model.ActionTruncateTablePartition:        func() interface{} { return &TruncateTablePartitionArgs{} },
+		model.ActionExchangeTablePartition:        func() interface{} { return &ExchangeTablePartitionArgs{} },
+		model.ActionSetTiFlashReplica:             func() interface{} { return &SetTiFlashReplicaArgs{} },
+		model.ActionUpdateTiFlashReplicaStatus:    func() interface{} { return &UpdateTiFlashReplicaStatusArgs{} },
+		model.ActionAlterSequence:                 func() interface{} { return &AlterSequenceArgs{} },
+		model.ActionCreatePlacementPolicy:         func() interface{} { return &PlacementPolicyArgs{} },
+		model.ActionAlterPlacementPolicy:          func() interface{} { return &PlacementPolicyArgs{} },
+		model.ActionDropPlacementPolicy:           func() interface{} { return &DropPlacementPolicyArgs{} },
+	} {
+		Register(tp, newArgs)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddljob
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testArgsSuite{})
+
+type testArgsSuite struct {
+}
+
+// encodeJob encodes and decodes a job like the DDL owner saves it.
+func encodeJob(c *C, tp model.ActionType, args ...interface{}) *model.Job {
+	job := &model.Job{ID: 1, Type: tp, Args: args}
+	b, err := job.Encode(true)
+	c.Assert(err, IsNil)
+	decoded := &model.Job{}
+	c.Assert(decoded.Decode(b), IsNil)
+	return decoded
+}
+
+func (s *testArgsSuite) TestDecodeArgs(c *C) {
+	col := &model.ColumnInfo{ID: 3, Name: model.NewCIStr("c")}
+	pos := &ast.ColumnPosition{Tp: ast.ColumnPositionAfter, RelativeColumn: &ast.ColumnName{Name: model.NewCIStr("a")}}
+	job := encodeJob(c, model.ActionAddColumn, col, pos, 2)
+	args, err := DecodeArgs(job)
+	c.Assert(err, IsNil)
+	addColumn, ok := args.(*AddColumnArgs)
+	c.Assert(ok, IsTrue)
+	c.Assert(addColumn.Column.ID, Equals, int64(3))
+	c.Assert(addColumn.Column.Name.O, Equals, "c")
+	c.Assert(addColumn.Position.Tp, Equals, ast.ColumnPositionAfter)
+	c.Assert(addColumn.Position.RelativeColumn.Name.L, Equals, "a")
+	c.Assert(addColumn.Offset, Equals, 2)
+	c.Assert(addColumn.IfNotExists, IsFalse)
+	version, err := ArgsVersion(job)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, 1)
+
+	// The arguments appended by newer servers are ignored.
+	job = encodeJob(c, model.ActionRenameIndex, model.NewCIStr("i1"), model.NewCIStr("i2"), "unknown")
+	args, err = DecodeArgs(job)
+	c.Assert(err, IsNil)
+	c.Assert(args, DeepEquals, &RenameIndexArgs{From: model.NewCIStr("i1"), To: model.NewCIStr("i2")})
+
+	job = encodeJob(c, model.ActionAddPrimaryKey, false, model.NewCIStr("PRIMARY"),
+		[]*ast.IndexPartSpecification{{Column: &ast.ColumnName{Name: model.NewCIStr("a")}, Length: -1}},
+		&ast.IndexOption{Comment: "pk"}, mysql.ModeStrictTransTables, nil, true)
+	args, err = DecodeArgs(job)
+	c.Assert(err, IsNil)
+	pk := args.(*AddPrimaryKeyArgs)
+	c.Assert(pk.IndexName.O, Equals, "PRIMARY")
+	c.Assert(pk.IndexPartSpecifications, HasLen, 1)
+	c.Assert(pk.IndexPartSpecifications[0].Column.Name.O, Equals, "a")
+	c.Assert(pk.IndexOption.Comment, Equals, "pk")
+	c.Assert(pk.SQLMode, Equals, mysql.ModeStrictTransTables)
+	c.Assert(pk.Global, IsTrue)
+	version, err = ArgsVersion(job)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, 2)
+
+	// A job without arguments.
+	job = encodeJob(c, model.ActionDropTable)
+	args, err = DecodeArgs(job)
+	c.Assert(err, IsNil)
+	c.Assert(args, DeepEquals, &DropTableArgs{})
+	version, err = ArgsVersion(job)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, 0)
+
+	job = encodeJob(c, model.ActionLockTable)
+	_, err = DecodeArgs(job)
+	c.Assert(err, ErrorMatches, "no typed arguments are registered for action lock table")
+
+	job = encodeJob(c, model.ActionRenameTable, "x")
+	_, err = DecodeArgs(job)
+	c.Assert(err, NotNil)
+
+	// The arguments of a done DROP PARTITION job are the IDs of the dropped partitions.
+	job = &model.Job{}
+	c.Assert(job.Decode([]byte(`{"id":58,"type":20,"schema_id":1,"table_id":54,"schema_name":"test","state":6,`+
+		`"err":null,"err_count":0,"row_count":0,"raw_args":[[55,56]],"schema_state":0,"snapshot_ver":0,`+
+		`"real_start_ts":0,"start_ts":426046452373635073,"dependency_id":0,"query":"alter table t drop partition p0, p1",`+
+		`"binlog":{"SchemaVersion":60,"DBInfo":null,"TableInfo":null,"FinishedTS":426046452386742273},`+
+		`"version":1,"reorg_meta":null,"multi_schema_info":null,"priority":0}`)), IsNil)
+	args, err = DecodeArgs(job)
+	c.Assert(err, IsNil)
+	c.Assert(args, DeepEquals, &DroppedTablePartitionArgs{PhysicalTableIDs: []int64{55, 56}})
+	job.State = model.JobStateRunning
+	job.RawArgs = []byte(`[["p0","p1"]]`)
+	args, err = DecodeArgs(job)
+	c.Assert(err, IsNil)
+	c.Assert(args, DeepEquals, &DropTablePartitionArgs{PartitionNames: []string{"p0", "p1"}})
+}
+
+func (s *testArgsSuite) TestMarshal(c *C) {
+	args := &ModifyColumnArgs{
+		Column:                &model.ColumnInfo{Name: model.NewCIStr("b")},
+		OldColumnName:         &model.CIStr{O: "a", L: "a"},
+		ModifyColumnType:      mysql.TypeNull,
+		UpdatedAutoRandomBits: 5,
+	}
+	job := &model.Job{Type: model.ActionModifyColumn}
+	c.Assert(Marshal(job, args, 0), IsNil)
+	c.Assert(job.Args, HasLen, 5)
+	version, err := ArgsVersion(job)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, 3)
+
+	// The arguments can be decoded positionally.
+	var (
+		col     model.ColumnInfo
+		oldName model.CIStr
+		pos     *ast.ColumnPosition
+		tp      byte
+		bits    uint64
+	)
+	c.Assert(job.DecodeArgs(&col, &oldName, &pos, &tp, &bits), IsNil)
+	c.Assert(col.Name.O, Equals, "b")
+	c.Assert(oldName.O, Equals, "a")
+	c.Assert(pos, IsNil)
+	c.Assert(tp, Equals, mysql.TypeNull)
+	c.Assert(bits, Equals, uint64(5))
+
+	// The arguments of an older version.
+	c.Assert(Marshal(job, args, 1), IsNil)
+	c.Assert(job.Args, HasLen, 3)
+	decoded := &ModifyColumnArgs{}
+	c.Assert(Unmarshal(job, decoded), IsNil)
+	c.Assert(decoded.OldColumnName.O, Equals, "a")
+	c.Assert(decoded.UpdatedAutoRandomBits, Equals, uint64(0))
+	version, err = ArgsVersion(job)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, 1)
+
+	// The gaps between the arguments are null.
+	c.Assert(Marshal(job, &AddPrimaryKeyArgs{Global: true}, 0), IsNil)
+	c.Assert(job.Args, HasLen, 7)
+	c.Assert(job.Args[5], IsNil)
+
+	c.Assert(Marshal(job, AddPrimaryKeyArgs{}, 0), ErrorMatches, "job arguments must be a non-nil pointer to struct.*")
+	c.Assert(Unmarshal(job, (*AddPrimaryKeyArgs)(nil)), NotNil)
+	type badArgs struct {
+		A int `arg:"x"`
+	}
+	c.Assert(Marshal(job, &badArgs{}, 0), ErrorMatches, "invalid arg tag.*")
+}
+
+func (s *testArgsSuite) TestRegister(c *C) {
+	c.Assert(func() { Register(model.ActionAddColumn, func() interface{} { return &AddColumnArgs{} }) }, PanicMatches, ".*twice.*")
+	args, err := NewArgs(model.ActionRebaseAutoRandomBase)
+	c.Assert(err, IsNil)
+	c.Assert(args, FitsTypeOf, &RebaseAutoIDArgs{})
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddljob
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// The arguments of a job are saved in model.Job.RawArgs as a JSON array. A
+// typed argument struct maps its fields to the array with `arg` tags:
+//
+//	Position *ast.ColumnPosition `arg:"1"`
+//	Offset   int                 `arg:"2,since=2"`
+//
+// The number is the position of the argument in the array, and since is the
+// version of the argument list which appends the argument. It is 1 if omitted.
+// The arguments missing in old jobs are left zero, and the unknown arguments
+// appended by newer servers are ignored.
+
+// argField is a field of a typed argument struct.
+type argField struct {
+	index    int
+	position int
+	since    int
+}
+
+var fieldsCache sync.Map // map[reflect.Type][]argField
+
+func argFields(tp reflect.Type) ([]argField, error) {
+	if fields, ok := fieldsCache.Load(tp); ok {
+		return fields.([]argField), nil
+	}
+	var fields []argField
+	for i := 0; i < tp.NumField(); i++ {
+		tag, ok := tp.Field(i).Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+		f := argField{index: i, since: 1}
+		parts := strings.Split(tag, ",")
+		var err error
+		if f.position, err = strconv.Atoi(parts[0]); err != nil || f.position < 0 {
+			return nil, errors.Errorf("invalid arg tag %q of %s.%s", tag, tp.Name(), tp.Field(i).Name)
+		}
+		for _, part := range parts[1:] {
+			if !strings.HasPrefix(part, "since=") {
+				return nil, errors.Errorf("invalid arg tag %q of %s.%s", tag, tp.Name(), tp.Field(i).Name)
+			}
+			if f.since, err = strconv.Atoi(strings.TrimPrefix(part, "since=")); err != nil || f.since < 1 {
+				return nil, errors.Errorf("invalid arg tag %q of %s.%s", tag, tp.Name(), tp.Field(i).Name)
+			}
+		}
+		fields = append(fields, f)
+	}
+	fieldsCache.Store(tp, fields)
+	return fields, nil
+}
+
+// structValue returns the struct a pointer points to.
+func structValue(args interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(args)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("job arguments must be a non-nil pointer to struct, got %T", args)
+	}
+	return v.Elem(), nil
+}
+
+func rawArgs(job *model.Job) ([]json.RawMessage, error) {
+	var raw []json.RawMessage
+	if len(job.RawArgs) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(job.RawArgs, &raw); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return raw, nil
+}
+
+// Unmarshal decodes the raw arguments of a job into a typed argument struct.
+// args must be a pointer to the struct.
+func Unmarshal(job *model.Job, args interface{}) error {
+	v, err := structValue(args)
+	if err != nil {
+		return err
+	}
+	fields, err := argFields(v.Type())
+	if err != nil {
+		return err
+	}
+	raw, err := rawArgs(job)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.position >= len(raw) {
+			continue
+		}
+		if err := json.Unmarshal(raw[f.position], v.Field(f.index).Addr().Interface()); err != nil {
+			return errors.Annotatef(err, "decode argument %d of %s job", f.position, job.Type)
+		}
+	}
+	return nil
+}
+
+// Marshal encodes a typed argument struct into the arguments of a job. Only
+// the arguments of the given version of the argument list are encoded, so that
+// the job can be read by an older server. All the arguments are encoded if
+// version is 0.
+func Marshal(job *model.Job, args interface{}, version int) error {
+	v, err := structValue(args)
+	if err != nil {
+		return err
+	}
+	fields, err := argFields(v.Type())
+	if err != nil {
+		return err
+	}
+	var list []interface{}
+	for _, f := range fields {
+		if version > 0 && f.since > version {
+			continue
+		}
+		for len(list) <= f.position {
+			list = append(list, nil)
+		}
+		list[f.position] = v.Field(f.index).Interface()
+	}
+	raw, err := json.Marshal(list)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	job.Args, job.RawArgs = list, raw
+	return nil
+}
+
+var registry = struct {
+	sync.RWMutex
+	newArgs map[model.ActionType]func() interface{}
+}{newArgs: make(map[model.ActionType]func() interface{})}
+
+// Register registers the typed arguments of an action type. newArgs returns a
+// pointer to a new argument struct. It panics if the action type is registered twice.
+func Register(tp model.ActionType, newArgs func() interface{}) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.newArgs[tp]; ok {
+		panic("ddljob: Register called twice for action " + tp.String())
+	}
+	registry.newArgs[tp] = newArgs
+}
+
+// NewArgs returns a pointer to a new argument struct of an action type.
+func NewArgs(tp model.ActionType) (interface{}, error) {
+	registry.RLock()
+	newArgs, ok := registry.newArgs[tp]
+	registry.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("no typed arguments are registered for action %s", tp)
+	}
+	return newArgs(), nil
+}
+
+// newJobArgs returns a pointer to a new argument struct of a job, which is
+// the one of its action type unless the arguments are replaced when the job is done.
+func newJobArgs(job *model.Job) (interface{}, error) {
+	if newArgs, ok := doneArgs[job.Type]; ok && (job.IsDone() || job.IsSynced()) {
+		return newArgs(), nil
+	}
+	return NewArgs(job.Type)
+}
+
+// DecodeArgs decodes the raw arguments of a job into the typed arguments
+// registered for its action type. The arguments of a done DROP PARTITION job
+// are decoded into DroppedTablePartitionArgs.
+func DecodeArgs(job *model.Job) (interface{}, error) {
+	args, err := newJobArgs(job)
+	if err != nil {
+		return nil, err
+	}
+	if err := Unmarshal(job, args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// ArgsVersion returns the version of the argument list of a job, which is the
+// largest version of the known arguments in it. It is 0 if the job has no arguments.
+func ArgsVersion(job *model.Job) (int, error) {
+	args, err := newJobArgs(job)
+	if err != nil {
+		return 0, err
+	}
+	fields, err := argFields(reflect.TypeOf(args).Elem())
+	if err != nil {
+		return 0, err
+	}
+	raw, err := rawArgs(job)
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for _, f := range fields {
+		if f.position < len(raw) && f.since > version {
+			version = f.since
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,341 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddljob
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/schema"
+)
+
+// BuildQuery builds a statement equivalent to a job from the arguments of the
+// job, for the jobs whose Query is missing. The names of the schema and the
+// table are taken from the history info of the job, so most jobs can only be
+// turned into statements once they are finished.
+// The expressions saved as strings in the arguments are parsed again, so a
+// parser driver (e.g. github.com/pingcap/parser/test_driver) must be imported.
+func BuildQuery(job *model.Job) (string, error) {
+	stmt, err := BuildStmt(job)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	ctx := format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestoreTiDBSpecialComment, &sb)
+	if err := stmt.Restore(ctx); err != nil {
+		return "", errors.Annotatef(err, "An error occurred while restore %s job", job.Type)
+	}
+	return sb.String(), nil
+}
+
+// BuildStmt builds a statement equivalent to a job from the arguments of the
+// job. See BuildQuery.
+func BuildStmt(job *model.Job) (ast.DDLNode, error) {
+	args, err := DecodeArgs(job)
+	if err != nil {
+		return nil, err
+	}
+	b := &queryBuilder{job: job}
+	b.schemaName, b.tableName = jobNames(job)
+	if job.BinlogInfo != nil {
+		b.tbInfo = job.BinlogInfo.TableInfo
+	}
+	if b.tbInfo == nil {
+		b.tbInfo = &model.TableInfo{Name: model.NewCIStr(b.tableName)}
+	}
+	stmt, err := b.build(args)
+	return stmt, errors.Trace(err)
+}
+
+type queryBuilder struct {
+	job        *model.Job
+	schemaName string
+	tableName  string
+	// tbInfo is the table after the job is done.
+	tbInfo *model.TableInfo
+}
+
+func (b *queryBuilder) unsupported() error {
+	return errors.Errorf("%s job %d can't be turned into a statement", b.job.Type, b.job.ID)
+}
+
+func (b *queryBuilder) table() (*ast.TableName, error) {
+	if b.tableName == "" {
+		return nil, errors.Errorf("the table name of %s job %d is unknown", b.job.Type, b.job.ID)
+	}
+	return &ast.TableName{Schema: model.NewCIStr(b.schemaName), Name: model.NewCIStr(b.tableName)}, nil
+}
+
+func (b *queryBuilder) schema() (string, error) {
+	if b.schemaName == "" {
+		return "", errors.Errorf("the schema name of %s job %d is unknown", b.job.Type, b.job.ID)
+	}
+	return b.schemaName, nil
+}
+
+func (b *queryBuilder) alterTable(specs ...*ast.AlterTableSpec) (ast.DDLNode, error) {
+	table, err := b.table()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.AlterTableStmt{Table: table, Specs: specs}, nil
+}
+
+func (b *queryBuilder) alterTableOptions(opts ...*ast.TableOption) (ast.DDLNode, error) {
+	return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableOption, Options: opts})
+}
+
+func (b *queryBuilder) build(args interface{}) (ast.DDLNode, error) {
+	switch args := args.(type) {
+	case *CreateSchemaArgs:
+		if args.DBInfo == nil {
+			return nil, b.unsupported()
+		}
+		stmt := &ast.CreateDatabaseStmt{Name: args.DBInfo.Name.O}
+		if args.DBInfo.Charset != "" {
+			stmt.Options = append(stmt.Options, &ast.DatabaseOption{Tp: ast.DatabaseOptionCharset, Value: args.DBInfo.Charset})
+		}
+		if args.DBInfo.Collate != "" {
+			stmt.Options = append(stmt.Options, &ast.DatabaseOption{Tp: ast.DatabaseOptionCollate, Value: args.DBInfo.Collate})
+		}
+		return stmt, nil
+	case *ModifySchemaCharsetAndCollateArgs:
+		name, err := b.schema()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.AlterDatabaseStmt{Name: name, Options: []*ast.DatabaseOption{
+			{Tp: ast.DatabaseOptionCharset, Value: args.ToCharset},
+			{Tp: ast.DatabaseOptionCollate, Value: args.ToCollate},
+		}}, nil
+	case *DropSchemaArgs:
+		name, err := b.schema()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.DropDatabaseStmt{Name: name}, nil
+	case *CreateTableArgs:
+		if b.job.Type != model.ActionCreateTable || args.TableInfo == nil {
+			return nil, b.unsupported()
+		}
+		stmt, err := schema.BuildCreateTableStmt(args.TableInfo)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Table.Schema = model.NewCIStr(b.schemaName)
+		return stmt, nil
+	case *DropTableArgs:
+		table, err := b.table()
+		if err != nil {
+			return nil, err
+		}
+		switch b.job.Type {
+		case model.ActionDropTable:
+			return &ast.DropTableStmt{Tables: []*ast.TableName{table}}, nil
+		case model.ActionDropView:
+			return &ast.DropTableStmt{Tables: []*ast.TableName{table}, IsView: true}, nil
+		case model.ActionDropSequence:
+			return &ast.DropSequenceStmt{Sequences: []*ast.TableName{table}}, nil
+		}
+	case *TruncateTableArgs:
+		table, err := b.table()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.TruncateTableStmt{Table: table}, nil
+	case *AddColumnArgs:
+		return b.addColumns([]*model.ColumnInfo{args.Column}, []*ast.ColumnPosition{args.Position}, []bool{args.IfNotExists})
+	case *AddColumnsArgs:
+		return b.addColumns(args.Columns, args.Positions, args.IfNotExists)
+	case *DropColumnArgs:
+		return b.dropColumns([]model.CIStr{args.ColumnName}, []bool{args.IfExists})
+	case *DropColumnsArgs:
+		return b.dropColumns(args.ColumnNames, args.IfExists)
+	case *ModifyColumnArgs:
+		return b.modifyColumn(args)
+	case *SetDefaultValueArgs:
+		if args.Column == nil {
+			return nil, b.unsupported()
+		}
+		def, err := schema.BuildColumnDef(b.tbInfo, args.Column)
+		if err != nil {
+			return nil, err
+		}
+		// The default value is the only option of the column, with no type,
+		// like what the parser builds for SET DEFAULT.
+		col := &ast.ColumnDef{Name: def.Name}
+		for _, opt := range def.Options {
+			if opt.Tp == ast.ColumnOptionDefaultValue {
+				col.Options = []*ast.ColumnOption{{Expr: opt.Expr}}
+			}
+		}
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableAlterColumn, NewColumns: []*ast.ColumnDef{col}})
+	case *AddIndexArgs:
+		cons := &ast.Constraint{
+			Tp:     ast.ConstraintIndex,
+			Name:   args.IndexName.O,
+			Keys:   args.IndexPartSpecifications,
+			Option: args.IndexOption,
+		}
+		if args.Unique {
+			cons.Tp = ast.ConstraintUniqIndex
+		}
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableAddConstraint, Constraint: cons})
+	case *AddPrimaryKeyArgs:
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableAddConstraint, Constraint: &ast.Constraint{
+			Tp:     ast.ConstraintPrimaryKey,
+			Keys:   args.IndexPartSpecifications,
+			Option: args.IndexOption,
+		}})
+	case *DropIndexArgs:
+		if b.job.Type == model.ActionDropPrimaryKey {
+			return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableDropPrimaryKey})
+		}
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableDropIndex, Name: args.IndexName.O, IfExists: args.IfExists})
+	case *DropIndexesArgs:
+		specs := make([]*ast.AlterTableSpec, 0, len(args.IndexNames))
+		for i, name := range args.IndexNames {
+			spec := &ast.AlterTableSpec{Tp: ast.AlterTableDropIndex, Name: name.O}
+			if name.L == "primary" {
+				spec = &ast.AlterTableSpec{Tp: ast.AlterTableDropPrimaryKey}
+			}
+			spec.IfExists = i < len(args.IfExists) && args.IfExists[i]
+			specs = append(specs, spec)
+		}
+		return b.alterTable(specs...)
+	case *RenameIndexArgs:
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableRenameIndex, FromKey: args.From, ToKey: args.To})
+	case *AlterIndexVisibilityArgs:
+		visibility := ast.IndexVisibilityVisible
+		if args.Invisible {
+			visibility = ast.IndexVisibilityInvisible
+		}
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableIndexInvisible, IndexName: args.IndexName, Visibility: visibility})
+	case *AddForeignKeyArgs:
+		if args.FKInfo == nil {
+			return nil, b.unsupported()
+		}
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableAddConstraint, Constraint: schema.BuildForeignKeyConstraint(args.FKInfo)})
+	case *DropForeignKeyArgs:
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableDropForeignKey, Name: args.FKName.O})
+	case *RebaseAutoIDArgs:
+		opt := &ast.TableOption{Tp: ast.TableOptionAutoIncrement, UintValue: uint64(args.NewBase), BoolValue: args.Force}
+		if b.job.Type == model.ActionRebaseAutoRandomBase {
+			opt = &ast.TableOption{Tp: ast.TableOptionAutoRandomBase, UintValue: uint64(args.NewBase)}
+		}
+		return b.alterTableOptions(opt)
+	case *ShardRowIDArgs:
+		return b.alterTableOptions(&ast.TableOption{Tp: ast.TableOptionShardRowID, UintValue: args.ShardRowIDBits})
+	case *ModifyTableCommentArgs:
+		return b.alterTableOptions(&ast.TableOption{Tp: ast.TableOptionComment, StrValue: args.Comment})
+	case *ModifyTableAutoIDCacheArgs:
+		return b.alterTableOptions(&ast.TableOption{Tp: ast.TableOptionAutoIdCache, UintValue: uint64(args.NewCache)})
+	case *ModifyTableCharsetAndCollateArgs:
+		charsetOpt := &ast.TableOption{Tp: ast.TableOptionCharset, StrValue: args.ToCharset}
+		if args.NeedsOverwriteCols {
+			charsetOpt.UintValue = ast.TableOptionCharsetWithConvertTo
+		}
+		return b.alterTableOptions(charsetOpt, &ast.TableOption{Tp: ast.TableOptionCollate, StrValue: args.ToCollate})
+	case *AddTablePartitionArgs:
+		if args.PartInfo == nil {
+			return nil, b.unsupported()
+		}
+		part, err := schema.BuildPartitionOptions(b.tbInfo, args.PartInfo)
+		if err != nil {
+			return nil, err
+		}
+		spec := &ast.AlterTableSpec{Tp: ast.AlterTableAddPartitions, PartDefinitions: part.Definitions}
+		if part.Definitions == nil {
+			spec.Num = part.Num
+		}
+		return b.alterTable(spec)
+	case *DropTablePartitionArgs:
+		spec := &ast.AlterTableSpec{Tp: ast.AlterTableDropPartition}
+		for _, name := range args.PartitionNames {
+			spec.PartitionNames = append(spec.PartitionNames, model.NewCIStr(name))
+		}
+		return b.alterTable(spec)
+	case *SetTiFlashReplicaArgs:
+		if args.Replica == nil {
+			return nil, b.unsupported()
+		}
+		return b.alterTable(&ast.AlterTableSpec{Tp: ast.AlterTableSetTiFlashReplica, TiFlashReplica: args.Replica})
+	}
+	// The other jobs don't keep all the names the statements need, e.g. a
+	// RENAME TABLE job only keeps the new name of the table, and a TRUNCATE
+	// PARTITION job and a done DROP PARTITION job only keep the IDs of the partitions.
+	return nil, b.unsupported()
+}
+
+func (b *queryBuilder) addColumns(cols []*model.ColumnInfo, positions []*ast.ColumnPosition, ifNotExists []bool) (ast.DDLNode, error) {
+	specs := make([]*ast.AlterTableSpec, 0, len(cols))
+	for i, col := range cols {
+		if col == nil {
+			return nil, b.unsupported()
+		}
+		def, err := schema.BuildColumnDef(b.tbInfo, col)
+		if err != nil {
+			return nil, err
+		}
+		spec := &ast.AlterTableSpec{
+			Tp:         ast.AlterTableAddColumns,
+			NewColumns: []*ast.ColumnDef{def},
+			Position:   &ast.ColumnPosition{Tp: ast.ColumnPositionNone},
+		}
+		if i < len(positions) && positions[i] != nil {
+			spec.Position = positions[i]
+		}
+		spec.IfNotExists = i < len(ifNotExists) && ifNotExists[i]
+		specs = append(specs, spec)
+	}
+	return b.alterTable(specs...)
+}
+
+func (b *queryBuilder) dropColumns(names []model.CIStr, ifExists []bool) (ast.DDLNode, error) {
+	specs := make([]*ast.AlterTableSpec, 0, len(names))
+	for i, name := range names {
+		specs = append(specs, &ast.AlterTableSpec{
+			Tp:            ast.AlterTableDropColumn,
+			OldColumnName: &ast.ColumnName{Name: name},
+			IfExists:      i < len(ifExists) && ifExists[i],
+		})
+	}
+	return b.alterTable(specs...)
+}
+
+func (b *queryBuilder) modifyColumn(args *ModifyColumnArgs) (ast.DDLNode, error) {
+	if args.Column == nil {
+		return nil, b.unsupported()
+	}
+	def, err := schema.BuildColumnDef(b.tbInfo, args.Column)
+	if err != nil {
+		return nil, err
+	}
+	spec := &ast.AlterTableSpec{
+		Tp:         ast.AlterTableModifyColumn,
+		NewColumns: []*ast.ColumnDef{def},
+		Position:   &ast.ColumnPosition{Tp: ast.ColumnPositionNone},
+	}
+	if args.Position != nil {
+		spec.Position = args.Position
+	}
+	if args.OldColumnName != nil && args.OldColumnName.L != args.Column.Name.L {
+		spec.Tp = ast.AlterTableChangeColumn
+		spec.OldColumnName = &ast.ColumnName{Name: *args.OldColumnName}
+	}
+	return b.alterTable(spec)
+}
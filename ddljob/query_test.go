@@ -0,0 +1,143 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddljob
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/schema"
+	_ "github.com/pingcap/parser/test_driver"
+)
+
+var _ = Suite(&testQuerySuite{})
+
+type testQuerySuite struct {
+	p *parser.Parser
+}
+
+func (s *testQuerySuite) SetUpSuite(c *C) {
+	s.p = parser.New()
+}
+
+func (s *testQuerySuite) tableInfo(c *C, sql string) *model.TableInfo {
+	stmt, err := s.p.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil)
+	tbInfo, err := schema.BuildTableInfo(stmt.(*ast.CreateTableStmt), nil)
+	c.Assert(err, IsNil)
+	return tbInfo
+}
+
+// finishedJob builds a finished job of table t in schema test.
+func (s *testQuerySuite) finishedJob(c *C, tp model.ActionType, tbInfo *model.TableInfo, args ...interface{}) *model.Job {
+	job := encodeJob(c, tp, args...)
+	job.SchemaName = "test"
+	job.BinlogInfo = &model.HistoryInfo{TableInfo: tbInfo}
+	return job
+}
+
+func (s *testQuerySuite) TestBuildQuery(c *C) {
+	t := s.tableInfo(c, "create table t (a int primary key, b varchar(10), c int not null default 1 comment 'c') charset utf8mb4 collate utf8mb4_bin")
+	colB, colC := t.Columns[1], t.Columns[2]
+	renamed := colC.Clone()
+	renamed.Name = model.NewCIStr("d")
+	after := &ast.ColumnPosition{Tp: ast.ColumnPositionAfter, RelativeColumn: &ast.ColumnName{Name: model.NewCIStr("a")}}
+	parts := []*ast.IndexPartSpecification{{Column: &ast.ColumnName{Name: model.NewCIStr("b")}, Length: 4}}
+	partitioned := s.tableInfo(c, "create table t (a int) partition by range (a) (partition p0 values less than (10))")
+	added := s.tableInfo(c, "create table t (a int) partition by range (a) (partition p1 values less than (20), partition p2 values less than maxvalue)")
+
+	tests := []struct {
+		tp     model.ActionType
+		tbInfo *model.TableInfo
+		args   []interface{}
+		query  string
+	}{
+		{model.ActionCreateTable, nil, []interface{}{t}, "CREATE TABLE `test`.`t` (`a` INT(11) NOT NULL,`b` VARCHAR(10) DEFAULT NULL,`c` INT(11) NOT NULL DEFAULT '1' COMMENT 'c',PRIMARY KEY(`a`) /*T![clustered_index] CLUSTERED */) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN"},
+		{model.ActionDropTable, t, nil, "DROP TABLE `test`.`t`"},
+		{model.ActionTruncateTable, t, []interface{}{100}, "TRUNCATE TABLE `test`.`t`"},
+		{model.ActionAddColumn, t, []interface{}{colC, after, 1}, "ALTER TABLE `test`.`t` ADD COLUMN `c` INT(11) NOT NULL DEFAULT '1' COMMENT 'c' AFTER `a`"},
+		{model.ActionAddColumns, t, []interface{}{[]*model.ColumnInfo{colB, colC}, []*ast.ColumnPosition{nil, nil}, []int{1, 2}, []bool{true, false}},
+			"ALTER TABLE `test`.`t` ADD COLUMN IF NOT EXISTS `b` VARCHAR(10) DEFAULT NULL, ADD COLUMN `c` INT(11) NOT NULL DEFAULT '1' COMMENT 'c'"},
+		{model.ActionDropColumn, t, []interface{}{model.NewCIStr("c"), true}, "ALTER TABLE `test`.`t` DROP COLUMN IF EXISTS `c`"},
+		{model.ActionModifyColumn, t, []interface{}{colC, model.NewCIStr("c"), nil}, "ALTER TABLE `test`.`t` MODIFY COLUMN `c` INT(11) NOT NULL DEFAULT '1' COMMENT 'c'"},
+		{model.ActionModifyColumn, t, []interface{}{renamed, model.NewCIStr("c"), &ast.ColumnPosition{Tp: ast.ColumnPositionFirst}},
+			"ALTER TABLE `test`.`t` CHANGE COLUMN `c` `d` INT(11) NOT NULL DEFAULT '1' COMMENT 'c' FIRST"},
+		{model.ActionSetDefaultValue, t, []interface{}{colC}, "ALTER TABLE `test`.`t` ALTER COLUMN `c` SET DEFAULT '1'"},
+		{model.ActionSetDefaultValue, t, []interface{}{colB}, "ALTER TABLE `test`.`t` ALTER COLUMN `b` SET DEFAULT NULL"},
+		{model.ActionSetDefaultValue, t, []interface{}{t.Columns[0]}, "ALTER TABLE `test`.`t` ALTER COLUMN `a` DROP DEFAULT"},
+		{model.ActionAddIndex, t, []interface{}{true, model.NewCIStr("i"), parts, &ast.IndexOption{Comment: "x"}},
+			"ALTER TABLE `test`.`t` ADD UNIQUE INDEX `i`(`b`(4)) COMMENT 'x'"},
+		{model.ActionAddPrimaryKey, t, []interface{}{true, model.NewCIStr("PRIMARY"), parts, nil}, "ALTER TABLE `test`.`t` ADD PRIMARY KEY(`b`(4))"},
+		{model.ActionDropIndex, t, []interface{}{model.NewCIStr("i")}, "ALTER TABLE `test`.`t` DROP INDEX `i`"},
+		{model.ActionDropPrimaryKey, t, []interface{}{model.NewCIStr("PRIMARY")}, "ALTER TABLE `test`.`t` DROP PRIMARY KEY"},
+		{model.ActionRenameIndex, t, []interface{}{model.NewCIStr("i"), model.NewCIStr("j")}, "ALTER TABLE `test`.`t` RENAME INDEX `i` TO `j`"},
+		{model.ActionAlterIndexVisibility, t, []interface{}{model.NewCIStr("i"), true}, "ALTER TABLE `test`.`t` ALTER INDEX `i` INVISIBLE"},
+		{model.ActionRebaseAutoID, t, []interface{}{100, true}, "ALTER TABLE `test`.`t` /*T![force_inc] FORCE */ AUTO_INCREMENT = 100"},
+		{model.ActionShardRowID, t, []interface{}{4}, "ALTER TABLE `test`.`t` /*T! SHARD_ROW_ID_BITS = 4 */"},
+		{model.ActionModifyTableComment, t, []interface{}{"it's"}, "ALTER TABLE `test`.`t` COMMENT = 'it''s'"},
+		{model.ActionModifyTableCharsetAndCollate, t, []interface{}{"utf8mb4", "utf8mb4_general_ci", true},
+			"ALTER TABLE `test`.`t` CONVERT TO CHARACTER SET UTF8MB4 COLLATE UTF8MB4_GENERAL_CI"},
+		{model.ActionAddTablePartition, partitioned, []interface{}{added.Partition},
+			"ALTER TABLE `test`.`t` ADD PARTITION (PARTITION `p1` VALUES LESS THAN (20), PARTITION `p2` VALUES LESS THAN (MAXVALUE))"},
+		{model.ActionDropTablePartition, partitioned, []interface{}{[]string{"p0"}}, "ALTER TABLE `test`.`t` DROP PARTITION `p0`"},
+	}
+	for _, tt := range tests {
+		comment := Commentf("%s", tt.tp)
+		job := s.finishedJob(c, tt.tp, tt.tbInfo, tt.args...)
+		query, err := BuildQuery(job)
+		c.Assert(err, IsNil, comment)
+		c.Assert(query, Equals, tt.query, comment)
+		_, err = s.p.ParseOneStmt(query, "", "")
+		c.Assert(err, IsNil, comment)
+	}
+
+	job := encodeJob(c, model.ActionCreateSchema, &model.DBInfo{Name: model.NewCIStr("db"), Charset: "utf8mb4"})
+	query, err := BuildQuery(job)
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "CREATE DATABASE `db` CHARACTER SET = utf8mb4")
+	job = encodeJob(c, model.ActionDropSchema, []int64{1})
+	job.BinlogInfo = &model.HistoryInfo{DBInfo: &model.DBInfo{Name: model.NewCIStr("db")}}
+	query, err = BuildQuery(job)
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "DROP DATABASE `db`")
+}
+
+func (s *testQuerySuite) TestBuildQueryErrors(c *C) {
+	t := s.tableInfo(c, "create table t (a int)")
+
+	// A job which isn't finished has no table name.
+	job := encodeJob(c, model.ActionDropColumn, model.NewCIStr("a"))
+	_, err := BuildQuery(job)
+	c.Assert(err, ErrorMatches, "the table name of drop column job 1 is unknown")
+
+	// A RENAME TABLE job doesn't keep the old name of the table.
+	job = s.finishedJob(c, model.ActionRenameTable, t, 1, model.NewCIStr("t"))
+	_, err = BuildQuery(job)
+	c.Assert(err, ErrorMatches, "rename table job 1 can't be turned into a statement")
+
+	job = s.finishedJob(c, model.ActionLockTable, t)
+	_, err = BuildQuery(job)
+	c.Assert(err, ErrorMatches, "no typed arguments are registered for action lock table")
+
+	// A done DROP PARTITION job only keeps the IDs of the dropped partitions.
+	job = s.finishedJob(c, model.ActionDropTablePartition, t, []int64{55, 56})
+	job.State = model.JobStateSynced
+	_, err = BuildQuery(job)
+	c.Assert(err, ErrorMatches, "drop partition job 1 can't be turned into a statement")
+
+	job = encodeJob(c, model.ActionModifySchemaCharsetAndCollate, "utf8mb4", "utf8mb4_bin")
+	_, err = BuildQuery(job)
+	c.Assert(err, ErrorMatches, "the schema name of modify schema charset and collate job 1 is unknown")
+}
@@ -0,0 +1,300 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddljob
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/parser/model"
+)
+
+// JobRecord is the stable representation of a DDL job, which is what the job
+// history is exported as. The JSON encoding of a record is described by
+// JobRecordJSONSchema.
+type JobRecord struct {
+	ID int64 `json:"id"`
+	// Action is the name of the action type, e.g. "add index".
+	Action     string `json:"action"`
+	ActionType int    `json:"action_type"`
+	SchemaID   int64  `json:"schema_id"`
+	SchemaName string `json:"schema_name,omitempty"`
+	TableID    int64  `json:"table_id"`
+	TableName  string `json:"table_name,omitempty"`
+	State      string `json:"state"`
+	// SchemaState is the current schema state of the job, and SchemaStates are
+	// all the schema states the action goes through in order.
+	SchemaState   string   `json:"schema_state"`
+	SchemaStates  []string `json:"schema_states"`
+	SchemaVersion int64    `json:"schema_version,omitempty"`
+	RowCount      int64    `json:"row_count"`
+	Error         string   `json:"error,omitempty"`
+	ErrorCount    int64    `json:"error_count"`
+	// Warnings are sorted by the error ID.
+	Warnings []JobWarning `json:"warnings,omitempty"`
+	// StartTime and FinishTime are in RFC 3339 format in UTC.
+	StartTime  string `json:"start_time,omitempty"`
+	FinishTime string `json:"finish_time,omitempty"`
+	Query      string `json:"query"`
+	// QueryReconstructed is true if the job has no query and Query is built
+	// from the arguments of the job by BuildQuery.
+	QueryReconstructed bool `json:"query_reconstructed,omitempty"`
+}
+
+// JobWarning is a warning raised while a job reorganizes data.
+type JobWarning struct {
+	ID      string `json:"id"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Count   int64  `json:"count"`
+}
+
+// JobRecordJSONSchema is the JSON schema (draft-07) of the JSON encoding of a
+// JobRecord. Fields are only ever added to it.
+const JobRecordJSONSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/pingcap/parser/ddljob/job-record.json",
+  "title": "DDL job record",
+  "type": "object",
+  "required": ["id", "action", "action_type", "schema_id", "table_id", "state", "schema_state", "schema_states", "row_count", "error_count", "query"],
+  "properties": {
+    "id": {"type": "integer"},
+    "action": {"type": "string"},
+    "action_type": {"type": "integer"},
+    "schema_id": {"type": "integer"},
+    "schema_name": {"type": "string"},
+    "table_id": {"type": "integer"},
+    "table_name": {"type": "string"},
+    "state": {"type": "string"},
+    "schema_state": {"type": "string"},
+    "schema_states": {"type": "array", "items": {"type": "string"}},
+    "schema_version": {"type": "integer"},
+    "row_count": {"type": "integer"},
+    "error": {"type": "string"},
+    "error_count": {"type": "integer"},
+    "warnings": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["id", "code", "message", "count"],
+        "properties": {
+          "id": {"type": "string"},
+          "code": {"type": "integer"},
+          "message": {"type": "string"},
+          "count": {"type": "integer"}
+        }
+      }
+    },
+    "start_time": {"type": "string", "format": "date-time"},
+    "finish_time": {"type": "string", "format": "date-time"},
+    "query": {"type": "string"},
+    "query_reconstructed": {"type": "boolean"}
+  }
+}`
+
+var (
+	addElementStates = []model.SchemaState{model.StateNone, model.StateDeleteOnly, model.StateWriteOnly,
+		model.StateWriteReorganization, model.StatePublic}
+	dropElementStates = []model.SchemaState{model.StatePublic, model.StateWriteOnly, model.StateDeleteOnly,
+		model.StateDeleteReorganization, model.StateNone}
+	dropObjectStates = []model.SchemaState{model.StatePublic, model.StateWriteOnly, model.StateDeleteOnly,
+		model.StateNone}
+	createObjectStates = []model.SchemaState{model.StateNone, model.StatePublic}
+	alterStates        = []model.SchemaState{model.StatePublic}
+)
+
+// SchemaStates returns the schema states a job of an action type goes through
+// in order. A MODIFY COLUMN job which changes the column data goes through the
+// states of adding a column instead, see JobSchemaStates.
+func SchemaStates(tp model.ActionType) []model.SchemaState {
+	switch tp {
+	case model.ActionAddColumn, model.ActionAddColumns, model.ActionAddIndex, model.ActionAddPrimaryKey:
+		return addElementStates
+	case model.ActionDropColumn, model.ActionDropColumns, model.ActionDropIndex, model.ActionDropIndexes,
+		model.ActionDropPrimaryKey:
+		return dropElementStates
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionDropView, model.ActionDropSequence:
+		return dropObjectStates
+	case model.ActionCreateSchema, model.ActionCreateTable, model.ActionCreateView, model.ActionCreateSequence,
+		model.ActionRecoverTable, model.ActionCreatePlacementPolicy:
+		return createObjectStates
+	}
+	return alterStates
+}
+
+// JobSchemaStates returns the schema states a job goes through in order.
+func JobSchemaStates(job *model.Job) []model.SchemaState {
+	if job.Type == model.ActionModifyColumn && modifyColumnReorgs(job) {
+		return addElementStates
+	}
+	return SchemaStates(job.Type)
+}
+
+// changingColumnArg is the position of the changing column, which TiDB appends
+// to the arguments of a MODIFY COLUMN job to change the column data.
+const changingColumnArg = 5
+
+// modifyColumnReorgs reports whether a MODIFY COLUMN job changes the column
+// data. The arguments of a done job which changed the data are replaced by the
+// IDs of the removed indexes and the partitions, so its first argument isn't a column.
+func modifyColumnReorgs(job *model.Job) bool {
+	switch job.SchemaState {
+	case model.StateDeleteOnly, model.StateWriteOnly, model.StateWriteReorganization:
+		return true
+	}
+	raw, err := rawArgs(job)
+	if err != nil || len(raw) == 0 {
+		return false
+	}
+	return len(raw) > changingColumnArg || !bytes.HasPrefix(raw[0], []byte("{"))
+}
+
+// NewJobRecord builds the record of a job. If the job has no query, the query
+// is built by BuildQuery, and it is left empty if the job can't be turned into
+// a statement.
+func NewJobRecord(job *model.Job) *JobRecord {
+	r := &JobRecord{
+		ID:          job.ID,
+		Action:      job.Type.String(),
+		ActionType:  int(job.Type),
+		SchemaID:    job.SchemaID,
+		TableID:     job.TableID,
+		State:       job.State.String(),
+		SchemaState: schemaStateName(job.SchemaState),
+		RowCount:    job.GetRowCount(),
+		ErrorCount:  job.ErrorCount,
+		Query:       job.Query,
+	}
+	r.SchemaName, r.TableName = jobNames(job)
+	for _, state := range JobSchemaStates(job) {
+		r.SchemaStates = append(r.SchemaStates, schemaStateName(state))
+	}
+	if job.Error != nil {
+		r.Error = job.Error.Error()
+	}
+	if job.ReorgMeta != nil {
+		warnings, counts := job.GetWarnings()
+		for id, warn := range warnings {
+			r.Warnings = append(r.Warnings, JobWarning{
+				ID:      string(id),
+				Code:    int(warn.Code()),
+				Message: warn.GetMsg(),
+				Count:   counts[id],
+			})
+		}
+		sort.Slice(r.Warnings, func(i, j int) bool { return r.Warnings[i].ID < r.Warnings[j].ID })
+	}
+	if job.StartTS > 0 {
+		r.StartTime = formatTS(job.StartTS)
+	}
+	if info := job.BinlogInfo; info != nil {
+		r.SchemaVersion = info.SchemaVersion
+		if info.FinishedTS > 0 {
+			r.FinishTime = formatTS(info.FinishedTS)
+		}
+	}
+	if r.Query == "" {
+		if query, err := BuildQuery(job); err == nil {
+			r.Query, r.QueryReconstructed = query, true
+		}
+	}
+	return r
+}
+
+// schemaStateName returns the name of a schema state. StateNone is "none"
+// rather than "queueing", since a job which drops an object ends in it.
+func schemaStateName(state model.SchemaState) string {
+	if state == model.StateNone {
+		return "none"
+	}
+	return state.String()
+}
+
+func formatTS(ts uint64) string {
+	return model.TSConvert2Time(ts).UTC().Format(time.RFC3339Nano)
+}
+
+// jobNames returns the names of the schema and the table of a job. They are
+// taken from the history info of a finished job, or from the arguments of the
+// job which creates them.
+func jobNames(job *model.Job) (schemaName, tableName string) {
+	schemaName = job.SchemaName
+	if info := job.BinlogInfo; info != nil {
+		if schemaName == "" && info.DBInfo != nil {
+			schemaName = info.DBInfo.Name.O
+		}
+		if info.TableInfo != nil {
+			tableName = info.TableInfo.Name.O
+		}
+	}
+	if schemaName != "" && tableName != "" {
+		return schemaName, tableName
+	}
+	args, err := DecodeArgs(job)
+	if err != nil {
+		return schemaName, tableName
+	}
+	switch args := args.(type) {
+	case *CreateSchemaArgs:
+		if schemaName == "" && args.DBInfo != nil {
+			schemaName = args.DBInfo.Name.O
+		}
+	case *CreateTableArgs:
+		if tableName == "" && args.TableInfo != nil {
+			tableName = args.TableInfo.Name.O
+		}
+	case *CreateViewArgs:
+		if tableName == "" && args.TableInfo != nil {
+			tableName = args.TableInfo.Name.O
+		}
+	case *RecoverTableArgs:
+		if tableName == "" && args.TableInfo != nil {
+			tableName = args.TableInfo.Name.O
+		}
+	}
+	return schemaName, tableName
+}
+
+// String returns the record in a human-readable form.
+func (r *JobRecord) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ID:%d, Action:%s", r.ID, r.Action)
+	if r.SchemaName != "" {
+		fmt.Fprintf(&sb, ", Schema:%s", r.SchemaName)
+	}
+	if r.TableName != "" {
+		fmt.Fprintf(&sb, ", Table:%s", r.TableName)
+	}
+	fmt.Fprintf(&sb, ", State:%s, SchemaState:%s (%s), RowCount:%d",
+		r.State, r.SchemaState, strings.Join(r.SchemaStates, " -> "), r.RowCount)
+	if r.Error != "" {
+		fmt.Fprintf(&sb, ", Err:%s, ErrCount:%d", r.Error, r.ErrorCount)
+	}
+	for _, warn := range r.Warnings {
+		fmt.Fprintf(&sb, ", Warning:%s (x%d)", warn.Message, warn.Count)
+	}
+	if r.StartTime != "" {
+		fmt.Fprintf(&sb, ", StartTime:%s", r.StartTime)
+	}
+	if r.FinishTime != "" {
+		fmt.Fprintf(&sb, ", FinishTime:%s", r.FinishTime)
+	}
+	if r.Query != "" {
+		fmt.Fprintf(&sb, ", Query:%s", r.Query)
+	}
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddljob
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/terror"
+)
+
+var _ = Suite(&testRecordSuite{})
+
+type testRecordSuite struct {
+}
+
+func toTS(t time.Time) uint64 {
+	return uint64(t.UnixNano()/int64(time.Millisecond)) << 18
+}
+
+func (s *testRecordSuite) TestJobRecord(c *C) {
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	job := encodeJob(c, model.ActionDropColumn, model.NewCIStr("b"))
+	job.ID, job.SchemaID, job.TableID = 10, 1, 2
+	job.SchemaName = "test"
+	job.State = model.JobStateSynced
+	job.SchemaState = model.StateNone
+	job.StartTS = toTS(start)
+	job.BinlogInfo = &model.HistoryInfo{
+		SchemaVersion: 30,
+		TableInfo:     &model.TableInfo{ID: 2, Name: model.NewCIStr("t")},
+		FinishedTS:    toTS(start.Add(1500 * time.Millisecond)),
+	}
+	job.SetRowCount(5)
+	warn1 := errors.Cause(terror.ClassTypes.NewStd(mysql.ErrTruncatedWrongValue).GenWithStackByArgs("DECIMAL", "x")).(*terror.Error)
+	warn2 := errors.Cause(terror.ClassTypes.NewStd(mysql.ErrDataTooLong).GenWithStackByArgs("c", 1)).(*terror.Error)
+	job.ReorgMeta = &model.DDLReorgMeta{
+		Warnings:      map[errors.ErrorID]*terror.Error{warn1.ID(): warn1, warn2.ID(): warn2},
+		WarningsCount: map[errors.ErrorID]int64{warn1.ID(): 3, warn2.ID(): 1},
+	}
+
+	r := NewJobRecord(job)
+	c.Assert(r.Action, Equals, "drop column")
+	c.Assert(r.ActionType, Equals, int(model.ActionDropColumn))
+	c.Assert(r.SchemaName, Equals, "test")
+	c.Assert(r.TableName, Equals, "t")
+	c.Assert(r.State, Equals, "synced")
+	c.Assert(r.SchemaState, Equals, "none")
+	c.Assert(r.SchemaStates, DeepEquals, []string{"public", "write only", "delete only", "delete reorganization", "none"})
+	c.Assert(r.SchemaVersion, Equals, int64(30))
+	c.Assert(r.RowCount, Equals, int64(5))
+	c.Assert(r.StartTime, Equals, "2021-06-01T10:00:00Z")
+	c.Assert(r.FinishTime, Equals, "2021-06-01T10:00:01.5Z")
+	c.Assert(r.Warnings, DeepEquals, []JobWarning{
+		{ID: "types:1292", Code: mysql.ErrTruncatedWrongValue, Message: "Truncated incorrect DECIMAL value: 'x'", Count: 3},
+		{ID: "types:1406", Code: mysql.ErrDataTooLong, Message: "Data too long for column 'c' at row 1", Count: 1},
+	})
+	c.Assert(r.Query, Equals, "ALTER TABLE `test`.`t` DROP COLUMN `b`")
+	c.Assert(r.QueryReconstructed, IsTrue)
+	c.Assert(r.String(), Equals, "ID:10, Action:drop column, Schema:test, Table:t, State:synced, "+
+		"SchemaState:none (public -> write only -> delete only -> delete reorganization -> none), RowCount:5, "+
+		"Warning:Truncated incorrect DECIMAL value: 'x' (x3), Warning:Data too long for column 'c' at row 1 (x1), "+
+		"StartTime:2021-06-01T10:00:00Z, FinishTime:2021-06-01T10:00:01.5Z, Query:ALTER TABLE `test`.`t` DROP COLUMN `b`")
+
+	// The query of the job is kept, and the records survive a JSON round trip.
+	job.Query = "alter table t drop b"
+	job.Error = errors.Cause(terror.ClassDDL.NewStd(mysql.ErrCantDropFieldOrKey).GenWithStackByArgs("b")).(*terror.Error)
+	job.ErrorCount = 1
+	r = NewJobRecord(job)
+	c.Assert(r.Query, Equals, "alter table t drop b")
+	c.Assert(r.QueryReconstructed, IsFalse)
+	c.Assert(r.Error, Equals, "[ddl:1091]Can't DROP 'b'; check that column/key exists")
+	b, err := json.Marshal(r)
+	c.Assert(err, IsNil)
+	decoded := &JobRecord{}
+	c.Assert(json.Unmarshal(b, decoded), IsNil)
+	c.Assert(decoded, DeepEquals, r)
+
+	// The table name of a job which isn't finished comes from the arguments.
+	job = encodeJob(c, model.ActionCreateTable, &model.TableInfo{Name: model.NewCIStr("t2")})
+	r = NewJobRecord(job)
+	c.Assert(r.TableName, Equals, "t2")
+	c.Assert(r.SchemaStates, DeepEquals, []string{"none", "public"})
+	c.Assert(r.Warnings, IsNil)
+	c.Assert(r.StartTime, Equals, "")
+}
+
+func (s *testRecordSuite) TestJobSchemaStates(c *C) {
+	col := &model.ColumnInfo{ID: 3, Name: model.NewCIStr("c")}
+	states := func(job *model.Job) []string {
+		return NewJobRecord(job).SchemaStates
+	}
+	addStates := []string{"none", "delete only", "write only", "write reorganization", "public"}
+
+	// A MODIFY COLUMN job which doesn't change the data only alters the column.
+	job := encodeJob(c, model.ActionModifyColumn, col, model.NewCIStr("c"), nil, 0, 0)
+	c.Assert(states(job), DeepEquals, []string{"public"})
+	job.State = model.JobStateSynced
+	job.SchemaState = model.StatePublic
+	c.Assert(states(job), DeepEquals, []string{"public"})
+
+	// The changing column is appended to change the data.
+	job = encodeJob(c, model.ActionModifyColumn, col, model.NewCIStr("c"), nil, 0, 0, col, nil)
+	c.Assert(states(job), DeepEquals, addStates)
+	job = encodeJob(c, model.ActionModifyColumn, col, model.NewCIStr("c"), nil, 0, 0)
+	job.SchemaState = model.StateWriteReorganization
+	c.Assert(states(job), DeepEquals, addStates)
+
+	// The arguments of a done job which changed the data are the IDs of the
+	// removed indexes and the partitions.
+	job = encodeJob(c, model.ActionModifyColumn, []int64{5}, []int64(nil))
+	job.State = model.JobStateSynced
+	job.SchemaState = model.StatePublic
+	c.Assert(states(job), DeepEquals, addStates)
+	c.Assert(SchemaStates(model.ActionModifyColumn), DeepEquals, []model.SchemaState{model.StatePublic})
+}
+
+func jsonFields(c *C, tp reflect.Type) (all, required []string) {
+	for i := 0; i < tp.NumField(); i++ {
+		tag := strings.Split(tp.Field(i).Tag.Get("json"), ",")
+		c.Assert(tag[0], Not(Equals), "")
+		all = append(all, tag[0])
+		if len(tag) == 1 {
+			required = append(required, tag[0])
+		}
+	}
+	sort.Strings(all)
+	sort.Strings(required)
+	return all, required
+}
+
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+}
+
+func (s *jsonSchema) check(c *C, tp reflect.Type) {
+	all, required := jsonFields(c, tp)
+	var properties []string
+	for name := range s.Properties {
+		properties = append(properties, name)
+	}
+	sort.Strings(properties)
+	sort.Strings(s.Required)
+	c.Assert(properties, DeepEquals, all)
+	c.Assert(s.Required, DeepEquals, required)
+}
+
+func (s *testRecordSuite) TestJSONSchema(c *C) {
+	schema := &jsonSchema{}
+	c.Assert(json.Unmarshal([]byte(JobRecordJSONSchema), schema), IsNil)
+	c.Assert(schema.Type, Equals, "object")
+	schema.check(c, reflect.TypeOf(JobRecord{}))
+	schema.Properties["warnings"].Items.check(c, reflect.TypeOf(JobWarning{}))
+
+	kinds := map[reflect.Kind]string{
+		reflect.Int: "integer", reflect.Int64: "integer", reflect.String: "string",
+		reflect.Bool: "boolean", reflect.Slice: "array",
+	}
+	tp := reflect.TypeOf(JobRecord{})
+	for i := 0; i < tp.NumField(); i++ {
+		name := strings.Split(tp.Field(i).Tag.Get("json"), ",")[0]
+		c.Assert(schema.Properties[name].Type, Equals, kinds[tp.Field(i).Type.Kind()], Commentf("%s", name))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Errcatalogue verifies that the localized error message files have a message
+// for every MySQL error code, with the same arguments as the English message.
+//
+// Usage
+//
+//	errcatalogue locale=file [locale=file ...]
+//
+// For example:
+//
+//	errcatalogue zh_CN=errors_zh_CN.json ja_JP=errors_ja_JP.json
+//
+// The files are the JSON objects loaded by terror.Catalogue.Load. The command
+// prints the codes failing the check, and exits with 1 if there are any.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pingcap/parser/terror"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: errcatalogue locale=file [locale=file ...]")
+		os.Exit(2)
+	}
+	failed := false
+	for _, arg := range os.Args[1:] {
+		ok, err := check(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		failed = failed || !ok
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func check(arg string) (bool, error) {
+	eq := strings.IndexByte(arg, '=')
+	if eq <= 0 {
+		return false, fmt.Errorf("invalid argument '%s', expected locale=file", arg)
+	}
+	locale, path := arg[:eq], arg[eq+1:]
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	catalogue := terror.NewCatalogue()
+	if err := catalogue.Load(locale, f); err != nil {
+		return false, err
+	}
+	missing, mismatched := catalogue.Verify(locale)
+	for _, code := range missing {
+		fmt.Printf("%s: missing message of error %d\n", locale, code)
+	}
+	for _, code := range mismatched {
+		fmt.Printf("%s: arguments of error %d don't match the English message\n", locale, code)
+	}
+	return len(missing) == 0 && len(mismatched) == 0, nil
+}
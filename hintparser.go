@@ -1053,12 +1053,14 @@ yynewstate:
 	case 2:
 		{
 			if yyS[yypt-0].hint != nil {
+				parser.setHintOffset(yyS[yypt-0].hint, yyS[yypt].offset)
 				parser.yyVAL.hints = []*ast.TableOptimizerHint{yyS[yypt-0].hint}
 			}
 		}
 	case 3:
 		{
 			if yyS[yypt-0].hint != nil {
+				parser.setHintOffset(yyS[yypt-0].hint, yyS[yypt].offset)
 				parser.yyVAL.hints = append(yyS[yypt-2].hints, yyS[yypt-0].hint)
 			} else {
 				parser.yyVAL.hints = yyS[yypt-2].hints
@@ -1202,6 +1204,7 @@ yynewstate:
 			for _, h := range hs {
 				h.HintName = name
 				h.QBName = qb
+				parser.setHintOffset(h, yyS[yypt-4].offset)
 			}
 			parser.yyVAL.hints = hs
 		}
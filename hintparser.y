@@ -180,12 +180,14 @@ OptimizerHintList:
 	TableOptimizerHintOpt
 	{
 		if $1 != nil {
+			parser.setHintOffset($1, yyS[yypt].offset)
 			$$ = []*ast.TableOptimizerHint{$1}
 		}
 	}
 |	OptimizerHintList CommaOpt TableOptimizerHintOpt
 	{
 		if $3 != nil {
+			parser.setHintOffset($3, yyS[yypt].offset)
 			$$ = append($1, $3)
 		} else {
 			$$ = $1
@@ -333,6 +335,7 @@ StorageOptimizerHintOpt:
 		for _, h := range hs {
 			h.HintName = name
 			h.QBName = qb
+			parser.setHintOffset(h, yyS[yypt-4].offset)
 		}
 		$$ = hs
 	}
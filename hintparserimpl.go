@@ -44,6 +44,7 @@ func (hs *hintScanner) Errorf(format string, args ...interface{}) error {
 func (hs *hintScanner) Lex(lval *yyhintSymType) int {
 	tok, pos, lit := hs.scan()
 	hs.lastScanOffset = pos.Offset
+	lval.offset = pos.Offset
 	var errorTokenType string
 
 	switch tok {
@@ -115,6 +116,11 @@ type hintParser struct {
 	lexer  hintScanner
 	result []*ast.TableOptimizerHint
 
+	// recordPositions is set to record the offsets of the hints in the SQL,
+	// and baseOffset is the offset of the hint text in the SQL.
+	recordPositions bool
+	baseOffset      int
+
 	// the following fields are used by yyParse to reduce allocation.
 	cache  []yyhintSymType
 	yylval yyhintSymType
@@ -127,6 +133,7 @@ func newHintParser() *hintParser {
 
 func (hp *hintParser) parse(input string, sqlMode mysql.SQLMode, initPos Pos) ([]*ast.TableOptimizerHint, []error) {
 	hp.result = nil
+	hp.baseOffset = initPos.Offset + 3
 	hp.lexer.reset(input[3:])
 	hp.lexer.SetSQLMode(sqlMode)
 	hp.lexer.r.updatePos(Pos{
@@ -156,6 +163,14 @@ func (hp *hintParser) warnUnsupportedHint(name string) {
 	hp.lexer.warns = append(hp.lexer.warns, warn)
 }
 
+// setHintOffset records the offset of a hint in the SQL, see
+// ast.Node.OriginTextPosition.
+func (hp *hintParser) setHintOffset(hint *ast.TableOptimizerHint, offset int) {
+	if hp.recordPositions {
+		hint.SetOriginTextPosition(hp.baseOffset + offset)
+	}
+}
+
 func (hp *hintParser) lastErrorAsWarn() {
 	hp.lexer.lastErrorAsWarn()
 }
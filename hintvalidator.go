@@ -0,0 +1,326 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/terror"
+)
+
+var (
+	// ErrWarnOptimizerHintConflicting returns when a hint conflicts with a previous one.
+	ErrWarnOptimizerHintConflicting = terror.ClassParser.NewStd(mysql.ErrWarnConflictingHint)
+	// ErrWarnOptimizerHintUnknownQBName returns when a hint refers to a query block which doesn't exist.
+	ErrWarnOptimizerHintUnknownQBName = terror.ClassParser.NewStd(mysql.ErrWarnUnknownQbName)
+	// ErrWarnOptimizerHintUnresolvedName returns when a table or an index of a hint can't be resolved.
+	ErrWarnOptimizerHintUnresolvedName = terror.ClassParser.NewStd(mysql.ErrUnresolvedHintName)
+)
+
+// ValidateHints cross-checks the optimizer hints of a statement with the
+// statement, since the hint parser only checks their syntax. sql is the text
+// which the statement was parsed from, and it is used to report the positions
+// of the hints. The returned warnings are *PositionError in the order of the
+// hints, whose causes are:
+//   - ErrWarnOptimizerHintUnknownQBName if a hint refers to a query block
+//     which is neither named by QB_NAME nor a default name like sel_2.
+//   - ErrWarnOptimizerHintUnresolvedName if a table of a hint isn't in the
+//     FROM clause of its query block, or an index name can't be an index.
+//   - ErrWarnOptimizerHintConflicting if a hint conflicts with a previous
+//     one, like HASH_JOIN(t) after MERGE_JOIN(t), or a query block is named
+//     twice.
+//
+// The SELECT blocks are numbered in the order they appear in the statement,
+// so the default name of the outermost one is sel_1. The default names of the
+// UPDATE and DELETE statements are upd_1 and del_1.
+func ValidateHints(sql string, stmt ast.StmtNode) []error {
+	c := &queryBlockCollector{}
+	stmt.Accept(c)
+	v := &hintValidator{
+		sql:    sql,
+		blocks: make(map[string]*queryBlock, len(c.blocks)),
+		used:   make(map[hintConflictKey]*ast.TableOptimizerHint),
+	}
+	for _, b := range c.blocks {
+		if b.name != "" {
+			v.blocks[b.name] = b
+		}
+	}
+	// The query blocks are named first, since the hints may refer to the
+	// blocks named later in the statement.
+	for _, b := range c.blocks {
+		for _, h := range b.hints {
+			if h.HintName.L == "qb_name" {
+				v.nameBlock(b, h)
+			}
+		}
+	}
+	for _, b := range c.blocks {
+		for _, h := range b.hints {
+			if h.HintName.L != "qb_name" {
+				v.checkHint(b, h)
+			}
+		}
+	}
+	sort.SliceStable(v.warns, func(i, j int) bool {
+		return v.warns[i].(*PositionError).Offset < v.warns[j].(*PositionError).Offset
+	})
+	return v.warns
+}
+
+// queryBlock is a SELECT, UPDATE, DELETE or INSERT of a statement, which the
+// hints apply to.
+type queryBlock struct {
+	// name is the default name of the block like sel_2.
+	name string
+	// named reports whether the block is named by QB_NAME.
+	named  bool
+	tables []hintTableName
+	hints  []*ast.TableOptimizerHint
+}
+
+// hintTableName is the name of a table source which the hints refer to, i.e.
+// its alias or the table name.
+type hintTableName struct {
+	schema model.CIStr
+	name   model.CIStr
+}
+
+func (b *queryBlock) hasTable(t ast.HintTable) bool {
+	for _, name := range b.tables {
+		if name.name.L == t.TableName.L && (t.DBName.L == "" || name.schema.L == "" || name.schema.L == t.DBName.L) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryBlockCollector collects the query blocks of a statement and the tables
+// in their FROM clauses.
+type queryBlockCollector struct {
+	blocks  []*queryBlock
+	stack   []*queryBlock
+	selects int
+}
+
+func (c *queryBlockCollector) push(name string, hints []*ast.TableOptimizerHint) {
+	b := &queryBlock{name: name, hints: hints}
+	c.blocks = append(c.blocks, b)
+	c.stack = append(c.stack, b)
+}
+
+// Enter implements ast.Visitor interface.
+func (c *queryBlockCollector) Enter(in ast.Node) (ast.Node, bool) {
+	switch n := in.(type) {
+	case *ast.SelectStmt:
+		c.selects++
+		c.push(fmt.Sprintf("sel_%d", c.selects), n.TableHints)
+	case *ast.UpdateStmt:
+		c.push("upd_1", n.TableHints)
+	case *ast.DeleteStmt:
+		c.push("del_1", n.TableHints)
+	case *ast.InsertStmt:
+		c.push("", n.TableHints)
+	case *ast.TableSource:
+		if len(c.stack) == 0 {
+			break
+		}
+		b := c.stack[len(c.stack)-1]
+		if n.AsName.L != "" {
+			b.tables = append(b.tables, hintTableName{name: n.AsName})
+		} else if t, ok := n.Source.(*ast.TableName); ok {
+			b.tables = append(b.tables, hintTableName{schema: t.Schema, name: t.Name})
+		}
+	}
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (c *queryBlockCollector) Leave(in ast.Node) (ast.Node, bool) {
+	switch in.(type) {
+	case *ast.SelectStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.InsertStmt:
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+	return in, true
+}
+
+// hintConflictKey identifies the hints which conflict if they are different,
+// e.g. the join algorithms of a table.
+type hintConflictKey struct {
+	block *queryBlock
+	group string
+	table string
+}
+
+// hintConflictGroups maps the hints to the groups of the conflicting hints
+// and their kinds in the groups. The hints of a group conflict if their kinds
+// are different.
+var hintConflictGroups = map[string]struct{ group, kind string }{
+	"tidb_hj":              {"join", "hash_join"},
+	"hash_join":            {"join", "hash_join"},
+	"tidb_smj":             {"join", "merge_join"},
+	"merge_join":           {"join", "merge_join"},
+	"tidb_inlj":            {"join", "inl_join"},
+	"inl_join":             {"join", "inl_join"},
+	"inl_hash_join":        {"join", "inl_hash_join"},
+	"inl_merge_join":       {"join", "inl_merge_join"},
+	"broadcast_join":       {"join", "broadcast_join"},
+	"broadcast_join_local": {"join", "broadcast_join_local"},
+	"swap_join_inputs":     {"swap", "swap_join_inputs"},
+	"no_swap_join_inputs":  {"swap", "no_swap_join_inputs"},
+	"hash_agg":             {"agg", "hash_agg"},
+	"stream_agg":           {"agg", "stream_agg"},
+	"use_index":            {"index", "use_index"},
+	"force_index":          {"index", "use_index"},
+	"ignore_index":         {"index", "ignore_index"},
+}
+
+type hintValidator struct {
+	sql string
+	// blocks maps the names of the query blocks to the blocks.
+	blocks map[string]*queryBlock
+	// used records the first hint of each conflict key.
+	used  map[hintConflictKey]*ast.TableOptimizerHint
+	warns []error
+}
+
+func (v *hintValidator) warn(h *ast.TableOptimizerHint, err error) {
+	v.warns = append(v.warns, newPositionError(v.sql, h.OriginTextPosition(), err))
+}
+
+// nameBlock names a query block by a QB_NAME hint. A block can't have two
+// names, and the names must be unique.
+func (v *hintValidator) nameBlock(b *queryBlock, h *ast.TableOptimizerHint) {
+	named, ok := v.blocks[h.QBName.L]
+	if b.named || (ok && named != b) {
+		v.warn(h, ErrWarnOptimizerHintConflicting.GenWithStackByArgs(hintString(h)))
+		return
+	}
+	b.named = true
+	v.blocks[h.QBName.L] = b
+}
+
+// queryBlock returns the query block which a hint or one of its tables refers
+// to. It reports a warning if the block doesn't exist.
+func (v *hintValidator) queryBlock(h *ast.TableOptimizerHint, b *queryBlock, qbName model.CIStr) *queryBlock {
+	if qbName.L == "" {
+		return b
+	}
+	if named, ok := v.blocks[qbName.L]; ok {
+		return named
+	}
+	v.warn(h, ErrWarnOptimizerHintUnknownQBName.GenWithStackByArgs(qbName.O, h.HintName.O))
+	return nil
+}
+
+func (v *hintValidator) checkHint(b *queryBlock, h *ast.TableOptimizerHint) {
+	b = v.queryBlock(h, b, h.QBName)
+	if b == nil {
+		return
+	}
+	conflict, hasConflict := hintConflictGroups[h.HintName.L]
+	warnConflict := func(key hintConflictKey) {
+		if !hasConflict {
+			return
+		}
+		first, ok := v.used[key]
+		if !ok {
+			v.used[key] = h
+			return
+		}
+		if hintConflictGroups[first.HintName.L].kind != conflict.kind {
+			v.warn(h, ErrWarnOptimizerHintConflicting.GenWithStackByArgs(hintString(h)))
+			hasConflict = false
+		}
+	}
+	if len(h.Tables) == 0 {
+		warnConflict(hintConflictKey{block: b, group: conflict.group})
+	}
+	for _, t := range h.Tables {
+		tb := v.queryBlock(h, b, t.QBName)
+		if tb == nil {
+			continue
+		}
+		if !tb.hasTable(t) {
+			v.warn(h, ErrWarnOptimizerHintUnresolvedName.GenWithStackByArgs(hintTableString(t), h.HintName.O))
+			continue
+		}
+		if conflict.group != "index" {
+			warnConflict(hintConflictKey{block: tb, group: conflict.group, table: t.TableName.L})
+			continue
+		}
+		for _, index := range h.Indexes {
+			warnConflict(hintConflictKey{block: tb, group: conflict.group, table: t.TableName.L + "." + index.L})
+		}
+	}
+	for _, index := range h.Indexes {
+		if !isPlausibleIndexName(index.O) {
+			v.warn(h, ErrWarnOptimizerHintUnresolvedName.GenWithStackByArgs(index.O, h.HintName.O))
+		}
+	}
+}
+
+// isPlausibleIndexName reports whether an index could be named so. The names
+// can't be empty, longer than 64 characters or end with a space.
+func isPlausibleIndexName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= mysql.MaxIndexIdentifierLen && !strings.HasSuffix(name, " ")
+}
+
+func hintTableString(t ast.HintTable) string {
+	var sb strings.Builder
+	if t.DBName.O != "" {
+		sb.WriteString(t.DBName.O)
+		sb.WriteByte('.')
+	}
+	sb.WriteString(t.TableName.O)
+	if t.QBName.O != "" {
+		sb.WriteByte('@')
+		sb.WriteString(t.QBName.O)
+	}
+	return sb.String()
+}
+
+// hintString formats a hint with its query block, tables and indexes, like
+// "USE_INDEX(@sel_2 t idx)".
+func hintString(h *ast.TableOptimizerHint) string {
+	var args []string
+	if h.QBName.O != "" {
+		if h.HintName.L == "qb_name" {
+			args = append(args, h.QBName.O)
+		} else {
+			args = append(args, "@"+h.QBName.O)
+		}
+	}
+	tables := make([]string, 0, len(h.Tables))
+	for _, t := range h.Tables {
+		tables = append(tables, hintTableString(t))
+	}
+	if len(tables) > 0 {
+		args = append(args, strings.Join(tables, ", "))
+	}
+	indexes := make([]string, 0, len(h.Indexes))
+	for _, index := range h.Indexes {
+		indexes = append(indexes, index.O)
+	}
+	if len(indexes) > 0 {
+		args = append(args, strings.Join(indexes, ", "))
+	}
+	return h.HintName.O + "(" + strings.Join(args, " ") + ")"
+}
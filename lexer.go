@@ -170,6 +170,39 @@ func (s *Scanner) getNextToken() int {
 	return tok
 }
 
+// isNextSystemVersioning reports whether the next two tokens are SYSTEM VERSIONING,
+// which can't be decided by the parser alone because a CTE may be named "system".
+func (s *Scanner) isNextSystemVersioning() bool {
+	r := s.r
+	defer func() { s.r = r }()
+	if s.getNextToken() != system {
+		return false
+	}
+	s.scan()
+	tok, _, lit := s.scan()
+	return tok == identifier && strings.EqualFold(lit, "VERSIONING")
+}
+
+// isNextSystemTimeClause reports whether the next tokens are SYSTEM_TIME followed by
+// AS OF, BETWEEN, FROM or ALL. Otherwise SYSTEM_TIME after FOR is left to the parser,
+// e.g. as the user name in SHOW GRANTS FOR system_time.
+func (s *Scanner) isNextSystemTimeClause() bool {
+	r := s.r
+	defer func() { s.r = r }()
+	if s.getNextToken() != systemTime {
+		return false
+	}
+	s.scan()
+	switch s.getNextToken() {
+	case as:
+		s.scan()
+		return s.getNextToken() == of
+	case between, from, all:
+		return true
+	}
+	return false
+}
+
 // Lex returns a token and store the token value in v.
 // Scanner satisfies yyLexer interface.
 // 0 and invalid are special token id this function would return:
@@ -213,6 +246,23 @@ func (s *Scanner) Lex(v *yySymType) int {
 		v.offset = pos.Offset
 		return asof
 	}
+	if tok == forKwd && s.isNextSystemTimeClause() {
+		_, pos, lit = s.scan()
+		v.ident = fmt.Sprintf("%s %s", v.ident, lit)
+		s.lastKeyword = forSystemTime
+		s.lastScanOffset = pos.Offset
+		v.offset = pos.Offset
+		return forSystemTime
+	}
+	if tok == with && s.isNextSystemVersioning() {
+		_, _, sys := s.scan()
+		_, pos, lit = s.scan()
+		v.ident = fmt.Sprintf("%s %s %s", v.ident, sys, lit)
+		s.lastKeyword = withSystemVersioning
+		s.lastScanOffset = pos.Offset
+		v.offset = pos.Offset
+		return withSystemVersioning
+	}
 
 	switch tok {
 	case intLit:
@@ -252,9 +302,10 @@ func (s *Scanner) LexLiteral() interface{} {
 	return symType.item
 }
 
-// SetSQLMode sets the SQL mode for scanner.
+// SetSQLMode sets the SQL mode for scanner. The combination modes are
+// expanded to the modes they imply.
 func (s *Scanner) SetSQLMode(mode mysql.SQLMode) {
-	s.sqlMode = mode
+	s.sqlMode = mode.ExpandCombinationModes()
 }
 
 // GetSQLMode return the SQL mode of scanner.
@@ -0,0 +1,185 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/terror"
+	"github.com/pingcap/parser/types"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	// ErrInvalidCharacterString returns when the bytes of a literal are invalid in its charset.
+	ErrInvalidCharacterString = terror.ClassParser.NewStd(mysql.ErrInvalidCharacterString)
+	// ErrCollationCharsetMismatch returns when the collation of a COLLATE clause doesn't belong to the charset of the literal.
+	ErrCollationCharsetMismatch = terror.ClassParser.NewStd(mysql.ErrCollationCharsetMismatch)
+)
+
+// introducerCollation returns the default collation of the charset of a
+// charset introducer. Besides the charsets of the old parser, the charsets
+// whose encodings can be decoded are supported.
+func introducerCollation(cs string) (string, error) {
+	co, err := charset.GetDefaultCollationLegacy(cs)
+	if err == nil || !charset.NewEncoding(cs).Enabled() {
+		return co, err
+	}
+	return charset.GetDefaultCollation(cs)
+}
+
+// checkLiteralCharset checks that the bytes of a literal with a charset
+// introducer are valid in the charset. The invalid bytes are an error in the
+// strict SQL mode, or else a warning and they are replaced with '?'. The
+// literal is converted to a utf8mb4 string if ConvertLiteralToUTF8 is enabled.
+// The check is skipped if EnableLiteralCharsetCheck isn't enabled.
+func (parser *Parser) checkLiteralCharset(expr ast.ValueExpr, offset int) error {
+	if !parser.literalCharsetCheck {
+		return nil
+	}
+	cs := expr.GetType().Charset
+	if cs == charset.CharsetBin {
+		return nil
+	}
+	s, err := decodeLiteral(cs, expr.GetString())
+	if err != nil {
+		litErr := newPositionError(parser.src, offset, err)
+		if parser.lexer.GetSQLMode().HasStrictMode() {
+			return litErr
+		}
+		parser.lexer.warns = append(parser.lexer.warns, litErr)
+	}
+	if parser.literalToUTF8 {
+		expr.SetValue(s)
+		tp := expr.GetType()
+		tp.Charset = charset.CharsetUTF8MB4
+		tp.Collate = charset.CollationUTF8MB4
+	}
+	return nil
+}
+
+// checkLiteralCollation checks that the collation of a COLLATE clause belongs
+// to the charset of the literal it applies to. The other expressions are
+// skipped, since their charsets aren't known while parsing.
+func (parser *Parser) checkLiteralCollation(expr ast.ExprNode, collation string, offset int) error {
+	if !parser.literalCharsetCheck {
+		return nil
+	}
+	for {
+		p, ok := expr.(*ast.ParenthesesExpr)
+		if !ok {
+			break
+		}
+		expr = p.Expr
+	}
+	v, ok := expr.(ast.ValueExpr)
+	if !ok {
+		return nil
+	}
+	tp := v.GetType()
+	if tp.Charset == "" || tp.EvalType() != types.ETString {
+		return nil
+	}
+	co, err := charset.GetCollationByName(collation)
+	if err != nil {
+		return nil
+	}
+	if !strings.EqualFold(co.CharsetName, tp.Charset) {
+		return newPositionError(parser.src, offset, ErrCollationCharsetMismatch.GenWithStackByArgs(co.Name, tp.Charset))
+	}
+	return nil
+}
+
+// decodeLiteral decodes the bytes of a literal in a charset to a UTF-8
+// string. The invalid characters are replaced with '?', and the error reports
+// the first one.
+func decodeLiteral(cs, s string) (string, error) {
+	var (
+		b       strings.Builder
+		invalid string
+	)
+	b.Grow(len(s))
+	appendInvalid := func(bytes string) {
+		if invalid == "" {
+			invalid = bytes
+		}
+		b.WriteByte('?')
+	}
+	switch strings.ToLower(cs) {
+	case charset.CharsetUTF8, charset.CharsetUTF8MB4:
+		maxLen := utf8.UTFMax
+		if strings.EqualFold(cs, charset.CharsetUTF8) {
+			maxLen = 3
+		}
+		for i := 0; i < len(s); {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			switch {
+			case r == utf8.RuneError && size <= 1:
+				appendInvalid(s[i : i+1])
+				size = 1
+			case size > maxLen:
+				appendInvalid(s[i : i+maxLen])
+			default:
+				b.WriteString(s[i : i+size])
+			}
+			i += size
+		}
+	case charset.CharsetASCII:
+		for i := 0; i < len(s); i++ {
+			if s[i] >= utf8.RuneSelf {
+				appendInvalid(s[i : i+1])
+				continue
+			}
+			b.WriteByte(s[i])
+		}
+	case charset.CharsetLatin1:
+		// MySQL latin1 is cp1252 whose undefined bytes are the C1 controls,
+		// so all the bytes are valid.
+		for i := 0; i < len(s); i++ {
+			r := charmap.Windows1252.DecodeByte(s[i])
+			if r == utf8.RuneError {
+				r = rune(s[i])
+			}
+			b.WriteRune(r)
+		}
+	default:
+		enc := charset.NewEncoding(cs)
+		if !enc.Enabled() {
+			return s, nil
+		}
+		charLength := charset.FindNextCharacterLength(enc.Name())
+		for i := 0; i < len(s); {
+			size := charLength([]byte(s[i:]))
+			if size <= 0 || i+size > len(s) {
+				size = len(s) - i
+			}
+			dec, err := enc.Decode(nil, []byte(s[i:i+size]))
+			if err != nil {
+				appendInvalid(s[i : i+size])
+			} else {
+				b.Write(dec)
+			}
+			i += size
+		}
+	}
+	if invalid != "" {
+		return b.String(), ErrInvalidCharacterString.GenWithStackByArgs(strings.ToLower(cs), fmt.Sprintf("%X", invalid))
+	}
+	return b.String(), nil
+}
@@ -161,6 +161,7 @@ var tokenMap = map[string]int{
 	"AS":                       as,
 	"ASC":                      asc,
 	"ASCII":                    ascii,
+	"ATTRIBUTE":                attribute,
 	"ATTRIBUTES":               attributes,
 	"AUTO_ID_CACHE":            autoIdCache,
 	"AUTO_INCREMENT":           autoIncrement,
@@ -207,6 +208,7 @@ var tokenMap = map[string]int{
 	"CAUSAL":                   causal,
 	"CHAIN":                    chain,
 	"CHANGE":                   change,
+	"CHANNEL":                  channel,
 	"CHAR":                     charType,
 	"CHARACTER":                character,
 	"CHARSET":                  charsetKwd,
@@ -232,6 +234,7 @@ var tokenMap = map[string]int{
 	"COMPRESSED":               compressed,
 	"COMPRESSION":              compression,
 	"CONCURRENCY":              concurrency,
+	"CONDITION":                condition,
 	"CONFIG":                   config,
 	"CONNECTION":               connection,
 	"CONSISTENCY":              consistency,
@@ -277,6 +280,7 @@ var tokenMap = map[string]int{
 	"DEC":                      decimalType,
 	"DECIMAL":                  decimalType,
 	"DEFAULT":                  defaultKwd,
+	"DEFAULT_AUTH":             defaultAuth,
 	"DEFINER":                  definer,
 	"DELAY_KEY_WRITE":          delayKeyWrite,
 	"DELAYED":                  delayed,
@@ -285,6 +289,7 @@ var tokenMap = map[string]int{
 	"DEPTH":                    depth,
 	"DESC":                     desc,
 	"DESCRIBE":                 describe,
+	"DIAGNOSTICS":              diagnostics,
 	"DIRECTORY":                directory,
 	"DISABLE":                  disable,
 	"DISCARD":                  discard,
@@ -329,11 +334,13 @@ var tokenMap = map[string]int{
 	"EXPR_PUSHDOWN_BLACKLIST":  exprPushdownBlacklist,
 	"EXTENDED":                 extended,
 	"EXTRACT":                  extract,
+	"FAILED_LOGIN_ATTEMPTS":    failedLoginAttempts,
 	"FALSE":                    falseKwd,
 	"FAULTS":                   faultsSym,
 	"FETCH":                    fetch,
 	"FIELDS":                   fields,
 	"FILE":                     file,
+	"FILTER":                   filter,
 	"FIRST":                    first,
 	"FIXED":                    fixed,
 	"FLASHBACK":                flashback,
@@ -353,6 +360,7 @@ var tokenMap = map[string]int{
 	"FUNCTION":                 function,
 	"GENERAL":                  general,
 	"GENERATED":                generated,
+	"GET":                      get,
 	"GET_FORMAT":               getFormat,
 	"GLOBAL":                   global,
 	"GRANT":                    grant,
@@ -401,6 +409,7 @@ var tokenMap = map[string]int{
 	"INVISIBLE":                invisible,
 	"INVOKER":                  invoker,
 	"IO":                       io,
+	"IO_THREAD":                ioThread,
 	"IPC":                      ipc,
 	"IS":                       is,
 	"ISOLATION":                isolation,
@@ -503,6 +512,7 @@ var tokenMap = map[string]int{
 	"OF":                       of,
 	"OFF":                      off,
 	"OFFSET":                   offset,
+	"OLD":                      old,
 	"ON_DUPLICATE":             onDuplicate,
 	"ON":                       on,
 	"ONLINE":                   online,
@@ -526,6 +536,7 @@ var tokenMap = map[string]int{
 	"PARTITIONING":             partitioning,
 	"PARTITIONS":               partitions,
 	"PASSWORD":                 password,
+	"PASSWORD_LOCK_TIME":       passwordLockTime,
 	"PERCENT":                  percent,
 	"PER_DB":                   per_db,
 	"PER_TABLE":                per_table,
@@ -533,6 +544,7 @@ var tokenMap = map[string]int{
 	"PLACEMENT":                placement,
 	"PLAN":                     plan,
 	"PLUGINS":                  plugins,
+	"PLUGIN_DIR":               pluginDir,
 	"POLICY":                   policy,
 	"POSITION":                 position,
 	"PRE_SPLIT_REGIONS":        preSplitRegions,
@@ -555,6 +567,7 @@ var tokenMap = map[string]int{
 	"QUERIES":                  queries,
 	"QUERY":                    query,
 	"QUICK":                    quick,
+	"RANDOM":                   random,
 	"RANGE":                    rangeKwd,
 	"RATE_LIMIT":               rateLimit,
 	"READ":                     read,
@@ -584,11 +597,15 @@ var tokenMap = map[string]int{
 	"REQUIRE":                  require,
 	"REQUIRED":                 required,
 	"RESET":                    reset,
+	"RESIGNAL":                 resignal,
+	"RESOURCE":                 resource,
 	"RESPECT":                  respect,
 	"RESTART":                  restart,
 	"RESTORE":                  restore,
 	"RESTORES":                 restores,
 	"RESTRICT":                 restrict,
+	"RETAIN":                   retain,
+	"REUSE":                    reuse,
 	"REVERSE":                  reverse,
 	"REVOKE":                   revoke,
 	"RIGHT":                    right,
@@ -629,6 +646,7 @@ var tokenMap = map[string]int{
 	"SHARED":                   shared,
 	"SHOW":                     show,
 	"SHUTDOWN":                 shutdown,
+	"SIGNAL":                   signal,
 	"SIGNED":                   signed,
 	"SIMPLE":                   simple,
 	"SKIP":                     skip,
@@ -641,12 +659,14 @@ var tokenMap = map[string]int{
 	"SOURCE":                   source,
 	"SPATIAL":                  spatial,
 	"SPLIT":                    split,
+	"SQLSTATE":                 sqlstate,
 	"SQL_BIG_RESULT":           sqlBigResult,
 	"SQL_BUFFER_RESULT":        sqlBufferResult,
 	"SQL_CACHE":                sqlCache,
 	"SQL_CALC_FOUND_ROWS":      sqlCalcFoundRows,
 	"SQL_NO_CACHE":             sqlNoCache,
 	"SQL_SMALL_RESULT":         sqlSmallResult,
+	"SQL_THREAD":               sqlThread,
 	"SQL_TSI_DAY":              sqlTsiDay,
 	"SQL_TSI_HOUR":             sqlTsiHour,
 	"SQL_TSI_MINUTE":           sqlTsiMinute,
@@ -657,6 +677,7 @@ var tokenMap = map[string]int{
 	"SQL_TSI_YEAR":             sqlTsiYear,
 	"SQL":                      sql,
 	"SSL":                      ssl,
+	"STACKED":                  stacked,
 	"STALENESS":                staleness,
 	"START":                    start,
 	"STARTING":                 starting,
@@ -708,6 +729,7 @@ var tokenMap = map[string]int{
 	"TEXT":                     textType,
 	"THAN":                     than,
 	"THEN":                     then,
+	"THREAD_PRIORITY":          threadPriority,
 	"TIDB":                     tidb,
 	"TIFLASH":                  tiFlash,
 	"TIKV_IMPORTER":            tikvImporter,
@@ -749,6 +771,7 @@ var tokenMap = map[string]int{
 	"UNKNOWN":                  unknown,
 	"UNLOCK":                   unlock,
 	"UNSIGNED":                 unsigned,
+	"UNTIL":                    until,
 	"UPDATE":                   update,
 	"USAGE":                    usage,
 	"USE":                      use,
@@ -768,6 +791,7 @@ var tokenMap = map[string]int{
 	"VARIABLES":                variables,
 	"VARIANCE":                 varPop,
 	"VARYING":                  varying,
+	"VCPU":                     vcpu,
 	"VERBOSE":                  verboseType,
 	"VOTER":                    voter,
 	"VOTER_CONSTRAINTS":        voterConstraints,
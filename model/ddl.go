@@ -86,6 +86,12 @@ const (
 	ActionDropPlacementPolicy           ActionType = 53
 	ActionAlterTablePartitionPolicy     ActionType = 54
 	ActionModifySchemaDefaultPlacement  ActionType = 55
+	ActionAlterTablePlacement           ActionType = 56
+
+	// The action types of partitioning a table again and removing the
+	// partitioning have the same values as the ones of TiDB.
+	ActionAlterTablePartitioning ActionType = 72
+	ActionRemovePartitioning     ActionType = 73
 )
 
 var actionMap = map[ActionType]string{
@@ -142,6 +148,9 @@ var actionMap = map[ActionType]string{
 	ActionAlterPlacementPolicy:          "alter placement policy",
 	ActionDropPlacementPolicy:           "drop placement policy",
 	ActionModifySchemaDefaultPlacement:  "modify schema default placement",
+	ActionAlterTablePlacement:           "alter table placement",
+	ActionAlterTablePartitioning:        "alter table partition by",
+	ActionRemovePartitioning:            "alter table remove partitioning",
 }
 
 // String return current ddl action in string
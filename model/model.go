@@ -293,6 +293,7 @@ type TableInfo struct {
 	AutoRandID      int64  `json:"auto_rand_id"`
 	MaxColumnID     int64  `json:"max_col_id"`
 	MaxIndexID      int64  `json:"max_idx_id"`
+	MaxForeignKeyID int64  `json:"max_fk_id"`
 	MaxConstraintID int64  `json:"max_cst_id"`
 	// UpdateTS is used to record the timestamp of updating the table's schema information.
 	// These changing schema operations don't include 'truncate table' and 'rename table'.
@@ -1199,3 +1200,81 @@ func (p *PlacementSettings) String() string {
 
 	return sb.String()
 }
+
+// ResourceGroupType is the type of the threads a resource group can be assigned to.
+type ResourceGroupType byte
+
+// List resource group types.
+const (
+	ResourceGroupTypeNone ResourceGroupType = iota
+	ResourceGroupTypeSystem
+	ResourceGroupTypeUser
+)
+
+// String implements fmt.Stringer interface.
+func (t ResourceGroupType) String() string {
+	switch t {
+	case ResourceGroupTypeSystem:
+		return "SYSTEM"
+	case ResourceGroupTypeUser:
+		return "USER"
+	}
+	return ""
+}
+
+// VCPURange is a range of virtual CPU IDs, Start equals End for a single CPU.
+type VCPURange struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// String implements fmt.Stringer interface.
+func (r VCPURange) String() string {
+	if r.Start == r.End {
+		return strconv.FormatUint(r.Start, 10)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// ResourceGroupSettings is the settings of the resource group.
+type ResourceGroupSettings struct {
+	Type           ResourceGroupType `json:"type"`
+	VCPUs          []VCPURange       `json:"vcpus"`
+	ThreadPriority int64             `json:"thread_priority"`
+	Enabled        bool              `json:"enabled"`
+}
+
+// String returns the settings like the options of CREATE RESOURCE GROUP,
+// e.g. "TYPE=USER VCPU=0-3 THREAD_PRIORITY=5 ENABLE".
+func (r *ResourceGroupSettings) String() string {
+	sb := new(strings.Builder)
+	if r.Type != ResourceGroupTypeNone {
+		writeSettingItemToBuilder(sb, fmt.Sprintf("TYPE=%s", r.Type))
+	}
+
+	if len(r.VCPUs) > 0 {
+		vcpus := make([]string, 0, len(r.VCPUs))
+		for _, vcpu := range r.VCPUs {
+			vcpus = append(vcpus, vcpu.String())
+		}
+		writeSettingItemToBuilder(sb, fmt.Sprintf("VCPU=%s", strings.Join(vcpus, ",")))
+	}
+
+	writeSettingItemToBuilder(sb, fmt.Sprintf("THREAD_PRIORITY=%d", r.ThreadPriority))
+
+	if r.Enabled {
+		writeSettingItemToBuilder(sb, "ENABLE")
+	} else {
+		writeSettingItemToBuilder(sb, "DISABLE")
+	}
+
+	return sb.String()
+}
+
+// ResourceGroupInfo is the struct to store the resource group.
+type ResourceGroupInfo struct {
+	*ResourceGroupSettings
+	ID    int64       `json:"id"`
+	Name  CIStr       `json:"name"`
+	State SchemaState `json:"state"`
+}
@@ -440,3 +440,28 @@ func TestPlacementSettingsString(t *testing.T) {
 	}
 	assert.Equal("CONSTRAINTS=\"{+us-east-1:1,+us-east-2:1}\" VOTERS=3 FOLLOWERS=2 LEARNERS=1", settings.String())
 }
+
+func TestResourceGroupSettingsString(t *testing.T) {
+	assert := assert.New(t)
+
+	settings := &ResourceGroupSettings{
+		Type:           ResourceGroupTypeUser,
+		VCPUs:          []VCPURange{{Start: 0, End: 3}, {Start: 5, End: 5}},
+		ThreadPriority: 5,
+		Enabled:        true,
+	}
+	assert.Equal("TYPE=USER VCPU=0-3,5 THREAD_PRIORITY=5 ENABLE", settings.String())
+
+	settings = &ResourceGroupSettings{
+		Type:           ResourceGroupTypeSystem,
+		ThreadPriority: -10,
+	}
+	assert.Equal("TYPE=SYSTEM THREAD_PRIORITY=-10 DISABLE", settings.String())
+
+	info := &ResourceGroupInfo{ResourceGroupSettings: settings, ID: 1, Name: NewCIStr("rg1")}
+	data, err := json.Marshal(info)
+	assert.Nil(err)
+	decoded := &ResourceGroupInfo{}
+	assert.Nil(json.Unmarshal(data, decoded))
+	assert.Equal(info, decoded)
+}
@@ -0,0 +1,418 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/terror"
+)
+
+// The error codes of the placement policies, which are defined by TiDB.
+const (
+	codeInvalidPlacementSpec            terror.ErrCode = 8227
+	codePlacementPolicyCheck            terror.ErrCode = 8228
+	codePlacementPolicyNotExists        terror.ErrCode = 8239
+	codePlacementPolicyWithDirectOption terror.ErrCode = 8240
+)
+
+var (
+	// ErrInvalidPlacementSpec is returned when a placement option is malformed
+	// or conflicts with another option.
+	ErrInvalidPlacementSpec = terror.ClassDDL.NewStdErr(codeInvalidPlacementSpec, mysql.Message("Invalid placement policy '%s': %s", nil))
+	// ErrPlacementPolicyCheck is returned when the replica counts can't satisfy the constraints.
+	ErrPlacementPolicyCheck = terror.ClassDDL.NewStdErr(codePlacementPolicyCheck, mysql.Message("Placement policy didn't meet the constraint, reason: %s", nil))
+	// ErrPlacementPolicyNotExists is returned when a referenced placement policy doesn't exist.
+	ErrPlacementPolicyNotExists = terror.ClassSchema.NewStdErr(codePlacementPolicyNotExists, mysql.Message("Unknown placement policy '%-.192s'", nil))
+	// ErrPlacementPolicyWithDirectOption is returned when a placement policy
+	// is referenced together with the direct placement options which aren't
+	// allowed to override it.
+	ErrPlacementPolicyWithDirectOption = terror.ClassDDL.NewStdErr(codePlacementPolicyWithDirectOption, mysql.Message("Placement policy '%s' can't co-exist with direct placement options", nil))
+)
+
+// PlacementConstraintOp is the operator of a label constraint.
+type PlacementConstraintOp byte
+
+// Operators of label constraints.
+const (
+	// PlacementConstraintIn means the store must have the label, e.g. +zone=z1.
+	PlacementConstraintIn PlacementConstraintOp = '+'
+	// PlacementConstraintNotIn means the store mustn't have the label, e.g. -zone=z1.
+	PlacementConstraintNotIn PlacementConstraintOp = '-'
+)
+
+// PlacementConstraint is a label constraint of the stores placing the replicas.
+type PlacementConstraint struct {
+	Op    PlacementConstraintOp
+	Key   string
+	Value string
+}
+
+// String implements fmt.Stringer interface.
+func (c PlacementConstraint) String() string {
+	return fmt.Sprintf("%c%s=%s", c.Op, c.Key, c.Value)
+}
+
+// PlacementConstraintGroup is an entry of the dict form of the constraints:
+// Count replicas are placed on the stores satisfying Constraints.
+type PlacementConstraintGroup struct {
+	Constraints []PlacementConstraint
+	Count       uint64
+}
+
+// PlacementConstraints is a parsed constraints option. It is either in the
+// list form, e.g. [+region=us, -zone=a], whose constraints apply to all the
+// replicas, or in the dict form, e.g. {"+zone=sh": 1, "+zone=bj": 2}.
+type PlacementConstraints struct {
+	List []PlacementConstraint
+	// Dict is sorted by the constraints.
+	Dict []PlacementConstraintGroup
+}
+
+// IsDict returns whether the constraints are in the dict form.
+func (c *PlacementConstraints) IsDict() bool {
+	return c.Dict != nil
+}
+
+// ReplicaCount returns the number of replicas the dict form requires.
+func (c *PlacementConstraints) ReplicaCount() uint64 {
+	var count uint64
+	for _, group := range c.Dict {
+		count += group.Count
+	}
+	return count
+}
+
+// parsePlacementConstraint parses a constraint like +key=value.
+func parsePlacementConstraint(s string) (PlacementConstraint, error) {
+	s = unquotePlacementString(strings.TrimSpace(s))
+	if len(s) < 4 || (s[0] != byte(PlacementConstraintIn) && s[0] != byte(PlacementConstraintNotIn)) {
+		return PlacementConstraint{}, errors.Errorf("label constraint '%s' should be in format '{+|-}key=value'", s)
+	}
+	kv := strings.SplitN(s[1:], "=", 2)
+	if len(kv) != 2 {
+		return PlacementConstraint{}, errors.Errorf("label constraint '%s' should be in format '{+|-}key=value'", s)
+	}
+	key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+	if key == "" || value == "" {
+		return PlacementConstraint{}, errors.Errorf("label constraint '%s' should be in format '{+|-}key=value'", s)
+	}
+	if key == "engine" && s[0] == byte(PlacementConstraintIn) && strings.EqualFold(value, "tiflash") {
+		return PlacementConstraint{}, errors.Errorf("unsupported label constraint '%s'", s)
+	}
+	return PlacementConstraint{Op: PlacementConstraintOp(s[0]), Key: key, Value: value}, nil
+}
+
+func unquotePlacementString(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// splitPlacementList splits a list by the separators which aren't quoted.
+func splitPlacementList(s string, sep byte) []string {
+	var (
+		items []string
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == sep:
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// parsePlacementConstraintList parses the constraints separated by commas.
+func parsePlacementConstraintList(s string) ([]PlacementConstraint, error) {
+	list := []PlacementConstraint{}
+	for _, item := range splitPlacementList(s, ',') {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		c, err := parsePlacementConstraint(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, checkPlacementConstraintConflicts(list, nil)
+}
+
+// ParsePlacementConstraints parses a constraints option in the list form or
+// in the dict form.
+func ParsePlacementConstraints(s string) (*PlacementConstraints, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return &PlacementConstraints{}, nil
+	case s[0] == '[' && s[len(s)-1] == ']':
+		list, err := parsePlacementConstraintList(s[1 : len(s)-1])
+		if err != nil {
+			return nil, err
+		}
+		return &PlacementConstraints{List: list}, nil
+	case s[0] == '{' && s[len(s)-1] == '}':
+		dict := []PlacementConstraintGroup{}
+		for _, entry := range splitPlacementList(s[1:len(s)-1], ',') {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			kv := splitPlacementList(entry, ':')
+			if len(kv) != 2 {
+				return nil, errors.Errorf("'%s' should be in format '\"{+|-}key=value,...\": count'", strings.TrimSpace(entry))
+			}
+			list, err := parsePlacementConstraintList(unquotePlacementString(strings.TrimSpace(kv[0])))
+			if err != nil {
+				return nil, err
+			}
+			count, err := strconv.ParseUint(strings.TrimSpace(kv[1]), 10, 64)
+			if err != nil || count == 0 {
+				return nil, errors.Errorf("count of '%s' should be a positive integer", strings.TrimSpace(kv[0]))
+			}
+			dict = append(dict, PlacementConstraintGroup{Constraints: list, Count: count})
+		}
+		sort.SliceStable(dict, func(i, j int) bool {
+			return fmt.Sprint(dict[i].Constraints) < fmt.Sprint(dict[j].Constraints)
+		})
+		return &PlacementConstraints{Dict: dict}, nil
+	}
+	return nil, errors.Errorf("constraints should be a list like [+key=value] or a dict like {\"+key=value\": count}")
+}
+
+// checkPlacementConstraintConflicts checks that no store can satisfy the
+// constraints in a and b at the same time, e.g. +zone=a and -zone=a, or
+// +zone=a and +zone=b.
+func checkPlacementConstraintConflicts(a, b []PlacementConstraint) error {
+	all := append(append([]PlacementConstraint(nil), a...), b...)
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			x, y := all[i], all[j]
+			if x.Key != y.Key {
+				continue
+			}
+			if (x.Op != y.Op && x.Value == y.Value) ||
+				(x.Op == PlacementConstraintIn && y.Op == PlacementConstraintIn && x.Value != y.Value) {
+				return errors.Errorf("conflicting label constraints '%s' and '%s'", x, y)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the placement settings: the constraints must be well formed
+// and mustn't conflict, PRIMARY_REGION, REGIONS and SCHEDULE can't be used
+// together with the constraints, and the replica counts must satisfy the
+// dict form constraints.
+func (p *PlacementSettings) Validate() error {
+	invalid := func(option string, value interface{}, reason string) error {
+		return ErrInvalidPlacementSpec.GenWithStackByArgs(fmt.Sprintf("%s=%v", option, value), reason)
+	}
+
+	// PRIMARY_REGION, REGIONS and SCHEDULE are the shorthand of the constraints.
+	if p.PrimaryRegion != "" || p.Regions != "" || p.Schedule != "" {
+		for _, opt := range []struct {
+			name  string
+			value string
+		}{
+			{"CONSTRAINTS", p.Constraints},
+			{"LEADER_CONSTRAINTS", p.LeaderConstraints},
+			{"FOLLOWER_CONSTRAINTS", p.FollowerConstraints},
+			{"VOTER_CONSTRAINTS", p.VoterConstraints},
+			{"LEARNER_CONSTRAINTS", p.LearnerConstraints},
+		} {
+			if opt.value != "" {
+				return invalid(opt.name, opt.value, "PRIMARY_REGION, REGIONS and SCHEDULE can't be used together with the constraints")
+			}
+		}
+		if p.PrimaryRegion == "" || p.Regions == "" {
+			return invalid("PRIMARY_REGION", p.PrimaryRegion, "PRIMARY_REGION and REGIONS must be specified together")
+		}
+		found := false
+		for _, region := range strings.Split(p.Regions, ",") {
+			if strings.TrimSpace(region) == p.PrimaryRegion {
+				found = true
+			}
+		}
+		if !found {
+			return invalid("PRIMARY_REGION", p.PrimaryRegion, "PRIMARY_REGION must be one of REGIONS")
+		}
+		switch strings.ToUpper(p.Schedule) {
+		case "", "EVEN", "MAJORITY_IN_PRIMARY":
+		default:
+			return invalid("SCHEDULE", p.Schedule, "SCHEDULE must be EVEN or MAJORITY_IN_PRIMARY")
+		}
+		return nil
+	}
+
+	constraints, err := ParsePlacementConstraints(p.Constraints)
+	if err != nil {
+		return invalid("CONSTRAINTS", p.Constraints, err.Error())
+	}
+	leader, err := ParsePlacementConstraints(p.LeaderConstraints)
+	if err != nil {
+		return invalid("LEADER_CONSTRAINTS", p.LeaderConstraints, err.Error())
+	}
+	if leader.IsDict() {
+		return invalid("LEADER_CONSTRAINTS", p.LeaderConstraints, "LEADER_CONSTRAINTS must be a list")
+	}
+	if err := checkPlacementConstraintConflicts(constraints.List, leader.List); err != nil {
+		return invalid("LEADER_CONSTRAINTS", p.LeaderConstraints, err.Error())
+	}
+	if constraints.IsDict() && p.Followers > 0 && constraints.ReplicaCount() > p.Followers+1 {
+		return ErrPlacementPolicyCheck.GenWithStackByArgs(fmt.Sprintf(
+			"CONSTRAINTS require %d replicas, but there are only %d replicas", constraints.ReplicaCount(), p.Followers+1))
+	}
+
+	for _, role := range []struct {
+		name        string
+		constraints string
+		countName   string
+		count       uint64
+	}{
+		{"FOLLOWER_CONSTRAINTS", p.FollowerConstraints, "FOLLOWERS", p.Followers},
+		{"VOTER_CONSTRAINTS", p.VoterConstraints, "VOTERS", p.Voters},
+		{"LEARNER_CONSTRAINTS", p.LearnerConstraints, "LEARNERS", p.Learners},
+	} {
+		c, err := ParsePlacementConstraints(role.constraints)
+		if err != nil {
+			return invalid(role.name, role.constraints, err.Error())
+		}
+		if err := checkPlacementConstraintConflicts(constraints.List, c.List); err != nil {
+			return invalid(role.name, role.constraints, err.Error())
+		}
+		if role.constraints != "" && role.count == 0 && role.name == "LEARNER_CONSTRAINTS" {
+			return invalid(role.name, role.constraints, "LEARNER_CONSTRAINTS require LEARNERS")
+		}
+		if c.IsDict() && role.count > 0 && c.ReplicaCount() > role.count {
+			return ErrPlacementPolicyCheck.GenWithStackByArgs(fmt.Sprintf(
+				"%s require %d replicas, but %s is %d", role.name, c.ReplicaCount(), role.countName, role.count))
+		}
+	}
+	return nil
+}
+
+// placementShorthandSet returns whether the shorthand options are set.
+func (p *PlacementSettings) placementShorthandSet() bool {
+	return p.PrimaryRegion != "" || p.Regions != "" || p.Schedule != ""
+}
+
+// placementConstraintsSet returns whether the constraints options are set.
+func (p *PlacementSettings) placementConstraintsSet() bool {
+	return p.Constraints != "" || p.LeaderConstraints != "" || p.FollowerConstraints != "" ||
+		p.VoterConstraints != "" || p.LearnerConstraints != ""
+}
+
+// MergePlacementSettings returns the settings of base overridden by the
+// options set in override. The shorthand options PRIMARY_REGION, REGIONS and
+// SCHEDULE can't be used together with the constraints, so the shorthand
+// options of base are dropped when override sets the constraints, and vice
+// versa. Neither base nor override is modified.
+func MergePlacementSettings(base, override *PlacementSettings) *PlacementSettings {
+	if base == nil && override == nil {
+		return nil
+	}
+	merged := &PlacementSettings{}
+	if base != nil {
+		*merged = *base
+	}
+	if override == nil {
+		return merged
+	}
+	if override.placementConstraintsSet() {
+		merged.PrimaryRegion, merged.Regions, merged.Schedule = "", "", ""
+	}
+	if override.placementShorthandSet() {
+		merged.Constraints, merged.LeaderConstraints, merged.FollowerConstraints = "", "", ""
+		merged.VoterConstraints, merged.LearnerConstraints = "", ""
+	}
+	overrideString := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	overrideUint := func(dst *uint64, src uint64) {
+		if src != 0 {
+			*dst = src
+		}
+	}
+	overrideString(&merged.PrimaryRegion, override.PrimaryRegion)
+	overrideString(&merged.Regions, override.Regions)
+	overrideString(&merged.Schedule, override.Schedule)
+	overrideString(&merged.Constraints, override.Constraints)
+	overrideString(&merged.LeaderConstraints, override.LeaderConstraints)
+	overrideString(&merged.FollowerConstraints, override.FollowerConstraints)
+	overrideString(&merged.VoterConstraints, override.VoterConstraints)
+	overrideString(&merged.LearnerConstraints, override.LearnerConstraints)
+	overrideUint(&merged.Followers, override.Followers)
+	overrideUint(&merged.Voters, override.Voters)
+	overrideUint(&merged.Learners, override.Learners)
+	return merged
+}
+
+// findPolicy finds the referenced policy by ID, or by name if the ID is unset.
+func findPolicy(ref *PolicyRefInfo, policies []*PolicyInfo) *PolicyInfo {
+	for _, policy := range policies {
+		if (ref.ID != 0 && policy.ID == ref.ID) || (ref.ID == 0 && policy.Name.L == ref.Name.L) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// EffectivePlacementSettings computes the placement settings of an object
+// which references a placement policy with ref and has the direct placement
+// options direct, either of which may be nil. The direct options override the
+// options of the policy, see MergePlacementSettings. If overrideAllowed is
+// false, ErrPlacementPolicyWithDirectOption is returned when both are set.
+// The effective settings are validated. It returns nil if neither is set.
+func EffectivePlacementSettings(ref *PolicyRefInfo, direct *PlacementSettings, policies []*PolicyInfo, overrideAllowed bool) (*PlacementSettings, error) {
+	var base *PlacementSettings
+	if ref != nil {
+		policy := findPolicy(ref, policies)
+		if policy == nil {
+			return nil, ErrPlacementPolicyNotExists.GenWithStackByArgs(ref.Name.O)
+		}
+		if direct != nil && !overrideAllowed {
+			return nil, ErrPlacementPolicyWithDirectOption.GenWithStackByArgs(ref.Name.O)
+		}
+		base = policy.PlacementSettings
+		if base == nil {
+			base = &PlacementSettings{}
+		}
+	}
+	settings := MergePlacementSettings(base, direct)
+	if settings == nil {
+		return nil, nil
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
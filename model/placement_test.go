@@ -0,0 +1,183 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/terror"
+)
+
+var _ = Suite(&testPlacementSuite{})
+
+type testPlacementSuite struct {
+}
+
+func (*testPlacementSuite) TestParseConstraints(c *C) {
+	cons, err := ParsePlacementConstraints(` [+region=us, "-zone=a" ,'+disk=ssd'] `)
+	c.Assert(err, IsNil)
+	c.Assert(cons.IsDict(), IsFalse)
+	c.Assert(cons.List, DeepEquals, []PlacementConstraint{
+		{Op: PlacementConstraintIn, Key: "region", Value: "us"},
+		{Op: PlacementConstraintNotIn, Key: "zone", Value: "a"},
+		{Op: PlacementConstraintIn, Key: "disk", Value: "ssd"},
+	})
+	c.Assert(cons.List[1].String(), Equals, "-zone=a")
+
+	cons, err = ParsePlacementConstraints(`{"+zone=sh,-disk=hdd": 1, "+zone=bj": 2}`)
+	c.Assert(err, IsNil)
+	c.Assert(cons.IsDict(), IsTrue)
+	c.Assert(cons.ReplicaCount(), Equals, uint64(3))
+	c.Assert(cons.Dict, DeepEquals, []PlacementConstraintGroup{
+		{Constraints: []PlacementConstraint{{Op: PlacementConstraintIn, Key: "zone", Value: "bj"}}, Count: 2},
+		{Constraints: []PlacementConstraint{
+			{Op: PlacementConstraintIn, Key: "zone", Value: "sh"},
+			{Op: PlacementConstraintNotIn, Key: "disk", Value: "hdd"},
+		}, Count: 1},
+	})
+
+	cons, err = ParsePlacementConstraints("")
+	c.Assert(err, IsNil)
+	c.Assert(cons.List, IsNil)
+	c.Assert(cons.IsDict(), IsFalse)
+	cons, err = ParsePlacementConstraints("[]")
+	c.Assert(err, IsNil)
+	c.Assert(cons.List, HasLen, 0)
+
+	tests := []struct {
+		constraints string
+		err         string
+	}{
+		{"+zone=sh", "constraints should be a list like .*"},
+		{"[zone=sh]", "label constraint 'zone=sh' should be in format '{\\+|-}key=value'"},
+		{"[+zone]", "label constraint '\\+zone' should be in format .*"},
+		{"[+zone=]", "label constraint '\\+zone=' should be in format .*"},
+		{"[+engine=tiflash]", "unsupported label constraint '\\+engine=tiflash'"},
+		{"[+zone=a, -zone=a]", "conflicting label constraints '\\+zone=a' and '-zone=a'"},
+		{"[+zone=a, +zone=b]", "conflicting label constraints '\\+zone=a' and '\\+zone=b'"},
+		{`{"+zone=sh": 0}`, "count of '\"\\+zone=sh\"' should be a positive integer"},
+		{`{"+zone=sh"}`, `'"\+zone=sh"' should be in format .*`},
+	}
+	for _, t := range tests {
+		_, err := ParsePlacementConstraints(t.constraints)
+		c.Assert(err, ErrorMatches, t.err, Commentf("%s", t.constraints))
+	}
+	// Different values of a label which is excluded don't conflict.
+	_, err = ParsePlacementConstraints("[-zone=a, -zone=b, +zone=c]")
+	c.Assert(err, IsNil)
+}
+
+func (*testPlacementSuite) TestValidate(c *C) {
+	valid := []*PlacementSettings{
+		{},
+		{PrimaryRegion: "us", Regions: "us, eu", Schedule: "EVEN"},
+		{PrimaryRegion: "us", Regions: "us", Followers: 4},
+		{Constraints: "[+disk=ssd]", LeaderConstraints: "[+zone=z1]", Followers: 2},
+		{Constraints: `{"+zone=z1": 1, "+zone=z2": 2}`, Followers: 2},
+		{FollowerConstraints: `{"+zone=z1": 2}`, Followers: 2},
+		{Learners: 1, LearnerConstraints: "[+engine=tiflash2]"},
+	}
+	for _, settings := range valid {
+		c.Assert(settings.Validate(), IsNil, Commentf("%s", settings))
+	}
+
+	tests := []struct {
+		settings *PlacementSettings
+		err      *terror.Error
+		msg      string
+	}{
+		{&PlacementSettings{PrimaryRegion: "us", Regions: "us", Constraints: "[+disk=ssd]"}, ErrInvalidPlacementSpec,
+			"[ddl:8227]Invalid placement policy 'CONSTRAINTS=[+disk=ssd]': PRIMARY_REGION, REGIONS and SCHEDULE can't be used together with the constraints"},
+		{&PlacementSettings{Schedule: "EVEN", LearnerConstraints: "[+disk=ssd]"}, ErrInvalidPlacementSpec, ""},
+		{&PlacementSettings{PrimaryRegion: "us"}, ErrInvalidPlacementSpec,
+			"[ddl:8227]Invalid placement policy 'PRIMARY_REGION=us': PRIMARY_REGION and REGIONS must be specified together"},
+		{&PlacementSettings{PrimaryRegion: "us", Regions: "eu,asia"}, ErrInvalidPlacementSpec,
+			"[ddl:8227]Invalid placement policy 'PRIMARY_REGION=us': PRIMARY_REGION must be one of REGIONS"},
+		{&PlacementSettings{PrimaryRegion: "us", Regions: "us", Schedule: "RANDOM"}, ErrInvalidPlacementSpec, ""},
+		{&PlacementSettings{Constraints: "+disk=ssd"}, ErrInvalidPlacementSpec, ""},
+		{&PlacementSettings{LeaderConstraints: `{"+zone=z1": 1}`}, ErrInvalidPlacementSpec,
+			"[ddl:8227]Invalid placement policy 'LEADER_CONSTRAINTS={\"+zone=z1\": 1}': LEADER_CONSTRAINTS must be a list"},
+		{&PlacementSettings{Constraints: "[+zone=z1]", LeaderConstraints: "[-zone=z1]"}, ErrInvalidPlacementSpec,
+			"[ddl:8227]Invalid placement policy 'LEADER_CONSTRAINTS=[-zone=z1]': conflicting label constraints '+zone=z1' and '-zone=z1'"},
+		{&PlacementSettings{Constraints: "[+zone=z1]", VoterConstraints: "[+zone=z2]"}, ErrInvalidPlacementSpec, ""},
+		{&PlacementSettings{LearnerConstraints: "[+disk=ssd]"}, ErrInvalidPlacementSpec,
+			"[ddl:8227]Invalid placement policy 'LEARNER_CONSTRAINTS=[+disk=ssd]': LEARNER_CONSTRAINTS require LEARNERS"},
+		{&PlacementSettings{Constraints: `{"+zone=z1": 2, "+zone=z2": 2}`, Followers: 2}, ErrPlacementPolicyCheck,
+			"[ddl:8228]Placement policy didn't meet the constraint, reason: CONSTRAINTS require 4 replicas, but there are only 3 replicas"},
+		{&PlacementSettings{VoterConstraints: `{"+zone=z1": 2}`, Voters: 1}, ErrPlacementPolicyCheck,
+			"[ddl:8228]Placement policy didn't meet the constraint, reason: VOTER_CONSTRAINTS require 2 replicas, but VOTERS is 1"},
+	}
+	for _, t := range tests {
+		comment := Commentf("%s", t.settings)
+		err := t.settings.Validate()
+		c.Assert(terror.ErrorEqual(err, t.err), IsTrue, comment)
+		if t.msg != "" {
+			c.Assert(err.Error(), Equals, t.msg, comment)
+		}
+	}
+}
+
+func (*testPlacementSuite) TestEffectiveSettings(c *C) {
+	policies := []*PolicyInfo{
+		{ID: 1, Name: NewCIStr("p1"), PlacementSettings: &PlacementSettings{PrimaryRegion: "us", Regions: "us,eu", Followers: 4}},
+		{ID: 2, Name: NewCIStr("p2"), PlacementSettings: &PlacementSettings{Constraints: "[+disk=ssd]", LeaderConstraints: "[+zone=z1]"}},
+		{ID: 3, Name: NewCIStr("p3")},
+	}
+
+	settings, err := EffectivePlacementSettings(nil, nil, policies, true)
+	c.Assert(err, IsNil)
+	c.Assert(settings, IsNil)
+
+	settings, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 1, Name: NewCIStr("p1")}, nil, policies, true)
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, policies[0].PlacementSettings)
+	c.Assert(settings == policies[0].PlacementSettings, IsFalse)
+
+	// The policy is found by name if the ID is unset.
+	settings, err = EffectivePlacementSettings(&PolicyRefInfo{Name: NewCIStr("P3")}, nil, policies, true)
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, &PlacementSettings{})
+
+	// The direct options override the options of the policy.
+	settings, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 1, Name: NewCIStr("p1")},
+		&PlacementSettings{PrimaryRegion: "eu", Followers: 2}, policies, true)
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, &PlacementSettings{PrimaryRegion: "eu", Regions: "us,eu", Followers: 2})
+
+	// The constraints replace the shorthand options of the policy, and vice versa.
+	settings, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 1, Name: NewCIStr("p1")},
+		&PlacementSettings{Constraints: "[+zone=z2]"}, policies, true)
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, &PlacementSettings{Constraints: "[+zone=z2]", Followers: 4})
+	settings, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 2, Name: NewCIStr("p2")},
+		&PlacementSettings{PrimaryRegion: "us", Regions: "us"}, policies, true)
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, &PlacementSettings{PrimaryRegion: "us", Regions: "us"})
+
+	// The effective settings are validated.
+	_, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 2, Name: NewCIStr("p2")},
+		&PlacementSettings{Constraints: "[-zone=z1]"}, policies, true)
+	c.Assert(terror.ErrorEqual(err, ErrInvalidPlacementSpec), IsTrue)
+
+	_, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 1, Name: NewCIStr("p1")}, &PlacementSettings{Followers: 2}, policies, false)
+	c.Assert(terror.ErrorEqual(err, ErrPlacementPolicyWithDirectOption), IsTrue)
+	c.Assert(err.Error(), Equals, "[ddl:8240]Placement policy 'p1' can't co-exist with direct placement options")
+	_, err = EffectivePlacementSettings(&PolicyRefInfo{ID: 9, Name: NewCIStr("p9")}, nil, policies, true)
+	c.Assert(terror.ErrorEqual(err, ErrPlacementPolicyNotExists), IsTrue)
+	c.Assert(err.Error(), Equals, "[schema:8239]Unknown placement policy 'p9'")
+
+	// The direct options alone.
+	settings, err = EffectivePlacementSettings(nil, &PlacementSettings{Learners: 1}, nil, false)
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, &PlacementSettings{Learners: 1})
+}
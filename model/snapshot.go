@@ -0,0 +1,247 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// Format versions of SchemaSnapshot.
+const (
+	// SchemaSnapshotVersion1 is the first format version.
+	SchemaSnapshotVersion1 = 1
+	// CurrentSchemaSnapshotVersion is the format version snapshots are encoded in.
+	CurrentSchemaSnapshotVersion = SchemaSnapshotVersion1
+)
+
+// SchemaSnapshot is the serialization format of the schema at a schema version.
+// The schema objects are encoded with their own JSON tags, which are part of
+// the format: e.g. TableInfo.ShardRowIDBits is encoded as "ShardRowIDBits"
+// since it has no tag, and TableInfo.Lock is encoded as "Lock". Renaming a
+// field or a tag breaks the decoding of the stored snapshots, which
+// CheckSchemaSnapshotCompatibility detects.
+type SchemaSnapshot struct {
+	FormatVersion  int                  `json:"format_version"`
+	SchemaVersion  int64                `json:"schema_version"`
+	Policies       []*PolicyInfo        `json:"policies"`
+	ResourceGroups []*ResourceGroupInfo `json:"resource_groups"`
+	Databases      []*DBSnapshotInfo    `json:"databases"`
+}
+
+// DBSnapshotInfo is a database in a SchemaSnapshot. DBInfo.Tables isn't
+// encoded, so the tables, the views and the sequences of the database are kept
+// beside it.
+type DBSnapshotInfo struct {
+	DB        *DBInfo      `json:"db"`
+	Tables    []*TableInfo `json:"tables"`
+	Sequences []*TableInfo `json:"sequences"`
+}
+
+// NewSchemaSnapshot creates a snapshot of the databases, the placement
+// policies and the resource groups at a schema version. The schema objects
+// aren't copied.
+func NewSchemaSnapshot(schemaVersion int64, dbs []*DBInfo, policies []*PolicyInfo, resourceGroups []*ResourceGroupInfo) *SchemaSnapshot {
+	s := &SchemaSnapshot{
+		FormatVersion:  CurrentSchemaSnapshotVersion,
+		SchemaVersion:  schemaVersion,
+		Policies:       policies,
+		ResourceGroups: resourceGroups,
+		Databases:      make([]*DBSnapshotInfo, 0, len(dbs)),
+	}
+	for _, db := range dbs {
+		info := &DBSnapshotInfo{DB: db}
+		for _, tbl := range db.Tables {
+			if tbl.IsSequence() {
+				info.Sequences = append(info.Sequences, tbl)
+			} else {
+				info.Tables = append(info.Tables, tbl)
+			}
+		}
+		s.Databases = append(s.Databases, info)
+	}
+	return s
+}
+
+// DBInfos returns the databases of the snapshot, with their tables, views and
+// sequences in DBInfo.Tables.
+func (s *SchemaSnapshot) DBInfos() []*DBInfo {
+	dbs := make([]*DBInfo, 0, len(s.Databases))
+	for _, info := range s.Databases {
+		db := *info.DB
+		db.Tables = make([]*TableInfo, 0, len(info.Tables)+len(info.Sequences))
+		db.Tables = append(db.Tables, info.Tables...)
+		db.Tables = append(db.Tables, info.Sequences...)
+		dbs = append(dbs, &db)
+	}
+	return dbs
+}
+
+// EncodeSchemaSnapshot encodes a snapshot in JSON. The encoding is
+// deterministic: the policies, the resource groups, the databases and the
+// tables are sorted by ID, so the same schema is always encoded into the same
+// bytes. The snapshot itself isn't modified.
+func EncodeSchemaSnapshot(s *SchemaSnapshot) ([]byte, error) {
+	sorted := *s
+	if sorted.FormatVersion == 0 {
+		sorted.FormatVersion = CurrentSchemaSnapshotVersion
+	}
+	if s.Policies != nil {
+		sorted.Policies = make([]*PolicyInfo, len(s.Policies))
+		copy(sorted.Policies, s.Policies)
+	}
+	sort.SliceStable(sorted.Policies, func(i, j int) bool { return sorted.Policies[i].ID < sorted.Policies[j].ID })
+	if s.ResourceGroups != nil {
+		sorted.ResourceGroups = make([]*ResourceGroupInfo, len(s.ResourceGroups))
+		copy(sorted.ResourceGroups, s.ResourceGroups)
+	}
+	sort.SliceStable(sorted.ResourceGroups, func(i, j int) bool { return sorted.ResourceGroups[i].ID < sorted.ResourceGroups[j].ID })
+	sorted.Databases = nil
+	if s.Databases != nil {
+		sorted.Databases = make([]*DBSnapshotInfo, 0, len(s.Databases))
+	}
+	for _, info := range s.Databases {
+		sorted.Databases = append(sorted.Databases, &DBSnapshotInfo{
+			DB:        info.DB,
+			Tables:    sortTablesByID(info.Tables),
+			Sequences: sortTablesByID(info.Sequences),
+		})
+	}
+	sort.SliceStable(sorted.Databases, func(i, j int) bool { return sorted.Databases[i].DB.ID < sorted.Databases[j].DB.ID })
+	data, err := json.Marshal(&sorted)
+	return data, errors.Trace(err)
+}
+
+func sortTablesByID(tbls []*TableInfo) []*TableInfo {
+	if tbls == nil {
+		return nil
+	}
+	sorted := make([]*TableInfo, len(tbls))
+	copy(sorted, tbls)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
+
+// DecodeSchemaSnapshot decodes a snapshot encoded by EncodeSchemaSnapshot.
+// It fails if the snapshot is encoded in a newer format version.
+func DecodeSchemaSnapshot(data []byte) (*SchemaSnapshot, error) {
+	s := &SchemaSnapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if s.FormatVersion < SchemaSnapshotVersion1 || s.FormatVersion > CurrentSchemaSnapshotVersion {
+		return nil, errors.Errorf("unsupported schema snapshot format version %d, supported versions are %d to %d",
+			s.FormatVersion, SchemaSnapshotVersion1, CurrentSchemaSnapshotVersion)
+	}
+	for i, info := range s.Databases {
+		if info == nil || info.DB == nil {
+			return nil, errors.Errorf("database %d of the schema snapshot is missing", i)
+		}
+	}
+	return s, nil
+}
+
+// CheckSchemaSnapshotCompatibility checks that a stored snapshot is decoded
+// without losing anything. It decodes the snapshot, encodes it again and
+// compares the two encodings: every value in the stored snapshot must be
+// encoded again at the same path with the same value. The values added by new
+// fields are ignored. The error lists the paths which differ, e.g. a field whose
+// JSON name is changed is reported as missing.
+func CheckSchemaSnapshotCompatibility(stored []byte) error {
+	s, err := DecodeSchemaSnapshot(stored)
+	if err != nil {
+		return err
+	}
+	encoded, err := EncodeSchemaSnapshot(s)
+	if err != nil {
+		return err
+	}
+	before, err := decodeJSONValue(stored)
+	if err != nil {
+		return err
+	}
+	after, err := decodeJSONValue(encoded)
+	if err != nil {
+		return err
+	}
+	var diffs []string
+	diffJSONValues("$", before, after, &diffs)
+	if len(diffs) > 0 {
+		return errors.Errorf("schema snapshot isn't compatible: %s", strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+func decodeJSONValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return v, nil
+}
+
+// diffJSONValues appends the paths where the stored value isn't kept after a
+// round trip.
+func diffJSONValues(path string, stored, encoded interface{}, diffs *[]string) {
+	switch stored := stored.(type) {
+	case map[string]interface{}:
+		m, ok := encoded.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: object is decoded as %s", path, jsonString(encoded)))
+			return
+		}
+		keys := make([]string, 0, len(stored))
+		for key := range stored {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := m[key]
+			if !ok {
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: missing after decoding", path, key))
+				continue
+			}
+			diffJSONValues(path+"."+key, stored[key], value, diffs)
+		}
+	case []interface{}:
+		list, ok := encoded.([]interface{})
+		if !ok || len(list) != len(stored) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %s is decoded as %s", path, jsonString(stored), jsonString(encoded)))
+			return
+		}
+		for i := range stored {
+			diffJSONValues(fmt.Sprintf("%s[%d]", path, i), stored[i], list[i], diffs)
+		}
+	default:
+		if !reflect.DeepEqual(stored, encoded) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %s is decoded as %s", path, jsonString(stored), jsonString(encoded)))
+		}
+	}
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,433 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/auth"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/types"
+)
+
+var _ = Suite(&testSnapshotSuite{})
+
+type testSnapshotSuite struct {
+}
+
+// The snapshots stored by older versions. A new snapshot is added for every
+// new format version, and the stored snapshots are never regenerated: when a
+// field is added to a schema object, only its key is added to them.
+var storedSnapshots = []string{
+	"testdata/schema_snapshot_v1.json",
+}
+
+// newTestSnapshot returns a snapshot with every field of the schema objects set.
+func newTestSnapshot() *SchemaSnapshot {
+	settings := &PlacementSettings{
+		PrimaryRegion:       "r1",
+		Regions:             "r1,r2",
+		Learners:            1,
+		Followers:           2,
+		Voters:              3,
+		Schedule:            "EVEN",
+		Constraints:         "[+disk=ssd]",
+		LeaderConstraints:   "[+zone=z1]",
+		LearnerConstraints:  "[+zone=z2]",
+		FollowerConstraints: "[+zone=z3]",
+		VoterConstraints:    "[+zone=z4]",
+	}
+	policyRef := &PolicyRefInfo{ID: 1, Name: NewCIStr("p1")}
+	policy := &PolicyInfo{PlacementSettings: settings, ID: 1, Name: NewCIStr("p1"), State: StatePublic}
+
+	tbl := &TableInfo{
+		ID:      10,
+		Name:    NewCIStr("T"),
+		Charset: "utf8mb4",
+		Collate: "utf8mb4_bin",
+		Columns: []*ColumnInfo{{
+			ID:                    1,
+			Name:                  NewCIStr("A"),
+			Offset:                0,
+			OriginDefaultValue:    "1",
+			OriginDefaultValueBit: []byte("b"),
+			DefaultValue:          "2",
+			DefaultValueBit:       []byte("c"),
+			DefaultIsExpr:         true,
+			GeneratedExprString:   "`b` + 1",
+			GeneratedStored:       true,
+			Dependences:           map[string]struct{}{"b": {}},
+			FieldType: types.FieldType{
+				Tp:      mysql.TypeEnum,
+				Flag:    mysql.NotNullFlag,
+				Flen:    1,
+				Decimal: 2,
+				Charset: "utf8mb4",
+				Collate: "utf8mb4_bin",
+				Elems:   []string{"x", "y"},
+			},
+			State:           StatePublic,
+			Comment:         "a",
+			Hidden:          true,
+			ChangeStateInfo: &ChangeStateInfo{DependencyColumnOffset: 1},
+			Version:         ColumnInfoVersion2,
+		}},
+		Indices: []*IndexInfo{{
+			ID:        1,
+			Name:      NewCIStr("I"),
+			Table:     NewCIStr("T"),
+			Columns:   []*IndexColumn{{Name: NewCIStr("A"), Offset: 0, Length: 4}},
+			State:     StatePublic,
+			Comment:   "i",
+			Tp:        IndexTypeHash,
+			Unique:    true,
+			Primary:   true,
+			Invisible: true,
+			Global:    true,
+		}},
+		Constraints: []*ConstraintInfo{{
+			ID:             1,
+			Name:           NewCIStr("C"),
+			Table:          NewCIStr("T"),
+			ConstraintCols: []CIStr{NewCIStr("A")},
+			Enforced:       true,
+			InColumn:       true,
+			ExprString:     "`a` > 0",
+			State:          StatePublic,
+		}},
+		ForeignKeys: []*FKInfo{{
+			ID:       1,
+			Name:     NewCIStr("FK"),
+			RefTable: NewCIStr("T2"),
+			RefCols:  []CIStr{NewCIStr("X")},
+			Cols:     []CIStr{NewCIStr("A")},
+			OnDelete: 1,
+			OnUpdate: 2,
+			State:    StatePublic,
+		}},
+		State:               StatePublic,
+		PKIsHandle:          true,
+		IsCommonHandle:      true,
+		CommonHandleVersion: 1,
+		Comment:             "t",
+		AutoIncID:           100,
+		AutoIdCache:         10,
+		AutoRandID:          5,
+		MaxColumnID:         1,
+		MaxIndexID:          1,
+		MaxForeignKeyID:     1,
+		MaxConstraintID:     1,
+		UpdateTS:            1234,
+		OldSchemaID:         2,
+		ShardRowIDBits:      4,
+		MaxShardRowIDBits:   5,
+		AutoRandomBits:      6,
+		PreSplitRegions:     2,
+		Partition: &PartitionInfo{
+			Type:    PartitionTypeRange,
+			Expr:    "`a`",
+			Columns: []CIStr{NewCIStr("A")},
+			Enable:  true,
+			Definitions: []PartitionDefinition{{
+				ID:                  11,
+				Name:                NewCIStr("P0"),
+				LessThan:            []string{"10"},
+				InValues:            [][]string{{"1", "2"}},
+				PlacementPolicyRef:  policyRef,
+				DirectPlacementOpts: settings,
+				Comment:             "p0",
+			}},
+			AddingDefinitions:   []PartitionDefinition{{ID: 12, Name: NewCIStr("P1"), LessThan: []string{"20"}}},
+			DroppingDefinitions: []PartitionDefinition{{ID: 13, Name: NewCIStr("P2"), LessThan: []string{"30"}}},
+			States:              []PartitionState{{ID: 11, State: StatePublic}},
+			Num:                 1,
+		},
+		Compression: "zlib",
+		Lock: &TableLockInfo{
+			Tp:       TableLockWrite,
+			Sessions: []SessionInfo{{ServerID: "s1", SessionID: 7}},
+			State:    TableLockStatePublic,
+			TS:       99,
+		},
+		Version: TableInfoVersion4,
+		TiFlashReplica: &TiFlashReplicaInfo{
+			Count:                 2,
+			LocationLabels:        []string{"zone"},
+			Available:             true,
+			AvailablePartitionIDs: []int64{11},
+		},
+		IsColumnar:          true,
+		TempTableType:       TempTableGlobal,
+		PlacementPolicyRef:  policyRef,
+		DirectPlacementOpts: settings,
+	}
+	view := &TableInfo{
+		ID:   20,
+		Name: NewCIStr("V"),
+		View: &ViewInfo{
+			Algorithm: AlgorithmMerge,
+			Definer: &auth.UserIdentity{
+				Username:     "u",
+				Hostname:     "%",
+				CurrentUser:  true,
+				AuthUsername: "u",
+				AuthHostname: "%",
+			},
+			Security:    SecurityInvoker,
+			SelectStmt:  "SELECT 1",
+			CheckOption: CheckOptionCascaded,
+			Cols:        []CIStr{NewCIStr("X")},
+		},
+		State: StatePublic,
+	}
+	seq := &TableInfo{
+		ID:   30,
+		Name: NewCIStr("S"),
+		Sequence: &SequenceInfo{
+			Start:      1,
+			Cache:      true,
+			Cycle:      true,
+			MinValue:   1,
+			MaxValue:   100,
+			Increment:  2,
+			CacheValue: 10,
+			Comment:    "s",
+		},
+		State: StatePublic,
+	}
+	db := &DBInfo{
+		ID:                  1,
+		Name:                NewCIStr("D"),
+		Charset:             "utf8mb4",
+		Collate:             "utf8mb4_bin",
+		Tables:              []*TableInfo{seq, view, tbl},
+		State:               StatePublic,
+		PlacementPolicyRef:  policyRef,
+		DirectPlacementOpts: settings,
+	}
+	group := &ResourceGroupInfo{
+		ResourceGroupSettings: &ResourceGroupSettings{
+			Type:           ResourceGroupTypeUser,
+			VCPUs:          []VCPURange{{Start: 0, End: 3}},
+			ThreadPriority: 5,
+			Enabled:        true,
+		},
+		ID:    2,
+		Name:  NewCIStr("rg1"),
+		State: StatePublic,
+	}
+	return NewSchemaSnapshot(42, []*DBInfo{db}, []*PolicyInfo{policy}, []*ResourceGroupInfo{group})
+}
+
+func (*testSnapshotSuite) TestEncodeDecode(c *C) {
+	s := newTestSnapshot()
+	db := s.Databases[0]
+	c.Assert(db.Tables, HasLen, 2)
+	c.Assert(db.Sequences, HasLen, 1)
+	c.Assert(db.Sequences[0].Name.O, Equals, "S")
+
+	data, err := EncodeSchemaSnapshot(s)
+	c.Assert(err, IsNil)
+	// The tables are sorted in the encoding but not in the snapshot.
+	c.Assert(db.Tables[0].Name.O, Equals, "V")
+	c.Assert(strings.Index(string(data), `"name":{"O":"T"`) < strings.Index(string(data), `"name":{"O":"V"`), IsTrue)
+
+	// The encoding is deterministic.
+	s.Databases[0].Tables[0], s.Databases[0].Tables[1] = s.Databases[0].Tables[1], s.Databases[0].Tables[0]
+	again, err := EncodeSchemaSnapshot(s)
+	c.Assert(err, IsNil)
+	c.Assert(string(again), Equals, string(data))
+
+	decoded, err := DecodeSchemaSnapshot(data)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.FormatVersion, Equals, CurrentSchemaSnapshotVersion)
+	c.Assert(decoded.SchemaVersion, Equals, int64(42))
+	c.Assert(decoded.Policies[0].PlacementSettings.Schedule, Equals, "EVEN")
+	c.Assert(decoded.ResourceGroups, HasLen, 1)
+	c.Assert(decoded.ResourceGroups[0].Name.O, Equals, "rg1")
+	c.Assert(decoded.ResourceGroups[0].ResourceGroupSettings.String(), Equals, "TYPE=USER VCPU=0-3 THREAD_PRIORITY=5 ENABLE")
+	dbs := decoded.DBInfos()
+	c.Assert(dbs, HasLen, 1)
+	c.Assert(dbs[0].Tables, HasLen, 3)
+	tbl := dbs[0].Tables[0]
+	c.Assert(tbl.Name.L, Equals, "t")
+	c.Assert(tbl.ShardRowIDBits, Equals, uint64(4))
+	c.Assert(tbl.Lock.Sessions, DeepEquals, []SessionInfo{{ServerID: "s1", SessionID: 7}})
+	c.Assert(dbs[0].Tables[2].IsSequence(), IsTrue)
+	c.Assert(CheckSchemaSnapshotCompatibility(data), IsNil)
+
+	_, err = DecodeSchemaSnapshot([]byte(`{"format_version":2,"databases":[]}`))
+	c.Assert(err, ErrorMatches, "unsupported schema snapshot format version 2, supported versions are 1 to 1")
+	_, err = DecodeSchemaSnapshot([]byte(`{"databases":[]}`))
+	c.Assert(err, ErrorMatches, "unsupported schema snapshot format version 0.*")
+	_, err = DecodeSchemaSnapshot([]byte(`{"format_version":1,"databases":[{"tables":[]}]}`))
+	c.Assert(err, ErrorMatches, "database 0 of the schema snapshot is missing")
+}
+
+func (*testSnapshotSuite) TestCheckCompatibility(c *C) {
+	for _, file := range storedSnapshots {
+		data, err := ioutil.ReadFile(file)
+		c.Assert(err, IsNil)
+		c.Assert(CheckSchemaSnapshotCompatibility(data), IsNil, Commentf("%s", file))
+	}
+
+	// A renamed key is lost when the snapshot is decoded.
+	data := []byte(`{"format_version":1,"schema_version":1,"policies":null,"databases":[{"db":{"id":1,"database_name":"d"},"tables":[],"sequences":null}]}`)
+	err := CheckSchemaSnapshotCompatibility(data)
+	c.Assert(err, ErrorMatches, `schema snapshot isn't compatible: \$\.databases\[0\]\.db\.database_name: missing after decoding`)
+	// A value which is decoded into another value.
+	data = []byte(`{"format_version":1,"schema_version":1,"policies":[{"id":1,"name":"p1"}],"databases":[]}`)
+	err = CheckSchemaSnapshotCompatibility(data)
+	c.Assert(err, ErrorMatches, `.*\$\.policies\[0\]\.name: "p1" is decoded as \{"L":"p1","O":"p1"\}`)
+}
+
+// untaggedFields are the fields encoded with their Go names, which can't be
+// renamed. New fields of the schema objects must have JSON tags.
+var untaggedFields = map[string]bool{
+	"TableInfo.ShardRowIDBits":                 true,
+	"TableLockInfo.Tp":                         true,
+	"TableLockInfo.Sessions":                   true,
+	"TableLockInfo.State":                      true,
+	"TableLockInfo.TS":                         true,
+	"SessionInfo.ServerID":                     true,
+	"SessionInfo.SessionID":                    true,
+	"TiFlashReplicaInfo.Count":                 true,
+	"TiFlashReplicaInfo.LocationLabels":        true,
+	"TiFlashReplicaInfo.Available":             true,
+	"TiFlashReplicaInfo.AvailablePartitionIDs": true,
+	"FieldType.Tp":                             true,
+	"FieldType.Flag":                           true,
+	"FieldType.Flen":                           true,
+	"FieldType.Decimal":                        true,
+	"FieldType.Charset":                        true,
+	"FieldType.Collate":                        true,
+	"FieldType.Elems":                          true,
+	"UserIdentity.Username":                    true,
+	"UserIdentity.Hostname":                    true,
+	"UserIdentity.CurrentUser":                 true,
+	"UserIdentity.AuthUsername":                true,
+	"UserIdentity.AuthHostname":                true,
+}
+
+// jsonKeys returns the JSON keys of the fields of a struct, with the types of
+// the fields. The fields of the untagged embedded structs are flattened.
+func jsonKeys(c *C, tp reflect.Type) map[string]reflect.Type {
+	keys := make(map[string]reflect.Type)
+	for i := 0; i < tp.NumField(); i++ {
+		f := tp.Field(i)
+		tag, hasTag := f.Tag.Lookup("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !hasTag && ft.Kind() == reflect.Struct {
+			for key, t := range jsonKeys(c, ft) {
+				keys[key] = t
+			}
+			continue
+		}
+		if !hasTag {
+			c.Assert(untaggedFields[tp.Name()+"."+f.Name], IsTrue, Commentf("%s.%s has no JSON tag", tp.Name(), f.Name))
+		}
+		if name == "" {
+			name = f.Name
+		}
+		keys[name] = f.Type
+	}
+	return keys
+}
+
+// coverKeys records the keys set in a JSON value for every struct type.
+func coverKeys(c *C, tp reflect.Type, v interface{}, covered map[reflect.Type]map[string]bool) {
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	if v == nil {
+		return
+	}
+	switch tp.Kind() {
+	case reflect.Slice, reflect.Array:
+		if tp.Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		list, ok := v.([]interface{})
+		c.Assert(ok, IsTrue, Commentf("%s is %#v", tp, v))
+		for _, elem := range list {
+			coverKeys(c, tp.Elem(), elem, covered)
+		}
+	case reflect.Struct:
+		if tp == reflect.TypeOf(CIStr{}) {
+			return
+		}
+		m, ok := v.(map[string]interface{})
+		c.Assert(ok, IsTrue, Commentf("%s is %#v", tp, v))
+		if covered[tp] == nil {
+			covered[tp] = make(map[string]bool)
+		}
+		for key, ft := range jsonKeys(c, tp) {
+			if value, ok := m[key]; ok {
+				covered[tp][key] = true
+				coverKeys(c, ft, value, covered)
+			}
+		}
+	}
+}
+
+// structTypes returns the struct types reachable from a type.
+func structTypes(c *C, tp reflect.Type, types map[reflect.Type]bool) {
+	for tp.Kind() == reflect.Ptr || tp.Kind() == reflect.Slice || tp.Kind() == reflect.Array {
+		tp = tp.Elem()
+	}
+	if tp.Kind() != reflect.Struct || tp == reflect.TypeOf(CIStr{}) || types[tp] {
+		return
+	}
+	types[tp] = true
+	for _, ft := range jsonKeys(c, tp) {
+		structTypes(c, ft, types)
+	}
+}
+
+// TestStoredSnapshotsCoverage checks that the latest stored snapshot sets every
+// field of the schema objects, so that renaming any field is detected.
+func (*testSnapshotSuite) TestStoredSnapshotsCoverage(c *C) {
+	data, err := ioutil.ReadFile(storedSnapshots[len(storedSnapshots)-1])
+	c.Assert(err, IsNil)
+	var v interface{}
+	c.Assert(json.Unmarshal(data, &v), IsNil)
+	covered := make(map[reflect.Type]map[string]bool)
+	tp := reflect.TypeOf(SchemaSnapshot{})
+	coverKeys(c, tp, v, covered)
+
+	all := make(map[reflect.Type]bool)
+	structTypes(c, tp, all)
+	var missing []string
+	for st := range all {
+		for key := range jsonKeys(c, st) {
+			if !covered[st][key] {
+				missing = append(missing, st.Name()+"."+key)
+			}
+		}
+	}
+	sort.Strings(missing)
+	c.Assert(missing, HasLen, 0, Commentf("the stored snapshot doesn't set %v", missing))
+}
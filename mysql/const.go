@@ -167,6 +167,8 @@ const (
 	AuthNativePassword      = "mysql_native_password"
 	AuthCachingSha2Password = "caching_sha2_password"
 	AuthSocket              = "auth_socket"
+	AuthSHA256Password      = "sha256_password"
+	AuthClearPassword       = "mysql_clear_password"
 )
 
 // MySQL database and tables.
@@ -386,6 +388,23 @@ func (m SQLMode) HasAllowInvalidDatesMode() bool {
 	return m&ModeAllowInvalidDates == ModeAllowInvalidDates
 }
 
+// HasMaxDBMode detects if 'MAXDB' mode is set in SQLMode
+func (m SQLMode) HasMaxDBMode() bool {
+	return m&ModeMaxdb == ModeMaxdb
+}
+
+// ExpandCombinationModes returns the SQLMode with the modes which are implied
+// by its combination modes, like MySQL does when sql_mode is set. For example,
+// 'ANSI' implies 'ANSI_QUOTES' and 'PIPES_AS_CONCAT'.
+func (m SQLMode) ExpandCombinationModes() SQLMode {
+	for mode, implied := range combinationSQLModes {
+		if m&mode == mode {
+			m |= implied
+		}
+	}
+	return m
+}
+
 // consts for sql modes.
 // see https://dev.mysql.com/doc/internals/en/query-event.html#q-sql-mode-code
 const (
@@ -463,7 +482,7 @@ func GetSQLMode(s string) (SQLMode, error) {
 		}
 		sqlMode = sqlMode | mode
 	}
-	return sqlMode, nil
+	return sqlMode.ExpandCombinationModes(), nil
 }
 
 // Str2SQLMode is the string represent of sql_mode to sql_mode map.
@@ -517,6 +536,19 @@ var CombinationSQLMode = map[string][]string{
 	"TRADITIONAL": {"STRICT_TRANS_TABLES", "STRICT_ALL_TABLES", "NO_ZERO_IN_DATE", "NO_ZERO_DATE", "ERROR_FOR_DIVISION_BY_ZERO", "NO_AUTO_CREATE_USER", "NO_ENGINE_SUBSTITUTION"},
 }
 
+// combinationSQLModes maps the combination modes to the modes they imply.
+var combinationSQLModes = func() map[SQLMode]SQLMode {
+	modes := make(map[SQLMode]SQLMode, len(CombinationSQLMode))
+	for name, parts := range CombinationSQLMode {
+		var implied SQLMode
+		for _, part := range parts {
+			implied |= Str2SQLMode[part]
+		}
+		modes[Str2SQLMode[name]] = implied
+	}
+	return modes
+}()
+
 // FormatFunc is the locale format function signature.
 type FormatFunc func(string, string) (string, error)
 
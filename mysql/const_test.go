@@ -102,3 +102,23 @@ func (s *testConstSuite) TestSQLMode(c *C) {
 		c.Assert(int(ca.code), Equals, ca.value)
 	}
 }
+
+func (s *testConstSuite) TestExpandCombinationModes(c *C) {
+	c.Assert(ModeANSI.ExpandCombinationModes(), Equals, ModeANSI|ModeRealAsFloat|ModePipesAsConcat|ModeANSIQuotes|ModeIgnoreSpace|ModeOnlyFullGroupBy)
+	c.Assert(ModeMySQL40.ExpandCombinationModes(), Equals, ModeMySQL40|ModeHighNotPrecedence)
+	c.Assert(ModeMaxdb.ExpandCombinationModes().HasNoAutoCreateUserMode(), IsTrue)
+	c.Assert(ModeStrictAllTables.ExpandCombinationModes(), Equals, ModeStrictAllTables)
+	c.Assert(SQLMode(ModeNone).ExpandCombinationModes(), Equals, SQLMode(ModeNone))
+
+	// The combination modes imply the same modes as CombinationSQLMode.
+	for name, parts := range CombinationSQLMode {
+		mode, err := GetSQLMode(name)
+		c.Assert(err, IsNil)
+		formatted, err := GetSQLMode(FormatSQLModeStr(name))
+		c.Assert(err, IsNil)
+		c.Assert(mode, Equals, formatted, Commentf("%s", name))
+		for _, part := range parts {
+			c.Assert(mode&Str2SQLMode[part], Equals, Str2SQLMode[part], Commentf("%s: %s", name, part))
+		}
+	}
+}
@@ -894,6 +894,9 @@ const (
 	ErrGeneratedColumnNonPrior                               = 3107
 	ErrDependentByGeneratedColumn                            = 3108
 	ErrGeneratedColumnRefAutoInc                             = 3109
+	ErrWarnConflictingHint                                   = 3126
+	ErrWarnUnknownQbName                                     = 3127
+	ErrUnresolvedHintName                                    = 3128
 	ErrInvalidJSONText                                       = 3140
 	ErrInvalidJSONPath                                       = 3143
 	ErrInvalidTypeForJSON                                    = 3146
@@ -928,6 +931,9 @@ const (
 	ErrWindowNoGroupOrderUnused                              = 3597
 	ErrWindowExplainJson                                     = 3598
 	ErrWindowFunctionIgnoresFrame                            = 3599
+	ErrInvalidVCPURange                                      = 3653
+	ErrInvalidThreadPriority                                 = 3654
+	ErrInvalidUseOfForceOption                               = 3662
 	ErrDataTruncatedFunctionalIndex                          = 3751
 	ErrDataOutOfRangeFunctionalIndex                         = 3752
 	ErrFunctionalIndexOnJsonOrGeometryFunction               = 3753
@@ -942,6 +948,7 @@ const (
 	ErrFunctionalIndexOnField                                = 3762
 	ErrFKIncompatibleColumns                                 = 3780
 	ErrFunctionalIndexRowValueIsNotAllowed                   = 3800
+	ErrCheckConstraintDupName                                = 3822
 	ErrDependentByFunctionalIndex                            = 3837
 	ErrInvalidJsonValueForFuncIndex                          = 3903
 	ErrJsonValueOutOfRangeForFuncIndex                       = 3904
@@ -897,6 +897,9 @@ var MySQLErrName = map[uint16]*ErrMessage{
 	ErrGeneratedColumnNonPrior:                               Message("Generated column can refer only to generated columns defined prior to it.", nil),
 	ErrDependentByGeneratedColumn:                            Message("Column '%s' has a generated column dependency.", nil),
 	ErrGeneratedColumnRefAutoInc:                             Message("Generated column '%s' cannot refer to auto-increment column.", nil),
+	ErrWarnConflictingHint:                                   Message("Hint %s is ignored as conflicting/duplicated", nil),
+	ErrWarnUnknownQbName:                                     Message("Query block name %s is not found for %s hint", nil),
+	ErrUnresolvedHintName:                                    Message("Unresolved name %s for %s hint", nil),
 	ErrInvalidFieldSize:                                      Message("Invalid size for column '%s'.", nil),
 	ErrIncorrectType:                                         Message("Incorrect type for argument %s in function %s.", nil),
 	ErrInvalidJSONData:                                       Message("Invalid JSON data provided to function %s: %s", nil),
@@ -932,6 +935,9 @@ var MySQLErrName = map[uint16]*ErrMessage{
 	ErrWindowNoGroupOrderUnused:                              Message("ASC or DESC with GROUP BY isn't allowed with window functions; put ASC or DESC in ORDER BY", nil),
 	ErrWindowExplainJson:                                     Message("To get information about window functions use EXPLAIN FORMAT=JSON", nil),
 	ErrWindowFunctionIgnoresFrame:                            Message("Window function '%s' ignores the frame clause of window '%s' and aggregates over the whole partition", nil),
+	ErrInvalidVCPURange:                                      Message("Invalid VCPU range %d-%d.", nil),
+	ErrInvalidThreadPriority:                                 Message("Invalid thread priority value %d for %s resource group %s. Allowed range is [%d, %d].", nil),
+	ErrInvalidUseOfForceOption:                               Message("Option FORCE invalid as DISABLE option is not specified.", nil),
 	ErrRoleNotGranted:                                        Message("%s is is not granted to %s", nil),
 	ErrMaxExecTimeExceeded:                                   Message("Query execution was interrupted, max_execution_time exceeded.", nil),
 	ErrLockAcquireFailAndNoWaitSet:                           Message("Statement aborted because lock(s) could not be acquired immediately and NOWAIT is set.", nil),
@@ -949,6 +955,7 @@ var MySQLErrName = map[uint16]*ErrMessage{
 	ErrFunctionalIndexOnField:                                Message("Functional index on a column is not supported. Consider using a regular index instead", nil),
 	ErrFKIncompatibleColumns:                                 Message("Referencing column '%s' in foreign key constraint '%s' are incompatible", nil),
 	ErrFunctionalIndexRowValueIsNotAllowed:                   Message("Expression of functional index '%s' cannot refer to a row value", nil),
+	ErrCheckConstraintDupName:                                Message("Duplicate check constraint name '%-.192s'.", nil),
 	ErrDependentByFunctionalIndex:                            Message("Column '%s' has a functional index dependency and cannot be dropped or renamed", nil),
 	ErrInvalidJsonValueForFuncIndex:                          Message("Invalid JSON value for CAST for functional index '%s'", nil),
 	ErrJsonValueOutOfRangeForFuncIndex:                       Message("Out of range JSON value for CAST for functional index '%s'", nil),
@@ -39,13 +39,7 @@ func (e *SQLError) Error() string {
 
 // NewErr generates a SQL error, with an error code and default format specifier defined in MySQLErrName.
 func NewErr(errCode uint16, args ...interface{}) *SQLError {
-	e := &SQLError{Code: errCode}
-
-	if s, ok := MySQLState[errCode]; ok {
-		e.State = s
-	} else {
-		e.State = DefaultMySQLState
-	}
+	e := &SQLError{Code: errCode, State: SQLState(errCode)}
 
 	if sqlErr, ok := MySQLErrName[errCode]; ok {
 		errors.RedactErrorArg(args, sqlErr.RedactArgPos)
@@ -59,13 +53,7 @@ func NewErr(errCode uint16, args ...interface{}) *SQLError {
 
 // NewErrf creates a SQL error, with an error code and a format specifier.
 func NewErrf(errCode uint16, format string, redactArgPos []int, args ...interface{}) *SQLError {
-	e := &SQLError{Code: errCode}
-
-	if s, ok := MySQLState[errCode]; ok {
-		e.State = s
-	} else {
-		e.State = DefaultMySQLState
-	}
+	e := &SQLError{Code: errCode, State: SQLState(errCode)}
 
 	errors.RedactErrorArg(args, redactArgPos)
 	e.Message = fmt.Sprintf(format, args...)
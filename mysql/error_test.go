@@ -35,3 +35,21 @@ func (s *testSQLErrorSuite) TestSQLError(c *C) {
 	e = NewErr(0, "customized error", nil)
 	c.Assert(len(e.Error()), Greater, 0)
 }
+
+func (s *testSQLErrorSuite) TestSQLState(c *C) {
+	c.Assert(IsValidSQLState("45000"), IsTrue)
+	c.Assert(IsValidSQLState("HY000"), IsTrue)
+	c.Assert(IsValidSQLState("hy000"), IsFalse)
+	c.Assert(IsValidSQLState("4500"), IsFalse)
+	c.Assert(IsValidSQLState("45-00"), IsFalse)
+	for _, state := range MySQLState {
+		c.Assert(IsValidSQLState(state), IsTrue, Commentf("%s", state))
+	}
+	c.Assert(IsCompletionSQLState("00000"), IsTrue)
+	c.Assert(IsCompletionSQLState("01000"), IsFalse)
+	c.Assert(SignalErrCode("01000"), Equals, uint16(ErrSignalWarn))
+	c.Assert(SignalErrCode("01xyz"), Equals, uint16(ErrSignalWarn))
+	c.Assert(SignalErrCode("02000"), Equals, uint16(ErrSignalNotFound))
+	c.Assert(SignalErrCode("45000"), Equals, uint16(ErrSignalException))
+	c.Assert(SignalErrCode("HY000"), Equals, uint16(ErrSignalException))
+}
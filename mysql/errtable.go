@@ -0,0 +1,75 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// SQLState returns the SQLSTATE of an error code, which is DefaultMySQLState
+// if MySQLState doesn't have the code.
+func SQLState(errCode uint16) string {
+	if s, ok := MySQLState[errCode]; ok {
+		return s
+	}
+	return DefaultMySQLState
+}
+
+// ErrorCodes returns the error codes of MySQLErrName in order.
+func ErrorCodes() []uint16 {
+	codes := make([]uint16, 0, len(MySQLErrName))
+	for code := range MySQLErrName {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// CheckErrorTables checks that MySQLErrName and MySQLState are consistent:
+// every error code has a message, and every SQLSTATE is well-formed and
+// belongs to an error code with a message. It returns an error which lists
+// the problems in the order of the codes.
+func CheckErrorTables() error {
+	type problem struct {
+		code uint16
+		msg  string
+	}
+	var problems []problem
+	for code, msg := range MySQLErrName {
+		if msg == nil || msg.Raw == "" {
+			problems = append(problems, problem{code, fmt.Sprintf("error %d has no message", code)})
+		}
+	}
+	for code, state := range MySQLState {
+		if _, ok := MySQLErrName[code]; !ok {
+			problems = append(problems, problem{code, fmt.Sprintf("error %d has a SQLSTATE but no message", code)})
+		}
+		if !IsValidSQLState(state) {
+			problems = append(problems, problem{code, fmt.Sprintf("error %d has an invalid SQLSTATE '%s'", code, state)})
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].code < problems[j].code })
+	msgs := make([]string, 0, len(problems))
+	for _, p := range problems {
+		msgs = append(msgs, p.msg)
+	}
+	return errors.Errorf("inconsistent error tables:\n%s", strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testErrTableSuite{})
+
+type testErrTableSuite struct{}
+
+func (s *testErrTableSuite) TestCheckErrorTables(c *C) {
+	c.Assert(CheckErrorTables(), IsNil)
+
+	MySQLState[65000] = "HY000"
+	MySQLState[ErrNoDB] = "3D0"
+	defer func() {
+		delete(MySQLState, 65000)
+		MySQLState[ErrNoDB] = "3D000"
+	}()
+	err := CheckErrorTables()
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "inconsistent error tables:\n"+
+		"error 1046 has an invalid SQLSTATE '3D0'\n"+
+		"error 65000 has a SQLSTATE but no message")
+}
+
+func (s *testErrTableSuite) TestSQLStateAndCodes(c *C) {
+	c.Assert(SQLState(ErrDupEntry), Equals, "23000")
+	c.Assert(SQLState(ErrUnknown), Equals, DefaultMySQLState)
+	c.Assert(SQLState(65000), Equals, DefaultMySQLState)
+
+	codes := ErrorCodes()
+	c.Assert(codes, HasLen, len(MySQLErrName))
+	for i := 1; i < len(codes); i++ {
+		c.Assert(codes[i-1] < codes[i], IsTrue)
+	}
+}
+
+// TestErrorCodeNames checks that every constant of errcode.go has a unique
+// code and a message in MySQLErrName.
+func (s *testErrTableSuite) TestErrorCodeNames(c *C) {
+	f, err := parser.ParseFile(token.NewFileSet(), "errcode.go", nil, 0)
+	c.Assert(err, IsNil)
+	// ErrErrorFirst and ErrErrorLast mark the range of the MySQL 5.7 codes.
+	markers := map[string]bool{"ErrErrorFirst": true, "ErrErrorLast": true}
+	names := make(map[uint16]string)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if markers[name.Name] {
+					continue
+				}
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				c.Assert(ok, IsTrue, Commentf("%s", name.Name))
+				code, err := strconv.ParseUint(lit.Value, 10, 16)
+				c.Assert(err, IsNil, Commentf("%s", name.Name))
+				dup, ok := names[uint16(code)]
+				c.Assert(ok, IsFalse, Commentf("%s and %s have the same code %d", dup, name.Name, code))
+				names[uint16(code)] = name.Name
+				_, ok = MySQLErrName[uint16(code)]
+				c.Assert(ok, IsTrue, Commentf("%s has no message", name.Name))
+			}
+		}
+	}
+	c.Assert(names, HasLen, len(MySQLErrName))
+}
@@ -13,6 +13,8 @@
 
 package mysql
 
+import "strings"
+
 const (
 	// DefaultMySQLState is default state of the mySQL
 	DefaultMySQLState = "HY000"
@@ -258,3 +260,36 @@ var MySQLState = map[uint16]string{
 	ErrJSONDocumentNULLKey:                 "22032",
 	ErrInvalidJSONPathArrayCell:            "42000",
 }
+
+// IsValidSQLState reports whether state is a well-formed SQLSTATE value,
+// which consists of five digits or uppercase letters.
+func IsValidSQLState(state string) bool {
+	if len(state) != 5 {
+		return false
+	}
+	for i := 0; i < len(state); i++ {
+		c := state[i]
+		if (c < '0' || c > '9') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCompletionSQLState reports whether state belongs to the successful completion class "00",
+// which can't be used to raise a condition.
+func IsCompletionSQLState(state string) bool {
+	return strings.HasPrefix(state, "00")
+}
+
+// SignalErrCode returns the error code of a condition raised by SIGNAL with the given SQLSTATE
+// when MYSQL_ERRNO isn't set. It depends on the class of state, which is looked up in MySQLState:
+// the warning class of ErrSignalWarn, the not found class of ErrSignalNotFound, or ErrSignalException otherwise.
+func SignalErrCode(state string) uint16 {
+	for _, code := range []uint16{ErrSignalWarn, ErrSignalNotFound} {
+		if strings.HasPrefix(state, MySQLState[code][:2]) {
+			return code
+		}
+	}
+	return ErrSignalException
+}
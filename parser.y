@@ -26,6 +26,8 @@
 package parser
 
 import (
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/parser/mysql"
@@ -50,8 +52,10 @@ import (
 %token	<ident>
 
 	/*yy:token "%c"     */
-	identifier "identifier"
-	asof       "AS OF"
+	identifier           "identifier"
+	asof                 "AS OF"
+	forSystemTime        "FOR SYSTEM_TIME"
+	withSystemVersioning "WITH SYSTEM VERSIONING"
 
 	/*yy:token "_%c"    */
 	underscoreCS "UNDERSCORE_CHARSET"
@@ -297,6 +301,7 @@ import (
 	always                "ALWAYS"
 	any                   "ANY"
 	ascii                 "ASCII"
+	attribute             "ATTRIBUTE"
 	attributes            "ATTRIBUTES"
 	autoIdCache           "AUTO_ID_CACHE"
 	autoIncrement         "AUTO_INCREMENT"
@@ -323,6 +328,7 @@ import (
 	cascaded              "CASCADED"
 	causal                "CAUSAL"
 	chain                 "CHAIN"
+	channel               "CHANNEL"
 	charsetKwd            "CHARSET"
 	checkpoint            "CHECKPOINT"
 	checksum              "CHECKSUM"
@@ -334,6 +340,7 @@ import (
 	collation             "COLLATION"
 	columnFormat          "COLUMN_FORMAT"
 	columns               "COLUMNS"
+	condition             "CONDITION"
 	config                "CONFIG"
 	comment               "COMMENT"
 	commit                "COMMIT"
@@ -362,8 +369,10 @@ import (
 	dateType              "DATE"
 	day                   "DAY"
 	deallocate            "DEALLOCATE"
+	defaultAuth           "DEFAULT_AUTH"
 	definer               "DEFINER"
 	delayKeyWrite         "DELAY_KEY_WRITE"
+	diagnostics           "DIAGNOSTICS"
 	directory             "DIRECTORY"
 	disable               "DISABLE"
 	discard               "DISCARD"
@@ -389,9 +398,11 @@ import (
 	expansion             "EXPANSION"
 	expire                "EXPIRE"
 	extended              "EXTENDED"
+	failedLoginAttempts   "FAILED_LOGIN_ATTEMPTS"
 	faultsSym             "FAULTS"
 	fields                "FIELDS"
 	file                  "FILE"
+	filter                "FILTER"
 	first                 "FIRST"
 	fixed                 "FIXED"
 	flush                 "FLUSH"
@@ -400,6 +411,7 @@ import (
 	full                  "FULL"
 	function              "FUNCTION"
 	general               "GENERAL"
+	get                   "GET"
 	global                "GLOBAL"
 	grants                "GRANTS"
 	hash                  "HASH"
@@ -420,6 +432,7 @@ import (
 	invisible             "INVISIBLE"
 	invoker               "INVOKER"
 	io                    "IO"
+	ioThread              "IO_THREAD"
 	ipc                   "IPC"
 	isolation             "ISOLATION"
 	issuer                "ISSUER"
@@ -474,6 +487,7 @@ import (
 	nulls                 "NULLS"
 	off                   "OFF"
 	offset                "OFFSET"
+	old                   "OLD"
 	onDuplicate           "ON_DUPLICATE"
 	online                "ONLINE"
 	only                  "ONLY"
@@ -486,11 +500,13 @@ import (
 	partitioning          "PARTITIONING"
 	partitions            "PARTITIONS"
 	password              "PASSWORD"
+	passwordLockTime      "PASSWORD_LOCK_TIME"
 	percent               "PERCENT"
 	per_db                "PER_DB"
 	per_table             "PER_TABLE"
 	pipesAsOr
 	plugins               "PLUGINS"
+	pluginDir             "PLUGIN_DIR"
 	policy                "POLICY"
 	preSplitRegions       "PRE_SPLIT_REGIONS"
 	preceding             "PRECEDING"
@@ -507,6 +523,7 @@ import (
 	queries               "QUERIES"
 	query                 "QUERY"
 	quick                 "QUICK"
+	random                "RANDOM"
 	rateLimit             "RATE_LIMIT"
 	rebuild               "REBUILD"
 	recover               "RECOVER"
@@ -520,11 +537,15 @@ import (
 	replicas              "REPLICAS"
 	replication           "REPLICATION"
 	required              "REQUIRED"
+	resignal              "RESIGNAL"
+	resource              "RESOURCE"
 	respect               "RESPECT"
 	restart               "RESTART"
 	restore               "RESTORE"
 	restores              "RESTORES"
 	resume                "RESUME"
+	retain                "RETAIN"
+	reuse                 "REUSE"
 	reverse               "REVERSE"
 	role                  "ROLE"
 	rollback              "ROLLBACK"
@@ -549,6 +570,7 @@ import (
 	share                 "SHARE"
 	shared                "SHARED"
 	shutdown              "SHUTDOWN"
+	signal                "SIGNAL"
 	signed                "SIGNED"
 	simple                "SIMPLE"
 	skip                  "SKIP"
@@ -558,9 +580,11 @@ import (
 	snapshot              "SNAPSHOT"
 	some                  "SOME"
 	source                "SOURCE"
+	sqlstate              "SQLSTATE"
 	sqlBufferResult       "SQL_BUFFER_RESULT"
 	sqlCache              "SQL_CACHE"
 	sqlNoCache            "SQL_NO_CACHE"
+	sqlThread             "SQL_THREAD"
 	sqlTsiDay             "SQL_TSI_DAY"
 	sqlTsiHour            "SQL_TSI_HOUR"
 	sqlTsiMinute          "SQL_TSI_MINUTE"
@@ -569,6 +593,7 @@ import (
 	sqlTsiSecond          "SQL_TSI_SECOND"
 	sqlTsiWeek            "SQL_TSI_WEEK"
 	sqlTsiYear            "SQL_TSI_YEAR"
+	stacked               "STACKED"
 	start                 "START"
 	statsAutoRecalc       "STATS_AUTO_RECALC"
 	statsPersistent       "STATS_PERSISTENT"
@@ -591,6 +616,7 @@ import (
 	temptable             "TEMPTABLE"
 	textType              "TEXT"
 	than                  "THAN"
+	threadPriority        "THREAD_PRIORITY"
 	tikvImporter          "TIKV_IMPORTER"
 	timestampType         "TIMESTAMP"
 	timeType              "TIME"
@@ -605,10 +631,12 @@ import (
 	undefined             "UNDEFINED"
 	unicodeSym            "UNICODE"
 	unknown               "UNKNOWN"
+	until                 "UNTIL"
 	user                  "USER"
 	validation            "VALIDATION"
 	value                 "VALUE"
 	variables             "VARIABLES"
+	vcpu                  "VCPU"
 	view                  "VIEW"
 	visible               "VISIBLE"
 	warnings              "WARNINGS"
@@ -800,6 +828,7 @@ import (
 
 %token not2
 %type	<expr>
+	SignalAllowedExpr      "Value allowed in SIGNAL"
 	Expression             "expression"
 	MaxValueOrExpression   "maxvalue or expression"
 	BoolPri                "boolean primary expression"
@@ -831,100 +860,111 @@ import (
 	ProcedureCall          "Procedure call with Identifier or identifier"
 
 %type	<statement>
-	AdminStmt                  "Check table statement or show ddl statement"
-	AlterDatabaseStmt          "Alter database statement"
-	AlterTableStmt             "Alter table statement"
-	AlterUserStmt              "Alter user statement"
-	AlterImportStmt            "ALTER IMPORT statement"
-	AlterInstanceStmt          "Alter instance statement"
-	AlterPolicyStmt            "Alter Placement Policy statement"
-	AlterSequenceStmt          "Alter sequence statement"
-	AnalyzeTableStmt           "Analyze table statement"
-	BeginTransactionStmt       "BEGIN TRANSACTION statement"
-	BinlogStmt                 "Binlog base64 statement"
-	BRIEStmt                   "BACKUP or RESTORE statement"
-	CommitStmt                 "COMMIT statement"
-	CreateTableStmt            "CREATE TABLE statement"
-	CreateViewStmt             "CREATE VIEW  statement"
-	CreateUserStmt             "CREATE User statement"
-	CreateRoleStmt             "CREATE Role statement"
-	CreateDatabaseStmt         "Create Database Statement"
-	CreateIndexStmt            "CREATE INDEX statement"
-	CreateImportStmt           "CREATE IMPORT statement"
-	CreateBindingStmt          "CREATE BINDING  statement"
-	CreatePolicyStmt           "CREATE PLACEMENT POLICY statement"
-	CreateSequenceStmt         "CREATE SEQUENCE statement"
-	CreateStatisticsStmt       "CREATE STATISTICS statement"
-	DoStmt                     "Do statement"
-	DropDatabaseStmt           "DROP DATABASE statement"
-	DropImportStmt             "DROP IMPORT statement"
-	DropIndexStmt              "DROP INDEX statement"
-	DropStatisticsStmt         "DROP STATISTICS statement"
-	DropStatsStmt              "DROP STATS statement"
-	DropTableStmt              "DROP TABLE statement"
-	DropSequenceStmt           "DROP SEQUENCE statement"
-	DropUserStmt               "DROP USER"
-	DropRoleStmt               "DROP ROLE"
-	DropViewStmt               "DROP VIEW statement"
-	DropBindingStmt            "DROP BINDING  statement"
-	DropPolicyStmt             "DROP PLACEMENT POLICY statement"
-	DeallocateStmt             "Deallocate prepared statement"
-	DeleteFromStmt             "DELETE FROM statement"
-	DeleteWithoutUsingStmt     "Normal DELETE statement"
-	DeleteWithUsingStmt        "DELETE USING statement"
-	EmptyStmt                  "empty statement"
-	ExecuteStmt                "Execute statement"
-	ExplainStmt                "EXPLAIN statement"
-	ExplainableStmt            "explainable statement"
-	FlushStmt                  "Flush statement"
-	FlashbackTableStmt         "Flashback table statement"
-	GrantStmt                  "Grant statement"
-	GrantProxyStmt             "Grant proxy statement"
-	GrantRoleStmt              "Grant role statement"
-	InsertIntoStmt             "INSERT INTO statement"
-	CallStmt                   "CALL statement"
-	IndexAdviseStmt            "INDEX ADVISE statement"
-	KillStmt                   "Kill statement"
-	LoadDataStmt               "Load data statement"
-	LoadStatsStmt              "Load statistic statement"
-	LockTablesStmt             "Lock tables statement"
-	PlanRecreatorStmt          "Plan recreator statement"
-	PreparedStmt               "PreparedStmt"
-	PurgeImportStmt            "PURGE IMPORT statement that removes a IMPORT task record"
-	SelectStmt                 "SELECT statement"
-	SelectStmtWithClause       "common table expression SELECT statement"
-	RenameTableStmt            "rename table statement"
-	RenameUserStmt             "rename user statement"
-	ReplaceIntoStmt            "REPLACE INTO statement"
-	RecoverTableStmt           "recover table statement"
-	ResumeImportStmt           "RESUME IMPORT statement"
-	RevokeStmt                 "Revoke statement"
-	RevokeRoleStmt             "Revoke role statement"
-	RollbackStmt               "ROLLBACK statement"
-	SplitRegionStmt            "Split index region statement"
-	SetStmt                    "Set variable statement"
-	ChangeStmt                 "Change statement"
-	SetRoleStmt                "Set active role statement"
-	SetDefaultRoleStmt         "Set default statement for some user"
-	ShowImportStmt             "SHOW IMPORT statement"
-	ShowStmt                   "Show engines/databases/tables/user/columns/warnings/status statement"
-	Statement                  "statement"
-	StopImportStmt             "STOP IMPORT statement"
-	TraceStmt                  "TRACE statement"
-	TraceableStmt              "traceable statement"
-	TruncateTableStmt          "TRUNCATE TABLE statement"
-	UnlockTablesStmt           "Unlock tables statement"
-	UpdateStmt                 "UPDATE statement"
-	SetOprStmt                 "Union/Except/Intersect select statement"
-	SetOprStmtWithLimitOrderBy "Union/Except/Intersect select statement with limit and order by"
-	SetOprStmtWoutLimitOrderBy "Union/Except/Intersect select statement without limit and order by"
-	UseStmt                    "USE statement"
-	ShutdownStmt               "SHUTDOWN statement"
-	RestartStmt                "RESTART statement"
-	CreateViewSelectOpt        "Select/Union/Except/Intersect statement in CREATE VIEW ... AS SELECT"
-	BindableStmt               "Statement that can be created binding on"
-	UpdateStmtNoWith           "Update statement without CTE clause"
-	HelpStmt                   "HELP statement"
+	AdminStmt                   "Check table statement or show ddl statement"
+	AlterDatabaseStmt           "Alter database statement"
+	AlterTableStmt              "Alter table statement"
+	AlterUserStmt               "Alter user statement"
+	AlterImportStmt             "ALTER IMPORT statement"
+	AlterInstanceStmt           "Alter instance statement"
+	AlterPolicyStmt             "Alter Placement Policy statement"
+	AlterResourceGroupStmt      "ALTER RESOURCE GROUP statement"
+	AlterSequenceStmt           "Alter sequence statement"
+	AnalyzeTableStmt            "Analyze table statement"
+	BeginTransactionStmt        "BEGIN TRANSACTION statement"
+	BinlogStmt                  "Binlog base64 statement"
+	BRIEStmt                    "BACKUP or RESTORE statement"
+	CommitStmt                  "COMMIT statement"
+	CreateTableStmt             "CREATE TABLE statement"
+	CreateViewStmt              "CREATE VIEW  statement"
+	CreateUserStmt              "CREATE User statement"
+	CreateRoleStmt              "CREATE Role statement"
+	CreateDatabaseStmt          "Create Database Statement"
+	CreateIndexStmt             "CREATE INDEX statement"
+	CreateImportStmt            "CREATE IMPORT statement"
+	CreateBindingStmt           "CREATE BINDING  statement"
+	CreatePolicyStmt            "CREATE PLACEMENT POLICY statement"
+	CreateResourceGroupStmt     "CREATE RESOURCE GROUP statement"
+	CreateSequenceStmt          "CREATE SEQUENCE statement"
+	CreateStatisticsStmt        "CREATE STATISTICS statement"
+	DoStmt                      "Do statement"
+	DropDatabaseStmt            "DROP DATABASE statement"
+	DropImportStmt              "DROP IMPORT statement"
+	DropIndexStmt               "DROP INDEX statement"
+	DropStatisticsStmt          "DROP STATISTICS statement"
+	DropStatsStmt               "DROP STATS statement"
+	DropTableStmt               "DROP TABLE statement"
+	DropSequenceStmt            "DROP SEQUENCE statement"
+	DropUserStmt                "DROP USER"
+	DropRoleStmt                "DROP ROLE"
+	DropViewStmt                "DROP VIEW statement"
+	DropBindingStmt             "DROP BINDING  statement"
+	DropPolicyStmt              "DROP PLACEMENT POLICY statement"
+	DropResourceGroupStmt       "DROP RESOURCE GROUP statement"
+	DeallocateStmt              "Deallocate prepared statement"
+	DeleteFromStmt              "DELETE FROM statement"
+	DeleteWithoutUsingStmt      "Normal DELETE statement"
+	DeleteWithUsingStmt         "DELETE USING statement"
+	EmptyStmt                   "empty statement"
+	ExecuteStmt                 "Execute statement"
+	ExplainStmt                 "EXPLAIN statement"
+	ExplainableStmt             "explainable statement"
+	FlushStmt                   "Flush statement"
+	FlashbackTableStmt          "Flashback table statement"
+	GetDiagnosticsStmt          "GET DIAGNOSTICS statement"
+	GrantStmt                   "Grant statement"
+	GrantProxyStmt              "Grant proxy statement"
+	GrantRoleStmt               "Grant role statement"
+	InsertIntoStmt              "INSERT INTO statement"
+	CallStmt                    "CALL statement"
+	IndexAdviseStmt             "INDEX ADVISE statement"
+	KillStmt                    "Kill statement"
+	LoadDataStmt                "Load data statement"
+	LoadStatsStmt               "Load statistic statement"
+	LockTablesStmt              "Lock tables statement"
+	PlanRecreatorStmt           "Plan recreator statement"
+	PreparedStmt                "PreparedStmt"
+	PurgeImportStmt             "PURGE IMPORT statement that removes a IMPORT task record"
+	SelectStmt                  "SELECT statement"
+	SelectStmtWithClause        "common table expression SELECT statement"
+	RenameTableStmt             "rename table statement"
+	RenameUserStmt              "rename user statement"
+	ReplaceIntoStmt             "REPLACE INTO statement"
+	RecoverTableStmt            "recover table statement"
+	ResumeImportStmt            "RESUME IMPORT statement"
+	RevokeStmt                  "Revoke statement"
+	RevokeRoleStmt              "Revoke role statement"
+	RollbackStmt                "ROLLBACK statement"
+	SplitRegionStmt             "Split index region statement"
+	SetStmt                     "Set variable statement"
+	ChangeStmt                  "Change statement"
+	ChangeReplicationFilterStmt "CHANGE REPLICATION FILTER statement"
+	ChangeReplicationSourceStmt "CHANGE REPLICATION SOURCE statement"
+	SetRoleStmt                 "Set active role statement"
+	SetDefaultRoleStmt          "Set default statement for some user"
+	ShowImportStmt              "SHOW IMPORT statement"
+	ShowStmt                    "Show engines/databases/tables/user/columns/warnings/status statement"
+	Statement                   "statement"
+	StopImportStmt              "STOP IMPORT statement"
+	TraceStmt                   "TRACE statement"
+	TraceableStmt               "traceable statement"
+	TruncateTableStmt           "TRUNCATE TABLE statement"
+	UnlockTablesStmt            "Unlock tables statement"
+	UpdateStmt                  "UPDATE statement"
+	SetOprStmt                  "Union/Except/Intersect select statement"
+	SetOprStmtWithLimitOrderBy  "Union/Except/Intersect select statement with limit and order by"
+	SetOprStmtWoutLimitOrderBy  "Union/Except/Intersect select statement without limit and order by"
+	UseStmt                     "USE statement"
+	ShutdownStmt                "SHUTDOWN statement"
+	SignalStmt                  "SIGNAL or RESIGNAL statement"
+	RestartStmt                 "RESTART statement"
+	ResetMasterStmt             "RESET MASTER statement"
+	ResetReplicaStmt            "RESET REPLICA statement"
+	StartReplicaStmt            "START REPLICA statement"
+	StopReplicaStmt             "STOP REPLICA statement"
+	CreateViewSelectOpt         "Select/Union/Except/Intersect statement in CREATE VIEW ... AS SELECT"
+	BindableStmt                "Statement that can be created binding on"
+	UpdateStmtNoWith            "Update statement without CTE clause"
+	HelpStmt                    "HELP statement"
 
 %type	<item>
 	AdminShowSlow                          "Admin Show Slow statement"
@@ -944,12 +984,15 @@ import (
 	AssignmentList                         "assignment list"
 	AssignmentListOpt                      "assignment list opt"
 	AuthOption                             "User auth option"
+	AuthFactor                             "Additional authentication factor"
+	AuthIdentifiedOption                   "IDENTIFIED clause of user"
 	Boolean                                "Boolean (0, 1, false, true)"
 	OptionalBraces                         "optional braces"
 	CastType                               "Cast function target type"
 	ClearPasswordExpireOptions             "Clear password expire options"
 	ColumnDef                              "table column definition"
 	ColumnDefList                          "table column definition list"
+	CommentOrAttributeOpt                  "Optional COMMENT or ATTRIBUTE of user"
 	ColumnName                             "column name"
 	ColumnNameOrUserVariable               "column name or user variable"
 	ColumnNameList                         "column name list"
@@ -984,6 +1027,11 @@ import (
 	DistinctOpt                            "Explicit distinct option"
 	DefaultFalseDistinctOpt                "Distinct option which defaults to false"
 	DefaultTrueDistinctOpt                 "Distinct option which defaults to true"
+	DiagnosticsAreaOpt                     "Optional diagnostics area"
+	DiagnosticsConditionItem               "Condition information item of GET DIAGNOSTICS"
+	DiagnosticsConditionItemList           "Condition information item list of GET DIAGNOSTICS"
+	DiagnosticsStatementItem               "Statement information item of GET DIAGNOSTICS"
+	DiagnosticsStatementItemList           "Statement information item list of GET DIAGNOSTICS"
 	BuggyDefaultFalseDistinctOpt           "Distinct option which accepts DISTINCT ALL and defaults to false"
 	RequireClause                          "Encrypted connections options"
 	RequireClauseOpt                       "optional Encrypted connections options"
@@ -1108,8 +1156,34 @@ import (
 	OptGConcatSeparator                    "optional GROUP_CONCAT SEPARATOR"
 	ReferOpt                               "reference option"
 	ReorganizePartitionRuleOpt             "optional reorganize partition partition list and definitions"
+	ReplaceRetainPasswordOpt               "optional REPLACE and RETAIN CURRENT PASSWORD of ALTER USER USER()"
+	ReplicaConnOption                      "Connection option of START REPLICA"
+	ReplicaConnOptionList                  "Connection option list of START REPLICA"
+	ReplicaConnOptionListOpt               "Optional connection option list of START REPLICA"
+	ReplicaKwd                             "REPLICA or SLAVE"
+	ReplicaResetAllOpt                     "Optional ALL of RESET REPLICA"
+	ReplicaThreadType                      "Replication thread type"
+	ReplicaThreadTypeList                  "Replication thread type list"
+	ReplicaThreadTypeListOpt               "Optional replication thread type list"
+	ReplicaUntilOpt                        "Optional UNTIL clause of START REPLICA"
+	ReplicaUntilOption                     "Condition of the UNTIL clause of START REPLICA"
+	ReplicaUntilOptionList                 "Condition list of the UNTIL clause of START REPLICA"
+	ReplicationFilter                      "Replication filter rule"
+	ReplicationFilterList                  "Replication filter rule list"
+	ReplicationFilterType                  "Replication filter rule name"
+	ReplicationOption                      "Replication source option"
+	ReplicationOptionList                  "Replication source option list"
+	ReplicationRewrite                     "Database pair of REPLICATE_REWRITE_DB"
+	ReplicationRewriteList                 "Database pair list of REPLICATE_REWRITE_DB"
+	ReplicationSourceKwd                   "MASTER or REPLICATION SOURCE"
 	RequireList                            "require list"
 	RequireListElement                     "require list element"
+	ResourceGroupEnableOpt                 "Optional ENABLE or DISABLE of resource group"
+	ResourceGroupThreadIDList              "Thread ID list of SET RESOURCE GROUP"
+	ResourceGroupThreadIDListOpt           "Optional thread ID list of SET RESOURCE GROUP"
+	ResourceGroupThreadPriorityOpt         "Optional THREAD_PRIORITY of resource group"
+	ResourceGroupType                      "Resource group type"
+	ResourceGroupVCPUOpt                   "Optional VCPU of resource group"
 	Rolename                               "Rolename"
 	RolenameComposed                       "Rolename that composed with more than 1 symbol"
 	RolenameList                           "RolenameList"
@@ -1161,6 +1235,9 @@ import (
 	SubPartitionMethod                     "SubPartition method"
 	SubPartitionOpt                        "SubPartition option"
 	SubPartitionNumOpt                     "SubPartition NUM option"
+	SystemTimeClause                       "FOR SYSTEM_TIME clause"
+	SystemTimeClauseOpt                    "Optional FOR SYSTEM_TIME clause"
+	SystemTimeUnitOpt                      "Optional unit of FOR SYSTEM_TIME point"
 	TableAliasRefList                      "table alias reference list"
 	TableAsName                            "table alias name"
 	TableAsNameOpt                         "table alias name optional"
@@ -1206,6 +1283,8 @@ import (
 	ValuesStmtList                         "VALUES statement field list"
 	VariableAssignment                     "set variable value"
 	VariableAssignmentList                 "set variable value list"
+	VCPURange                              "Virtual CPU range"
+	VCPURangeList                          "Virtual CPU range list"
 	ViewAlgorithm                          "view algorithm"
 	ViewCheckOption                        "view check option"
 	ViewDefiner                            "view definer"
@@ -1279,6 +1358,10 @@ import (
 	NumList                                "Some numbers"
 	LengthNum                              "Field length num(uint64)"
 	SignedNum                              "Signed num(int64)"
+	SignalConditionValue                   "Condition value of SIGNAL"
+	SignalInfo                             "Condition information item of SIGNAL"
+	SignalInfoList                         "Condition information item list of SIGNAL"
+	SignalInfoListOpt                      "Optional condition information item list of SIGNAL"
 	TableOptimizerHints                    "Table level optimizer hints"
 	TableOptimizerHintsOpt                 "Table level optimizer hints option"
 	EnforcedOrNot                          "{ENFORCED|NOT ENFORCED}"
@@ -1369,6 +1452,8 @@ import (
 	ColumnFormat                    "Column format"
 	DBName                          "Database Name"
 	PolicyName                      "Placement Policy Name"
+	ReplicaConnOptionName           "Connection option name of START REPLICA"
+	ReplicationChannelOpt           "Optional replication channel"
 	ExplainFormatType               "explain format type"
 	FieldAsName                     "Field alias name"
 	FieldAsNameOpt                  "Field alias name opt"
@@ -1423,6 +1508,8 @@ import (
 %precedence order
 %precedence lowerThanFunction
 %precedence function
+%precedence lowerThanSystemTimeUnit
+%precedence timestampType transaction
 
 /* A dummy token to force the priority of TableRef production in a join. */
 %left tableRefPriority
@@ -6087,6 +6174,30 @@ UnReservedKeyword:
 |	"CLUSTERED"
 |	"NONCLUSTERED"
 |	"PRESERVE"
+|	"CHANNEL"
+|	"DEFAULT_AUTH"
+|	"FILTER"
+|	"IO_THREAD"
+|	"PLUGIN_DIR"
+|	"SQL_THREAD"
+|	"UNTIL"
+|	"RESOURCE"
+|	"THREAD_PRIORITY"
+|	"VCPU"
+|	"CONDITION"
+|	"DIAGNOSTICS"
+|	"GET"
+|	"RESIGNAL"
+|	"SIGNAL"
+|	"SQLSTATE"
+|	"STACKED"
+|	"ATTRIBUTE"
+|	"FAILED_LOGIN_ATTEMPTS"
+|	"OLD"
+|	"PASSWORD_LOCK_TIME"
+|	"RANDOM"
+|	"RETAIN"
+|	"REUSE"
 
 TiDBKeyword:
 	"ADMIN"
@@ -6478,7 +6589,7 @@ Literal:
 |	"UNDERSCORE_CHARSET" stringLit
 	{
 		// See https://dev.mysql.com/doc/refman/5.7/en/charset-literal.html
-		co, err := charset.GetDefaultCollationLegacy($1)
+		co, err := introducerCollation($1)
 		if err != nil {
 			yylex.AppendError(ast.ErrUnknownCharacterSet.GenWithStack("Unsupported character introducer: '%-.64s'", $1))
 			return 1
@@ -6490,6 +6601,10 @@ Literal:
 		if tp.Collate == charset.CollationBin {
 			tp.Flag |= mysql.BinaryFlag
 		}
+		if err := parser.checkLiteralCharset(expr, parser.startOffset(&yyS[yypt-1])); err != nil {
+			yylex.AppendError(err)
+			return 1
+		}
 		$$ = expr
 	}
 |	hexLit
@@ -6502,7 +6617,7 @@ Literal:
 	}
 |	"UNDERSCORE_CHARSET" hexLit
 	{
-		co, err := charset.GetDefaultCollationLegacy($1)
+		co, err := introducerCollation($1)
 		if err != nil {
 			yylex.AppendError(ast.ErrUnknownCharacterSet.GenWithStack("Unsupported character introducer: '%-.64s'", $1))
 			return 1
@@ -6514,11 +6629,15 @@ Literal:
 		if tp.Collate == charset.CollationBin {
 			tp.Flag |= mysql.BinaryFlag
 		}
+		if err := parser.checkLiteralCharset(expr, parser.startOffset(&yyS[yypt-1])); err != nil {
+			yylex.AppendError(err)
+			return 1
+		}
 		$$ = expr
 	}
 |	"UNDERSCORE_CHARSET" bitLit
 	{
-		co, err := charset.GetDefaultCollationLegacy($1)
+		co, err := introducerCollation($1)
 		if err != nil {
 			yylex.AppendError(ast.ErrUnknownCharacterSet.GenWithStack("Unsupported character introducer: '%-.64s'", $1))
 			return 1
@@ -6530,6 +6649,10 @@ Literal:
 		if tp.Collate == charset.CollationBin {
 			tp.Flag |= mysql.BinaryFlag
 		}
+		if err := parser.checkLiteralCharset(expr, parser.startOffset(&yyS[yypt-1])); err != nil {
+			yylex.AppendError(err)
+			return 1
+		}
 		$$ = expr
 	}
 
@@ -6744,6 +6867,10 @@ SimpleExpr:
 |	FunctionCallGeneric
 |	SimpleExpr "COLLATE" CollationName
 	{
+		if err := parser.checkLiteralCollation($1, $3, parser.startOffset(&yyS[yypt-2])); err != nil {
+			yylex.AppendError(err)
+			return 1
+		}
 		$$ = &ast.SetCollationExpr{Expr: $1, Collate: $3}
 	}
 |	WindowFuncCall
@@ -8729,18 +8856,21 @@ TableRef:
 |	JoinTable
 
 TableFactor:
-	TableName PartitionNameListOpt TableAsNameOpt AsOfClauseOpt IndexHintListOpt TableSampleOpt
+	TableName PartitionNameListOpt SystemTimeClauseOpt TableAsNameOpt AsOfClauseOpt IndexHintListOpt TableSampleOpt
 	{
 		tn := $1.(*ast.TableName)
 		tn.PartitionNames = $2.([]model.CIStr)
-		tn.IndexHints = $5.([]*ast.IndexHint)
-		if $6 != nil {
-			tn.TableSample = $6.(*ast.TableSample)
+		if $3 != nil {
+			tn.SystemTime = $3.(*ast.SystemTimeClause)
 		}
-		if $4 != nil {
-			tn.AsOf = $4.(*ast.AsOfClause)
+		tn.IndexHints = $6.([]*ast.IndexHint)
+		if $7 != nil {
+			tn.TableSample = $7.(*ast.TableSample)
 		}
-		$$ = &ast.TableSource{Source: tn, AsName: $3.(model.CIStr)}
+		if $5 != nil {
+			tn.AsOf = $5.(*ast.AsOfClause)
+		}
+		$$ = &ast.TableSource{Source: tn, AsName: $4.(model.CIStr)}
 	}
 |	SubSelect TableAsNameOpt
 	{
@@ -8764,6 +8894,62 @@ PartitionNameListOpt:
 		$$ = $3
 	}
 
+SystemTimeClauseOpt:
+	%prec empty
+	{
+		$$ = nil
+	}
+|	SystemTimeClause
+
+SystemTimeClause:
+	forSystemTime asof SystemTimeUnitOpt BitExpr
+	{
+		// See https://mariadb.com/kb/en/system-versioned-tables/#querying-historical-data
+		$$ = &ast.SystemTimeClause{
+			Tp:        ast.SystemTimeAsOf,
+			StartUnit: $3.(ast.SystemTimeUnit),
+			Start:     $4,
+		}
+	}
+|	forSystemTime "BETWEEN" SystemTimeUnitOpt BitExpr "AND" SystemTimeUnitOpt BitExpr
+	{
+		$$ = &ast.SystemTimeClause{
+			Tp:        ast.SystemTimeBetween,
+			StartUnit: $3.(ast.SystemTimeUnit),
+			Start:     $4,
+			EndUnit:   $6.(ast.SystemTimeUnit),
+			End:       $7,
+		}
+	}
+|	forSystemTime "FROM" SystemTimeUnitOpt BitExpr "TO" SystemTimeUnitOpt BitExpr
+	{
+		$$ = &ast.SystemTimeClause{
+			Tp:        ast.SystemTimeFromTo,
+			StartUnit: $3.(ast.SystemTimeUnit),
+			Start:     $4,
+			EndUnit:   $6.(ast.SystemTimeUnit),
+			End:       $7,
+		}
+	}
+|	forSystemTime "ALL"
+	{
+		$$ = &ast.SystemTimeClause{Tp: ast.SystemTimeAll}
+	}
+
+SystemTimeUnitOpt:
+	%prec lowerThanSystemTimeUnit
+	{
+		$$ = ast.SystemTimeUnitDefault
+	}
+|	"TIMESTAMP"
+	{
+		$$ = ast.SystemTimeUnitTimestamp
+	}
+|	"TRANSACTION"
+	{
+		$$ = ast.SystemTimeUnitTransaction
+	}
+
 TableAsNameOpt:
 	%prec empty
 	{
@@ -9541,6 +9727,504 @@ ChangeStmt:
 		}
 	}
 
+/********************Replication Statements*******************************/
+ChangeReplicationSourceStmt:
+	"CHANGE" ReplicationSourceKwd "TO" ReplicationOptionList ReplicationChannelOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/change-replication-source-to.html
+		$$ = &ast.ChangeReplicationSourceStmt{
+			IsMaster: $2.(bool),
+			Options:  $4.([]*ast.ReplicationOption),
+			Channel:  $5,
+		}
+	}
+
+ReplicationSourceKwd:
+	"MASTER"
+	{
+		$$ = true
+	}
+|	"REPLICATION" "SOURCE"
+	{
+		$$ = false
+	}
+
+ReplicationOptionList:
+	ReplicationOption
+	{
+		$$ = []*ast.ReplicationOption{$1.(*ast.ReplicationOption)}
+	}
+|	ReplicationOptionList ',' ReplicationOption
+	{
+		$$ = append($1.([]*ast.ReplicationOption), $3.(*ast.ReplicationOption))
+	}
+
+ReplicationOption:
+	Identifier eq SignedLiteral
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), Value: $3}
+	}
+|	Identifier eq Identifier
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), Keyword: strings.ToUpper($3)}
+	}
+|	Identifier eq "ON"
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), Keyword: "ON"}
+	}
+|	Identifier eq StringName '@' StringName
+	{
+		// The value of PRIVILEGE_CHECKS_USER is an account.
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), User: &auth.UserIdentity{Username: $3, Hostname: $5}}
+	}
+|	Identifier eq StringName singleAtIdentifier
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), User: &auth.UserIdentity{Username: $3, Hostname: strings.TrimPrefix($4, "@")}}
+	}
+|	Identifier eq '(' ')'
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), ServerIDs: []int64{}}
+	}
+|	Identifier eq '(' NumList ')'
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), ServerIDs: $4.([]int64)}
+	}
+
+ReplicationChannelOpt:
+	{
+		$$ = ""
+	}
+|	"FOR" "CHANNEL" StringName
+	{
+		$$ = $3
+	}
+
+ChangeReplicationFilterStmt:
+	"CHANGE" "REPLICATION" "FILTER" ReplicationFilterList ReplicationChannelOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/change-replication-filter.html
+		$$ = &ast.ChangeReplicationFilterStmt{
+			Filters: $4.([]*ast.ReplicationFilter),
+			Channel: $5,
+		}
+	}
+
+ReplicationFilterList:
+	ReplicationFilter
+	{
+		$$ = []*ast.ReplicationFilter{$1.(*ast.ReplicationFilter)}
+	}
+|	ReplicationFilterList ',' ReplicationFilter
+	{
+		$$ = append($1.([]*ast.ReplicationFilter), $3.(*ast.ReplicationFilter))
+	}
+
+ReplicationFilter:
+	ReplicationFilterType eq '(' ')'
+	{
+		$$ = &ast.ReplicationFilter{Tp: $1.(ast.ReplicationFilterType)}
+	}
+|	ReplicationFilterType eq '(' TableNameList ')'
+	{
+		tp := $1.(ast.ReplicationFilterType)
+		tables := $4.([]*ast.TableName)
+		filter := &ast.ReplicationFilter{Tp: tp}
+		switch tp {
+		case ast.ReplicateDoDB, ast.ReplicateIgnoreDB:
+			for _, t := range tables {
+				if t.Schema.L != "" {
+					yylex.AppendError(yylex.Errorf("Invalid database name '%s.%s' for %s", t.Schema.O, t.Name.O, tp))
+					return 1
+				}
+				filter.DBNames = append(filter.DBNames, t.Name)
+			}
+		case ast.ReplicateDoTable, ast.ReplicateIgnoreTable:
+			for _, t := range tables {
+				if t.Schema.L == "" {
+					yylex.AppendError(yylex.Errorf("Table name '%s' for %s must be qualified with the database name", t.Name.O, tp))
+					return 1
+				}
+			}
+			filter.Tables = tables
+		default:
+			yylex.AppendError(yylex.Errorf("%s requires a list of string patterns or database pairs", tp))
+			return 1
+		}
+		$$ = filter
+	}
+|	ReplicationFilterType eq '(' StringList ')'
+	{
+		tp := $1.(ast.ReplicationFilterType)
+		if tp != ast.ReplicateWildDoTable && tp != ast.ReplicateWildIgnoreTable {
+			yylex.AppendError(yylex.Errorf("%s doesn't accept string patterns", tp))
+			return 1
+		}
+		$$ = &ast.ReplicationFilter{Tp: tp, Patterns: $4.([]string)}
+	}
+|	ReplicationFilterType eq '(' ReplicationRewriteList ')'
+	{
+		tp := $1.(ast.ReplicationFilterType)
+		if tp != ast.ReplicateRewriteDB {
+			yylex.AppendError(yylex.Errorf("%s doesn't accept database pairs", tp))
+			return 1
+		}
+		$$ = &ast.ReplicationFilter{Tp: tp, Rewrites: $4.([]*ast.ReplicationRewrite)}
+	}
+
+ReplicationFilterType:
+	Identifier
+	{
+		tp, ok := ast.ReplicationFilterTypeFromName($1)
+		if !ok {
+			yylex.AppendError(yylex.Errorf("Unknown replication filter: %s", $1))
+			return 1
+		}
+		$$ = tp
+	}
+
+ReplicationRewriteList:
+	ReplicationRewrite
+	{
+		$$ = []*ast.ReplicationRewrite{$1.(*ast.ReplicationRewrite)}
+	}
+|	ReplicationRewriteList ',' ReplicationRewrite
+	{
+		$$ = append($1.([]*ast.ReplicationRewrite), $3.(*ast.ReplicationRewrite))
+	}
+
+ReplicationRewrite:
+	'(' Identifier ',' Identifier ')'
+	{
+		$$ = &ast.ReplicationRewrite{From: model.NewCIStr($2), To: model.NewCIStr($4)}
+	}
+
+StartReplicaStmt:
+	"START" ReplicaKwd ReplicaThreadTypeListOpt ReplicaUntilOpt ReplicaConnOptionListOpt ReplicationChannelOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/start-replica.html
+		$$ = &ast.StartReplicaStmt{
+			IsSlave:      $2.(bool),
+			ThreadTypes:  $3.([]ast.ReplicaThreadType),
+			UntilOptions: $4.([]*ast.ReplicationOption),
+			ConnOptions:  $5.([]*ast.ReplicationOption),
+			Channel:      $6,
+		}
+	}
+
+StopReplicaStmt:
+	"STOP" ReplicaKwd ReplicaThreadTypeListOpt ReplicationChannelOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/stop-replica.html
+		$$ = &ast.StopReplicaStmt{
+			IsSlave:     $2.(bool),
+			ThreadTypes: $3.([]ast.ReplicaThreadType),
+			Channel:     $4,
+		}
+	}
+
+ReplicaKwd:
+	"REPLICA"
+	{
+		$$ = false
+	}
+|	"SLAVE"
+	{
+		$$ = true
+	}
+
+ReplicaThreadTypeListOpt:
+	{
+		$$ = []ast.ReplicaThreadType{}
+	}
+|	ReplicaThreadTypeList
+
+ReplicaThreadTypeList:
+	ReplicaThreadType
+	{
+		$$ = []ast.ReplicaThreadType{$1.(ast.ReplicaThreadType)}
+	}
+|	ReplicaThreadTypeList ',' ReplicaThreadType
+	{
+		$$ = append($1.([]ast.ReplicaThreadType), $3.(ast.ReplicaThreadType))
+	}
+
+ReplicaThreadType:
+	"IO_THREAD"
+	{
+		$$ = ast.ReplicaIOThread
+	}
+|	"SQL_THREAD"
+	{
+		$$ = ast.ReplicaSQLThread
+	}
+
+ReplicaUntilOpt:
+	{
+		$$ = []*ast.ReplicationOption{}
+	}
+|	"UNTIL" ReplicaUntilOptionList
+	{
+		$$ = $2
+	}
+
+ReplicaUntilOptionList:
+	ReplicaUntilOption
+	{
+		$$ = []*ast.ReplicationOption{$1.(*ast.ReplicationOption)}
+	}
+|	ReplicaUntilOptionList ',' ReplicaUntilOption
+	{
+		$$ = append($1.([]*ast.ReplicationOption), $3.(*ast.ReplicationOption))
+	}
+
+ReplicaUntilOption:
+	Identifier eq SignedLiteral
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), Value: $3}
+	}
+|	Identifier
+	{
+		$$ = &ast.ReplicationOption{Name: strings.ToUpper($1), NoValue: true}
+	}
+
+ReplicaConnOptionListOpt:
+	{
+		$$ = []*ast.ReplicationOption{}
+	}
+|	ReplicaConnOptionList
+
+ReplicaConnOptionList:
+	ReplicaConnOption
+	{
+		$$ = []*ast.ReplicationOption{$1.(*ast.ReplicationOption)}
+	}
+|	ReplicaConnOptionList ReplicaConnOption
+	{
+		$$ = append($1.([]*ast.ReplicationOption), $2.(*ast.ReplicationOption))
+	}
+
+ReplicaConnOption:
+	ReplicaConnOptionName eq stringLit
+	{
+		$$ = &ast.ReplicationOption{Name: $1, Value: ast.NewValueExpr($3, "", "")}
+	}
+
+ReplicaConnOptionName:
+	"USER"
+	{
+		$$ = "USER"
+	}
+|	"PASSWORD"
+	{
+		$$ = "PASSWORD"
+	}
+|	"DEFAULT_AUTH"
+	{
+		$$ = "DEFAULT_AUTH"
+	}
+|	"PLUGIN_DIR"
+	{
+		$$ = "PLUGIN_DIR"
+	}
+
+ResetMasterStmt:
+	"RESET" "MASTER"
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/reset-master.html
+		$$ = &ast.ResetMasterStmt{}
+	}
+|	"RESET" "MASTER" "TO" LengthNum
+	{
+		index := $4.(uint64)
+		if index == 0 || index > math.MaxInt32 {
+			yylex.AppendError(yylex.Errorf("The requested value '%d' for the next binary log index is out of range", index))
+			return 1
+		}
+		$$ = &ast.ResetMasterStmt{BinlogIndex: index}
+	}
+
+ResetReplicaStmt:
+	"RESET" ReplicaKwd ReplicaResetAllOpt ReplicationChannelOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/reset-replica.html
+		$$ = &ast.ResetReplicaStmt{
+			IsSlave: $2.(bool),
+			All:     $3.(bool),
+			Channel: $4,
+		}
+	}
+
+ReplicaResetAllOpt:
+	{
+		$$ = false
+	}
+|	"ALL"
+	{
+		$$ = true
+	}
+
+/********************Condition Handling Statements*******************************/
+SignalStmt:
+	"SIGNAL" SignalConditionValue SignalInfoListOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/signal.html
+		stmt := $2.(*ast.SignalStmt)
+		stmt.Infos = $3.([]*ast.SignalInfoItem)
+		$$ = stmt
+	}
+|	"RESIGNAL" SignalInfoListOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/resignal.html
+		$$ = &ast.SignalStmt{IsResignal: true, Infos: $2.([]*ast.SignalInfoItem)}
+	}
+|	"RESIGNAL" SignalConditionValue SignalInfoListOpt
+	{
+		stmt := $2.(*ast.SignalStmt)
+		stmt.IsResignal = true
+		stmt.Infos = $3.([]*ast.SignalInfoItem)
+		$$ = stmt
+	}
+
+SignalConditionValue:
+	"SQLSTATE" ValueOpt stringLit
+	{
+		if !mysql.IsValidSQLState($3) || mysql.IsCompletionSQLState($3) {
+			yylex.AppendError(ErrSpBadSQLState.GenWithStackByArgs($3))
+			return 1
+		}
+		$$ = &ast.SignalStmt{SQLState: $3}
+	}
+|	Identifier
+	{
+		$$ = &ast.SignalStmt{ConditionName: $1}
+	}
+
+ValueOpt:
+	{}
+|	"VALUE"
+
+SignalInfoListOpt:
+	{
+		$$ = []*ast.SignalInfoItem{}
+	}
+|	"SET" SignalInfoList
+	{
+		$$ = $2
+	}
+
+SignalInfoList:
+	SignalInfo
+	{
+		$$ = []*ast.SignalInfoItem{$1.(*ast.SignalInfoItem)}
+	}
+|	SignalInfoList ',' SignalInfo
+	{
+		infos, info := $1.([]*ast.SignalInfoItem), $3.(*ast.SignalInfoItem)
+		for _, i := range infos {
+			if i.Name == info.Name {
+				yylex.AppendError(ErrDupSignalSet.GenWithStackByArgs(info.Name.String()))
+				return 1
+			}
+		}
+		$$ = append(infos, info)
+	}
+
+SignalInfo:
+	Identifier eq SignalAllowedExpr
+	{
+		item, ok := ast.ConditionInfoItemFromName($1)
+		if !ok || item == ast.ConditionReturnedSQLState {
+			yylex.AppendError(yylex.Errorf("Unknown condition information item '%s'", $1))
+			return 1
+		}
+		if err := checkSignalInfo(item, $3); err != nil {
+			yylex.AppendError(err)
+			return 1
+		}
+		$$ = &ast.SignalInfoItem{Name: item, Value: $3}
+	}
+
+SignalAllowedExpr:
+	SignedLiteral
+|	Variable
+|	paramMarker
+	{
+		$$ = ast.NewParamMarkerExpr(yyS[yypt].offset)
+	}
+
+GetDiagnosticsStmt:
+	"GET" DiagnosticsAreaOpt "DIAGNOSTICS" DiagnosticsStatementItemList
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/get-diagnostics.html
+		$$ = &ast.GetDiagnosticsStmt{
+			Area:           $2.(ast.DiagnosticsArea),
+			StatementItems: $4.([]*ast.DiagnosticsStatementItem),
+		}
+	}
+|	"GET" DiagnosticsAreaOpt "DIAGNOSTICS" "CONDITION" SignalAllowedExpr DiagnosticsConditionItemList
+	{
+		$$ = &ast.GetDiagnosticsStmt{
+			Area:            $2.(ast.DiagnosticsArea),
+			ConditionNumber: $5,
+			ConditionItems:  $6.([]*ast.DiagnosticsConditionItem),
+		}
+	}
+
+DiagnosticsAreaOpt:
+	{
+		$$ = ast.DiagnosticsAreaDefault
+	}
+|	"CURRENT"
+	{
+		$$ = ast.DiagnosticsAreaCurrent
+	}
+|	"STACKED"
+	{
+		$$ = ast.DiagnosticsAreaStacked
+	}
+
+DiagnosticsStatementItemList:
+	DiagnosticsStatementItem
+	{
+		$$ = []*ast.DiagnosticsStatementItem{$1.(*ast.DiagnosticsStatementItem)}
+	}
+|	DiagnosticsStatementItemList ',' DiagnosticsStatementItem
+	{
+		$$ = append($1.([]*ast.DiagnosticsStatementItem), $3.(*ast.DiagnosticsStatementItem))
+	}
+
+DiagnosticsStatementItem:
+	UserVariable eq Identifier
+	{
+		item, ok := ast.StatementInfoItemFromName($3)
+		if !ok {
+			yylex.AppendError(yylex.Errorf("Unknown statement information item '%s'", $3))
+			return 1
+		}
+		$$ = &ast.DiagnosticsStatementItem{Target: $1, Name: item}
+	}
+
+DiagnosticsConditionItemList:
+	DiagnosticsConditionItem
+	{
+		$$ = []*ast.DiagnosticsConditionItem{$1.(*ast.DiagnosticsConditionItem)}
+	}
+|	DiagnosticsConditionItemList ',' DiagnosticsConditionItem
+	{
+		$$ = append($1.([]*ast.DiagnosticsConditionItem), $3.(*ast.DiagnosticsConditionItem))
+	}
+
+DiagnosticsConditionItem:
+	UserVariable eq Identifier
+	{
+		item, ok := ast.ConditionInfoItemFromName($3)
+		if !ok {
+			yylex.AppendError(yylex.Errorf("Unknown condition information item '%s'", $3))
+			return 1
+		}
+		$$ = &ast.DiagnosticsConditionItem{Target: $1, Name: item}
+	}
+
 /********************Set Statement*******************************/
 SetStmt:
 	"SET" VariableAssignmentList
@@ -9555,6 +10239,14 @@ SetStmt:
 	{
 		$$ = &ast.SetPwdStmt{User: $4.(*auth.UserIdentity), Password: $6}
 	}
+|	"SET" "RESOURCE" "GROUP" Identifier ResourceGroupThreadIDListOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/set-resource-group.html
+		$$ = &ast.SetResourceGroupStmt{
+			ResourceGroupName: model.NewCIStr($4),
+			ThreadIDs:         $5.([]uint64),
+		}
+	}
 |	"SET" "GLOBAL" "TRANSACTION" TransactionChars
 	{
 		vars := $4.([]*ast.VariableAssignment)
@@ -10894,6 +11586,7 @@ Statement:
 |	AlterInstanceStmt
 |	AlterSequenceStmt
 |	AlterPolicyStmt
+|	AlterResourceGroupStmt
 |	AnalyzeTableStmt
 |	BeginTransactionStmt
 |	BinlogStmt
@@ -10904,6 +11597,8 @@ Statement:
 |	ExecuteStmt
 |	ExplainStmt
 |	ChangeStmt
+|	ChangeReplicationFilterStmt
+|	ChangeReplicationSourceStmt
 |	CreateDatabaseStmt
 |	CreateImportStmt
 |	CreateIndexStmt
@@ -10913,6 +11608,7 @@ Statement:
 |	CreateRoleStmt
 |	CreateBindingStmt
 |	CreatePolicyStmt
+|	CreateResourceGroupStmt
 |	CreateSequenceStmt
 |	CreateStatisticsStmt
 |	DoStmt
@@ -10921,6 +11617,7 @@ Statement:
 |	DropIndexStmt
 |	DropTableStmt
 |	DropPolicyStmt
+|	DropResourceGroupStmt
 |	DropSequenceStmt
 |	DropViewStmt
 |	DropUserStmt
@@ -10930,6 +11627,7 @@ Statement:
 |	DropBindingStmt
 |	FlushStmt
 |	FlashbackTableStmt
+|	GetDiagnosticsStmt
 |	GrantStmt
 |	GrantProxyStmt
 |	GrantRoleStmt
@@ -10980,8 +11678,13 @@ Statement:
 |	UnlockTablesStmt
 |	LockTablesStmt
 |	ShutdownStmt
+|	SignalStmt
 |	RestartStmt
 |	HelpStmt
+|	ResetMasterStmt
+|	ResetReplicaStmt
+|	StartReplicaStmt
+|	StopReplicaStmt
 
 TraceableStmt:
 	DeleteFromStmt
@@ -11122,6 +11825,11 @@ TableElementListOpt:
 
 TableOption:
 	PartDefOption
+|	withSystemVersioning
+	{
+		// See https://mariadb.com/kb/en/system-versioned-tables/
+		$$ = &ast.TableOption{Tp: ast.TableOptionWithSystemVersioning}
+	}
 |	DefaultKwdOpt CharsetKw EqOpt CharsetName
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionCharset, StrValue: $4,
@@ -11866,6 +12574,10 @@ DateAndTimeType:
 |	"TIMESTAMP" OptFieldLen
 	{
 		x := types.NewFieldType(mysql.TypeTimestamp)
+		// TIMESTAMP columns are DATETIME columns in the MAXDB mode.
+		if parser.lexer.GetSQLMode().HasMaxDBMode() {
+			x.Tp = mysql.TypeDatetime
+		}
 		x.Flen = mysql.MaxDatetimeWidthNoFsp
 		x.Decimal = $2.(int)
 		if x.Decimal > 0 {
@@ -12126,17 +12838,28 @@ CommaOpt:
  *  https://dev.mysql.com/doc/refman/5.7/en/account-management-sql.html
  ************************************************************************************/
 CreateUserStmt:
-	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions
+	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions CommentOrAttributeOpt
 	{
-		// See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
-		$$ = &ast.CreateUserStmt{
+		// See https://dev.mysql.com/doc/refman/8.0/en/create-user.html
+		specs := $4.([]*ast.UserSpec)
+		for _, spec := range specs {
+			if opt := spec.AuthOpt; opt != nil && (opt.DiscardOldPassword || opt.ReplaceString != "" || opt.RetainCurrentPassword) {
+				yylex.AppendError(yylex.Errorf("REPLACE, RETAIN CURRENT PASSWORD and DISCARD OLD PASSWORD are only allowed in ALTER USER"))
+				return 1
+			}
+		}
+		stmt := &ast.CreateUserStmt{
 			IsCreateRole:          false,
 			IfNotExists:           $3.(bool),
-			Specs:                 $4.([]*ast.UserSpec),
+			Specs:                 specs,
 			TLSOptions:            $5.([]*ast.TLSOption),
 			ResourceOptions:       $6.([]*ast.ResourceOption),
 			PasswordOrLockOptions: $7.([]*ast.PasswordOrLockOption),
 		}
+		if $8 != nil {
+			stmt.CommentOrAttributeOption = $8.(*ast.CommentOrAttributeOption)
+		}
+		$$ = stmt
 	}
 
 CreateRoleStmt:
@@ -12152,28 +12875,48 @@ CreateRoleStmt:
 
 /* See http://dev.mysql.com/doc/refman/5.7/en/alter-user.html */
 AlterUserStmt:
-	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions
+	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions CommentOrAttributeOpt
 	{
-		$$ = &ast.AlterUserStmt{
+		stmt := &ast.AlterUserStmt{
 			IfExists:              $3.(bool),
 			Specs:                 $4.([]*ast.UserSpec),
 			TLSOptions:            $5.([]*ast.TLSOption),
 			ResourceOptions:       $6.([]*ast.ResourceOption),
 			PasswordOrLockOptions: $7.([]*ast.PasswordOrLockOption),
 		}
+		if $8 != nil {
+			stmt.CommentOrAttributeOption = $8.(*ast.CommentOrAttributeOption)
+		}
+		$$ = stmt
 	}
-|	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString
+|	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString ReplaceRetainPasswordOpt
 	{
-		auth := &ast.AuthOption{
-			AuthString:   $9,
-			ByAuthString: true,
-		}
+		auth := $10.(*ast.AuthOption)
+		auth.AuthString = $9
+		auth.ByAuthString = true
 		$$ = &ast.AlterUserStmt{
 			IfExists:    $3.(bool),
 			CurrentAuth: auth,
 		}
 	}
 
+ReplaceRetainPasswordOpt:
+	{
+		$$ = &ast.AuthOption{}
+	}
+|	"REPLACE" AuthString
+	{
+		$$ = &ast.AuthOption{ReplaceString: $2}
+	}
+|	"RETAIN" "CURRENT" "PASSWORD"
+	{
+		$$ = &ast.AuthOption{RetainCurrentPassword: true}
+	}
+|	"REPLACE" AuthString "RETAIN" "CURRENT" "PASSWORD"
+	{
+		$$ = &ast.AuthOption{ReplaceString: $2, RetainCurrentPassword: true}
+	}
+
 /* See https://dev.mysql.com/doc/refman/8.0/en/alter-instance.html */
 AlterInstanceStmt:
 	"ALTER" "INSTANCE" InstanceOption
@@ -12415,6 +13158,80 @@ PasswordOrLockOption:
 			Type: ast.PasswordExpireDefault,
 		}
 	}
+|	"FAILED_LOGIN_ATTEMPTS" Int64Num
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/create-user.html#create-user-password-management
+		if $2.(int64) > math.MaxInt16 {
+			yylex.AppendError(ErrWrongValue.GenWithStackByArgs("failed_login_attempts", strconv.FormatInt($2.(int64), 10)))
+			return 1
+		}
+		$$ = &ast.PasswordOrLockOption{
+			Type:  ast.FailedLoginAttempts,
+			Count: $2.(int64),
+		}
+	}
+|	"PASSWORD_LOCK_TIME" Int64Num
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/create-user.html#create-user-password-management
+		if $2.(int64) > math.MaxInt16 {
+			yylex.AppendError(ErrWrongValue.GenWithStackByArgs("password_lock_time", strconv.FormatInt($2.(int64), 10)))
+			return 1
+		}
+		$$ = &ast.PasswordOrLockOption{
+			Type:  ast.PasswordLockTime,
+			Count: $2.(int64),
+		}
+	}
+|	"PASSWORD_LOCK_TIME" "UNBOUNDED"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordLockTimeUnbounded,
+		}
+	}
+|	"PASSWORD" "HISTORY" Int64Num
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type:  ast.PasswordHistory,
+			Count: $3.(int64),
+		}
+	}
+|	"PASSWORD" "HISTORY" "DEFAULT"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordHistoryDefault,
+		}
+	}
+|	"PASSWORD" "REUSE" "INTERVAL" Int64Num "DAY"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type:  ast.PasswordReuseInterval,
+			Count: $4.(int64),
+		}
+	}
+|	"PASSWORD" "REUSE" "INTERVAL" "DEFAULT"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordReuseDefault,
+		}
+	}
+|	"PASSWORD" "REQUIRE" "CURRENT"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordRequireCurrent,
+		}
+	}
+|	"PASSWORD" "REQUIRE" "CURRENT" "DEFAULT"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordRequireCurrentDefault,
+		}
+	}
+|	"PASSWORD" "REQUIRE" "CURRENT" "OPTIONAL"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordRequireCurrentOptional,
+		}
+	}
 
 PasswordExpire:
 	"PASSWORD" "EXPIRE" ClearPasswordExpireOptions
@@ -12431,13 +13248,78 @@ AuthOption:
 	{
 		$$ = nil
 	}
-|	"IDENTIFIED" "BY" AuthString
+|	AuthIdentifiedOption
+|	AuthIdentifiedOption AuthFactor
+	{
+		opt := $1.(*ast.AuthOption)
+		opt.Factors = []*ast.AuthOption{$2.(*ast.AuthOption)}
+		$$ = opt
+	}
+|	AuthIdentifiedOption AuthFactor AuthFactor
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/multifactor-authentication.html
+		opt := $1.(*ast.AuthOption)
+		opt.Factors = []*ast.AuthOption{$2.(*ast.AuthOption), $3.(*ast.AuthOption)}
+		$$ = opt
+	}
+|	AuthIdentifiedOption "REPLACE" AuthString
+	{
+		opt := $1.(*ast.AuthOption)
+		if !opt.ByAuthString && !opt.RandomPassword {
+			yylex.AppendError(yylex.Errorf("REPLACE is only allowed with IDENTIFIED BY"))
+			return 1
+		}
+		opt.ReplaceString = $3
+		$$ = opt
+	}
+|	AuthIdentifiedOption "RETAIN" "CURRENT" "PASSWORD"
+	{
+		opt := $1.(*ast.AuthOption)
+		if !opt.ByAuthString && !opt.RandomPassword {
+			yylex.AppendError(yylex.Errorf("RETAIN CURRENT PASSWORD is only allowed with IDENTIFIED BY"))
+			return 1
+		}
+		opt.RetainCurrentPassword = true
+		$$ = opt
+	}
+|	AuthIdentifiedOption "REPLACE" AuthString "RETAIN" "CURRENT" "PASSWORD"
+	{
+		opt := $1.(*ast.AuthOption)
+		if !opt.ByAuthString && !opt.RandomPassword {
+			yylex.AppendError(yylex.Errorf("REPLACE is only allowed with IDENTIFIED BY"))
+			return 1
+		}
+		opt.ReplaceString = $3
+		opt.RetainCurrentPassword = true
+		$$ = opt
+	}
+|	"DISCARD" "OLD" "PASSWORD"
+	{
+		$$ = &ast.AuthOption{
+			DiscardOldPassword: true,
+		}
+	}
+
+AuthFactor:
+	"AND" AuthIdentifiedOption
+	{
+		$$ = $2
+	}
+
+AuthIdentifiedOption:
+	"IDENTIFIED" "BY" AuthString
 	{
 		$$ = &ast.AuthOption{
 			AuthString:   $3,
 			ByAuthString: true,
 		}
 	}
+|	"IDENTIFIED" "BY" "RANDOM" "PASSWORD"
+	{
+		$$ = &ast.AuthOption{
+			RandomPassword: true,
+		}
+	}
 |	"IDENTIFIED" "WITH" AuthPlugin
 	{
 		$$ = &ast.AuthOption{
@@ -12452,6 +13334,13 @@ AuthOption:
 			ByAuthString: true,
 		}
 	}
+|	"IDENTIFIED" "WITH" AuthPlugin "BY" "RANDOM" "PASSWORD"
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin:     $3,
+			RandomPassword: true,
+		}
+	}
 |	"IDENTIFIED" "WITH" AuthPlugin "AS" HashString
 	{
 		$$ = &ast.AuthOption{
@@ -12467,6 +13356,19 @@ AuthOption:
 		}
 	}
 
+CommentOrAttributeOpt:
+	{
+		$$ = nil
+	}
+|	"COMMENT" stringLit
+	{
+		$$ = &ast.CommentOrAttributeOption{Type: ast.UserCommentType, Value: $2}
+	}
+|	"ATTRIBUTE" stringLit
+	{
+		$$ = &ast.CommentOrAttributeOption{Type: ast.UserAttributeType, Value: $2}
+	}
+
 AuthPlugin:
 	StringName
 
@@ -13328,6 +14230,159 @@ AlterPolicyStmt:
 		}
 	}
 
+/********************************************************************************************
+ *
+ *  Resource Group Statements
+ *
+ *  Example:
+ *	CREATE RESOURCE GROUP group_name TYPE = {SYSTEM|USER}
+ *	[VCPU [=] vcpu_spec [, vcpu_spec] ...]
+ *	[THREAD_PRIORITY [=] N]
+ *	[ENABLE|DISABLE]
+ ********************************************************************************************/
+CreateResourceGroupStmt:
+	"CREATE" "RESOURCE" "GROUP" Identifier "TYPE" EqOpt ResourceGroupType ResourceGroupVCPUOpt ResourceGroupThreadPriorityOpt ResourceGroupEnableOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/create-resource-group.html
+		if $9 != nil {
+			if err := checkThreadPriority($4, $7.(model.ResourceGroupType), $9.(*ast.ResourceGroupOption).IntValue); err != nil {
+				yylex.AppendError(err)
+				return 1
+			}
+		}
+		opts := []*ast.ResourceGroupOption{{Tp: ast.ResourceGroupOptionGroupType, GroupType: $7.(model.ResourceGroupType)}}
+		for _, opt := range []interface{}{$8, $9, $10} {
+			if opt != nil {
+				opts = append(opts, opt.(*ast.ResourceGroupOption))
+			}
+		}
+		$$ = &ast.CreateResourceGroupStmt{
+			ResourceGroupName:    model.NewCIStr($4),
+			ResourceGroupOptions: opts,
+		}
+	}
+
+AlterResourceGroupStmt:
+	"ALTER" "RESOURCE" "GROUP" Identifier ResourceGroupVCPUOpt ResourceGroupThreadPriorityOpt ResourceGroupEnableOpt ForceOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/alter-resource-group.html
+		if $8.(bool) && ($7 == nil || $7.(*ast.ResourceGroupOption).Tp != ast.ResourceGroupOptionDisable) {
+			yylex.AppendError(ErrInvalidUseOfForceOption.GenWithStackByArgs())
+			return 1
+		}
+		if $6 != nil {
+			if err := checkThreadPriority($4, model.ResourceGroupTypeNone, $6.(*ast.ResourceGroupOption).IntValue); err != nil {
+				yylex.AppendError(err)
+				return 1
+			}
+		}
+		opts := []*ast.ResourceGroupOption{}
+		for _, opt := range []interface{}{$5, $6, $7} {
+			if opt != nil {
+				opts = append(opts, opt.(*ast.ResourceGroupOption))
+			}
+		}
+		$$ = &ast.AlterResourceGroupStmt{
+			ResourceGroupName:    model.NewCIStr($4),
+			ResourceGroupOptions: opts,
+			Force:                $8.(bool),
+		}
+	}
+
+DropResourceGroupStmt:
+	"DROP" "RESOURCE" "GROUP" Identifier ForceOpt
+	{
+		// See https://dev.mysql.com/doc/refman/8.0/en/drop-resource-group.html
+		$$ = &ast.DropResourceGroupStmt{
+			ResourceGroupName: model.NewCIStr($4),
+			Force:             $5.(bool),
+		}
+	}
+
+ResourceGroupType:
+	"USER"
+	{
+		$$ = model.ResourceGroupTypeUser
+	}
+|	"SYSTEM"
+	{
+		$$ = model.ResourceGroupTypeSystem
+	}
+
+ResourceGroupVCPUOpt:
+	{
+		$$ = nil
+	}
+|	"VCPU" EqOpt VCPURangeList
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionVCPU, VCPUs: $3.([]model.VCPURange)}
+	}
+
+VCPURangeList:
+	VCPURange
+	{
+		$$ = []model.VCPURange{$1.(model.VCPURange)}
+	}
+|	VCPURangeList ',' VCPURange
+	{
+		$$ = append($1.([]model.VCPURange), $3.(model.VCPURange))
+	}
+
+VCPURange:
+	LengthNum
+	{
+		$$ = model.VCPURange{Start: $1.(uint64), End: $1.(uint64)}
+	}
+|	LengthNum '-' LengthNum
+	{
+		if $1.(uint64) > $3.(uint64) {
+			yylex.AppendError(ErrInvalidVCPURange.GenWithStackByArgs($1, $3))
+			return 1
+		}
+		$$ = model.VCPURange{Start: $1.(uint64), End: $3.(uint64)}
+	}
+
+ResourceGroupThreadPriorityOpt:
+	{
+		$$ = nil
+	}
+|	"THREAD_PRIORITY" EqOpt SignedNum
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionThreadPriority, IntValue: $3.(int64)}
+	}
+
+ResourceGroupEnableOpt:
+	{
+		$$ = nil
+	}
+|	"ENABLE"
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionEnable}
+	}
+|	"DISABLE"
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionDisable}
+	}
+
+ResourceGroupThreadIDListOpt:
+	{
+		$$ = []uint64{}
+	}
+|	"FOR" ResourceGroupThreadIDList
+	{
+		$$ = $2
+	}
+
+ResourceGroupThreadIDList:
+	LengthNum
+	{
+		$$ = []uint64{$1.(uint64)}
+	}
+|	ResourceGroupThreadIDList ',' LengthNum
+	{
+		$$ = append($1.([]uint64), $3.(uint64))
+	}
+
 /********************************************************************************************
  *
  *  Create Sequence Statement
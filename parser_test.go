@@ -4130,6 +4130,10 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{`ALTER USER 'root'@'localhost' IDENTIFIED BY 'new-password', 'root'@'127.0.0.1' IDENTIFIED BY PASSWORD 'hashstring'`, true, "ALTER USER `root`@`localhost` IDENTIFIED BY 'new-password', `root`@`127.0.0.1` IDENTIFIED WITH 'mysql_native_password' AS 'hashstring'"},
 		{`ALTER USER USER() IDENTIFIED BY 'new-password'`, true, "ALTER USER USER() IDENTIFIED BY 'new-password'"},
 		{`ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'`, true, "ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'"},
+		{`ALTER USER USER() IDENTIFIED BY 'x' REPLACE 'y'`, true, "ALTER USER USER() IDENTIFIED BY 'x' REPLACE 'y'"},
+		{`ALTER USER USER() IDENTIFIED BY 'x' RETAIN CURRENT PASSWORD`, true, "ALTER USER USER() IDENTIFIED BY 'x' RETAIN CURRENT PASSWORD"},
+		{`ALTER USER USER() IDENTIFIED BY 'x' REPLACE 'y' RETAIN CURRENT PASSWORD`, true, "ALTER USER USER() IDENTIFIED BY 'x' REPLACE 'y' RETAIN CURRENT PASSWORD"},
+		{`ALTER USER USER() IDENTIFIED BY 'x' RETAIN CURRENT PASSWORD REPLACE 'y'`, false, ""},
 		{"alter user 'test@localhost' password expire;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE"},
 		{"alter user 'test@localhost' password expire never;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE NEVER"},
 		{"alter user 'test@localhost' password expire default;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE DEFAULT"},
@@ -4143,6 +4147,33 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"ALTER USER 'ttt' WITH MAX_CONNECTIONS_PER_HOUR 2;", true, "ALTER USER `ttt`@`%` WITH MAX_CONNECTIONS_PER_HOUR 2"},
 		{"ALTER USER 'ttt' WITH MAX_USER_CONNECTIONS 2;", true, "ALTER USER `ttt`@`%` WITH MAX_USER_CONNECTIONS 2"},
 		{"ALTER USER 'ttt'@'localhost' REQUIRE NONE WITH MAX_QUERIES_PER_HOUR 1 MAX_UPDATES_PER_HOUR 10 PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK;", true, "ALTER USER `ttt`@`localhost` REQUIRE NONE WITH MAX_QUERIES_PER_HOUR 1 MAX_UPDATES_PER_HOUR 10 PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK"},
+		{"create user u failed_login_attempts 3 password_lock_time 2", true, "CREATE USER `u`@`%` FAILED_LOGIN_ATTEMPTS 3 PASSWORD_LOCK_TIME 2"},
+		{"create user u password_lock_time unbounded password history 5 password reuse interval 30 day", true, "CREATE USER `u`@`%` PASSWORD_LOCK_TIME UNBOUNDED PASSWORD HISTORY 5 PASSWORD REUSE INTERVAL 30 DAY"},
+		{"create user u password history default password reuse interval default password require current", true, "CREATE USER `u`@`%` PASSWORD HISTORY DEFAULT PASSWORD REUSE INTERVAL DEFAULT PASSWORD REQUIRE CURRENT"},
+		{"alter user u password require current optional password expire account lock", true, "ALTER USER `u`@`%` PASSWORD REQUIRE CURRENT OPTIONAL PASSWORD EXPIRE ACCOUNT LOCK"},
+		{"alter user u password require current default", true, "ALTER USER `u`@`%` PASSWORD REQUIRE CURRENT DEFAULT"},
+		{"create user u password reuse interval 3", false, ""},
+		{"create user u failed_login_attempts", false, ""},
+		{"create user u failed_login_attempts 0 password_lock_time 32767", true, "CREATE USER `u`@`%` FAILED_LOGIN_ATTEMPTS 0 PASSWORD_LOCK_TIME 32767"},
+		{"create user u failed_login_attempts 32768", false, ""},
+		{"alter user u password_lock_time 32768", false, ""},
+		{"create user u identified by random password", true, "CREATE USER `u`@`%` IDENTIFIED BY RANDOM PASSWORD"},
+		{"create user u identified with 'caching_sha2_password' by random password", true, "CREATE USER `u`@`%` IDENTIFIED WITH 'caching_sha2_password' BY RANDOM PASSWORD"},
+		{"create user u identified by 'p1' and identified with authentication_ldap_sasl", true, "CREATE USER `u`@`%` IDENTIFIED BY 'p1' AND IDENTIFIED WITH 'authentication_ldap_sasl'"},
+		{"create user u identified by 'p1' and identified with 'authentication_ldap_sasl' as 'h' and identified with 'authentication_fido'", true, "CREATE USER `u`@`%` IDENTIFIED BY 'p1' AND IDENTIFIED WITH 'authentication_ldap_sasl' AS 'h' AND IDENTIFIED WITH 'authentication_fido'"},
+		{"create user u identified by 'p1' and identified by 'p2' and identified by 'p3' and identified by 'p4'", false, ""},
+		{"create user u comment 'some comment'", true, "CREATE USER `u`@`%` COMMENT 'some comment'"},
+		{`create user u password expire never attribute '{"fname": "James"}'`, true, "CREATE USER `u`@`%` PASSWORD EXPIRE NEVER ATTRIBUTE '{\"fname\": \"James\"}'"},
+		{"create user u comment 'a' attribute '{}'", false, ""},
+		{"alter user u attribute '{}'", true, "ALTER USER `u`@`%` ATTRIBUTE '{}'"},
+		{"alter user u identified by 'new' replace 'old' retain current password", true, "ALTER USER `u`@`%` IDENTIFIED BY 'new' REPLACE 'old' RETAIN CURRENT PASSWORD"},
+		{"alter user u identified with 'caching_sha2_password' by 'new' retain current password", true, "ALTER USER `u`@`%` IDENTIFIED WITH 'caching_sha2_password' BY 'new' RETAIN CURRENT PASSWORD"},
+		{"alter user u identified by random password replace 'old'", true, "ALTER USER `u`@`%` IDENTIFIED BY RANDOM PASSWORD REPLACE 'old'"},
+		{"alter user u identified with 'mysql_native_password' as 'h' retain current password", false, ""},
+		{"alter user u discard old password, v identified by 'p'", true, "ALTER USER `u`@`%` DISCARD OLD PASSWORD, `v`@`%` IDENTIFIED BY 'p'"},
+		{"create user u identified by 'new' retain current password", false, ""},
+		{"create user u discard old password", false, ""},
+		{"create table t (random int, retain int, reuse int, old int, attribute int, failed_login_attempts int, password_lock_time int)", true, "CREATE TABLE `t` (`random` INT,`retain` INT,`reuse` INT,`old` INT,`attribute` INT,`failed_login_attempts` INT,`password_lock_time` INT)"},
 		{`DROP USER 'root'@'localhost', 'root1'@'localhost'`, true, "DROP USER `root`@`localhost`, `root1`@`localhost`"},
 		{`DROP USER IF EXISTS 'root'@'localhost'`, true, "DROP USER IF EXISTS `root`@`localhost`"},
 		{`RENAME USER 'root'@'localhost' TO 'root'@'%'`, true, "RENAME USER `root`@`localhost` TO `root`@`%`"},
@@ -4207,6 +4238,16 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"REVOKE APPLICATION_PASSWORD_ADMIN,AUDIT_ADMIN ON *.* FROM 'root'@'localhost'", true, "REVOKE APPLICATION_PASSWORD_ADMIN, AUDIT_ADMIN ON *.* FROM `root`@`localhost`"},
 	}
 	s.RunTest(c, table)
+
+	p := parser.New()
+	_, _, err := p.Parse("create user u failed_login_attempts 32768", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1525\]Incorrect failed_login_attempts value: '32768'`)
+	_, _, err = p.Parse("alter user u password_lock_time 40000", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1525\]Incorrect password_lock_time value: '40000'`)
+	_, warns, err := p.Parse("create user u failed_login_attempts 3", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(warns, HasLen, 1)
+	c.Assert(warns[0], ErrorMatches, ".*TiDB does not support PASSWORD EXPIRE and ACCOUNT LOCK now, they would be parsed but ignored.*")
 }
 
 func (s *testParserSuite) TestComment(c *C) {
@@ -6191,6 +6232,37 @@ func (s *testParserSuite) TestAsOfClause(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestSystemTimeClause(c *C) {
+	table := []testCase{
+		{"select * from t for system_time as of timestamp '2016-10-09 08:07:06'", true, "SELECT * FROM `t` FOR SYSTEM_TIME AS OF TIMESTAMP _UTF8MB4'2016-10-09 08:07:06'"},
+		{"select * from t for system_time as of '2016-10-09 08:07:06' as x", true, "SELECT * FROM `t` FOR SYSTEM_TIME AS OF _UTF8MB4'2016-10-09 08:07:06' AS `x`"},
+		{"select * from t for system_time as of transaction @trx", true, "SELECT * FROM `t` FOR SYSTEM_TIME AS OF TRANSACTION @`trx`"},
+		{"select * from t for system_time as of timestamp timestamp '2016-10-09 08:07:06'", true, "SELECT * FROM `t` FOR SYSTEM_TIME AS OF TIMESTAMP TIMESTAMP '2016-10-09 08:07:06'"},
+		{"SELECT * FROM t FOR SYSTEM_TIME BETWEEN (NOW() - INTERVAL 1 YEAR) AND NOW()", true, "SELECT * FROM `t` FOR SYSTEM_TIME BETWEEN (DATE_SUB(NOW(), INTERVAL 1 YEAR)) AND NOW()"},
+		{"select * from t for system_time between timestamp '2016-01-01' and transaction 100 where a > 1", true, "SELECT * FROM `t` FOR SYSTEM_TIME BETWEEN TIMESTAMP _UTF8MB4'2016-01-01' AND TRANSACTION 100 WHERE `a`>1"},
+		{"select * from t for system_time from '2016-01-01 00:00:00' to '2017-01-01 00:00:00'", true, "SELECT * FROM `t` FOR SYSTEM_TIME FROM _UTF8MB4'2016-01-01 00:00:00' TO _UTF8MB4'2017-01-01 00:00:00'"},
+		{"select * from t partition (p0) for system_time all x join s for system_time all on x.id = s.id", true, "SELECT * FROM `t` PARTITION(`p0`) FOR SYSTEM_TIME ALL AS `x` JOIN `s` FOR SYSTEM_TIME ALL ON `x`.`id`=`s`.`id`"},
+		{"select * from t for system_time all for update", true, "SELECT * FROM `t` FOR SYSTEM_TIME ALL FOR UPDATE"},
+		{"select * from t for update", true, "SELECT * FROM `t` FOR UPDATE"},
+		{"select system_time from t", true, "SELECT `system_time` FROM `t`"},
+		{"select * from t for system_time", false, ""},
+		{"select * from t for system_time between '2016-01-01'", false, ""},
+		{"select * from t as x for system_time all", false, ""},
+		{"show grants for system_time", true, "SHOW GRANTS FOR `system_time`@`%`"},
+		{"show grants for system_time@localhost using r", true, "SHOW GRANTS FOR `system_time`@`localhost` USING `r`@`%`"},
+		{"show grants for system_time using 'system_time'", true, "SHOW GRANTS FOR `system_time`@`%` USING `system_time`@`%`"},
+		{"set password for system_time = 'x'", true, "SET PASSWORD FOR `system_time`@`%`='x'"},
+		{"set password for 'system_time'@'%' = 'x'", true, "SET PASSWORD FOR `system_time`@`%`='x'"},
+		{"select * from t for system_time as x", false, ""},
+
+		{"create table t (a int) with system versioning", true, "CREATE TABLE `t` (`a` INT) WITH SYSTEM VERSIONING"},
+		{"create table t (a int) engine = innodb with system versioning partition by system_time limit 100 (partition p0 history, partition pn current)", true, "CREATE TABLE `t` (`a` INT) ENGINE = innodb WITH SYSTEM VERSIONING PARTITION BY SYSTEM_TIME LIMIT 100 (PARTITION `p0` HISTORY,PARTITION `pn` CURRENT)"},
+		{"create table t with system as (select 1) select * from system", true, "CREATE TABLE `t` AS WITH `system` AS (SELECT 1) SELECT * FROM `system`"},
+		{"create table t (a int) with system", false, ""},
+	}
+	s.RunTest(c, table)
+}
+
 // For `PARTITION BY [LINEAR] KEY ALGORITHM` syntax
 func (s *testParserSuite) TestPartitionKeyAlgorithm(c *C) {
 	table := []testCase{
@@ -6347,28 +6419,35 @@ func (s *testParserSuite) TestPlanRecreator(c *C) {
 
 func (s *testParserSuite) TestCharsetIntroducer(c *C) {
 	p := parser.New()
-	// `_gbk` is treated as an identifier.
-	_, _, err := p.Parse("select _gbk 'a';", "", "")
+	// `_dec8` is treated as an identifier.
+	_, _, err := p.Parse("select _dec8 'a';", "", "")
 	c.Assert(err, IsNil)
 
 	charset.AddCharset(&charset.Charset{
-		Name:             "gbk",
-		DefaultCollation: "gbk_bin",
+		Name:             "dec8",
+		DefaultCollation: "dec8_bin",
 		Collations:       map[string]*charset.Collation{},
-		Desc:             "gbk",
+		Desc:             "dec8",
 		Maxlen:           2,
 	})
-	defer charset.RemoveCharset("gbk")
-	// `_gbk` is treated as a character set.
-	_, _, err = p.Parse("select _gbk 'a';", "", "")
+	defer charset.RemoveCharset("dec8")
+	// `_dec8` is treated as a character set.
+	_, _, err = p.Parse("select _dec8 'a';", "", "")
 	c.Assert(err, NotNil)
-	c.Assert(err.Error(), Equals, "[ddl:1115]Unsupported character introducer: 'gbk'")
-	_, _, err = p.Parse("select _gbk 0x1234;", "", "")
+	c.Assert(err.Error(), Equals, "[ddl:1115]Unsupported character introducer: 'dec8'")
+	_, _, err = p.Parse("select _dec8 0x1234;", "", "")
 	c.Assert(err, NotNil)
-	c.Assert(err.Error(), Equals, "[ddl:1115]Unsupported character introducer: 'gbk'")
-	_, _, err = p.Parse("select _gbk 0b101001;", "", "")
+	c.Assert(err.Error(), Equals, "[ddl:1115]Unsupported character introducer: 'dec8'")
+	_, _, err = p.Parse("select _dec8 0b101001;", "", "")
 	c.Assert(err, NotNil)
-	c.Assert(err.Error(), Equals, "[ddl:1115]Unsupported character introducer: 'gbk'")
+	c.Assert(err.Error(), Equals, "[ddl:1115]Unsupported character introducer: 'dec8'")
+
+	// The charsets whose encodings can be decoded are supported.
+	stmts, _, err := p.Parse("select _gbk 'a', _big5 0x1234, _utf16le 0b101001;", "", "")
+	c.Assert(err, IsNil)
+	for i, cs := range []string{"gbk", "big5", "utf16le"} {
+		c.Assert(stmts[0].(*ast.SelectStmt).Fields.Fields[i].Expr.GetType().Charset, Equals, cs)
+	}
 }
 
 func (s *testParserSuite) TestGBKEncoding(c *C) {
@@ -6406,6 +6485,53 @@ func (s *testParserSuite) TestGBKEncoding(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testParserSuite) TestClientCharsetEncoding(c *C) {
+	tests := []struct {
+		charset string
+		tblName string
+		colName string
+		expr    string
+	}{
+		{"big5", "測試表", "測試列", "Big5測試用例"},
+		{"sjis", "テスト表", "ﾃｽﾄ列", "SJISテスト"},
+		{"cp932", "テスト表", "テスト列", "①②③"},
+		{"euckr", "테스트표", "테스트열", "EUC-KR테스트"},
+		{"gb18030", "测试表", "测试列", "GB18030测试𠀀€"},
+		{"latin1", "tâble", "cölumn", "Ünïcödé"},
+		{"utf16", "测试表", "测试列", "UTF16测试𠀀"},
+	}
+	p := parser.New()
+	for _, tc := range tests {
+		cmt := Commentf("%v", tc)
+		enc := charset.NewEncoding(tc.charset)
+		utf8SQL := fmt.Sprintf("create table %s (%s varchar(255) charset %s default '%s');", tc.tblName, tc.colName, tc.charset, tc.expr)
+		sql, err := enc.Encode(nil, []byte(utf8SQL))
+		c.Assert(err, IsNil, cmt)
+
+		p.SetParserConfig(parser.ParserConfig{CharsetClient: tc.charset})
+		stmt, warns, err := p.Parse(string(sql), "", "")
+		c.Assert(err, IsNil, cmt)
+		c.Assert(warns, HasLen, 0, cmt)
+		checker := &gbkEncodingChecker{}
+		_, _ = stmt[0].Accept(checker)
+		c.Assert(checker.tblName, Equals, tc.tblName, cmt)
+		c.Assert(checker.colName, Equals, tc.colName, cmt)
+		c.Assert(checker.expr, Equals, tc.expr, cmt)
+
+		sql, err = enc.Encode(nil, []byte("set names "+tc.charset))
+		c.Assert(err, IsNil, cmt)
+		_, err = p.ParseOneStmt(string(sql), "", "")
+		c.Assert(err, IsNil, cmt)
+	}
+
+	// The bytes which can't be decoded are replaced with '?'.
+	p.SetParserConfig(parser.ParserConfig{CharsetClient: "sjis"})
+	stmt, err := p.ParseOneStmt("select '\x81\x20'", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(ast.ValueExpr).GetString(), Equals, "?")
+	p.SetParserConfig(parser.ParserConfig{CharsetClient: ""})
+}
+
 type gbkEncodingChecker struct {
 	tblName string
 	colName string
@@ -6433,3 +6559,344 @@ func (g *gbkEncodingChecker) Enter(n ast.Node) (node ast.Node, skipChildren bool
 func (g *gbkEncodingChecker) Leave(n ast.Node) (node ast.Node, ok bool) {
 	return n, true
 }
+
+func (s *testParserSuite) TestReplicationStmt(c *C) {
+	table := []testCase{
+		// CHANGE REPLICATION SOURCE / CHANGE MASTER
+		{"change replication source to source_host = '127.0.0.1', source_port = 3306, source_user = 'repl', source_password = 'secret'", true, "CHANGE REPLICATION SOURCE TO SOURCE_HOST = '127.0.0.1', SOURCE_PORT = 3306, SOURCE_USER = 'repl', SOURCE_PASSWORD = 'secret'"},
+		{"CHANGE MASTER TO MASTER_HOST='h', MASTER_LOG_FILE='binlog.000001', MASTER_LOG_POS=4 FOR CHANNEL 'c1'", true, "CHANGE MASTER TO MASTER_HOST = 'h', MASTER_LOG_FILE = 'binlog.000001', MASTER_LOG_POS = 4 FOR CHANNEL 'c1'"},
+		{"change replication source to source_heartbeat_period = 1.5, source_auto_position = 1, require_row_format = true", true, "CHANGE REPLICATION SOURCE TO SOURCE_HEARTBEAT_PERIOD = 1.5, SOURCE_AUTO_POSITION = 1, REQUIRE_ROW_FORMAT = TRUE"},
+		{"change replication source to ignore_server_ids = (1, 2, 3), privilege_checks_user = null", true, "CHANGE REPLICATION SOURCE TO IGNORE_SERVER_IDS = (1,2,3), PRIVILEGE_CHECKS_USER = NULL"},
+		{"change replication source to ignore_server_ids = ()", true, "CHANGE REPLICATION SOURCE TO IGNORE_SERVER_IDS = ()"},
+		{"change replication source to privilege_checks_user = 'u'@'h', source_user = 'repl'", true, "CHANGE REPLICATION SOURCE TO PRIVILEGE_CHECKS_USER = `u`@`h`, SOURCE_USER = 'repl'"},
+		{"change master to privilege_checks_user = u@localhost for channel 'c1'", true, "CHANGE MASTER TO PRIVILEGE_CHECKS_USER = `u`@`localhost` FOR CHANNEL 'c1'"},
+		{"change replication source to require_table_primary_key_check = stream, assign_gtids_to_anonymous_transactions = off, gtid_only = on", true, "CHANGE REPLICATION SOURCE TO REQUIRE_TABLE_PRIMARY_KEY_CHECK = STREAM, ASSIGN_GTIDS_TO_ANONYMOUS_TRANSACTIONS = OFF, GTID_ONLY = ON"},
+		{"change replication source to", false, ""},
+		{"change master source_host = 'h'", false, ""},
+
+		// CHANGE REPLICATION FILTER
+		{"change replication filter replicate_do_db = (d1, `d2`), replicate_ignore_db = ()", true, "CHANGE REPLICATION FILTER REPLICATE_DO_DB = (`d1`, `d2`), REPLICATE_IGNORE_DB = ()"},
+		{"change replication filter replicate_do_table = (db1.t1, db2.t2) for channel c", true, "CHANGE REPLICATION FILTER REPLICATE_DO_TABLE = (`db1`.`t1`, `db2`.`t2`) FOR CHANNEL 'c'"},
+		{"change replication filter replicate_wild_ignore_table = ('db%.t%', 'a.b_')", true, "CHANGE REPLICATION FILTER REPLICATE_WILD_IGNORE_TABLE = ('db%.t%', 'a.b_')"},
+		{"change replication filter replicate_rewrite_db = ((db1, db2), (db3, db4))", true, "CHANGE REPLICATION FILTER REPLICATE_REWRITE_DB = ((`db1`, `db2`), (`db3`, `db4`))"},
+		{"change replication filter replicate_do_db = (db1.t1)", false, ""},
+		{"change replication filter replicate_do_table = (t1)", false, ""},
+		{"change replication filter replicate_do_db = ('db%')", false, ""},
+		{"change replication filter replicate_do_db = ((db1, db2))", false, ""},
+		{"change replication filter replicate_do_something = (db1)", false, ""},
+
+		// START / STOP REPLICA
+		{"start replica", true, "START REPLICA"},
+		{"start slave io_thread, sql_thread", true, "START SLAVE IO_THREAD, SQL_THREAD"},
+		{"start replica sql_thread until source_log_file = 'binlog.000002', source_log_pos = 120", true, "START REPLICA SQL_THREAD UNTIL SOURCE_LOG_FILE = 'binlog.000002', SOURCE_LOG_POS = 120"},
+		{"start replica until sql_after_mts_gaps user = 'u' password = 'p' default_auth = 'caching_sha2_password' plugin_dir = '/usr/lib' for channel 'c'", true, "START REPLICA UNTIL SQL_AFTER_MTS_GAPS USER = 'u' PASSWORD = 'p' DEFAULT_AUTH = 'caching_sha2_password' PLUGIN_DIR = '/usr/lib' FOR CHANNEL 'c'"},
+		{"start replica until sql_before_gtids = '3e11fa47-71ca-11e1-9e33-c80aa9429562:11-56'", true, "START REPLICA UNTIL SQL_BEFORE_GTIDS = '3e11fa47-71ca-11e1-9e33-c80aa9429562:11-56'"},
+		{"start replica until", false, ""},
+		{"stop replica", true, "STOP REPLICA"},
+		{"stop slave io_thread for channel 'c'", true, "STOP SLAVE IO_THREAD FOR CHANNEL 'c'"},
+
+		// RESET
+		{"reset master", true, "RESET MASTER"},
+		{"reset master to 1234", true, "RESET MASTER TO 1234"},
+		{"reset master to 0", false, ""},
+		{"reset replica", true, "RESET REPLICA"},
+		{"reset slave all for channel 'c'", true, "RESET SLAVE ALL FOR CHANNEL 'c'"},
+
+		// the new keywords are not reserved
+		{"create table until (channel int, filter int, io_thread int, sql_thread int, default_auth int, plugin_dir int)", true, "CREATE TABLE `until` (`channel` INT,`filter` INT,`io_thread` INT,`sql_thread` INT,`default_auth` INT,`plugin_dir` INT)"},
+	}
+
+	s.RunTest(c, table)
+}
+
+func (s *testParserSuite) TestResourceGroup(c *C) {
+	table := []testCase{
+		{"create resource group rg type = user", true, "CREATE RESOURCE GROUP `rg` TYPE = USER"},
+		{"create resource group rg type = user vcpu = 0-3 thread_priority = 5 enable", true, "CREATE RESOURCE GROUP `rg` TYPE = USER VCPU = 0-3 THREAD_PRIORITY = 5 ENABLE"},
+		{"CREATE RESOURCE GROUP rg TYPE SYSTEM VCPU 0-3, 5, 7-8 THREAD_PRIORITY -10 DISABLE", true, "CREATE RESOURCE GROUP `rg` TYPE = SYSTEM VCPU = 0-3,5,7-8 THREAD_PRIORITY = -10 DISABLE"},
+		{"create resource group rg", false, ""},
+		{"create resource group rg type = user thread_priority = 5 vcpu = 1", false, ""},
+		{"alter resource group rg vcpu = 2, 3", true, "ALTER RESOURCE GROUP `rg` VCPU = 2,3"},
+		{"alter resource group rg thread_priority = 10 disable force", true, "ALTER RESOURCE GROUP `rg` THREAD_PRIORITY = 10 DISABLE FORCE"},
+		{"alter resource group rg", true, "ALTER RESOURCE GROUP `rg`"},
+		{"drop resource group rg", true, "DROP RESOURCE GROUP `rg`"},
+		{"drop resource group rg force", true, "DROP RESOURCE GROUP `rg` FORCE"},
+		{"set resource group rg", true, "SET RESOURCE GROUP `rg`"},
+		{"set resource group rg for 14, 78, 4", true, "SET RESOURCE GROUP `rg` FOR 14, 78, 4"},
+		{"set resource = 1", true, "SET @@SESSION.`resource`=1"},
+		{"create table resource (vcpu int, thread_priority int)", true, "CREATE TABLE `resource` (`vcpu` INT,`thread_priority` INT)"},
+		{"create resource group rg type = system thread_priority = 0", true, "CREATE RESOURCE GROUP `rg` TYPE = SYSTEM THREAD_PRIORITY = 0"},
+		{"create resource group rg type = user thread_priority = 19", true, "CREATE RESOURCE GROUP `rg` TYPE = USER THREAD_PRIORITY = 19"},
+		{"alter resource group rg thread_priority = -20 enable", true, "ALTER RESOURCE GROUP `rg` THREAD_PRIORITY = -20 ENABLE"},
+		{"alter resource group rg vcpu = 3-3", true, "ALTER RESOURCE GROUP `rg` VCPU = 3"},
+	}
+
+	s.RunTest(c, table)
+
+	p := parser.New()
+	errTests := []struct {
+		sql string
+		err string
+	}{
+		{"alter resource group rg force", `\[parser:3662\]Option FORCE invalid as DISABLE option is not specified.`},
+		{"alter resource group rg enable force", `\[parser:3662\].*`},
+		{"create resource group rg type = user vcpu = 3-1", `\[parser:3653\]Invalid VCPU range 3-1.`},
+		{"alter resource group rg vcpu = 0-3, 8-4", `\[parser:3653\]Invalid VCPU range 8-4.`},
+		{"create resource group rg type = user thread_priority = -1", `\[parser:3654\]Invalid thread priority value -1 for USER resource group rg. Allowed range is \[0, 19\].`},
+		{"create resource group rg type = system thread_priority = 1", `\[parser:3654\]Invalid thread priority value 1 for SYSTEM resource group rg. Allowed range is \[-20, 0\].`},
+		{"alter resource group rg thread_priority = 20", `\[parser:3654\]Invalid thread priority value 20 for USER resource group rg. Allowed range is \[0, 19\].`},
+		{"alter resource group rg thread_priority = -21", `\[parser:3654\]Invalid thread priority value -21 for SYSTEM resource group rg. Allowed range is \[-20, 0\].`},
+	}
+	for _, t := range errTests {
+		_, _, err := p.Parse(t.sql, "", "")
+		c.Assert(err, ErrorMatches, t.err, Commentf("%s", t.sql))
+	}
+}
+
+func (s *testParserSuite) TestSignalStmt(c *C) {
+	table := []testCase{
+		{"signal sqlstate '45000'", true, "SIGNAL SQLSTATE '45000'"},
+		{"signal sqlstate value '45000' set message_text = 'error', mysql_errno = 1001", true, "SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = _UTF8MB4'error', MYSQL_ERRNO = 1001"},
+		{"SIGNAL my_error SET class_origin = @a, table_name = @@tidb_snapshot", true, "SIGNAL `my_error` SET CLASS_ORIGIN = @`a`, TABLE_NAME = @@`tidb_snapshot`"},
+		{"signal sqlstate '01000' set message_text = 'warn', constraint_catalog = -1", true, "SIGNAL SQLSTATE '01000' SET MESSAGE_TEXT = _UTF8MB4'warn', CONSTRAINT_CATALOG = -1"},
+		{"signal", false, ""},
+		{"signal sqlstate '00000'", false, ""},
+		{"signal sqlstate '4500'", false, ""},
+		{"signal sqlstate 'hy000'", false, ""},
+		{"signal sqlstate '45000' set returned_sqlstate = '45000'", false, ""},
+		{"signal sqlstate '45000' set message_text = 'a', message_text = 'b'", false, ""},
+		{"signal sqlstate '45000' set unknown_item = 1", false, ""},
+		{"signal sqlstate '45000' set message_text = concat('a', 'b')", false, ""},
+		{"resignal", true, "RESIGNAL"},
+		{"resignal set message_text = 'again'", true, "RESIGNAL SET MESSAGE_TEXT = _UTF8MB4'again'"},
+		{"resignal sqlstate '45000' set mysql_errno = 5", true, "RESIGNAL SQLSTATE '45000' SET MYSQL_ERRNO = 5"},
+		{"signal sqlstate '45000' set mysql_errno = 65535, message_text = @m", true, "SIGNAL SQLSTATE '45000' SET MYSQL_ERRNO = 65535, MESSAGE_TEXT = @`m`"},
+
+		{"get diagnostics @n = number, @r = row_count", true, "GET DIAGNOSTICS @`n` = NUMBER, @`r` = ROW_COUNT"},
+		{"get current diagnostics condition 1 @s = returned_sqlstate, @m = message_text", true, "GET CURRENT DIAGNOSTICS CONDITION 1 @`s` = RETURNED_SQLSTATE, @`m` = MESSAGE_TEXT"},
+		{"GET STACKED DIAGNOSTICS CONDITION @i @e = MYSQL_ERRNO", true, "GET STACKED DIAGNOSTICS CONDITION @`i` @`e` = MYSQL_ERRNO"},
+		{"get diagnostics @n = message_text", false, ""},
+		{"get diagnostics condition 1 @n = number", false, ""},
+		{"get diagnostics", false, ""},
+		{"create table signal (get int, diagnostics int, stacked int, sqlstate int, `condition` int)", true, "CREATE TABLE `signal` (`get` INT,`diagnostics` INT,`stacked` INT,`sqlstate` INT,`condition` INT)"},
+	}
+
+	s.RunTest(c, table)
+
+	p := parser.New()
+	_, _, err := p.Parse("signal sqlstate '00001'", "", "")
+	c.Assert(parser.ErrSpBadSQLState.Equal(err), IsTrue, Commentf("%v", err))
+	_, _, err = p.Parse("signal sqlstate '00000'", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1407\]Bad SQLSTATE: '00000'`)
+	_, _, err = p.Parse("resignal sqlstate value '00000' set message_text = 'a'", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1407\]Bad SQLSTATE: '00000'`)
+	_, _, err = p.Parse("signal sqlstate '45000' set mysql_errno = 0", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1231\]Variable 'MYSQL_ERRNO' can't be set to the value of '0'`)
+	_, _, err = p.Parse("signal sqlstate '45000' set mysql_errno = -1", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1231\]Variable 'MYSQL_ERRNO' can't be set to the value of '-1'`)
+	_, _, err = p.Parse("resignal set mysql_errno = 65536", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1231\]Variable 'MYSQL_ERRNO' can't be set to the value of '65536'`)
+	_, _, err = p.Parse("signal sqlstate '45000' set mysql_errno = 'a'", "", "")
+	c.Assert(err, ErrorMatches, `\[parser:1231\]Variable 'MYSQL_ERRNO' can't be set to the value of 'a'`)
+	_, _, err = p.Parse("signal sqlstate '45000' set mysql_errno = 1, mysql_errno = 2", "", "")
+	c.Assert(parser.ErrDupSignalSet.Equal(err), IsTrue, Commentf("%v", err))
+}
+
+func (s *testParserSuite) TestLiteralCharsetCheck(c *C) {
+	p := parser.New()
+	p.SetParserConfig(parser.ParserConfig{EnableWindowFunction: true, EnableStrictDoubleTypeCheck: true, EnableLiteralCharsetCheck: true})
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"select _utf8mb4'abc', _utf8mb4 x'e4b8ad', _utf8 0xc3a9, _ascii b'1100001', _latin1 x'81e9'", ""},
+		{"select _binary x'ff', x'ff', 'a' collate utf8mb4_bin, _latin1'a' collate latin1_bin", ""},
+		{"select (_ascii'a') collate ascii_bin, x'61' collate binary, a collate latin1_bin from t", ""},
+		{"select _gbk'abc', _gbk X'C4E3', _gbk 0xc4e3 collate gbk_chinese_ci, _utf16le x'6100'", ""},
+		{"select _gbk x'c4'", `\[parser:1300\]Invalid gbk character string: 'C4' \(line 1 column 8\)`},
+		{"select _gbk'a' collate utf8mb4_bin", `\[parser:1253\]COLLATION 'utf8mb4_bin' is not valid for CHARACTER SET 'gbk' \(line 1 column 8\)`},
+		{"select _utf8mb4 x'ff'", `\[parser:1300\]Invalid utf8mb4 character string: 'FF' \(line 1 column 8\)`},
+		{"select 1,\n  _utf8mb4 x'61e4b8'", `\[parser:1300\]Invalid utf8mb4 character string: 'E4' \(line 2 column 3\)`},
+		{"select _utf8 x'f09f9883'", `\[parser:1300\]Invalid utf8 character string: 'F09F98'.*`},
+		{"select _ascii x'80'", `\[parser:1300\]Invalid ascii character string: '80'.*`},
+		{"select _utf8mb4 b'11111111'", `\[parser:1300\]Invalid utf8mb4 character string: 'FF'.*`},
+		{"select _latin1'a' collate utf8mb4_bin", `\[parser:1253\]COLLATION 'utf8mb4_bin' is not valid for CHARACTER SET 'latin1' \(line 1 column 8\)`},
+		{"select 'a' collate latin1_bin", `\[parser:1253\]COLLATION 'latin1_bin' is not valid for CHARACTER SET 'utf8mb4'.*`},
+		{"select x'61' collate utf8mb4_bin", `\[parser:1253\]COLLATION 'utf8mb4_bin' is not valid for CHARACTER SET 'binary'.*`},
+		{"select (('a')) collate latin1_bin", `\[parser:1253\].*`},
+	}
+	for _, t := range tests {
+		_, _, err := p.Parse(t.sql, "", "")
+		if t.err == "" {
+			c.Assert(err, IsNil, Commentf("%s", t.sql))
+			continue
+		}
+		c.Assert(err, ErrorMatches, t.err, Commentf("%s", t.sql))
+	}
+
+	_, _, err := p.Parse("select _utf8mb4 x'ff'", "", "")
+	c.Assert(terror.ErrorEqual(err, parser.ErrInvalidCharacterString), IsTrue)
+	c.Assert(errors.Cause(err).(*terror.Error).Code(), Equals, errors.ErrCode(mysql.ErrInvalidCharacterString))
+	_, _, err = p.Parse("select 'a' collate latin1_bin", "", "")
+	c.Assert(terror.ErrorEqual(err, parser.ErrCollationCharsetMismatch), IsTrue)
+
+	// The invalid characters are warnings and replaced with '?' if the SQL mode isn't strict.
+	p.SetSQLMode(mysql.ModeNone)
+	stmts, warns, err := p.Parse("select _utf8mb4 x'61ff62'", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(warns, HasLen, 1)
+	c.Assert(terror.ErrorEqual(warns[0], parser.ErrInvalidCharacterString), IsTrue)
+	c.Assert(warns[0].Error(), Equals, "[parser:1300]Invalid utf8mb4 character string: 'FF' (line 1 column 8)")
+	c.Assert(warns[0].(*parser.PositionError).Offset, Equals, 7)
+	c.Assert(stmts[0].(*ast.SelectStmt).Fields.Fields[0].Expr.(ast.ValueExpr).GetString(), Equals, "a\xffb")
+
+	// The literals are converted to utf8mb4 strings.
+	p.SetParserConfig(parser.ParserConfig{EnableLiteralCharsetCheck: true, ConvertLiteralToUTF8: true})
+	stmts, warns, err = p.Parse("select _utf8mb4 x'61ff62', _latin1 x'e981', _utf8mb4 0xe4b8ad, _binary x'ff', _gbk X'C4E3'", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(warns, HasLen, 1)
+	fields := stmts[0].(*ast.SelectStmt).Fields.Fields
+	c.Assert(fields[0].Expr.(ast.ValueExpr).GetString(), Equals, "a?b")
+	c.Assert(fields[1].Expr.(ast.ValueExpr).GetString(), Equals, "é\u0081")
+	c.Assert(fields[1].Expr.GetType().Charset, Equals, "utf8mb4")
+	c.Assert(fields[1].Expr.GetType().Collate, Equals, "utf8mb4_bin")
+	c.Assert(fields[2].Expr.(ast.ValueExpr).GetString(), Equals, "中")
+	c.Assert(fields[3].Expr.(ast.ValueExpr).GetString(), Equals, "\xff")
+	c.Assert(fields[3].Expr.GetType().Charset, Equals, "binary")
+	c.Assert(fields[4].Expr.(ast.ValueExpr).GetString(), Equals, "你")
+	var sb strings.Builder
+	c.Assert(fields[1].Expr.Restore(NewRestoreCtx(DefaultRestoreFlags, &sb)), IsNil)
+	c.Assert(sb.String(), Equals, "_UTF8MB4'é\u0081'")
+	sb.Reset()
+	c.Assert(fields[4].Expr.Restore(NewRestoreCtx(DefaultRestoreFlags, &sb)), IsNil)
+	c.Assert(sb.String(), Equals, "_UTF8MB4'你'")
+
+	// The check is disabled by default.
+	p = parser.New()
+	_, _, err = p.Parse("select _utf8mb4 x'ff', _latin1'a' collate utf8mb4_bin", "", "")
+	c.Assert(err, IsNil)
+}
+
+func (s *testParserSuite) TestValidateHints(c *C) {
+	p := parser.New()
+	tests := []struct {
+		sql   string
+		warns []string
+	}{
+		{"select /*+ hash_join(t1), merge_join(t2), read_from_storage(tiflash[t1]) */ * from t1, t2", nil},
+		{"select /*+ hash_join(a), inl_join(test.t2) */ * from t1 a, test.t2", nil},
+		{"select /*+ hash_join(t3) */ * from t1, t2", []string{`\[parser:3128\]Unresolved name t3 for hash_join hint \(line 1 column 12\)`}},
+		{"select /*+ hash_join(t1) */ * from t1 a", []string{`\[parser:3128\]Unresolved name t1 for hash_join hint.*`}},
+		{"select /*+ inl_join(db.t1) */ * from test.t1", []string{`\[parser:3128\]Unresolved name db.t1 for inl_join hint.*`}},
+		{"select /*+ read_from_storage(tikv[t1], tiflash[t3]) */ * from t1", []string{`\[parser:3128\]Unresolved name t3 for read_from_storage hint.*`}},
+
+		// The query blocks.
+		{"select /*+ hash_join(@sel_2 t2), merge_join(t3@sel_2) */ * from t1 where a in (select a from t2, t3)", nil},
+		{"select /*+ hash_join(@sel_3 t2) */ * from t1 where a in (select a from t2)", []string{`\[parser:3127\]Query block name sel_3 is not found for hash_join hint \(line 1 column 12\)`}},
+		{"select /*+ hash_join(t2@qb) */ * from t1 where a in (select a from t2)", []string{`\[parser:3127\]Query block name qb is not found for hash_join hint.*`}},
+		{"select /*+ hash_join(@qb t2) */ * from t1 where a in (select /*+ qb_name(qb) */ a from t2)", nil},
+		{"select /*+ hash_join(@sel_2 t2) */ * from t1 union select * from t2", nil},
+		{"select /*+ hash_join(d, t1) */ * from (select * from t2) d, t1", nil},
+		{"select /*+ hash_join(t2) */ * from (select * from t2) d, t1", []string{`\[parser:3128\]Unresolved name t2 for hash_join hint.*`}},
+		{"select /*+ qb_name(qb1) qb_name(qb2) */ * from t1", []string{`\[parser:3126\]Hint qb_name\(qb2\) is ignored as conflicting/duplicated \(line 1 column 25\)`}},
+		{"select /*+ qb_name(sel_2) */ * from t1 where a in (select a from t2)", []string{`\[parser:3126\]Hint qb_name\(sel_2\) is ignored.*`}},
+		{"update /*+ inl_join(t2), hash_join(@sel_1 t3) */ t1, t2 set t1.a = (select a from t3)", nil},
+		{"update /*+ hash_join(@upd_1 t3) */ t1 set a = 1", []string{`\[parser:3128\]Unresolved name t3 for hash_join hint.*`}},
+		{"delete /*+ hash_join(@del_1 t1) */ from t1", nil},
+
+		// The conflicting hints.
+		{"select /*+ hash_join(t1), merge_join(t1, t2) */ * from t1, t2", []string{`\[parser:3126\]Hint merge_join\(t1, t2\) is ignored as conflicting/duplicated \(line 1 column 27\)`}},
+		{"select /*+ tidb_hj(t1), hash_join(t1), swap_join_inputs(t1), swap_join_inputs(t1) */ * from t1, t2", nil},
+		{"select /*+ swap_join_inputs(t1), no_swap_join_inputs(t1) */ * from t1, t2", []string{`\[parser:3126\]Hint no_swap_join_inputs\(t1\).*`}},
+		{"select /*+ hash_agg(), stream_agg() */ count(*) from t1", []string{`\[parser:3126\]Hint stream_agg\(\).*`}},
+		{"select /*+ hash_agg(), stream_agg(@sel_2) */ count(*) from t1 where a in (select a from t2 group by a)", nil},
+		{"select /*+ use_index(t1, a), ignore_index(t1, b), force_index(t1, a) */ * from t1", nil},
+		{"select /*+ use_index(t1, a), ignore_index(t1, a) */ * from t1", []string{`\[parser:3126\]Hint ignore_index\(t1 a\).*`}},
+
+		// The index names.
+		{"select /*+ use_index(t1, `a `), use_index_merge(t1, LONG) */ * from t1", []string{
+			`\[parser:3128\]Unresolved name a  for use_index hint.*`,
+			`\[parser:3128\]Unresolved name i{65} for use_index_merge hint.*`,
+		}},
+
+		// The warnings are in the order of the hints.
+		{"select * from t1\nwhere a in (select /*+ hash_join(t9) */ a from t2)\nand b in (select /*+ qb_name(qb) */ b from t3 where c in (select /*+ hash_join(@qb t8) */ c from t4))", []string{
+			`\[parser:3128\]Unresolved name t9 for hash_join hint \(line 2 column 24\)`,
+			`\[parser:3128\]Unresolved name t8 for hash_join hint \(line 3 column 70\)`,
+		}},
+	}
+	for _, t := range tests {
+		sql := strings.Replace(t.sql, "LONG", strings.Repeat("i", 65), 1)
+		stmt, err := p.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, Commentf("%s", sql))
+		warns := parser.ValidateHints(sql, stmt)
+		c.Assert(warns, HasLen, len(t.warns), Commentf("%s: %v", sql, warns))
+		for i, warn := range warns {
+			c.Assert(warn, ErrorMatches, t.warns[i], Commentf("%s", sql))
+		}
+	}
+
+	sql := "select /*+ hash_join(t3) */ * from t1"
+	stmt, err := p.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil)
+	warns := parser.ValidateHints(sql, stmt)
+	c.Assert(warns, HasLen, 1)
+	c.Assert(terror.ErrorEqual(warns[0], parser.ErrWarnOptimizerHintUnresolvedName), IsTrue)
+	c.Assert(warns[0].(*parser.PositionError).Offset, Equals, 11)
+	c.Assert(stmt.(*ast.SelectStmt).TableHints[0].OriginTextPosition(), Equals, 11)
+
+	// The positions of the hints aren't recorded if the parser skips them.
+	p.SetParserConfig(parser.ParserConfig{SkipPositionRecording: true})
+	stmt, err = p.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.SelectStmt).TableHints[0].OriginTextPosition(), Equals, 0)
+	p.SetParserConfig(parser.ParserConfig{})
+	stmt, err = p.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.SelectStmt).TableHints[0].OriginTextPosition(), Equals, 11)
+}
+
+func (s *testParserSuite) TestCombinationSQLModes(c *C) {
+	// The restored SQLs follow how MySQL 5.7 parses the SQLs in the modes.
+	tests := []struct {
+		mode    string
+		src     string
+		restore string
+	}{
+		{"", `select "a" || b`, "SELECT _UTF8MB4'a' OR `b`"},
+		{"", "create table t (a real, b timestamp)", "CREATE TABLE `t` (`a` DOUBLE,`b` TIMESTAMP)"},
+		{"ANSI", `select "a" || b`, "SELECT CONCAT(`a`, `b`)"},
+		{"ANSI", "select count (*) from t", "SELECT COUNT(1) FROM `t`"},
+		{"ANSI", "create table t (a real)", "CREATE TABLE `t` (`a` FLOAT)"},
+		{"ANSI", "select cast(1 as real)", "SELECT CAST(1 AS FLOAT)"},
+		{"ORACLE", `select "a" || 'b'`, "SELECT CONCAT(`a`, _UTF8MB4'b')"},
+		{"ORACLE", "create table t (a real)", "CREATE TABLE `t` (`a` DOUBLE)"},
+		{"POSTGRESQL", `select "a" || 'b'`, "SELECT CONCAT(`a`, _UTF8MB4'b')"},
+		{"MSSQL", "select sum (a) from t", "SELECT SUM(`a`) FROM `t`"},
+		{"DB2", `select "a" from "t"`, "SELECT `a` FROM `t`"},
+		{"MAXDB", `create table "t" (a timestamp(3), b datetime)`, "CREATE TABLE `t` (`a` DATETIME(3),`b` DATETIME)"},
+		{"MYSQL40", "select not 1 between -5 and 5", "SELECT !1 BETWEEN -5 AND 5"},
+		{"MYSQL323", "select not 1 between -5 and 5", "SELECT !1 BETWEEN -5 AND 5"},
+		{"TRADITIONAL", `select "a" || b`, "SELECT _UTF8MB4'a' OR `b`"},
+	}
+	for _, t := range tests {
+		comment := Commentf("%s: %s", t.mode, t.src)
+		mode, err := mysql.GetSQLMode(t.mode)
+		c.Assert(err, IsNil, comment)
+		for _, mode := range []mysql.SQLMode{mode, mysql.Str2SQLMode[t.mode]} {
+			p := parser.New()
+			p.SetSQLMode(mode)
+			stmt, err := p.ParseOneStmt(t.src, "", "")
+			c.Assert(err, IsNil, comment)
+			var sb strings.Builder
+			c.Assert(stmt.Restore(NewRestoreCtx(DefaultRestoreFlags, &sb)), IsNil, comment)
+			c.Assert(sb.String(), Equals, t.restore, comment)
+		}
+	}
+
+	// Without IGNORE_SPACE, the function names followed by spaces aren't function calls.
+	p := parser.New()
+	p.SetSQLMode(mysql.ModeNone)
+	_, err := p.ParseOneStmt("select count (*) from t", "", "")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,823 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/types"
+)
+
+// tableAlterer applies ALTER TABLE specs to the CREATE TABLE statement of a
+// table, and builds the table again. The columns, indexes, constraints and
+// partitions which are kept or renamed keep their IDs.
+type tableAlterer struct {
+	old  *model.TableInfo
+	stmt *ast.CreateTableStmt
+	// actions are the actions of the applied specs.
+	actions []model.ActionType
+
+	// columnIDs, indexIDs and checkIDs map the current lower case names of the
+	// existing objects to their IDs.
+	columnIDs map[string]int64
+	indexIDs  map[string]int64
+	checkIDs  map[string]int64
+	// noDefault keeps the nullable columns without default value, which can't
+	// be written in a column definition.
+	noDefault map[string]struct{}
+	// truncated keeps the lower case names of the truncated partitions.
+	truncated map[string]struct{}
+	// repartitioned is set if the table is partitioned again, so that all the
+	// partitions are new.
+	repartitioned  bool
+	tiflashReplica *model.TiFlashReplicaInfo
+}
+
+func newTableAlterer(tbInfo *model.TableInfo) (*tableAlterer, error) {
+	stmt, err := BuildCreateTableStmt(tbInfo)
+	if err != nil {
+		return nil, err
+	}
+	t := &tableAlterer{
+		old:            tbInfo,
+		stmt:           stmt,
+		columnIDs:      make(map[string]int64),
+		indexIDs:       make(map[string]int64),
+		checkIDs:       make(map[string]int64),
+		noDefault:      make(map[string]struct{}),
+		truncated:      make(map[string]struct{}),
+		tiflashReplica: tbInfo.TiFlashReplica,
+	}
+	for _, col := range tbInfo.Columns {
+		if col.State != model.StatePublic {
+			continue
+		}
+		// The hidden columns of the expression indexes keep their IDs as well.
+		t.columnIDs[col.Name.L] = col.ID
+		if !col.Hidden && !mysql.HasNotNullFlag(col.Flag) && mysql.HasNoDefaultValueFlag(col.Flag) {
+			t.noDefault[col.Name.L] = struct{}{}
+		}
+	}
+	// The columns keep their charsets if the table charset is changed.
+	for _, def := range stmt.Cols {
+		col := model.FindColumnInfo(tbInfo.Columns, def.Name.Name.L)
+		if types.HasCharset(def.Tp) && def.Tp.Charset == "" {
+			def.Tp.Charset, def.Tp.Collate = col.Charset, col.Collate
+		}
+	}
+	for _, idx := range tbInfo.Indices {
+		t.indexIDs[idx.Name.L] = idx.ID
+	}
+	for _, cst := range tbInfo.Constraints {
+		t.checkIDs[cst.Name.L] = cst.ID
+	}
+	return t, nil
+}
+
+func (t *tableAlterer) tableName() string {
+	return t.stmt.Table.Name.O
+}
+
+func (t *tableAlterer) addAction(tp model.ActionType) {
+	t.actions = append(t.actions, tp)
+}
+
+func (t *tableAlterer) alter(spec *ast.AlterTableSpec) error {
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		return t.alterOptions(spec.Options)
+	case ast.AlterTableAddColumns:
+		return t.addColumns(spec)
+	case ast.AlterTableDropColumn:
+		return t.dropColumn(spec.OldColumnName.Name, spec.IfExists)
+	case ast.AlterTableModifyColumn:
+		def := spec.NewColumns[0]
+		return t.changeColumn(def.Name.Name, def, spec.Position)
+	case ast.AlterTableChangeColumn:
+		return t.changeColumn(spec.OldColumnName.Name, spec.NewColumns[0], spec.Position)
+	case ast.AlterTableRenameColumn:
+		return t.renameColumn(spec.OldColumnName.Name, spec.NewColumnName.Name)
+	case ast.AlterTableAlterColumn:
+		return t.alterColumnDefault(spec.NewColumns[0])
+	case ast.AlterTableAddConstraint:
+		return t.addConstraint(spec.Constraint)
+	case ast.AlterTableDropPrimaryKey:
+		return t.dropIndex(mysql.PrimaryKeyName, false)
+	case ast.AlterTableDropIndex:
+		return t.dropIndex(spec.Name, spec.IfExists)
+	case ast.AlterTableDropForeignKey:
+		return t.dropConstraint(ast.ConstraintForeignKey, spec.Name, spec.IfExists, model.ActionDropForeignKey)
+	case ast.AlterTableDropCheck:
+		return t.dropConstraint(ast.ConstraintCheck, spec.Constraint.Name, false, model.ActionDropCheckConstraint)
+	case ast.AlterTableAlterCheck:
+		cons := t.findConstraint(ast.ConstraintCheck, spec.Constraint.Name)
+		if cons == nil {
+			return ErrCantDropFieldOrKey.GenWithStackByArgs(spec.Constraint.Name)
+		}
+		cons.Enforced = spec.Constraint.Enforced
+		t.addAction(model.ActionAlterCheckConstraint)
+		return nil
+	case ast.AlterTableRenameIndex:
+		return t.renameIndex(spec.FromKey, spec.ToKey)
+	case ast.AlterTableIndexInvisible:
+		return t.alterIndexVisibility(spec.IndexName, spec.Visibility)
+	case ast.AlterTableAddPartitions:
+		return t.addPartitions(spec)
+	case ast.AlterTableDropPartition:
+		return t.dropPartitions(spec.PartitionNames, spec.IfExists)
+	case ast.AlterTableTruncatePartition:
+		return t.truncatePartitions(spec)
+	case ast.AlterTablePartition:
+		t.stmt.Partition = spec.Partition
+		t.repartitioned = true
+		t.addAction(model.ActionAlterTablePartitioning)
+		return nil
+	case ast.AlterTableRemovePartitioning:
+		if t.stmt.Partition == nil {
+			return ErrPartitionMgmtOnNonpartitioned
+		}
+		t.stmt.Partition = nil
+		t.addAction(model.ActionRemovePartitioning)
+		return nil
+	case ast.AlterTableSetTiFlashReplica:
+		t.tiflashReplica = nil
+		if spec.TiFlashReplica.Count > 0 {
+			t.tiflashReplica = &model.TiFlashReplicaInfo{
+				Count:          spec.TiFlashReplica.Count,
+				LocationLabels: spec.TiFlashReplica.Labels,
+			}
+		}
+		t.addAction(model.ActionSetTiFlashReplica)
+		return nil
+	case ast.AlterTableLock, ast.AlterTableAlgorithm, ast.AlterTableForce:
+		// They don't change the schema.
+		return nil
+	case ast.AlterTableCoalescePartitions:
+		// COALESCE PARTITION isn't supported by TiDB, whose partition IDs
+		// the catalog allocates.
+		return ErrNotSupportedYet.GenWithStackByArgs("ALTER TABLE COALESCE PARTITION")
+	}
+	text, _ := restoreText(spec)
+	return ErrNotSupportedYet.GenWithStackByArgs("ALTER TABLE " + text)
+}
+
+// alterOptions changes the table options. The options which are not saved in
+// the table info, e.g. ENGINE, are ignored. The placement options replace all
+// the placement options of the table, and PLACEMENT POLICY = DEFAULT removes them.
+func (t *tableAlterer) alterOptions(options []*ast.TableOption) error {
+	var charsetOpt, collateOpt *ast.TableOption
+	placementAltered := false
+	for _, op := range options {
+		if isPlacementOption(op.Tp) {
+			if !placementAltered {
+				t.removePlacementOptions()
+				t.addAction(model.ActionAlterTablePlacement)
+				placementAltered = true
+			}
+			if op.Tp != ast.TableOptionPlacementPolicy || !strings.EqualFold(op.StrValue, "default") {
+				t.setOption(op)
+			}
+			continue
+		}
+		var tp model.ActionType
+		switch op.Tp {
+		case ast.TableOptionCharset:
+			charsetOpt = op
+			continue
+		case ast.TableOptionCollate:
+			collateOpt = op
+			continue
+		case ast.TableOptionPreSplitRegion, ast.TableOptionCompression:
+			text, _ := restoreText(&ast.AlterTableSpec{Tp: ast.AlterTableOption, Options: []*ast.TableOption{op}})
+			return ErrNotSupportedYet.GenWithStackByArgs("ALTER TABLE " + text)
+		default:
+			if tp = tableOptionAction(op.Tp); tp == model.ActionNone {
+				continue
+			}
+		}
+		t.setOption(op)
+		t.addAction(tp)
+	}
+	if charsetOpt == nil && collateOpt == nil {
+		return nil
+	}
+	// The missing one of the charset and the collation is derived from the other.
+	t.removeOption(ast.TableOptionCharset)
+	t.removeOption(ast.TableOptionCollate)
+	if charsetOpt != nil {
+		t.setOption(charsetOpt)
+	}
+	if collateOpt != nil {
+		t.setOption(collateOpt)
+	}
+	if charsetOpt != nil && charsetOpt.UintValue == ast.TableOptionCharsetWithConvertTo {
+		// CONVERT TO CHARACTER SET converts the columns to the table charset.
+		for _, def := range t.stmt.Cols {
+			if types.HasCharset(def.Tp) {
+				def.Tp.Charset, def.Tp.Collate = "", ""
+			}
+		}
+	}
+	t.addAction(model.ActionModifyTableCharsetAndCollate)
+	return nil
+}
+
+func (t *tableAlterer) setOption(op *ast.TableOption) {
+	for i, o := range t.stmt.Options {
+		if o.Tp == op.Tp {
+			t.stmt.Options[i] = op
+			return
+		}
+	}
+	t.stmt.Options = append(t.stmt.Options, op)
+}
+
+func (t *tableAlterer) removePlacementOptions() {
+	opts := t.stmt.Options[:0]
+	for _, o := range t.stmt.Options {
+		if !isPlacementOption(o.Tp) {
+			opts = append(opts, o)
+		}
+	}
+	t.stmt.Options = opts
+}
+
+func (t *tableAlterer) removeOption(tp ast.TableOptionType) {
+	opts := t.stmt.Options[:0]
+	for _, o := range t.stmt.Options {
+		if o.Tp != tp {
+			opts = append(opts, o)
+		}
+	}
+	t.stmt.Options = opts
+}
+
+func (t *tableAlterer) columnOffset(name model.CIStr) int {
+	for i, def := range t.stmt.Cols {
+		if def.Name.Name.L == name.L {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertColumn inserts a column definition at a position, or at offset if the
+// position isn't given.
+func (t *tableAlterer) insertColumn(def *ast.ColumnDef, pos *ast.ColumnPosition, offset int) error {
+	if pos != nil {
+		switch pos.Tp {
+		case ast.ColumnPositionFirst:
+			offset = 0
+		case ast.ColumnPositionAfter:
+			offset = t.columnOffset(pos.RelativeColumn.Name)
+			if offset < 0 {
+				return ErrBadField.GenWithStackByArgs(pos.RelativeColumn.Name.O, t.tableName())
+			}
+			offset++
+		}
+	}
+	cols := append(t.stmt.Cols, nil)
+	copy(cols[offset+1:], cols[offset:])
+	cols[offset] = def
+	t.stmt.Cols = cols
+	return nil
+}
+
+func (t *tableAlterer) removeColumn(offset int) {
+	t.stmt.Cols = append(t.stmt.Cols[:offset], t.stmt.Cols[offset+1:]...)
+}
+
+func (t *tableAlterer) addColumns(spec *ast.AlterTableSpec) error {
+	added := 0
+	for _, def := range spec.NewColumns {
+		if t.columnOffset(def.Name.Name) >= 0 {
+			if spec.IfNotExists {
+				continue
+			}
+			return ErrDupFieldName.GenWithStackByArgs(def.Name.Name.O)
+		}
+		if err := t.insertColumn(def, spec.Position, len(t.stmt.Cols)); err != nil {
+			return err
+		}
+		added++
+	}
+	t.stmt.Constraints = append(t.stmt.Constraints, spec.NewConstraints...)
+	switch {
+	case added == 1:
+		t.addAction(model.ActionAddColumn)
+	case added > 1:
+		t.addAction(model.ActionAddColumns)
+	}
+	return nil
+}
+
+// dropColumn drops a column, and removes it from the indexes like MySQL does.
+// The indexes which have no column left are dropped.
+func (t *tableAlterer) dropColumn(name model.CIStr, ifExists bool) error {
+	offset := t.columnOffset(name)
+	if offset < 0 {
+		if ifExists {
+			return nil
+		}
+		return ErrCantDropFieldOrKey.GenWithStackByArgs(name.O)
+	}
+	if len(t.stmt.Cols) == 1 {
+		return ErrCantRemoveAllFields
+	}
+	for _, def := range t.stmt.Cols {
+		for _, opt := range def.Options {
+			if opt.Tp != ast.ColumnOptionGenerated {
+				continue
+			}
+			for _, ref := range referredColumns(opt.Expr) {
+				if ref.L == name.L {
+					return ErrDependentByGeneratedColumn.GenWithStackByArgs(name.O)
+				}
+			}
+		}
+	}
+	for _, cons := range t.stmt.Constraints {
+		for _, key := range cons.Keys {
+			if key.Expr == nil {
+				continue
+			}
+			for _, ref := range referredColumns(key.Expr) {
+				if ref.L == name.L {
+					return ErrCannotDropColumnFunctionalIndex.GenWithStackByArgs(name.O)
+				}
+			}
+		}
+	}
+	t.removeColumn(offset)
+	delete(t.columnIDs, name.L)
+	delete(t.noDefault, name.L)
+
+	constraints := t.stmt.Constraints[:0]
+	for _, cons := range t.stmt.Constraints {
+		if isIndexConstraint(cons) {
+			keys := make([]*ast.IndexPartSpecification, 0, len(cons.Keys))
+			for _, key := range cons.Keys {
+				if key.Column == nil || key.Column.Name.L != name.L {
+					keys = append(keys, key)
+				}
+			}
+			if len(keys) == 0 {
+				continue
+			}
+			cons.Keys = keys
+		}
+		constraints = append(constraints, cons)
+	}
+	t.stmt.Constraints = constraints
+	t.addAction(model.ActionDropColumn)
+	return nil
+}
+
+// changeColumn replaces the definition of a column, which may rename it.
+func (t *tableAlterer) changeColumn(name model.CIStr, def *ast.ColumnDef, pos *ast.ColumnPosition) error {
+	offset := t.columnOffset(name)
+	if offset < 0 {
+		return ErrBadField.GenWithStackByArgs(name.O, t.tableName())
+	}
+	newName := def.Name.Name
+	if newName.L != name.L && t.columnOffset(newName) >= 0 {
+		return ErrDupFieldName.GenWithStackByArgs(newName.O)
+	}
+	t.removeColumn(offset)
+	if err := t.insertColumn(def, pos, offset); err != nil {
+		return err
+	}
+	delete(t.noDefault, name.L)
+	t.renameReferences(name, newName)
+	t.addAction(model.ActionModifyColumn)
+	return nil
+}
+
+func (t *tableAlterer) renameColumn(oldName, newName model.CIStr) error {
+	offset := t.columnOffset(oldName)
+	if offset < 0 {
+		return ErrBadField.GenWithStackByArgs(oldName.O, t.tableName())
+	}
+	if newName.L != oldName.L && t.columnOffset(newName) >= 0 {
+		return ErrDupFieldName.GenWithStackByArgs(newName.O)
+	}
+	def := *t.stmt.Cols[offset]
+	def.Name = &ast.ColumnName{Name: newName}
+	t.stmt.Cols[offset] = &def
+	if _, ok := t.noDefault[oldName.L]; ok {
+		delete(t.noDefault, oldName.L)
+		t.noDefault[newName.L] = struct{}{}
+	}
+	t.renameReferences(oldName, newName)
+	t.addAction(model.ActionModifyColumn)
+	return nil
+}
+
+// columnRenameVisitor renames the columns referred by an expression.
+type columnRenameVisitor struct {
+	oldName model.CIStr
+	newName model.CIStr
+}
+
+func (v *columnRenameVisitor) Enter(n ast.Node) (ast.Node, bool) {
+	if col, ok := n.(*ast.ColumnName); ok && col.Name.L == v.oldName.L {
+		col.Name = v.newName
+	}
+	return n, false
+}
+
+func (v *columnRenameVisitor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// renameReferences renames a column in the indexes, constraints, generated
+// columns and partitioning which refer to it.
+func (t *tableAlterer) renameReferences(oldName, newName model.CIStr) {
+	if id, ok := t.columnIDs[oldName.L]; ok {
+		delete(t.columnIDs, oldName.L)
+		t.columnIDs[newName.L] = id
+	}
+	if oldName.L == newName.L {
+		return
+	}
+	v := &columnRenameVisitor{oldName: oldName, newName: newName}
+	for _, def := range t.stmt.Cols {
+		for _, opt := range def.Options {
+			if opt.Tp == ast.ColumnOptionGenerated {
+				opt.Expr.Accept(v)
+			}
+		}
+	}
+	for _, cons := range t.stmt.Constraints {
+		for _, key := range cons.Keys {
+			if key.Column != nil && key.Column.Name.L == oldName.L {
+				key.Column = &ast.ColumnName{Name: newName}
+			}
+		}
+		if cons.Expr != nil {
+			cons.Expr.Accept(v)
+		}
+	}
+	if part := t.stmt.Partition; part != nil {
+		if part.Expr != nil {
+			part.Expr.Accept(v)
+		}
+		for i, col := range part.ColumnNames {
+			if col.Name.L == oldName.L {
+				part.ColumnNames[i] = &ast.ColumnName{Name: newName}
+			}
+		}
+	}
+}
+
+// alterColumnDefault sets or drops the default value of a column.
+func (t *tableAlterer) alterColumnDefault(spec *ast.ColumnDef) error {
+	offset := t.columnOffset(spec.Name.Name)
+	if offset < 0 {
+		return ErrBadField.GenWithStackByArgs(spec.Name.Name.O, t.tableName())
+	}
+	def := *t.stmt.Cols[offset]
+	def.Options = nil
+	for _, opt := range t.stmt.Cols[offset].Options {
+		if opt.Tp != ast.ColumnOptionDefaultValue {
+			def.Options = append(def.Options, opt)
+		}
+	}
+	name := def.Name.Name.L
+	delete(t.noDefault, name)
+	if len(spec.Options) > 0 {
+		def.Options = append(def.Options, spec.Options...)
+	} else if !hasColumnOption(&def, ast.ColumnOptionNotNull) {
+		t.noDefault[name] = struct{}{}
+	}
+	t.stmt.Cols[offset] = &def
+	t.addAction(model.ActionSetDefaultValue)
+	return nil
+}
+
+func hasColumnOption(def *ast.ColumnDef, tp ast.ColumnOptionType) bool {
+	for _, opt := range def.Options {
+		if opt.Tp == tp {
+			return true
+		}
+	}
+	return false
+}
+
+func isIndexConstraint(cons *ast.Constraint) bool {
+	return cons.Tp != ast.ConstraintForeignKey && cons.Tp != ast.ConstraintCheck
+}
+
+// findIndex returns the index constraint of a name, the primary key is named PRIMARY.
+func (t *tableAlterer) findIndex(name string) *ast.Constraint {
+	for _, cons := range t.stmt.Constraints {
+		if !isIndexConstraint(cons) {
+			continue
+		}
+		if cons.Tp == ast.ConstraintPrimaryKey {
+			if strings.EqualFold(name, mysql.PrimaryKeyName) {
+				return cons
+			}
+		} else if strings.EqualFold(cons.Name, name) {
+			return cons
+		}
+	}
+	return nil
+}
+
+func (t *tableAlterer) findConstraint(tp ast.ConstraintType, name string) *ast.Constraint {
+	for _, cons := range t.stmt.Constraints {
+		if cons.Tp == tp && strings.EqualFold(cons.Name, name) {
+			return cons
+		}
+	}
+	return nil
+}
+
+func (t *tableAlterer) removeConstraint(target *ast.Constraint) {
+	constraints := t.stmt.Constraints[:0]
+	for _, cons := range t.stmt.Constraints {
+		if cons != target {
+			constraints = append(constraints, cons)
+		}
+	}
+	t.stmt.Constraints = constraints
+}
+
+func (t *tableAlterer) addConstraint(cons *ast.Constraint) error {
+	var tp model.ActionType
+	switch cons.Tp {
+	case ast.ConstraintPrimaryKey:
+		if t.findIndex(mysql.PrimaryKeyName) != nil {
+			return ErrMultiplePriKey
+		}
+		tp = model.ActionAddPrimaryKey
+	case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+		tp = model.ActionAddIndex
+	case ast.ConstraintForeignKey:
+		tp = model.ActionAddForeignKey
+	case ast.ConstraintCheck:
+		tp = model.ActionAddCheckConstraint
+	default:
+		return ErrNotSupportedYet.GenWithStackByArgs("FULLTEXT index")
+	}
+	t.stmt.Constraints = append(t.stmt.Constraints, cons)
+	t.addAction(tp)
+	return nil
+}
+
+func (t *tableAlterer) dropIndex(name string, ifExists bool) error {
+	cons := t.findIndex(name)
+	if cons == nil {
+		if ifExists {
+			return nil
+		}
+		return ErrCantDropFieldOrKey.GenWithStackByArgs(name)
+	}
+	t.removeConstraint(cons)
+	delete(t.indexIDs, strings.ToLower(name))
+	if cons.Tp == ast.ConstraintPrimaryKey {
+		t.addAction(model.ActionDropPrimaryKey)
+	} else {
+		t.addAction(model.ActionDropIndex)
+	}
+	return nil
+}
+
+func (t *tableAlterer) dropConstraint(tp ast.ConstraintType, name string, ifExists bool, action model.ActionType) error {
+	cons := t.findConstraint(tp, name)
+	if cons == nil {
+		if ifExists {
+			return nil
+		}
+		return ErrCantDropFieldOrKey.GenWithStackByArgs(name)
+	}
+	t.removeConstraint(cons)
+	delete(t.checkIDs, strings.ToLower(name))
+	t.addAction(action)
+	return nil
+}
+
+func (t *tableAlterer) renameIndex(from, to model.CIStr) error {
+	if from.L == strings.ToLower(mysql.PrimaryKeyName) || to.L == strings.ToLower(mysql.PrimaryKeyName) {
+		return ErrWrongNameForIndex.GenWithStackByArgs(mysql.PrimaryKeyName)
+	}
+	cons := t.findIndex(from.O)
+	if cons == nil {
+		return ErrKeyDoesNotExist.GenWithStackByArgs(from.O, t.tableName())
+	}
+	if from.L != to.L && t.findIndex(to.O) != nil {
+		return ErrDupKeyName.GenWithStackByArgs(to.O)
+	}
+	cons.Name = to.O
+	if id, ok := t.indexIDs[from.L]; ok {
+		delete(t.indexIDs, from.L)
+		t.indexIDs[to.L] = id
+	}
+	t.addAction(model.ActionRenameIndex)
+	return nil
+}
+
+func (t *tableAlterer) alterIndexVisibility(name model.CIStr, visibility ast.IndexVisibility) error {
+	cons := t.findIndex(name.O)
+	if cons == nil {
+		return ErrKeyDoesNotExist.GenWithStackByArgs(name.O, t.tableName())
+	}
+	option := &ast.IndexOption{}
+	if cons.Option != nil {
+		*option = *cons.Option
+	}
+	option.Visibility = visibility
+	cons.Option = option
+	t.addAction(model.ActionAlterIndexVisibility)
+	return nil
+}
+
+// rangeOrListPartition returns the partitioning of the table, which must be RANGE or LIST.
+func (t *tableAlterer) rangeOrListPartition(op string) (*ast.PartitionOptions, error) {
+	part := t.stmt.Partition
+	if part == nil {
+		return nil, ErrPartitionMgmtOnNonpartitioned
+	}
+	if part.Tp != model.PartitionTypeRange && part.Tp != model.PartitionTypeList {
+		return nil, ErrOnlyOnRangeListPartition.GenWithStackByArgs(op)
+	}
+	return part, nil
+}
+
+func (t *tableAlterer) addPartitions(spec *ast.AlterTableSpec) error {
+	part, err := t.rangeOrListPartition("ADD")
+	if err != nil {
+		return err
+	}
+	names := make(map[string]struct{}, len(part.Definitions))
+	for _, def := range part.Definitions {
+		names[def.Name.L] = struct{}{}
+	}
+	for _, def := range spec.PartDefinitions {
+		if _, ok := names[def.Name.L]; ok {
+			return ErrSameNamePartition.GenWithStackByArgs(def.Name.O)
+		}
+		names[def.Name.L] = struct{}{}
+	}
+	// The RANGE bounds are checked to be increasing when the table is built.
+	part.Definitions = append(part.Definitions, spec.PartDefinitions...)
+	t.addAction(model.ActionAddTablePartition)
+	return nil
+}
+
+func (t *tableAlterer) dropPartitions(names []model.CIStr, ifExists bool) error {
+	part, err := t.rangeOrListPartition("DROP")
+	if err != nil {
+		return err
+	}
+	dropped := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		dropped[name.L] = struct{}{}
+	}
+	defs := make([]*ast.PartitionDefinition, 0, len(part.Definitions))
+	for _, def := range part.Definitions {
+		if _, ok := dropped[def.Name.L]; ok {
+			delete(dropped, def.Name.L)
+			continue
+		}
+		defs = append(defs, def)
+	}
+	if len(dropped) > 0 && !ifExists {
+		return ErrDropPartitionNonExistent.GenWithStackByArgs("DROP")
+	}
+	if len(defs) == 0 {
+		return ErrDropLastPartition
+	}
+	if len(defs) == len(part.Definitions) {
+		return nil
+	}
+	part.Definitions = defs
+	t.addAction(model.ActionDropTablePartition)
+	return nil
+}
+
+// partitionNames returns the names of the partitions, including the ones given by PARTITIONS n.
+func partitionNames(part *ast.PartitionOptions) []model.CIStr {
+	if len(part.Definitions) == 0 {
+		names := make([]model.CIStr, 0, part.Num)
+		for i := uint64(0); i < part.Num; i++ {
+			names = append(names, model.NewCIStr(fmt.Sprintf("p%d", i)))
+		}
+		return names
+	}
+	names := make([]model.CIStr, 0, len(part.Definitions))
+	for _, def := range part.Definitions {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+func (t *tableAlterer) truncatePartitions(spec *ast.AlterTableSpec) error {
+	if t.stmt.Partition == nil {
+		return ErrPartitionMgmtOnNonpartitioned
+	}
+	existing := partitionNames(t.stmt.Partition)
+	names := spec.PartitionNames
+	if spec.OnAllPartitions {
+		names = existing
+	}
+	for _, name := range names {
+		found := false
+		for _, n := range existing {
+			found = found || n.L == name.L
+		}
+		if !found {
+			return ErrUnknownPartition.GenWithStackByArgs(name.O, t.tableName())
+		}
+		t.truncated[name.L] = struct{}{}
+	}
+	t.addAction(model.ActionTruncateTablePartition)
+	return nil
+}
+
+// build builds the altered table, allocating the IDs of the new partitions by allocID.
+func (t *tableAlterer) build(opts *BuildOptions, allocID func() int64) (*model.TableInfo, error) {
+	buildOpts := *opts
+	// The table and partition IDs are set below.
+	buildOpts.AllocID = func() int64 { return 0 }
+	tbInfo, err := BuildTableInfo(t.stmt, &buildOpts)
+	if err != nil {
+		return nil, err
+	}
+	old := t.old
+	tbInfo.ID = old.ID
+	tbInfo.TiFlashReplica = t.tiflashReplica
+
+	tbInfo.MaxColumnID = old.MaxColumnID
+	for _, col := range tbInfo.Columns {
+		if id, ok := t.columnIDs[col.Name.L]; ok {
+			col.ID = id
+		} else {
+			tbInfo.MaxColumnID++
+			col.ID = tbInfo.MaxColumnID
+		}
+		if _, ok := t.noDefault[col.Name.L]; ok {
+			col.Flag |= mysql.NoDefaultValueFlag
+		}
+	}
+	tbInfo.MaxIndexID = old.MaxIndexID
+	for _, idx := range tbInfo.Indices {
+		if id, ok := t.indexIDs[idx.Name.L]; ok {
+			idx.ID = id
+		} else {
+			tbInfo.MaxIndexID++
+			idx.ID = tbInfo.MaxIndexID
+		}
+	}
+	tbInfo.MaxConstraintID = old.MaxConstraintID
+	for _, cst := range tbInfo.Constraints {
+		if id, ok := t.checkIDs[cst.Name.L]; ok {
+			cst.ID = id
+		} else {
+			tbInfo.MaxConstraintID++
+			cst.ID = tbInfo.MaxConstraintID
+		}
+	}
+	tbInfo.MaxForeignKeyID = old.MaxForeignKeyID
+	for _, fk := range tbInfo.ForeignKeys {
+		fk.ID = 0
+		for _, oldFK := range old.ForeignKeys {
+			if oldFK.Name.L == fk.Name.L {
+				fk.ID = oldFK.ID
+			}
+		}
+		if fk.ID == 0 {
+			tbInfo.MaxForeignKeyID++
+			fk.ID = tbInfo.MaxForeignKeyID
+		}
+	}
+	if pi := tbInfo.Partition; pi != nil {
+		for i := range pi.Definitions {
+			def := &pi.Definitions[i]
+			if _, ok := t.truncated[def.Name.L]; !ok && !t.repartitioned && old.Partition != nil {
+				if oldDef := old.FindPartitionDefinitionByName(def.Name.L); oldDef != nil {
+					def.ID = oldDef.ID
+					continue
+				}
+			}
+			def.ID = allocID()
+		}
+	}
+	return tbInfo, nil
+}
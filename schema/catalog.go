@@ -0,0 +1,936 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+)
+
+// Catalog is an in-memory catalog of databases, tables, views and sequences.
+// DDL statements are applied to it like to a server without data, so that a
+// chain of migrations can be validated with no database.
+// The schema objects returned by the catalog must not be modified. The catalog
+// replaces the objects a statement changes instead of modifying them, so the
+// returned objects stay unchanged after other statements are applied.
+type Catalog struct {
+	dbs       []*model.DBInfo
+	currentDB string
+	version   int64
+	lastID    int64
+}
+
+// NewCatalog creates an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{}
+}
+
+// CurrentDB returns the database selected by the last USE statement.
+func (c *Catalog) CurrentDB() string {
+	return c.currentDB
+}
+
+// SchemaMetaVersion returns the schema version, which is increased by every schema change.
+func (c *Catalog) SchemaMetaVersion() int64 {
+	return c.version
+}
+
+// AllSchemas returns the databases in the order they are created.
+func (c *Catalog) AllSchemas() []*model.DBInfo {
+	return append([]*model.DBInfo(nil), c.dbs...)
+}
+
+// SchemaByName returns a database by name.
+func (c *Catalog) SchemaByName(name model.CIStr) (*model.DBInfo, bool) {
+	db := findDB(c.dbs, name.L)
+	return db, db != nil
+}
+
+// TableByName returns a table, view or sequence by name.
+func (c *Catalog) TableByName(schema, table model.CIStr) (*model.TableInfo, error) {
+	if db := findDB(c.dbs, schema.L); db != nil {
+		if tbl := findTable(db, table.L); tbl != nil {
+			return tbl, nil
+		}
+	}
+	return nil, ErrNoSuchTable.GenWithStackByArgs(schema.O, table.O)
+}
+
+// Apply applies a statement to the catalog, and returns the schema changes it
+// makes, one for each action. The changes which have no action type, e.g.
+// ALTER TABLE ... LOCK, are ActionNone. USE statements select the current database, and
+// the other statements which don't change the schema are ignored. The DDL
+// statements which need a server, e.g. FLASHBACK TABLE, CREATE TABLE ...
+// SELECT, and COALESCE PARTITION, which TiDB doesn't support, return
+// ErrNotSupportedYet.
+// The expressions saved in the schema objects are parsed again to alter them,
+// so a parser driver (e.g. github.com/pingcap/parser/test_driver) must be imported.
+// The catalog isn't changed if an error is returned.
+func (c *Catalog) Apply(stmt ast.StmtNode) ([]*model.SchemaDiff, error) {
+	a := &applier{
+		dbs:       append([]*model.DBInfo(nil), c.dbs...),
+		currentDB: c.currentDB,
+		lastID:    c.lastID,
+	}
+	if err := a.apply(stmt); err != nil {
+		return nil, errors.Trace(err)
+	}
+	c.dbs, c.currentDB, c.lastID = a.dbs, a.currentDB, a.lastID
+	for _, diff := range a.diffs {
+		c.version++
+		diff.Version = c.version
+	}
+	return a.diffs, nil
+}
+
+func findDB(dbs []*model.DBInfo, name string) *model.DBInfo {
+	for _, db := range dbs {
+		if db.Name.L == name {
+			return db
+		}
+	}
+	return nil
+}
+
+func findTable(db *model.DBInfo, name string) *model.TableInfo {
+	for _, tbl := range db.Tables {
+		if tbl.Name.L == name {
+			return tbl
+		}
+	}
+	return nil
+}
+
+// applier applies a statement to a copy of the catalog state.
+type applier struct {
+	dbs       []*model.DBInfo
+	currentDB string
+	lastID    int64
+	diffs     []*model.SchemaDiff
+}
+
+func (a *applier) allocID() int64 {
+	a.lastID++
+	return a.lastID
+}
+
+func (a *applier) addDiff(tp model.ActionType, schemaID, tableID int64) *model.SchemaDiff {
+	diff := &model.SchemaDiff{Type: tp, SchemaID: schemaID, TableID: tableID}
+	a.diffs = append(a.diffs, diff)
+	return diff
+}
+
+// setDB replaces the database of the same ID.
+func (a *applier) setDB(db *model.DBInfo) {
+	for i, d := range a.dbs {
+		if d.ID == db.ID {
+			a.dbs[i] = db
+			return
+		}
+	}
+}
+
+// putTable adds a table to a database, or replaces the table of the same ID.
+func (a *applier) putTable(schemaID int64, tbl *model.TableInfo) {
+	for _, d := range a.dbs {
+		if d.ID != schemaID {
+			continue
+		}
+		db := d.Copy()
+		for i, t := range db.Tables {
+			if t.ID == tbl.ID {
+				db.Tables[i] = tbl
+				a.setDB(db)
+				return
+			}
+		}
+		db.Tables = append(db.Tables, tbl)
+		a.setDB(db)
+	}
+}
+
+func (a *applier) removeTable(schemaID, tableID int64) {
+	for _, d := range a.dbs {
+		if d.ID != schemaID {
+			continue
+		}
+		db := d.Copy()
+		tables := db.Tables[:0]
+		for _, t := range db.Tables {
+			if t.ID != tableID {
+				tables = append(tables, t)
+			}
+		}
+		db.Tables = tables
+		a.setDB(db)
+	}
+}
+
+// schemaName returns the database a table name refers to.
+func (a *applier) schemaName(tn *ast.TableName) (model.CIStr, error) {
+	if tn.Schema.O != "" {
+		return tn.Schema, nil
+	}
+	if a.currentDB == "" {
+		return model.CIStr{}, ErrNoDB
+	}
+	return model.NewCIStr(a.currentDB), nil
+}
+
+// schemaForCreate returns the database an object is created in.
+func (a *applier) schemaForCreate(tn *ast.TableName) (*model.DBInfo, error) {
+	name, err := a.schemaName(tn)
+	if err != nil {
+		return nil, err
+	}
+	db := findDB(a.dbs, name.L)
+	if db == nil {
+		return nil, ErrBadDB.GenWithStackByArgs(name.O)
+	}
+	return db, nil
+}
+
+// table returns an existing table, view or sequence.
+func (a *applier) table(tn *ast.TableName) (*model.DBInfo, *model.TableInfo, error) {
+	name, err := a.schemaName(tn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if db := findDB(a.dbs, name.L); db != nil {
+		if tbl := findTable(db, tn.Name.L); tbl != nil {
+			return db, tbl, nil
+		}
+	}
+	return nil, nil, ErrNoSuchTable.GenWithStackByArgs(name.O, tn.Name.O)
+}
+
+// baseTable returns an existing table which is neither a view nor a sequence.
+func (a *applier) baseTable(tn *ast.TableName) (*model.DBInfo, *model.TableInfo, error) {
+	db, tbl, err := a.table(tn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tbl.IsView() || tbl.IsSequence() {
+		return nil, nil, ErrWrongObject.GenWithStackByArgs(db.Name.O, tbl.Name.O, "BASE TABLE")
+	}
+	return db, tbl, nil
+}
+
+func (a *applier) apply(stmt ast.StmtNode) error {
+	switch x := stmt.(type) {
+	case *ast.UseStmt:
+		if findDB(a.dbs, strings.ToLower(x.DBName)) == nil {
+			return ErrBadDB.GenWithStackByArgs(x.DBName)
+		}
+		a.currentDB = x.DBName
+		return nil
+	case *ast.CreateDatabaseStmt:
+		return a.createDatabase(x)
+	case *ast.AlterDatabaseStmt:
+		return a.alterDatabase(x)
+	case *ast.DropDatabaseStmt:
+		return a.dropDatabase(x)
+	case *ast.CreateTableStmt:
+		return a.createTable(x)
+	case *ast.DropTableStmt:
+		return a.dropTables(x)
+	case *ast.TruncateTableStmt:
+		return a.truncateTable(x)
+	case *ast.RenameTableStmt:
+		for _, t2t := range x.TableToTables {
+			if err := a.renameTable(t2t.OldTable, t2t.NewTable); err != nil {
+				return err
+			}
+		}
+		a.mergeRenameDiffs()
+		return nil
+	case *ast.AlterTableStmt:
+		return a.alterTable(x)
+	case *ast.CreateIndexStmt:
+		return a.createIndex(x)
+	case *ast.DropIndexStmt:
+		return a.alterTable(&ast.AlterTableStmt{
+			Table: x.Table,
+			Specs: []*ast.AlterTableSpec{{Tp: ast.AlterTableDropIndex, Name: x.IndexName, IfExists: x.IfExists}},
+		})
+	case *ast.CreateViewStmt:
+		return a.createView(x)
+	case *ast.CreateSequenceStmt:
+		return a.createSequence(x)
+	case *ast.AlterSequenceStmt:
+		return a.alterSequence(x)
+	case *ast.DropSequenceStmt:
+		return a.dropSequences(x)
+	case ast.DDLNode:
+		return ErrNotSupportedYet.GenWithStackByArgs(strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ast."))
+	}
+	return nil
+}
+
+// setDatabaseOptions sets the charset, collation and placement options of a database.
+func setDatabaseOptions(db *model.DBInfo, options []*ast.DatabaseOption) error {
+	var cs, co string
+	for _, op := range options {
+		switch op.Tp {
+		case ast.DatabaseOptionCharset:
+			cs = op.Value
+		case ast.DatabaseOptionCollate:
+			co = op.Value
+		case ast.DatabaseOptionPlacementPolicy:
+			db.PlacementPolicyRef = &model.PolicyRefInfo{Name: model.NewCIStr(op.Value)}
+		default:
+			// The placement options of databases and tables have the same types.
+			setPlacementOption(&db.DirectPlacementOpts, &ast.TableOption{
+				Tp:        ast.TableOptionType(op.Tp),
+				StrValue:  op.Value,
+				UintValue: op.UintValue,
+			})
+		}
+	}
+	if cs == "" && co == "" && db.Charset != "" {
+		return nil
+	}
+	var err error
+	db.Charset, db.Collate, err = resolveCharsetAndCollate(cs, co)
+	return err
+}
+
+func (a *applier) createDatabase(stmt *ast.CreateDatabaseStmt) error {
+	if err := checkIdent(stmt.Name, mysql.MaxDatabaseNameLength, ErrWrongDBName); err != nil {
+		return err
+	}
+	if findDB(a.dbs, strings.ToLower(stmt.Name)) != nil {
+		if stmt.IfNotExists {
+			return nil
+		}
+		return ErrDBCreateExists.GenWithStackByArgs(stmt.Name)
+	}
+	db := &model.DBInfo{
+		ID:    a.allocID(),
+		Name:  model.NewCIStr(stmt.Name),
+		State: model.StatePublic,
+	}
+	if err := setDatabaseOptions(db, stmt.Options); err != nil {
+		return err
+	}
+	a.dbs = append(a.dbs, db)
+	a.addDiff(model.ActionCreateSchema, db.ID, 0)
+	return nil
+}
+
+func (a *applier) alterDatabase(stmt *ast.AlterDatabaseStmt) error {
+	name := stmt.Name
+	if stmt.AlterDefaultDatabase {
+		if a.currentDB == "" {
+			return ErrNoDB
+		}
+		name = a.currentDB
+	}
+	old := findDB(a.dbs, strings.ToLower(name))
+	if old == nil {
+		return ErrBadDB.GenWithStackByArgs(name)
+	}
+	db := old.Copy()
+	if err := setDatabaseOptions(db, stmt.Options); err != nil {
+		return err
+	}
+	a.setDB(db)
+	if db.Charset != old.Charset || db.Collate != old.Collate {
+		a.addDiff(model.ActionModifySchemaCharsetAndCollate, db.ID, 0)
+	}
+	if db.PlacementPolicyRef != old.PlacementPolicyRef || db.DirectPlacementOpts != old.DirectPlacementOpts {
+		a.addDiff(model.ActionModifySchemaDefaultPlacement, db.ID, 0)
+	}
+	return nil
+}
+
+func (a *applier) dropDatabase(stmt *ast.DropDatabaseStmt) error {
+	db := findDB(a.dbs, strings.ToLower(stmt.Name))
+	if db == nil {
+		if stmt.IfExists {
+			return nil
+		}
+		return ErrDBDropExists.GenWithStackByArgs(stmt.Name)
+	}
+	dbs := a.dbs[:0]
+	for _, d := range a.dbs {
+		if d != db {
+			dbs = append(dbs, d)
+		}
+	}
+	a.dbs = dbs
+	if strings.EqualFold(a.currentDB, db.Name.O) {
+		a.currentDB = ""
+	}
+	a.addDiff(model.ActionDropSchema, db.ID, 0)
+	return nil
+}
+
+func (a *applier) buildOptions(db *model.DBInfo) *BuildOptions {
+	return &BuildOptions{Charset: db.Charset, Collate: db.Collate, AllocID: a.allocID}
+}
+
+func (a *applier) createTable(stmt *ast.CreateTableStmt) error {
+	if stmt.TemporaryKeyword != ast.TemporaryNone {
+		return ErrNotSupportedYet.GenWithStackByArgs("CREATE TEMPORARY TABLE")
+	}
+	if stmt.Select != nil {
+		return ErrNotSupportedYet.GenWithStackByArgs("CREATE TABLE ... SELECT")
+	}
+	db, err := a.schemaForCreate(stmt.Table)
+	if err != nil {
+		return err
+	}
+	if findTable(db, stmt.Table.Name.L) != nil {
+		if stmt.IfNotExists {
+			return nil
+		}
+		return ErrTableExists.GenWithStackByArgs(stmt.Table.Name.O)
+	}
+	if stmt.ReferTable != nil {
+		// CREATE TABLE ... LIKE copies the definition of the referred table.
+		_, ref, err := a.baseTable(stmt.ReferTable)
+		if err != nil {
+			return err
+		}
+		like, err := BuildCreateTableStmt(ref)
+		if err != nil {
+			return err
+		}
+		like.Table = stmt.Table
+		stmt = like
+	}
+	tbInfo, err := BuildTableInfo(stmt, a.buildOptions(db))
+	if err != nil {
+		return err
+	}
+	a.putTable(db.ID, tbInfo)
+	a.addDiff(model.ActionCreateTable, db.ID, tbInfo.ID)
+	return nil
+}
+
+// dropTables drops tables or views. Nothing is dropped if any of them doesn't exist.
+func (a *applier) dropTables(stmt *ast.DropTableStmt) error {
+	if stmt.TemporaryKeyword != ast.TemporaryNone {
+		return ErrNotSupportedYet.GenWithStackByArgs("DROP TEMPORARY TABLE")
+	}
+	var unknown []string
+	for _, tn := range stmt.Tables {
+		name, err := a.schemaName(tn)
+		if err != nil {
+			return err
+		}
+		var tbl *model.TableInfo
+		db := findDB(a.dbs, name.L)
+		if db != nil {
+			tbl = findTable(db, tn.Name.L)
+		}
+		if tbl != nil && stmt.IsView && !tbl.IsView() {
+			return ErrWrongObject.GenWithStackByArgs(db.Name.O, tbl.Name.O, "VIEW")
+		}
+		if tbl == nil || (!stmt.IsView && (tbl.IsView() || tbl.IsSequence())) {
+			unknown = append(unknown, name.O+"."+tn.Name.O)
+			continue
+		}
+		a.removeTable(db.ID, tbl.ID)
+		if tbl.IsView() {
+			a.addDiff(model.ActionDropView, db.ID, tbl.ID)
+		} else {
+			a.addDiff(model.ActionDropTable, db.ID, tbl.ID)
+		}
+	}
+	if len(unknown) > 0 && !stmt.IfExists {
+		return ErrBadTable.GenWithStackByArgs(strings.Join(unknown, ","))
+	}
+	return nil
+}
+
+// truncateTable replaces a table with an empty one of new IDs.
+func (a *applier) truncateTable(stmt *ast.TruncateTableStmt) error {
+	db, tbl, err := a.baseTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	newTbl := tbl.Clone()
+	newTbl.ID = a.allocID()
+	newTbl.AutoIncID = 0
+	if tbl.Partition != nil {
+		pi := *tbl.Partition
+		pi.Definitions = append([]model.PartitionDefinition(nil), tbl.Partition.Definitions...)
+		for i := range pi.Definitions {
+			pi.Definitions[i].ID = a.allocID()
+		}
+		newTbl.Partition = &pi
+	}
+	a.removeTable(db.ID, tbl.ID)
+	a.putTable(db.ID, newTbl)
+	diff := a.addDiff(model.ActionTruncateTable, db.ID, newTbl.ID)
+	diff.OldTableID = tbl.ID
+	return nil
+}
+
+func (a *applier) renameTable(oldName, newName *ast.TableName) error {
+	oldDB, tbl, err := a.table(oldName)
+	if err != nil {
+		return err
+	}
+	if err := checkIdent(newName.Name.O, mysql.MaxTableNameLength, ErrWrongTableName); err != nil {
+		return err
+	}
+	newDB, err := a.schemaForCreate(newName)
+	if err != nil {
+		return err
+	}
+	if findTable(newDB, newName.Name.L) != nil {
+		return ErrTableExists.GenWithStackByArgs(newName.Name.O)
+	}
+	newTbl := tbl.Clone()
+	newTbl.Name = newName.Name
+	a.removeTable(oldDB.ID, tbl.ID)
+	a.putTable(newDB.ID, newTbl)
+	diff := a.addDiff(model.ActionRenameTable, newDB.ID, tbl.ID)
+	diff.OldSchemaID = oldDB.ID
+	return nil
+}
+
+// mergeRenameDiffs merges the diffs of renaming several tables into one
+// ActionRenameTables diff, whose affected options are the other tables.
+func (a *applier) mergeRenameDiffs() {
+	if len(a.diffs) < 2 {
+		return
+	}
+	diff := a.diffs[0]
+	diff.Type = model.ActionRenameTables
+	for _, d := range a.diffs[1:] {
+		diff.AffectedOpts = append(diff.AffectedOpts, &model.AffectedOption{
+			SchemaID:    d.SchemaID,
+			TableID:     d.TableID,
+			OldSchemaID: d.OldSchemaID,
+			OldTableID:  d.TableID,
+		})
+	}
+	a.diffs = a.diffs[:1]
+}
+
+// cloneStmt copies a statement by restoring and parsing it, so that it can be
+// modified.
+func cloneStmt(stmt ast.StmtNode) (ast.StmtNode, error) {
+	var sb strings.Builder
+	if err := stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return parser.New().ParseOneStmt(sb.String(), "", "")
+}
+
+func (a *applier) alterTable(stmt *ast.AlterTableStmt) error {
+	db, tbl, err := a.baseTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	// The specs are added to the definition of the table, which may be modified.
+	clone, err := cloneStmt(stmt)
+	if err != nil {
+		return err
+	}
+	t, err := newTableAlterer(tbl)
+	if err != nil {
+		return err
+	}
+	var rename *ast.TableName
+	for _, spec := range clone.(*ast.AlterTableStmt).Specs {
+		if spec.Tp == ast.AlterTableRenameTable {
+			rename = spec.NewTable
+			continue
+		}
+		if err := t.alter(spec); err != nil {
+			return err
+		}
+	}
+	if len(t.actions) > 0 {
+		newTbl, err := t.build(a.buildOptions(db), a.allocID)
+		if err != nil {
+			return err
+		}
+		a.putTable(db.ID, newTbl)
+		for _, tp := range t.actions {
+			a.addDiff(tp, db.ID, newTbl.ID)
+		}
+	}
+	if rename != nil {
+		return a.renameTable(&ast.TableName{Schema: db.Name, Name: tbl.Name}, rename)
+	}
+	return nil
+}
+
+func (a *applier) createIndex(stmt *ast.CreateIndexStmt) error {
+	cons := &ast.Constraint{
+		Name:   stmt.IndexName,
+		Keys:   stmt.IndexPartSpecifications,
+		Option: stmt.IndexOption,
+	}
+	switch stmt.KeyType {
+	case ast.IndexKeyTypeNone:
+		cons.Tp = ast.ConstraintIndex
+	case ast.IndexKeyTypeUnique:
+		cons.Tp = ast.ConstraintUniqIndex
+	default:
+		return ErrNotSupportedYet.GenWithStackByArgs("FULLTEXT and SPATIAL index")
+	}
+	if stmt.IfNotExists {
+		_, tbl, err := a.baseTable(stmt.Table)
+		if err != nil {
+			return err
+		}
+		if tbl.FindIndexByName(strings.ToLower(stmt.IndexName)) != nil {
+			return nil
+		}
+	}
+	return a.alterTable(&ast.AlterTableStmt{
+		Table: stmt.Table,
+		Specs: []*ast.AlterTableSpec{{Tp: ast.AlterTableAddConstraint, Constraint: cons}},
+	})
+}
+
+func (a *applier) createView(stmt *ast.CreateViewStmt) error {
+	db, err := a.schemaForCreate(stmt.ViewName)
+	if err != nil {
+		return err
+	}
+	name := stmt.ViewName.Name
+	if err := checkIdent(name.O, mysql.MaxTableNameLength, ErrWrongTableName); err != nil {
+		return err
+	}
+	old := findTable(db, name.L)
+	if old != nil {
+		if !stmt.OrReplace {
+			return ErrTableExists.GenWithStackByArgs(name.O)
+		}
+		if !old.IsView() {
+			return ErrWrongObject.GenWithStackByArgs(db.Name.O, name.O, "VIEW")
+		}
+	}
+	cols, err := a.viewColumns(db, stmt)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if err := stmt.Select.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return errors.Trace(err)
+	}
+	tbInfo := &model.TableInfo{
+		Name:    name,
+		Charset: db.Charset,
+		Collate: db.Collate,
+		State:   model.StatePublic,
+		Version: model.CurrLatestTableInfoVersion,
+		View: &model.ViewInfo{
+			Algorithm:   stmt.Algorithm,
+			Definer:     stmt.Definer,
+			Security:    stmt.Security,
+			SelectStmt:  sb.String(),
+			CheckOption: stmt.CheckOption,
+			Cols:        stmt.Cols,
+		},
+	}
+	for i, col := range cols {
+		tbInfo.Columns = append(tbInfo.Columns, &model.ColumnInfo{
+			ID:     int64(i + 1),
+			Name:   col,
+			Offset: i,
+			State:  model.StatePublic,
+		})
+	}
+	tbInfo.MaxColumnID = int64(len(cols))
+	if old != nil {
+		tbInfo.ID = old.ID
+	} else {
+		tbInfo.ID = a.allocID()
+	}
+	a.putTable(db.ID, tbInfo)
+	a.addDiff(model.ActionCreateView, db.ID, tbInfo.ID)
+	return nil
+}
+
+// viewColumns returns the column names of a view. The tables in the FROM
+// clause must exist, and the wildcards are expanded to their columns.
+func (a *applier) viewColumns(db *model.DBInfo, stmt *ast.CreateViewStmt) ([]model.CIStr, error) {
+	var sel ast.Node = stmt.Select
+	for {
+		setOpr, ok := sel.(*ast.SetOprStmt)
+		if !ok || len(setOpr.SelectList.Selects) == 0 {
+			break
+		}
+		sel = setOpr.SelectList.Selects[0]
+	}
+	var cols []model.CIStr
+	if selStmt, ok := sel.(*ast.SelectStmt); ok {
+		var sources []*viewSource
+		if selStmt.From != nil {
+			var err error
+			if sources, err = a.viewSources(selStmt.From.TableRefs, sources); err != nil {
+				return nil, err
+			}
+		}
+		for _, field := range selStmt.Fields.Fields {
+			if field.WildCard == nil {
+				cols = append(cols, fieldName(field))
+				continue
+			}
+			expanded := false
+			for _, src := range sources {
+				if field.WildCard.Table.L != "" && field.WildCard.Table.L != src.name.L {
+					continue
+				}
+				if src.tbl == nil {
+					return nil, ErrNotSupportedYet.GenWithStackByArgs("wildcard of a derived table in view")
+				}
+				for _, col := range src.tbl.Columns {
+					cols = append(cols, col.Name)
+				}
+				expanded = true
+			}
+			if !expanded {
+				return nil, ErrBadTable.GenWithStackByArgs(field.WildCard.Table.O)
+			}
+		}
+	}
+	if len(stmt.Cols) > 0 {
+		if len(cols) > 0 && len(cols) != len(stmt.Cols) {
+			return nil, ErrNotSupportedYet.GenWithStackByArgs("view column list of a different length")
+		}
+		cols = stmt.Cols
+	}
+	names := make(map[string]struct{}, len(cols))
+	for _, col := range cols {
+		if _, ok := names[col.L]; ok {
+			return nil, ErrDupFieldName.GenWithStackByArgs(col.O)
+		}
+		names[col.L] = struct{}{}
+	}
+	return cols, nil
+}
+
+// viewSource is a table in the FROM clause of a view.
+type viewSource struct {
+	name model.CIStr
+	// tbl is nil for a derived table.
+	tbl *model.TableInfo
+}
+
+func (a *applier) viewSources(node ast.ResultSetNode, sources []*viewSource) ([]*viewSource, error) {
+	switch x := node.(type) {
+	case *ast.Join:
+		sources, err := a.viewSources(x.Left, sources)
+		if err != nil || x.Right == nil {
+			return sources, err
+		}
+		return a.viewSources(x.Right, sources)
+	case *ast.TableSource:
+		src := &viewSource{name: x.AsName}
+		if tn, ok := x.Source.(*ast.TableName); ok {
+			_, tbl, err := a.table(tn)
+			if err != nil {
+				return nil, err
+			}
+			src.tbl = tbl
+			if src.name.L == "" {
+				src.name = tn.Name
+			}
+		}
+		return append(sources, src), nil
+	}
+	return sources, nil
+}
+
+// fieldName returns the name of a select field, which is its alias, column
+// name or text.
+func fieldName(field *ast.SelectField) model.CIStr {
+	if field.AsName.L != "" {
+		return field.AsName
+	}
+	if col, ok := field.Expr.(*ast.ColumnNameExpr); ok {
+		return col.Name.Name
+	}
+	if text := strings.TrimSpace(field.Text()); text != "" {
+		return model.NewCIStr(text)
+	}
+	text, _ := restoreExpr(field.Expr)
+	return model.NewCIStr(text)
+}
+
+func (a *applier) createSequence(stmt *ast.CreateSequenceStmt) error {
+	db, err := a.schemaForCreate(stmt.Name)
+	if err != nil {
+		return err
+	}
+	name := stmt.Name.Name
+	if err := checkIdent(name.O, mysql.MaxTableNameLength, ErrWrongTableName); err != nil {
+		return err
+	}
+	if findTable(db, name.L) != nil {
+		if stmt.IfNotExists {
+			return nil
+		}
+		return ErrTableExists.GenWithStackByArgs(name.O)
+	}
+	seq := &model.SequenceInfo{
+		Increment:  model.DefaultSequenceIncrementValue,
+		Cache:      model.DefaultSequenceCacheBool,
+		Cycle:      model.DefaultSequenceCycleBool,
+		CacheValue: model.DefaultSequenceCacheValue,
+	}
+	if err := setSequenceOptions(seq, stmt.SeqOptions, true); err != nil {
+		return ErrSequenceInvalidData.GenWithStackByArgs(db.Name.O, name.O)
+	}
+	for _, op := range stmt.TblOptions {
+		if op.Tp == ast.TableOptionComment {
+			seq.Comment = op.StrValue
+		}
+	}
+	tbInfo := &model.TableInfo{
+		ID:       a.allocID(),
+		Name:     name,
+		Charset:  db.Charset,
+		Collate:  db.Collate,
+		State:    model.StatePublic,
+		Version:  model.CurrLatestTableInfoVersion,
+		Sequence: seq,
+	}
+	a.putTable(db.ID, tbInfo)
+	a.addDiff(model.ActionCreateSequence, db.ID, tbInfo.ID)
+	return nil
+}
+
+// setSequenceOptions sets the options of a sequence. The bounds and the start
+// value which aren't given are derived from the increment when the sequence is
+// created.
+func setSequenceOptions(seq *model.SequenceInfo, options []*ast.SequenceOption, create bool) error {
+	var hasMin, hasMax, hasStart bool
+	for _, op := range options {
+		switch op.Tp {
+		case ast.SequenceOptionIncrementBy:
+			seq.Increment = op.IntValue
+		case ast.SequenceStartWith, ast.SequenceRestartWith:
+			seq.Start, hasStart = op.IntValue, true
+		case ast.SequenceMinValue:
+			seq.MinValue, hasMin = op.IntValue, true
+		case ast.SequenceNoMinValue:
+			hasMin = false
+		case ast.SequenceMaxValue:
+			seq.MaxValue, hasMax = op.IntValue, true
+		case ast.SequenceNoMaxValue:
+			hasMax = false
+		case ast.SequenceCache:
+			seq.Cache, seq.CacheValue = true, op.IntValue
+		case ast.SequenceNoCache:
+			seq.Cache, seq.CacheValue = false, 0
+		case ast.SequenceCycle:
+			seq.Cycle = true
+		case ast.SequenceNoCycle:
+			seq.Cycle = false
+		}
+	}
+	if create {
+		if !hasMin {
+			seq.MinValue = model.DefaultPositiveSequenceMinValue
+			if seq.Increment < 0 {
+				seq.MinValue = model.DefaultNegativeSequenceMinValue
+			}
+		}
+		if !hasMax {
+			seq.MaxValue = model.DefaultPositiveSequenceMaxValue
+			if seq.Increment < 0 {
+				seq.MaxValue = model.DefaultNegativeSequenceMaxValue
+			}
+		}
+		if !hasStart {
+			seq.Start = seq.MinValue
+			if seq.Increment < 0 {
+				seq.Start = seq.MaxValue
+			}
+		}
+	}
+	if seq.Increment == 0 || seq.MinValue >= seq.MaxValue || seq.Start < seq.MinValue ||
+		seq.Start > seq.MaxValue || (seq.Cache && seq.CacheValue <= 0) {
+		return errors.New("invalid sequence options")
+	}
+	return nil
+}
+
+func (a *applier) sequence(tn *ast.TableName) (*model.DBInfo, *model.TableInfo, error) {
+	db, tbl, err := a.table(tn)
+	if err != nil {
+		if terrorNoSuchTable(err) {
+			name, _ := a.schemaName(tn)
+			return nil, nil, ErrUnknownSequence.GenWithStackByArgs(name.O + "." + tn.Name.O)
+		}
+		return nil, nil, err
+	}
+	if !tbl.IsSequence() {
+		return nil, nil, ErrNotSequence.GenWithStackByArgs(db.Name.O, tbl.Name.O)
+	}
+	return db, tbl, nil
+}
+
+func terrorNoSuchTable(err error) bool {
+	return ErrNoSuchTable.Equal(err)
+}
+
+func (a *applier) alterSequence(stmt *ast.AlterSequenceStmt) error {
+	db, tbl, err := a.sequence(stmt.Name)
+	if err != nil {
+		if stmt.IfExists && ErrUnknownSequence.Equal(err) {
+			return nil
+		}
+		return err
+	}
+	newTbl := tbl.Clone()
+	seq := *tbl.Sequence
+	if err := setSequenceOptions(&seq, stmt.SeqOptions, false); err != nil {
+		return ErrSequenceInvalidData.GenWithStackByArgs(db.Name.O, tbl.Name.O)
+	}
+	newTbl.Sequence = &seq
+	a.putTable(db.ID, newTbl)
+	a.addDiff(model.ActionAlterSequence, db.ID, newTbl.ID)
+	return nil
+}
+
+// dropSequences drops sequences. Nothing is dropped if any of them doesn't exist.
+func (a *applier) dropSequences(stmt *ast.DropSequenceStmt) error {
+	var unknown []string
+	for _, tn := range stmt.Sequences {
+		db, tbl, err := a.sequence(tn)
+		if err != nil {
+			if !ErrUnknownSequence.Equal(err) {
+				return err
+			}
+			name, _ := a.schemaName(tn)
+			unknown = append(unknown, name.O+"."+tn.Name.O)
+			continue
+		}
+		a.removeTable(db.ID, tbl.ID)
+		a.addDiff(model.ActionDropSequence, db.ID, tbl.ID)
+	}
+	if len(unknown) > 0 && !stmt.IfExists {
+		return ErrUnknownSequence.GenWithStackByArgs(strings.Join(unknown, ","))
+	}
+	return nil
+}
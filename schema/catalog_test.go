@@ -0,0 +1,309 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/schema"
+	"github.com/pingcap/parser/terror"
+)
+
+var _ = Suite(&testCatalogSuite{})
+
+type testCatalogSuite struct {
+	*parser.Parser
+}
+
+func (s *testCatalogSuite) SetUpSuite(c *C) {
+	s.Parser = parser.New()
+}
+
+func (s *testCatalogSuite) apply(c *C, catalog *schema.Catalog, sql string) ([]*model.SchemaDiff, error) {
+	stmts, _, err := s.Parse(sql, "", "")
+	c.Assert(err, IsNil, Commentf("%s", sql))
+	var diffs []*model.SchemaDiff
+	for _, stmt := range stmts {
+		d, err := catalog.Apply(stmt)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d...)
+	}
+	return diffs, nil
+}
+
+func (s *testCatalogSuite) mustApply(c *C, catalog *schema.Catalog, sql string) []*model.SchemaDiff {
+	diffs, err := s.apply(c, catalog, sql)
+	c.Assert(err, IsNil, Commentf("%s", sql))
+	return diffs
+}
+
+func (s *testCatalogSuite) table(c *C, catalog *schema.Catalog, db, tbl string) *model.TableInfo {
+	tbInfo, err := catalog.TableByName(model.NewCIStr(db), model.NewCIStr(tbl))
+	c.Assert(err, IsNil)
+	return tbInfo
+}
+
+func diffTypes(diffs []*model.SchemaDiff) []model.ActionType {
+	types := make([]model.ActionType, 0, len(diffs))
+	for _, diff := range diffs {
+		types = append(types, diff.Type)
+	}
+	return types
+}
+
+func (s *testCatalogSuite) TestCreateAndAlter(c *C) {
+	catalog := schema.NewCatalog()
+	diffs := s.mustApply(c, catalog, "create database test charset latin1; use test; create table t (a int primary key, b varchar(10))")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionCreateSchema, model.ActionCreateTable})
+	c.Assert(diffs[1].Version, Equals, int64(2))
+	c.Assert(catalog.CurrentDB(), Equals, "test")
+
+	db, ok := catalog.SchemaByName(model.NewCIStr("TEST"))
+	c.Assert(ok, IsTrue)
+	c.Assert(db.Charset, Equals, "latin1")
+	tbl := s.table(c, catalog, "test", "t")
+	c.Assert(tbl.ID, Equals, diffs[1].TableID)
+	c.Assert(tbl.Charset, Equals, "latin1")
+	oldCols := tbl.Columns
+
+	diffs = s.mustApply(c, catalog, "alter table t add column c int after a, add index idx (b), comment 'x'")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionAddColumn, model.ActionAddIndex, model.ActionModifyTableComment})
+	for _, diff := range diffs {
+		c.Assert(diff.SchemaID, Equals, db.ID)
+		c.Assert(diff.TableID, Equals, tbl.ID)
+	}
+	c.Assert(catalog.SchemaMetaVersion(), Equals, int64(5))
+	newTbl := s.table(c, catalog, "test", "t")
+	c.Assert(newTbl.ID, Equals, tbl.ID)
+	c.Assert(newTbl.Comment, Equals, "x")
+	c.Assert(newTbl.Columns, HasLen, 3)
+	c.Assert(newTbl.Columns[1].Name.L, Equals, "c")
+	c.Assert(newTbl.Columns[2].ID, Equals, oldCols[1].ID)
+	c.Assert(newTbl.Columns[1].ID, Equals, int64(3))
+	c.Assert(newTbl.Indices, HasLen, 1)
+	// The old table isn't modified.
+	c.Assert(tbl.Columns, HasLen, 2)
+	c.Assert(tbl.Comment, Equals, "")
+
+	diffs = s.mustApply(c, catalog, "create index idx2 on t (c); drop index idx on t; alter table t drop column c")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionAddIndex, model.ActionDropIndex, model.ActionDropColumn})
+	newTbl = s.table(c, catalog, "test", "t")
+	c.Assert(newTbl.Columns, HasLen, 2)
+	c.Assert(newTbl.Indices, HasLen, 0)
+
+	diffs = s.mustApply(c, catalog, "create table t2 like t; alter table t2 rename to t3")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionCreateTable, model.ActionRenameTable})
+	t3 := s.table(c, catalog, "test", "t3")
+	c.Assert(t3.ID, Not(Equals), tbl.ID)
+	c.Assert(t3.Columns, HasLen, 2)
+
+	// The IDs of the dropped foreign keys aren't reused.
+	s.mustApply(c, catalog, "create table t4 (a int, constraint fk1 foreign key (a) references t (a), constraint fk2 foreign key (a) references t (a))")
+	s.mustApply(c, catalog, "alter table t4 drop foreign key fk2")
+	s.mustApply(c, catalog, "alter table t4 add constraint fk3 foreign key (a) references t (a)")
+	t4 := s.table(c, catalog, "test", "t4")
+	c.Assert(t4.ForeignKeys, HasLen, 2)
+	c.Assert(t4.ForeignKeys[1].ID, Equals, int64(3))
+	c.Assert(t4.MaxForeignKeyID, Equals, int64(3))
+
+	// The placement options of an ALTER TABLE replace the old ones.
+	diffs = s.mustApply(c, catalog, "alter table t4 placement policy = `p1`")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionAlterTablePlacement})
+	t4 = s.table(c, catalog, "test", "t4")
+	c.Assert(t4.PlacementPolicyRef.Name.L, Equals, "p1")
+	s.mustApply(c, catalog, "alter table t4 followers = 2 primary_region = 'r1'")
+	t4 = s.table(c, catalog, "test", "t4")
+	c.Assert(t4.PlacementPolicyRef, IsNil)
+	c.Assert(t4.DirectPlacementOpts.Followers, Equals, uint64(2))
+	c.Assert(t4.DirectPlacementOpts.PrimaryRegion, Equals, "r1")
+	s.mustApply(c, catalog, "alter table t4 placement policy = `p1`; alter table t4 placement policy = `DEFAULT`")
+	t4 = s.table(c, catalog, "test", "t4")
+	c.Assert(t4.PlacementPolicyRef, IsNil)
+	c.Assert(t4.DirectPlacementOpts, IsNil)
+}
+
+func (s *testCatalogSuite) TestRenameDropTruncate(c *C) {
+	catalog := schema.NewCatalog()
+	s.mustApply(c, catalog, "create database d1; create database d2; create table d1.t1 (a int); create table d1.t2 (a int) partition by hash (a) partitions 2")
+	t1 := s.table(c, catalog, "d1", "t1")
+
+	diffs := s.mustApply(c, catalog, "rename table d1.t1 to d2.t1")
+	c.Assert(diffs, HasLen, 1)
+	c.Assert(diffs[0].Type, Equals, model.ActionRenameTable)
+	c.Assert(diffs[0].TableID, Equals, t1.ID)
+	c.Assert(diffs[0].SchemaID, Not(Equals), diffs[0].OldSchemaID)
+	c.Assert(s.table(c, catalog, "d2", "t1").ID, Equals, t1.ID)
+
+	diffs = s.mustApply(c, catalog, "rename table d2.t1 to d1.t3, d1.t2 to d2.t2")
+	c.Assert(diffs, HasLen, 1)
+	c.Assert(diffs[0].Type, Equals, model.ActionRenameTables)
+	c.Assert(diffs[0].AffectedOpts, HasLen, 1)
+
+	t2 := s.table(c, catalog, "d2", "t2")
+	diffs = s.mustApply(c, catalog, "truncate table d2.t2")
+	c.Assert(diffs, HasLen, 1)
+	c.Assert(diffs[0].Type, Equals, model.ActionTruncateTable)
+	c.Assert(diffs[0].OldTableID, Equals, t2.ID)
+	newT2 := s.table(c, catalog, "d2", "t2")
+	c.Assert(newT2.ID, Equals, diffs[0].TableID)
+	c.Assert(newT2.Partition.Definitions[0].ID, Not(Equals), t2.Partition.Definitions[0].ID)
+
+	diffs = s.mustApply(c, catalog, "drop table d1.t3, d2.t2; drop database d2")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionDropTable, model.ActionDropTable, model.ActionDropSchema})
+	c.Assert(catalog.AllSchemas(), HasLen, 1)
+}
+
+func (s *testCatalogSuite) TestPartitioning(c *C) {
+	catalog := schema.NewCatalog()
+	s.mustApply(c, catalog, "create database test; use test; create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20))")
+	tbl := s.table(c, catalog, "test", "t")
+
+	for _, sql := range []string{
+		"alter table t add partition (partition p1 values less than (30))",
+		"alter table t add partition (partition p2 values less than (30), partition P2 values less than (40))",
+	} {
+		_, err := s.apply(c, catalog, sql)
+		c.Assert(terror.ErrorEqual(err, schema.ErrSameNamePartition), IsTrue, Commentf("%s: %v", sql, err))
+	}
+	for _, sql := range []string{
+		"alter table t add partition (partition p2 values less than (15))",
+		"alter table t add partition (partition p2 values less than (20))",
+		"alter table t add partition (partition p2 values less than maxvalue, partition p3 values less than (40))",
+	} {
+		_, err := s.apply(c, catalog, sql)
+		c.Assert(terror.ErrorEqual(err, schema.ErrRangeNotIncreasing), IsTrue, Commentf("%s: %v", sql, err))
+	}
+	_, err := s.apply(c, catalog, "create table t2 (a int, b int) partition by range columns (a, b) (partition p0 values less than (1, 10), partition p1 values less than (1, 5))")
+	c.Assert(terror.ErrorEqual(err, schema.ErrRangeNotIncreasing), IsTrue)
+	s.mustApply(c, catalog, "create table t2 (a int, b int) partition by range columns (a, b) (partition p0 values less than (1, 10), partition p1 values less than (2, 5), partition p2 values less than (maxvalue, maxvalue))")
+
+	diffs := s.mustApply(c, catalog, "alter table t partition by range (a) (partition p0 values less than (10), partition p1 values less than (15), partition p2 values less than (20))")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionAlterTablePartitioning})
+	newTbl := s.table(c, catalog, "test", "t")
+	c.Assert(newTbl.Partition.Definitions, HasLen, 3)
+	c.Assert(newTbl.Partition.Definitions[0].ID, Not(Equals), tbl.Partition.Definitions[0].ID)
+
+	diffs = s.mustApply(c, catalog, "alter table t remove partitioning")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionRemovePartitioning})
+	c.Assert(s.table(c, catalog, "test", "t").Partition, IsNil)
+
+	s.mustApply(c, catalog, "create table t3 (a int) partition by hash (a) partitions 4")
+	_, err = s.apply(c, catalog, "alter table t3 coalesce partition 2")
+	c.Assert(terror.ErrorEqual(err, schema.ErrNotSupportedYet), IsTrue)
+}
+
+func (s *testCatalogSuite) TestViewAndSequence(c *C) {
+	catalog := schema.NewCatalog()
+	s.mustApply(c, catalog, "create database test; use test; create table t (a int, b int)")
+
+	diffs := s.mustApply(c, catalog, "create view v as select *, a + 1, b as c from t")
+	c.Assert(diffs, HasLen, 1)
+	c.Assert(diffs[0].Type, Equals, model.ActionCreateView)
+	v := s.table(c, catalog, "test", "v")
+	c.Assert(v.IsView(), IsTrue)
+	names := make([]string, 0, len(v.Columns))
+	for _, col := range v.Columns {
+		names = append(names, col.Name.O)
+	}
+	c.Assert(names, DeepEquals, []string{"a", "b", "a + 1", "c"})
+
+	diffs = s.mustApply(c, catalog, "create or replace view v (x) as select a from t")
+	c.Assert(diffs[0].TableID, Equals, v.ID)
+	c.Assert(s.table(c, catalog, "test", "v").Columns[0].Name.O, Equals, "x")
+
+	diffs = s.mustApply(c, catalog, "create sequence seq increment by -2; alter sequence seq cache 10")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionCreateSequence, model.ActionAlterSequence})
+	seq := s.table(c, catalog, "test", "seq").Sequence
+	c.Assert(seq.Increment, Equals, int64(-2))
+	c.Assert(seq.Start, Equals, model.DefaultNegativeSequenceMaxValue)
+	c.Assert(seq.CacheValue, Equals, int64(10))
+
+	diffs = s.mustApply(c, catalog, "drop view v; drop sequence seq")
+	c.Assert(diffTypes(diffs), DeepEquals, []model.ActionType{model.ActionDropView, model.ActionDropSequence})
+}
+
+func (s *testCatalogSuite) TestErrors(c *C) {
+	cases := []struct {
+		sql string
+		err *terror.Error
+	}{
+		{"create table t2 (a int)", schema.ErrNoDB},
+		{"use test2", schema.ErrBadDB},
+		{"create database test", schema.ErrDBCreateExists},
+		{"drop database test2", schema.ErrDBDropExists},
+		{"create table test.t (a int)", schema.ErrTableExists},
+		{"create table test2.t (a int)", schema.ErrBadDB},
+		{"create table test.t2 (a int, a int)", schema.ErrDupFieldName},
+		{"create table test.t2 select 1", schema.ErrNotSupportedYet},
+		{"alter table test.t2 add column c int", schema.ErrNoSuchTable},
+		{"alter table test.t add column a int", schema.ErrDupFieldName},
+		{"alter table test.t drop column c", schema.ErrCantDropFieldOrKey},
+		{"alter table test.t drop column a, drop column b", schema.ErrCantRemoveAllFields},
+		{"alter table test.t drop index idx", schema.ErrCantDropFieldOrKey},
+		{"alter table test.t add primary key (b)", schema.ErrMultiplePriKey},
+		{"alter table test.t rename index idx to idx2", schema.ErrKeyDoesNotExist},
+		{"alter table test.t drop partition p0", schema.ErrPartitionMgmtOnNonpartitioned},
+		{"alter table test.t remove partitioning", schema.ErrPartitionMgmtOnNonpartitioned},
+		{"alter table test.t modify column c int", schema.ErrBadField},
+		{"alter table test.v add column c int", schema.ErrWrongObject},
+		{"drop table test.t, test.t2", schema.ErrBadTable},
+		{"drop view test.t", schema.ErrWrongObject},
+		{"drop table test.v", schema.ErrBadTable},
+		{"rename table test.t to test.v", schema.ErrTableExists},
+		{"truncate table test.v", schema.ErrWrongObject},
+		{"create view test.v as select 1", schema.ErrTableExists},
+		{"create or replace view test.t as select 1", schema.ErrWrongObject},
+		{"create view test.v2 as select * from test.t2", schema.ErrNoSuchTable},
+		{"create view test.v2 as select a, a from test.t", schema.ErrDupFieldName},
+		{"create sequence test.s2 increment by 0", schema.ErrSequenceInvalidData},
+		{"create sequence test.s2 start with 10 maxvalue 5", schema.ErrSequenceInvalidData},
+		{"drop sequence test.t", schema.ErrNotSequence},
+		{"drop sequence test.s2", schema.ErrUnknownSequence},
+		{"flashback table test.t", schema.ErrNotSupportedYet},
+	}
+	catalog := schema.NewCatalog()
+	s.mustApply(c, catalog, "create database test; create table test.t (a int primary key, b int); create view test.v as select a from test.t")
+	version := catalog.SchemaMetaVersion()
+	for _, ca := range cases {
+		_, err := s.apply(c, catalog, ca.sql)
+		c.Assert(terror.ErrorEqual(err, ca.err), IsTrue, Commentf("%s: %v", ca.sql, err))
+	}
+	c.Assert(catalog.SchemaMetaVersion(), Equals, version)
+
+	// Nothing is changed if a statement fails.
+	_, err := s.apply(c, catalog, "alter table test.t add column c int, add column c2 int, drop column d")
+	c.Assert(terror.ErrorEqual(err, schema.ErrCantDropFieldOrKey), IsTrue)
+	c.Assert(s.table(c, catalog, "test", "t").Columns, HasLen, 2)
+	_, err = s.apply(c, catalog, "drop table if exists test.t2; drop table test.t, test.t2")
+	c.Assert(terror.ErrorEqual(err, schema.ErrBadTable), IsTrue)
+	c.Assert(strings.Contains(err.Error(), "test.t2"), IsTrue)
+	s.table(c, catalog, "test", "t")
+	c.Assert(catalog.SchemaMetaVersion(), Equals, version)
+
+	_, err = s.apply(c, catalog, "create database "+strings.Repeat("a", mysql.MaxDatabaseNameLength+1))
+	c.Assert(terror.ErrorEqual(err, schema.ErrTooLongIdent), IsTrue)
+	_, err = s.apply(c, catalog, "create database `d `")
+	c.Assert(terror.ErrorEqual(err, schema.ErrWrongDBName), IsTrue)
+
+	s.mustApply(c, catalog, "create table test.t3 (a int, b int, key idx ((a + 1)))")
+	_, err = s.apply(c, catalog, "alter table test.t3 drop column a")
+	c.Assert(terror.ErrorEqual(err, schema.ErrCannotDropColumnFunctionalIndex), IsTrue)
+	s.mustApply(c, catalog, "alter table test.t3 drop column b")
+}
@@ -0,0 +1,806 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/types"
+)
+
+// DiffOptions controls how the renamed columns and indexes are recognized
+// when diffing two tables. Without renames, a renamed column or index is
+// dropped and added again.
+type DiffOptions struct {
+	// ColumnRenames and IndexRenames map the old names of the renamed columns
+	// and indexes to their new names. They take precedence over the detection.
+	ColumnRenames map[string]string
+	IndexRenames  map[string]string
+	// DetectColumnRenames treats a dropped column and an added column with
+	// the same definition as a renamed column.
+	DetectColumnRenames bool
+	// DetectIndexRenames treats a dropped index and an added index with the
+	// same columns and options as a renamed index.
+	DetectIndexRenames bool
+}
+
+// DiffCreateTableStmt returns the ALTER TABLE statement which turns the table
+// created by from into the table created by to. See DiffTableInfo.
+func DiffCreateTableStmt(from, to *ast.CreateTableStmt, opts *DiffOptions) (*ast.AlterTableStmt, error) {
+	fromInfo, err := BuildTableInfo(from, nil)
+	if err != nil {
+		return nil, err
+	}
+	toInfo, err := BuildTableInfo(to, nil)
+	if err != nil {
+		return nil, err
+	}
+	return DiffTableInfo(fromInfo, toInfo, opts)
+}
+
+// DiffTableInfo returns the ALTER TABLE statement which turns the table from
+// into the table to, or nil if there is no difference. The table names aren't
+// compared.
+// The specs are ordered so that they can be applied one by one: foreign keys,
+// check constraints and indexes are dropped first, then the columns are
+// dropped, changed and added, then the indexes and constraints are added,
+// and the table options and partitioning are changed last.
+func DiffTableInfo(from, to *model.TableInfo, opts *DiffOptions) (*ast.AlterTableStmt, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+	fromStmt, err := BuildCreateTableStmt(from)
+	if err != nil {
+		return nil, err
+	}
+	toStmt, err := BuildCreateTableStmt(to)
+	if err != nil {
+		return nil, err
+	}
+	d := &tableDiffer{opts: opts, from: fromStmt, to: toStmt, fromInfo: from, toInfo: to}
+	if err := d.diff(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(d.specs) == 0 {
+		return nil, nil
+	}
+	return &ast.AlterTableStmt{
+		Table: &ast.TableName{Name: from.Name},
+		Specs: d.specs,
+	}, nil
+}
+
+type tableDiffer struct {
+	opts  *DiffOptions
+	from  *ast.CreateTableStmt
+	to    *ast.CreateTableStmt
+	specs []*ast.AlterTableSpec
+	// renames maps the lower case old names of the renamed columns to the new names.
+	renames map[string]model.CIStr
+	// fromInfo and toInfo give the effective charsets of the columns, which
+	// the statements omit if they are the table charset.
+	fromInfo *model.TableInfo
+	toInfo   *model.TableInfo
+	// convertTo is set if the table is converted to the new table charset.
+	convertTo bool
+}
+
+func (d *tableDiffer) addSpec(spec *ast.AlterTableSpec) {
+	d.specs = append(d.specs, spec)
+}
+
+// restoreText restores a node for comparison.
+func restoreText(node ast.Node) (string, error) {
+	var sb strings.Builder
+	if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return "", errors.Trace(err)
+	}
+	return sb.String(), nil
+}
+
+// columnDefText restores a column definition without its name.
+func columnDefText(def *ast.ColumnDef) (string, error) {
+	return restoreText(&ast.ColumnDef{Name: &ast.ColumnName{}, Tp: def.Tp, Options: def.Options})
+}
+
+// constraintText restores a constraint without its name and, if ignoreVisibility
+// is set, without its visibility.
+func constraintText(cons *ast.Constraint, ignoreVisibility bool) (string, error) {
+	c := *cons
+	c.Name = ""
+	if c.Option != nil && ignoreVisibility {
+		option := *c.Option
+		option.Visibility = ast.IndexVisibilityDefault
+		c.Option = &option
+		if option == (ast.IndexOption{}) {
+			c.Option = nil
+		}
+	}
+	return restoreText(&c)
+}
+
+func (d *tableDiffer) diff() error {
+	if err := d.matchColumns(); err != nil {
+		return err
+	}
+	d.renameReferredColumns()
+
+	fromIdx, fromFKs, fromChecks := splitConstraints(d.from.Constraints)
+	toIdx, toFKs, toChecks := splitConstraints(d.to.Constraints)
+	droppedFKs, addedFKs, _, err := diffNamedConstraints(fromFKs, toFKs)
+	if err != nil {
+		return err
+	}
+	droppedChecks, addedChecks, _, err := diffNamedConstraints(fromChecks, toChecks)
+	if err != nil {
+		return err
+	}
+	for _, fk := range droppedFKs {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableDropForeignKey, Name: fk.Name})
+	}
+	for _, check := range droppedChecks {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableDropCheck, Constraint: &ast.Constraint{Name: check.Name}})
+	}
+	addedIdx, visibility, err := d.diffIndexes(fromIdx, toIdx)
+	if err != nil {
+		return err
+	}
+	d.convertTo = d.isConvertedCharset()
+	if d.tableCharsetChanged() && !d.convertTo {
+		setExplicitCharsets(d.from.Cols, d.fromInfo)
+		setExplicitCharsets(d.to.Cols, d.toInfo)
+	}
+	if err := d.diffColumns(); err != nil {
+		return err
+	}
+	for _, idx := range addedIdx {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableAddConstraint, Constraint: idx})
+	}
+	for _, idx := range visibility {
+		d.addSpec(&ast.AlterTableSpec{
+			Tp:         ast.AlterTableIndexInvisible,
+			IndexName:  model.NewCIStr(idx.Name),
+			Visibility: indexVisibility(idx),
+		})
+	}
+	for _, check := range addedChecks {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableAddConstraint, Constraint: check})
+	}
+	for _, fk := range addedFKs {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableAddConstraint, Constraint: fk})
+	}
+	if err := d.diffTableOptions(); err != nil {
+		return err
+	}
+	return d.diffPartition()
+}
+
+func findColumnDef(defs []*ast.ColumnDef, name string) *ast.ColumnDef {
+	for _, def := range defs {
+		if def.Name.Name.L == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// matchColumns finds the renamed columns.
+func (d *tableDiffer) matchColumns() error {
+	d.renames = make(map[string]model.CIStr)
+	for oldName, newName := range d.opts.ColumnRenames {
+		oldDef := findColumnDef(d.from.Cols, strings.ToLower(oldName))
+		newDef := findColumnDef(d.to.Cols, strings.ToLower(newName))
+		if oldDef == nil || newDef == nil || findColumnDef(d.to.Cols, oldDef.Name.Name.L) != nil {
+			continue
+		}
+		d.renames[oldDef.Name.Name.L] = newDef.Name.Name
+	}
+	if !d.opts.DetectColumnRenames {
+		return nil
+	}
+	renamed := make(map[string]struct{}, len(d.renames))
+	for _, name := range d.renames {
+		renamed[name.L] = struct{}{}
+	}
+	for _, newDef := range d.to.Cols {
+		if _, ok := renamed[newDef.Name.Name.L]; ok || findColumnDef(d.from.Cols, newDef.Name.Name.L) != nil {
+			continue
+		}
+		newText, err := columnDefText(newDef)
+		if err != nil {
+			return err
+		}
+		for _, oldDef := range d.from.Cols {
+			if _, ok := d.renames[oldDef.Name.Name.L]; ok || findColumnDef(d.to.Cols, oldDef.Name.Name.L) != nil {
+				continue
+			}
+			oldText, err := columnDefText(oldDef)
+			if err != nil {
+				return err
+			}
+			if oldText == newText {
+				d.renames[oldDef.Name.Name.L] = newDef.Name.Name
+				renamed[newDef.Name.Name.L] = struct{}{}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// newColumnName returns the name of an old column in the new table.
+func (d *tableDiffer) newColumnName(name model.CIStr) model.CIStr {
+	if newName, ok := d.renames[name.L]; ok {
+		return newName
+	}
+	return name
+}
+
+type columnRenamer struct {
+	d *tableDiffer
+}
+
+func (r *columnRenamer) Enter(n ast.Node) (ast.Node, bool) {
+	if col, ok := n.(*ast.ColumnName); ok {
+		col.Name = r.d.newColumnName(col.Name)
+	}
+	return n, false
+}
+
+func (r *columnRenamer) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// renameReferredColumns renames the columns referred by the old indexes,
+// constraints and generated columns, like MySQL does when a column is renamed,
+// so that they can be compared with the new ones.
+func (d *tableDiffer) renameReferredColumns() {
+	if len(d.renames) == 0 {
+		return
+	}
+	renamer := &columnRenamer{d: d}
+	for _, def := range d.from.Cols {
+		for _, opt := range def.Options {
+			if opt.Tp == ast.ColumnOptionGenerated {
+				opt.Expr.Accept(renamer)
+			}
+		}
+	}
+	for _, cons := range d.from.Constraints {
+		for _, key := range cons.Keys {
+			if key.Column != nil {
+				key.Column.Name = d.newColumnName(key.Column.Name)
+			}
+		}
+		if cons.Expr != nil {
+			cons.Expr.Accept(renamer)
+		}
+	}
+	if d.from.Partition != nil {
+		if d.from.Partition.Expr != nil {
+			d.from.Partition.Expr.Accept(renamer)
+		}
+		for _, col := range d.from.Partition.ColumnNames {
+			col.Name = d.newColumnName(col.Name)
+		}
+	}
+}
+
+// splitConstraints splits the constraints into indexes, foreign keys and check constraints.
+func splitConstraints(constraints []*ast.Constraint) (indexes, fks, checks []*ast.Constraint) {
+	for _, cons := range constraints {
+		switch cons.Tp {
+		case ast.ConstraintForeignKey:
+			fks = append(fks, cons)
+		case ast.ConstraintCheck:
+			checks = append(checks, cons)
+		default:
+			indexes = append(indexes, cons)
+		}
+	}
+	return
+}
+
+// constraintKey returns the name identifying a constraint.
+func constraintKey(cons *ast.Constraint) string {
+	if cons.Tp == ast.ConstraintPrimaryKey {
+		return "primary"
+	}
+	return strings.ToLower(cons.Name)
+}
+
+func findConstraint(constraints []*ast.Constraint, key string) *ast.Constraint {
+	for _, cons := range constraints {
+		if constraintKey(cons) == key {
+			return cons
+		}
+	}
+	return nil
+}
+
+// diffNamedConstraints matches the constraints by name, and returns the dropped
+// and the added ones. A changed constraint is both dropped and added.
+// The constraints which only differ in visibility are returned as changed.
+func diffNamedConstraints(from, to []*ast.Constraint) (dropped, added, changed []*ast.Constraint, err error) {
+	for _, cons := range from {
+		if findConstraint(to, constraintKey(cons)) == nil {
+			dropped = append(dropped, cons)
+		}
+	}
+	for _, cons := range to {
+		old := findConstraint(from, constraintKey(cons))
+		if old == nil {
+			added = append(added, cons)
+			continue
+		}
+		oldText, err := constraintText(old, true)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		newText, err := constraintText(cons, true)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if oldText != newText {
+			dropped = append(dropped, old)
+			added = append(added, cons)
+		} else if indexVisibility(old) != indexVisibility(cons) {
+			changed = append(changed, cons)
+		}
+	}
+	return dropped, added, changed, nil
+}
+
+func indexVisibility(cons *ast.Constraint) ast.IndexVisibility {
+	if cons.Option != nil && cons.Option.Visibility == ast.IndexVisibilityInvisible {
+		return ast.IndexVisibilityInvisible
+	}
+	return ast.IndexVisibilityVisible
+}
+
+// diffIndexes adds the specs dropping and renaming the indexes, and returns the
+// indexes to add and the indexes whose visibility is changed.
+func (d *tableDiffer) diffIndexes(from, to []*ast.Constraint) (added, visibility []*ast.Constraint, err error) {
+	renamed := make(map[*ast.Constraint]*ast.Constraint)
+	for oldName, newName := range d.opts.IndexRenames {
+		old := findConstraint(from, strings.ToLower(oldName))
+		cons := findConstraint(to, strings.ToLower(newName))
+		if old != nil && cons != nil && old.Tp != ast.ConstraintPrimaryKey && cons.Tp != ast.ConstraintPrimaryKey &&
+			findConstraint(to, constraintKey(old)) == nil && findConstraint(from, constraintKey(cons)) == nil {
+			renamed[old] = cons
+		}
+	}
+	if d.opts.DetectIndexRenames {
+		for _, cons := range to {
+			if cons.Tp == ast.ConstraintPrimaryKey || findConstraint(from, constraintKey(cons)) != nil || isRenameTarget(renamed, cons) {
+				continue
+			}
+			newText, err := constraintText(cons, false)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, old := range from {
+				if _, ok := renamed[old]; ok || old.Tp == ast.ConstraintPrimaryKey || findConstraint(to, constraintKey(old)) != nil {
+					continue
+				}
+				oldText, err := constraintText(old, false)
+				if err != nil {
+					return nil, nil, err
+				}
+				if oldText == newText {
+					renamed[old] = cons
+					break
+				}
+			}
+		}
+	}
+
+	// Compare the renamed indexes as if they were not renamed.
+	var renameSpecs []*ast.AlterTableSpec
+	toCompared := make([]*ast.Constraint, 0, len(to))
+	for _, cons := range to {
+		if !isRenameTarget(renamed, cons) {
+			toCompared = append(toCompared, cons)
+		}
+	}
+	for _, old := range from {
+		cons, ok := renamed[old]
+		if !ok {
+			continue
+		}
+		renameSpecs = append(renameSpecs, &ast.AlterTableSpec{
+			Tp:      ast.AlterTableRenameIndex,
+			FromKey: model.NewCIStr(old.Name),
+			ToKey:   model.NewCIStr(cons.Name),
+		})
+		c := *cons
+		c.Name = old.Name
+		toCompared = append(toCompared, &c)
+	}
+	dropped, added, visibility, err := diffNamedConstraints(from, toCompared)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, old := range dropped {
+		if old.Tp == ast.ConstraintPrimaryKey {
+			d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableDropPrimaryKey})
+		} else {
+			d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableDropIndex, Name: old.Name})
+		}
+	}
+	// A renamed index is dropped with its old name if it is changed as well.
+	droppedNames := make(map[string]struct{}, len(dropped))
+	for _, old := range dropped {
+		droppedNames[constraintKey(old)] = struct{}{}
+	}
+	for _, spec := range renameSpecs {
+		if _, ok := droppedNames[spec.FromKey.L]; !ok {
+			d.addSpec(spec)
+		}
+	}
+	// The renamed indexes are added or altered with their new names.
+	for i, cons := range added {
+		added[i] = d.renamedIndex(renamed, cons)
+	}
+	for i, cons := range visibility {
+		visibility[i] = d.renamedIndex(renamed, cons)
+	}
+	return added, visibility, nil
+}
+
+func isRenameTarget(renamed map[*ast.Constraint]*ast.Constraint, cons *ast.Constraint) bool {
+	for _, target := range renamed {
+		if target == cons {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *tableDiffer) renamedIndex(renamed map[*ast.Constraint]*ast.Constraint, cons *ast.Constraint) *ast.Constraint {
+	for old, target := range renamed {
+		if old.Name == cons.Name && target != cons {
+			return target
+		}
+	}
+	return cons
+}
+
+// diffColumns adds the specs dropping, changing and adding the columns.
+func (d *tableDiffer) diffColumns() error {
+	oldNames := make(map[string]model.CIStr)
+	var cur []string
+	for _, def := range d.from.Cols {
+		newName := d.newColumnName(def.Name.Name)
+		if findColumnDef(d.to.Cols, newName.L) == nil {
+			d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableDropColumn, OldColumnName: &ast.ColumnName{Name: def.Name.Name}})
+			continue
+		}
+		oldNames[newName.L] = def.Name.Name
+		cur = append(cur, newName.L)
+	}
+
+	for i, def := range d.to.Cols {
+		name := def.Name.Name
+		position := &ast.ColumnPosition{Tp: ast.ColumnPositionNone}
+		j := indexOf(cur, name.L)
+		if (j < 0 && i != len(cur)) || (j >= 0 && j != i) {
+			position.Tp = ast.ColumnPositionFirst
+			if i > 0 {
+				position.Tp = ast.ColumnPositionAfter
+				position.RelativeColumn = &ast.ColumnName{Name: d.to.Cols[i-1].Name.Name}
+			}
+		}
+		if j < 0 {
+			d.addSpec(&ast.AlterTableSpec{
+				Tp:         ast.AlterTableAddColumns,
+				NewColumns: []*ast.ColumnDef{def},
+				Position:   position,
+			})
+			cur = insertAt(cur, i, name.L)
+			continue
+		}
+		cur = insertAt(append(cur[:j], cur[j+1:]...), i, name.L)
+
+		oldName := oldNames[name.L]
+		oldText, err := columnDefText(findColumnDef(d.from.Cols, oldName.L))
+		if err != nil {
+			return err
+		}
+		newText, err := columnDefText(def)
+		if err != nil {
+			return err
+		}
+		renamed := oldName.O != name.O
+		switch {
+		case oldText == newText && position.Tp == ast.ColumnPositionNone && !renamed:
+		case oldText == newText && position.Tp == ast.ColumnPositionNone:
+			d.addSpec(&ast.AlterTableSpec{
+				Tp:            ast.AlterTableRenameColumn,
+				OldColumnName: &ast.ColumnName{Name: oldName},
+				NewColumnName: &ast.ColumnName{Name: name},
+			})
+		case renamed:
+			d.addSpec(&ast.AlterTableSpec{
+				Tp:            ast.AlterTableChangeColumn,
+				OldColumnName: &ast.ColumnName{Name: oldName},
+				NewColumns:    []*ast.ColumnDef{def},
+				Position:      position,
+			})
+		default:
+			d.addSpec(&ast.AlterTableSpec{
+				Tp:         ast.AlterTableModifyColumn,
+				NewColumns: []*ast.ColumnDef{def},
+				Position:   position,
+			})
+		}
+	}
+	return nil
+}
+
+// columnCharsetChanged reports whether the effective charset or collation of a column is changed.
+func (d *tableDiffer) columnCharsetChanged(oldName, newName model.CIStr) bool {
+	oldCol := model.FindColumnInfo(d.fromInfo.Columns, oldName.L)
+	newCol := model.FindColumnInfo(d.toInfo.Columns, newName.L)
+	if oldCol == nil || newCol == nil || !types.HasCharset(&oldCol.FieldType) || !types.HasCharset(&newCol.FieldType) {
+		return false
+	}
+	return oldCol.Charset != newCol.Charset || oldCol.Collate != newCol.Collate
+}
+
+// tableCharsetChanged reports whether the table charset or collation is changed.
+func (d *tableDiffer) tableCharsetChanged() bool {
+	return d.fromInfo.Charset != d.toInfo.Charset || d.fromInfo.Collate != d.toInfo.Collate
+}
+
+// isConvertedCharset reports whether the table charset is changed and every
+// existing column with a charset is changed to it, which is what CONVERT TO
+// CHARACTER SET does.
+func (d *tableDiffer) isConvertedCharset() bool {
+	if !d.tableCharsetChanged() {
+		return false
+	}
+	converted := false
+	for _, def := range d.from.Cols {
+		name := d.newColumnName(def.Name.Name)
+		oldCol := model.FindColumnInfo(d.fromInfo.Columns, def.Name.Name.L)
+		newCol := model.FindColumnInfo(d.toInfo.Columns, name.L)
+		if oldCol == nil || newCol == nil || !types.HasCharset(&oldCol.FieldType) || !types.HasCharset(&newCol.FieldType) {
+			continue
+		}
+		if !d.columnCharsetChanged(def.Name.Name, name) ||
+			newCol.Charset != d.toInfo.Charset || newCol.Collate != d.toInfo.Collate {
+			return false
+		}
+		converted = true
+	}
+	return converted
+}
+
+// setExplicitCharsets gives the charsets and collations of the columns
+// explicitly, since the statement omits them if they are the table charset,
+// so that the columns can be compared if the table charset is changed.
+func setExplicitCharsets(defs []*ast.ColumnDef, tbInfo *model.TableInfo) {
+	for _, def := range defs {
+		col := model.FindColumnInfo(tbInfo.Columns, def.Name.Name.L)
+		if col == nil || !types.HasCharset(def.Tp) {
+			continue
+		}
+		def.Tp.Charset, def.Tp.Collate = col.Charset, col.Collate
+	}
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func insertAt(names []string, i int, name string) []string {
+	names = append(names, "")
+	copy(names[i+1:], names[i:])
+	names[i] = name
+	return names
+}
+
+// diffTableOptions adds the specs changing the table options.
+func (d *tableDiffer) diffTableOptions() error {
+	fromOpts := make(map[ast.TableOptionType]*ast.TableOption, len(d.from.Options))
+	for _, opt := range d.from.Options {
+		fromOpts[opt.Tp] = opt
+	}
+	var charsetOpts, opts []*ast.TableOption
+	for _, opt := range d.to.Options {
+		if old, ok := fromOpts[opt.Tp]; ok && tableOptionEqual(old, opt) {
+			continue
+		}
+		switch opt.Tp {
+		case ast.TableOptionCharset, ast.TableOptionCollate:
+		case ast.TableOptionAutoIncrement, ast.TableOptionAutoRandomBase:
+			// The auto ID bases are allocated at runtime, and can't be decreased.
+			if old, ok := fromOpts[opt.Tp]; ok && old.UintValue > opt.UintValue {
+				continue
+			}
+			opts = append(opts, opt)
+		default:
+			opts = append(opts, opt)
+		}
+	}
+	toOpts := make(map[ast.TableOptionType]*ast.TableOption, len(d.to.Options))
+	for _, opt := range d.to.Options {
+		toOpts[opt.Tp] = opt
+	}
+	fromCharset, fromCollate := fromOpts[ast.TableOptionCharset], fromOpts[ast.TableOptionCollate]
+	toCharset, toCollate := toOpts[ast.TableOptionCharset], toOpts[ast.TableOptionCollate]
+	if toCharset != nil && toCollate != nil && (fromCharset == nil || fromCollate == nil ||
+		!tableOptionEqual(fromCharset, toCharset) || !tableOptionEqual(fromCollate, toCollate)) {
+		charsetOpts = []*ast.TableOption{toCharset, toCollate}
+	}
+	for _, opt := range d.from.Options {
+		if _, ok := toOpts[opt.Tp]; ok {
+			continue
+		}
+		switch opt.Tp {
+		case ast.TableOptionComment:
+			opts = append(opts, &ast.TableOption{Tp: ast.TableOptionComment})
+		case ast.TableOptionShardRowID, ast.TableOptionPreSplitRegion:
+			opts = append(opts, &ast.TableOption{Tp: opt.Tp})
+		case ast.TableOptionPlacementPolicy:
+			opts = append(opts, &ast.TableOption{Tp: opt.Tp, StrValue: "DEFAULT"})
+		}
+	}
+	if charsetOpts != nil && d.convertTo {
+		convert := *charsetOpts[0]
+		convert.UintValue = ast.TableOptionCharsetWithConvertTo
+		charsetOpts[0] = &convert
+	}
+	if charsetOpts != nil {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableOption, Options: charsetOpts})
+	}
+	if len(opts) > 0 {
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableOption, Options: opts})
+	}
+	return nil
+}
+
+// tableOptionEqual reports whether two table options built from TableInfo are the same.
+func tableOptionEqual(a, b *ast.TableOption) bool {
+	return a.Tp == b.Tp && a.Default == b.Default && a.StrValue == b.StrValue &&
+		a.UintValue == b.UintValue && a.BoolValue == b.BoolValue
+}
+
+// partitionMethodText restores the partitioning method without the partitions.
+func partitionMethodText(part *ast.PartitionOptions) (string, error) {
+	method := part.PartitionMethod
+	method.Num = 0
+	return restoreText(&ast.PartitionOptions{PartitionMethod: method})
+}
+
+// diffPartition adds the spec changing the partitioning. The parser accepts
+// at most one partition spec at the end of ALTER TABLE, so the RANGE and LIST
+// partitions are only dropped or added if the partitioning method isn't changed
+// and the partitions are either all dropped or all added, otherwise the table
+// is partitioned again. Since RANGE partitions can only be added after the last
+// one, the table is partitioned again as well if one is inserted before.
+func (d *tableDiffer) diffPartition() error {
+	from, to := d.from.Partition, d.to.Partition
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case to == nil:
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableRemovePartitioning})
+		return nil
+	case from == nil:
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTablePartition, Partition: to})
+		return nil
+	}
+	fromText, err := partitionMethodText(from)
+	if err != nil {
+		return err
+	}
+	toText, err := partitionMethodText(to)
+	if err != nil {
+		return err
+	}
+	if fromText != toText || (to.Tp != model.PartitionTypeRange && to.Tp != model.PartitionTypeList) {
+		fullFrom, err := restoreText(from)
+		if err != nil {
+			return err
+		}
+		fullTo, err := restoreText(to)
+		if err != nil {
+			return err
+		}
+		if fullFrom != fullTo {
+			d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTablePartition, Partition: to})
+		}
+		return nil
+	}
+
+	var dropped []model.CIStr
+	var added []*ast.PartitionDefinition
+	defText := func(def *ast.PartitionDefinition) (string, error) {
+		return restoreText(&ast.PartitionOptions{PartitionMethod: to.PartitionMethod, Definitions: []*ast.PartitionDefinition{def}})
+	}
+	for _, def := range from.Definitions {
+		var newDef *ast.PartitionDefinition
+		for _, nd := range to.Definitions {
+			if nd.Name.L == def.Name.L {
+				newDef = nd
+			}
+		}
+		if newDef == nil {
+			dropped = append(dropped, def.Name)
+			continue
+		}
+		oldText, err := defText(def)
+		if err != nil {
+			return err
+		}
+		newText, err := defText(newDef)
+		if err != nil {
+			return err
+		}
+		if oldText != newText {
+			dropped = append(dropped, def.Name)
+		}
+	}
+	for _, def := range to.Definitions {
+		var oldDef *ast.PartitionDefinition
+		for _, od := range from.Definitions {
+			if od.Name.L == def.Name.L {
+				oldDef = od
+			}
+		}
+		if oldDef == nil {
+			added = append(added, def)
+			continue
+		}
+		for _, name := range dropped {
+			if name.L == def.Name.L {
+				added = append(added, def)
+			}
+		}
+	}
+	switch {
+	case len(dropped) > 0 && len(added) > 0, to.Tp == model.PartitionTypeRange && !addedAfterLast(to.Definitions, added):
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTablePartition, Partition: to})
+	case len(dropped) > 0:
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableDropPartition, PartitionNames: dropped})
+	case len(added) > 0:
+		d.addSpec(&ast.AlterTableSpec{Tp: ast.AlterTableAddPartitions, PartDefinitions: added, Num: uint64(len(added))})
+	}
+	return nil
+}
+
+// addedAfterLast reports whether the added partitions follow the existing ones in defs.
+func addedAfterLast(defs, added []*ast.PartitionDefinition) bool {
+	for i, def := range defs {
+		isAdded := false
+		for _, a := range added {
+			isAdded = isAdded || a == def
+		}
+		if isAdded {
+			return len(defs)-i == len(added)
+		}
+	}
+	return true
+}
@@ -0,0 +1,313 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/schema"
+)
+
+var _ = Suite(&testDiffSuite{})
+
+type testDiffSuite struct {
+	*parser.Parser
+}
+
+func (s *testDiffSuite) SetUpSuite(c *C) {
+	s.Parser = parser.New()
+}
+
+func (s *testDiffSuite) diff(c *C, from, to string, opts *schema.DiffOptions) string {
+	fromStmt, err := s.ParseOneStmt(from, "", "")
+	c.Assert(err, IsNil, Commentf("%s", from))
+	toStmt, err := s.ParseOneStmt(to, "", "")
+	c.Assert(err, IsNil, Commentf("%s", to))
+	alter, err := schema.DiffCreateTableStmt(fromStmt.(*ast.CreateTableStmt), toStmt.(*ast.CreateTableStmt), opts)
+	c.Assert(err, IsNil, Commentf("%s -> %s", from, to))
+	if alter == nil {
+		return ""
+	}
+	var sb strings.Builder
+	err = alter.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestoreStringWithoutDefaultCharset, &sb))
+	c.Assert(err, IsNil)
+
+	// The generated statement can be parsed.
+	_, err = s.ParseOneStmt(sb.String(), "", "")
+	c.Assert(err, IsNil, Commentf("%s", sb.String()))
+	return sb.String()
+}
+
+func (s *testDiffSuite) TestDiffColumns(c *C) {
+	cases := []struct {
+		from   string
+		to     string
+		opts   *schema.DiffOptions
+		expect string
+	}{
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, b int)",
+			nil,
+			"",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, b bigint not null, c int)",
+			nil,
+			"ALTER TABLE `t` MODIFY COLUMN `b` BIGINT(20) NOT NULL, ADD COLUMN `c` INT(11) DEFAULT NULL",
+		},
+		{
+			"create table t (a int, b int, c int)",
+			"create table t (c int, a int)",
+			nil,
+			"ALTER TABLE `t` DROP COLUMN `b`, MODIFY COLUMN `c` INT(11) DEFAULT NULL FIRST",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (x int, a int, y int, b int)",
+			nil,
+			"ALTER TABLE `t` ADD COLUMN `x` INT(11) DEFAULT NULL FIRST, ADD COLUMN `y` INT(11) DEFAULT NULL AFTER `a`",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, c int)",
+			nil,
+			"ALTER TABLE `t` DROP COLUMN `b`, ADD COLUMN `c` INT(11) DEFAULT NULL",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, c int)",
+			&schema.DiffOptions{DetectColumnRenames: true},
+			"ALTER TABLE `t` RENAME COLUMN `b` TO `c`",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, c bigint)",
+			&schema.DiffOptions{DetectColumnRenames: true},
+			"ALTER TABLE `t` DROP COLUMN `b`, ADD COLUMN `c` BIGINT(20) DEFAULT NULL",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, c bigint)",
+			&schema.DiffOptions{ColumnRenames: map[string]string{"B": "c"}},
+			"ALTER TABLE `t` CHANGE COLUMN `b` `c` BIGINT(20) DEFAULT NULL",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (c int, a int)",
+			&schema.DiffOptions{ColumnRenames: map[string]string{"b": "c"}},
+			"ALTER TABLE `t` CHANGE COLUMN `b` `c` INT(11) DEFAULT NULL FIRST",
+		},
+		{
+			"create table t (a int, b int as (a + 1), key idx (a))",
+			"create table t (x int, b int as (x + 1), key idx (x))",
+			&schema.DiffOptions{DetectColumnRenames: true},
+			"ALTER TABLE `t` RENAME COLUMN `a` TO `x`",
+		},
+	}
+	for _, ca := range cases {
+		c.Assert(s.diff(c, ca.from, ca.to, ca.opts), Equals, ca.expect, Commentf("%s -> %s", ca.from, ca.to))
+	}
+}
+
+func (s *testDiffSuite) TestDiffIndexes(c *C) {
+	cases := []struct {
+		from   string
+		to     string
+		opts   *schema.DiffOptions
+		expect string
+	}{
+		{
+			"create table t (a int, b int, key idx (a))",
+			"create table t (a int, b int, key idx (a, b), unique key uk (b))",
+			nil,
+			"ALTER TABLE `t` DROP INDEX `idx`, ADD KEY `idx`(`a`, `b`), ADD UNIQUE KEY `uk`(`b`)",
+		},
+		{
+			"create table t (a int, b int, key idx (a))",
+			"create table t (a int, b int, key idx2 (a))",
+			&schema.DiffOptions{DetectIndexRenames: true},
+			"ALTER TABLE `t` RENAME INDEX `idx` TO `idx2`",
+		},
+		{
+			"create table t (a int, b int, key idx (a))",
+			"create table t (a int, b int, key idx2 (a) invisible)",
+			&schema.DiffOptions{IndexRenames: map[string]string{"idx": "idx2"}},
+			"ALTER TABLE `t` RENAME INDEX `idx` TO `idx2`, ALTER INDEX `idx2` INVISIBLE",
+		},
+		{
+			"create table t (a int, b int, key idx (a))",
+			"create table t (a int, b int, key idx2 (b))",
+			&schema.DiffOptions{IndexRenames: map[string]string{"idx": "idx2"}},
+			"ALTER TABLE `t` DROP INDEX `idx`, ADD KEY `idx2`(`b`)",
+		},
+		{
+			"create table t (a int, b int, key idx (a) invisible)",
+			"create table t (a int, b int, key idx (a))",
+			nil,
+			"ALTER TABLE `t` ALTER INDEX `idx` VISIBLE",
+		},
+		{
+			"create table t (a int, b int, primary key (a))",
+			"create table t (a int, b int not null, primary key (a, b))",
+			nil,
+			"ALTER TABLE `t` DROP PRIMARY KEY, MODIFY COLUMN `b` INT(11) NOT NULL, ADD PRIMARY KEY(`a`, `b`) NONCLUSTERED",
+		},
+		{
+			"create table t (a int, b int, c int, key idx (b))",
+			"create table t (a int, c int, key idx (c))",
+			nil,
+			"ALTER TABLE `t` DROP INDEX `idx`, DROP COLUMN `b`, ADD KEY `idx`(`c`)",
+		},
+	}
+	for _, ca := range cases {
+		c.Assert(s.diff(c, ca.from, ca.to, ca.opts), Equals, ca.expect, Commentf("%s -> %s", ca.from, ca.to))
+	}
+}
+
+func (s *testDiffSuite) TestDiffConstraints(c *C) {
+	cases := []struct {
+		from   string
+		to     string
+		expect string
+	}{
+		{
+			"create table t (a int, constraint fk foreign key (a) references p (id), constraint c1 check (a > 0))",
+			"create table t (a int, constraint fk foreign key (a) references p (id) on delete cascade, constraint c1 check (a > 0))",
+			"ALTER TABLE `t` DROP FOREIGN KEY `fk`, ADD CONSTRAINT `fk` FOREIGN KEY (`a`) REFERENCES `p`(`id`) ON DELETE CASCADE",
+		},
+		{
+			"create table t (a int, constraint c1 check (a > 0))",
+			"create table t (a int, constraint c2 check (a > 1))",
+			"ALTER TABLE `t` DROP CHECK `c1`, ADD CONSTRAINT `c2` CHECK(`a`>1) ENFORCED",
+		},
+	}
+	for _, ca := range cases {
+		c.Assert(s.diff(c, ca.from, ca.to, nil), Equals, ca.expect, Commentf("%s -> %s", ca.from, ca.to))
+	}
+}
+
+func (s *testDiffSuite) TestDiffTableOptions(c *C) {
+	cases := []struct {
+		from   string
+		to     string
+		expect string
+	}{
+		{
+			"create table t (a int) comment 'x'",
+			"create table t (a int) charset latin1 shard_row_id_bits 2",
+			"ALTER TABLE `t` CHARACTER SET LATIN1 COLLATE LATIN1_BIN, SHARD_ROW_ID_BITS = 2 COMMENT = ''",
+		},
+		{
+			"create table t (a int) auto_increment 100",
+			"create table t (a int) auto_increment 10",
+			"",
+		},
+		{
+			"create table t (a int) auto_increment 10",
+			"create table t (a int) auto_increment 100",
+			"ALTER TABLE `t` AUTO_INCREMENT = 100",
+		},
+		{
+			"create table t (a int, b varchar(10), c text) charset utf8mb4",
+			"create table t (a int, b varchar(10), c text) charset latin1",
+			"ALTER TABLE `t` CONVERT TO CHARACTER SET LATIN1 COLLATE LATIN1_BIN",
+		},
+		{
+			"create table t (a int, b varchar(10), c text) charset utf8mb4",
+			"create table t (a int, b varchar(10), c text charset utf8mb4) charset latin1",
+			"ALTER TABLE `t` MODIFY COLUMN `b` VARCHAR(10) CHARACTER SET LATIN1 COLLATE latin1_bin DEFAULT NULL, CHARACTER SET LATIN1 COLLATE LATIN1_BIN",
+		},
+		{
+			"create table t (a int, b varchar(10) charset latin1) charset utf8mb4",
+			"create table t (a int, b varchar(10)) charset utf8mb4",
+			"ALTER TABLE `t` MODIFY COLUMN `b` VARCHAR(10) DEFAULT NULL",
+		},
+		{
+			"create table t (a int)",
+			"create table t (a int) placement policy p1",
+			"ALTER TABLE `t` PLACEMENT POLICY = `p1`",
+		},
+		{
+			"create table t (a int) placement policy p1",
+			"create table t (a int)",
+			"ALTER TABLE `t` PLACEMENT POLICY = `DEFAULT`",
+		},
+	}
+	for _, ca := range cases {
+		c.Assert(s.diff(c, ca.from, ca.to, nil), Equals, ca.expect, Commentf("%s -> %s", ca.from, ca.to))
+	}
+}
+
+func (s *testDiffSuite) TestDiffPartition(c *C) {
+	cases := []struct {
+		from   string
+		to     string
+		expect string
+	}{
+		{
+			"create table t (a int)",
+			"create table t (a int) partition by hash (a) partitions 4",
+			"ALTER TABLE `t` PARTITION BY HASH (`a`) PARTITIONS 4",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"create table t (a int)",
+			"ALTER TABLE `t` REMOVE PARTITIONING",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"create table t (a int) partition by hash (a) partitions 4",
+			"",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20))",
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p2 values less than (30))",
+			"ALTER TABLE `t` PARTITION BY RANGE (`a`) (PARTITION `p0` VALUES LESS THAN (10),PARTITION `p2` VALUES LESS THAN (30))",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20))",
+			"create table t (a int) partition by range (a) (partition p0 values less than (10))",
+			"ALTER TABLE `t` DROP PARTITION `p1`",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p2 values less than (30))",
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20), partition p2 values less than (30))",
+			"ALTER TABLE `t` PARTITION BY RANGE (`a`) (PARTITION `p0` VALUES LESS THAN (10),PARTITION `p1` VALUES LESS THAN (20),PARTITION `p2` VALUES LESS THAN (30))",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10))",
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20))",
+			"ALTER TABLE `t` ADD PARTITION (PARTITION `p1` VALUES LESS THAN (20))",
+		},
+		{
+			"create table t (a int, b int) partition by list (a) (partition p0 values in (1))",
+			"create table t (a int, c int) partition by list (a) (partition p0 values in (1), partition p1 values in (2, 3))",
+			"ALTER TABLE `t` DROP COLUMN `b`, ADD COLUMN `c` INT(11) DEFAULT NULL, ADD PARTITION (PARTITION `p1` VALUES IN (2, 3))",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10))",
+			"create table t (a int) partition by list (a) (partition p0 values in (1))",
+			"ALTER TABLE `t` PARTITION BY LIST (`a`) (PARTITION `p0` VALUES IN (1))",
+		},
+	}
+	for _, ca := range cases {
+		c.Assert(s.diff(c, ca.from, ca.to, nil), Equals, ca.expect, Commentf("%s -> %s", ca.from, ca.to))
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema converts between the DDL statements of package ast and the
+// schema objects of package model without depending on a running server.
+package schema
+
+import (
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/terror"
+)
+
+// codeInvalidAutoRandom is the error code of ErrInvalidAutoRandom, which is defined by TiDB.
+const codeInvalidAutoRandom terror.ErrCode = 8216
+
+// The errors of building the schema objects of DDL statements.
+var (
+	// ErrDupFieldName returns when a table has two columns with the same name.
+	ErrDupFieldName = terror.ClassDDL.NewStd(mysql.ErrDupFieldName)
+	// ErrDupKeyName returns when a table has two indexes with the same name.
+	ErrDupKeyName = terror.ClassDDL.NewStd(mysql.ErrDupKeyName)
+	// ErrMultiplePriKey returns when a table has more than one primary key.
+	ErrMultiplePriKey = terror.ClassDDL.NewStd(mysql.ErrMultiplePriKey)
+	// ErrKeyColumnDoesNotExist returns when an index or foreign key refers to an unknown column.
+	ErrKeyColumnDoesNotExist = terror.ClassDDL.NewStd(mysql.ErrKeyColumnDoesNotExits)
+	// ErrWrongAutoKey returns when there are several auto-increment columns or the column isn't a key.
+	ErrWrongAutoKey = terror.ClassDDL.NewStd(mysql.ErrWrongAutoKey)
+	// ErrInvalidDefault returns when the default value doesn't fit the type or range of a column.
+	ErrInvalidDefault = terror.ClassDDL.NewStd(mysql.ErrInvalidDefault)
+	// ErrInvalidOnUpdate returns when ON UPDATE is given to a column that isn't a TIMESTAMP or DATETIME.
+	ErrInvalidOnUpdate = terror.ClassDDL.NewStd(mysql.ErrInvalidOnUpdate)
+	// ErrBlobCantHaveDefault returns when a BLOB, TEXT, GEOMETRY or JSON column has a literal default value.
+	ErrBlobCantHaveDefault = terror.ClassDDL.NewStd(mysql.ErrBlobCantHaveDefault)
+	// ErrBadField returns when an expression refers to an unknown column.
+	ErrBadField = terror.ClassDDL.NewStd(mysql.ErrBadField)
+	// ErrTooLongIdent returns when an identifier exceeds its maximum length.
+	ErrTooLongIdent = terror.ClassDDL.NewStd(mysql.ErrTooLongIdent)
+	// ErrWrongNameForIndex returns when an index that isn't the primary key is named PRIMARY.
+	ErrWrongNameForIndex = terror.ClassDDL.NewStd(mysql.ErrWrongNameForIndex)
+	// ErrWrongColumnName returns for an empty column name or one ending with a space.
+	ErrWrongColumnName = terror.ClassDDL.NewStd(mysql.ErrWrongColumnName)
+	// ErrWrongTableName returns for an empty table name or one ending with a space.
+	ErrWrongTableName = terror.ClassDDL.NewStd(mysql.ErrWrongTableName)
+	// ErrBlobKeyWithoutLength returns when a BLOB or TEXT column is indexed without a prefix length.
+	ErrBlobKeyWithoutLength = terror.ClassDDL.NewStd(mysql.ErrBlobKeyWithoutLength)
+	// ErrJSONUsedAsKey returns when a JSON column is indexed.
+	ErrJSONUsedAsKey = terror.ClassDDL.NewStd(mysql.ErrJSONUsedAsKey)
+	// ErrWrongSubKey returns when an index prefix length is given for a column which isn't a string or is shorter than it.
+	ErrWrongSubKey = terror.ClassDDL.NewStd(mysql.ErrWrongSubKey)
+	// ErrFunctionalIndexPrimaryKey returns when the primary key has an expression part.
+	ErrFunctionalIndexPrimaryKey = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexPrimaryKey)
+	// ErrFunctionalIndexRefAutoIncrement returns when an expression index part refers to an auto-increment column.
+	ErrFunctionalIndexRefAutoIncrement = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexRefAutoIncrement)
+	// ErrInvalidAutoRandom returns when the AUTO_RANDOM column isn't a BIGINT primary key which is the handle.
+	ErrInvalidAutoRandom = terror.ClassDDL.NewStdErr(codeInvalidAutoRandom, mysql.Message("Invalid auto random: %s", nil))
+	// ErrTooManyFields returns when a table has more than 4096 columns.
+	ErrTooManyFields = terror.ClassDDL.NewStd(mysql.ErrTooManyFields)
+	// ErrTooManyKeyParts returns when an index has more than mysql.MaxKeyParts columns.
+	ErrTooManyKeyParts = terror.ClassDDL.NewStd(mysql.ErrTooManyKeyParts)
+	// ErrTableMustHaveColumns returns when a table has no column.
+	ErrTableMustHaveColumns = terror.ClassDDL.NewStd(mysql.ErrTableMustHaveColumns)
+	// ErrPrimaryCantHaveNull returns when a column of the primary key is declared NULL.
+	ErrPrimaryCantHaveNull = terror.ClassDDL.NewStd(mysql.ErrPrimaryCantHaveNull)
+	// ErrUnknownCharacterSet returns for an unknown charset.
+	ErrUnknownCharacterSet = terror.ClassDDL.NewStd(mysql.ErrUnknownCharacterSet)
+	// ErrGeneratedColumnRefAutoInc returns when a generated column refers to an auto-increment column.
+	ErrGeneratedColumnRefAutoInc = terror.ClassDDL.NewStd(mysql.ErrGeneratedColumnRefAutoInc)
+	// ErrGeneratedColumnNonPrior returns when a generated column refers to a later generated column.
+	ErrGeneratedColumnNonPrior = terror.ClassDDL.NewStd(mysql.ErrGeneratedColumnNonPrior)
+	// ErrUnsupportedOnGeneratedColumn returns when a generated column has DEFAULT, AUTO_INCREMENT or ON UPDATE.
+	ErrUnsupportedOnGeneratedColumn = terror.ClassDDL.NewStd(mysql.ErrUnsupportedOnGeneratedColumn)
+	// ErrWrongFkDef returns when the columns of a foreign key don't match the referenced ones.
+	ErrWrongFkDef = terror.ClassDDL.NewStd(mysql.ErrWrongFkDef)
+	// ErrFkDupName returns when a table has two foreign keys with the same name.
+	ErrFkDupName = terror.ClassDDL.NewStd(mysql.ErrFkDupName)
+	// ErrCheckConstraintDupName returns when a table has two check constraints with the same name.
+	ErrCheckConstraintDupName = terror.ClassDDL.NewStd(mysql.ErrCheckConstraintDupName)
+	// ErrFieldNotFoundPart returns when a partitioning column isn't a column of the table.
+	ErrFieldNotFoundPart = terror.ClassDDL.NewStd(mysql.ErrFieldNotFoundPart)
+	// ErrSameNamePartition returns when two partitions have the same name.
+	ErrSameNamePartition = terror.ClassDDL.NewStd(mysql.ErrSameNamePartition)
+	// ErrRangeNotIncreasing returns when the bounds of the RANGE partitions aren't increasing.
+	ErrRangeNotIncreasing = terror.ClassDDL.NewStd(mysql.ErrRangeNotIncreasing)
+	// ErrNotSupportedYet returns for statements whose schema objects can't be built offline.
+	ErrNotSupportedYet = terror.ClassDDL.NewStd(mysql.ErrNotSupportedYet)
+)
+
+// The errors of applying DDL statements to a Catalog.
+var (
+	// ErrDBCreateExists returns when the database to create exists.
+	ErrDBCreateExists = terror.ClassSchema.NewStd(mysql.ErrDBCreateExists)
+	// ErrDBDropExists returns when the database to drop doesn't exist.
+	ErrDBDropExists = terror.ClassSchema.NewStd(mysql.ErrDBDropExists)
+	// ErrBadDB returns when a database doesn't exist.
+	ErrBadDB = terror.ClassSchema.NewStd(mysql.ErrBadDB)
+	// ErrNoDB returns when a table isn't qualified and there is no current database.
+	ErrNoDB = terror.ClassSchema.NewStd(mysql.ErrNoDB)
+	// ErrTableExists returns when the table, view or sequence to create exists.
+	ErrTableExists = terror.ClassSchema.NewStd(mysql.ErrTableExists)
+	// ErrNoSuchTable returns when a table doesn't exist.
+	ErrNoSuchTable = terror.ClassSchema.NewStd(mysql.ErrNoSuchTable)
+	// ErrBadTable returns when a table to drop or referred by a view doesn't exist.
+	ErrBadTable = terror.ClassSchema.NewStd(mysql.ErrBadTable)
+	// ErrWrongObject returns when a table is a view or a view is a table.
+	ErrWrongObject = terror.ClassSchema.NewStd(mysql.ErrWrongObject)
+	// ErrUnknownSequence returns when a sequence doesn't exist.
+	ErrUnknownSequence = terror.ClassSchema.NewStd(mysql.ErrUnknownSequence)
+	// ErrNotSequence returns when a sequence statement refers to a table.
+	ErrNotSequence = terror.ClassSchema.NewStd(mysql.ErrNotSequence)
+
+	// ErrWrongDBName returns when a database name is invalid.
+	ErrWrongDBName = terror.ClassDDL.NewStd(mysql.ErrWrongDBName)
+	// ErrCantDropFieldOrKey returns when the column, index or constraint to drop or alter doesn't exist.
+	ErrCantDropFieldOrKey = terror.ClassDDL.NewStd(mysql.ErrCantDropFieldOrKey)
+	// ErrCantRemoveAllFields returns when the last column of a table is dropped.
+	ErrCantRemoveAllFields = terror.ClassDDL.NewStd(mysql.ErrCantRemoveAllFields)
+	// ErrKeyDoesNotExist returns when the index to rename or alter doesn't exist.
+	ErrKeyDoesNotExist = terror.ClassDDL.NewStd(mysql.ErrKeyDoesNotExist)
+	// ErrDependentByGeneratedColumn returns when a column referred by a generated column is dropped.
+	ErrDependentByGeneratedColumn = terror.ClassDDL.NewStd(mysql.ErrDependentByGeneratedColumn)
+	// ErrCannotDropColumnFunctionalIndex returns when a column referred by an expression index is dropped.
+	ErrCannotDropColumnFunctionalIndex = terror.ClassDDL.NewStd(mysql.ErrCannotDropColumnFunctionalIndex)
+	// ErrPartitionMgmtOnNonpartitioned returns when the partitions of a table without partitioning are changed.
+	ErrPartitionMgmtOnNonpartitioned = terror.ClassDDL.NewStd(mysql.ErrPartitionMgmtOnNonpartitioned)
+	// ErrOnlyOnRangeListPartition returns when partitions are added to or dropped from a table without RANGE or LIST partitioning.
+	ErrOnlyOnRangeListPartition = terror.ClassDDL.NewStd(mysql.ErrOnlyOnRangeListPartition)
+	// ErrDropPartitionNonExistent returns when the partition to drop doesn't exist.
+	ErrDropPartitionNonExistent = terror.ClassDDL.NewStd(mysql.ErrDropPartitionNonExistent)
+	// ErrDropLastPartition returns when all the partitions of a table are dropped.
+	ErrDropLastPartition = terror.ClassDDL.NewStd(mysql.ErrDropLastPartition)
+	// ErrUnknownPartition returns when the partition to truncate doesn't exist.
+	ErrUnknownPartition = terror.ClassDDL.NewStd(mysql.ErrUnknownPartition)
+	// ErrSequenceInvalidData returns when the options of a sequence are inconsistent.
+	ErrSequenceInvalidData = terror.ClassDDL.NewStd(mysql.ErrSequenceInvalidData)
+)
+
+// The errors of checking the ALGORITHM and LOCK clauses of DDL statements.
+var (
+	// ErrAlterOperationNotSupported returns when the requested ALGORITHM or LOCK can't be satisfied.
+	ErrAlterOperationNotSupported = terror.ClassDDL.NewStd(mysql.ErrAlterOperationNotSupported)
+	// ErrAlterOperationNotSupportedReason returns when the requested ALGORITHM or LOCK can't be satisfied for a known reason.
+	ErrAlterOperationNotSupportedReason = terror.ClassDDL.NewStd(mysql.ErrAlterOperationNotSupportedReason)
+)
@@ -0,0 +1,686 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/types"
+)
+
+// OnlineDDLRules are the rules which decide how a DDL operation is executed.
+type OnlineDDLRules byte
+
+// List the online DDL rules.
+const (
+	// MySQLRules are the rules of InnoDB in MySQL 8.0.29 and later.
+	MySQLRules OnlineDDLRules = iota
+	// TiDBRules are the rules of the online schema change of TiDB.
+	TiDBRules
+)
+
+// OnlineDDL describes how an ALTER TABLE spec is executed.
+type OnlineDDL struct {
+	Spec *ast.AlterTableSpec
+	// Action is the schema change of the spec. It is ActionNone if the spec
+	// doesn't change the schema, e.g. LOCK and ALGORITHM, or if there is no
+	// action type for it, e.g. REORGANIZE PARTITION.
+	Action model.ActionType
+	// Algorithm is the cheapest algorithm the spec can be executed with.
+	Algorithm ast.AlgorithmType
+	// BlocksWrites reports whether the concurrent writes are blocked while the spec is executed.
+	BlocksWrites bool
+	// Reorg reports whether the data is reorganized, e.g. the table is rebuilt
+	// or an index is backfilled.
+	Reorg bool
+	// Reason explains why the spec can't be executed with a cheaper algorithm
+	// or without blocking writes. It may be empty.
+	Reason string
+}
+
+// AnalyzeOnlineDDL reports how each spec of an ALTER TABLE statement is executed
+// on a table. CREATE INDEX and DROP INDEX statements are analyzed as the
+// equivalent ALTER TABLE statements. The specs are not validated against the
+// table, so an unknown column is treated as a new one.
+// ErrAlterOperationNotSupported or ErrAlterOperationNotSupportedReason is
+// returned if the ALGORITHM or LOCK clause of the statement can't be satisfied,
+// and ErrNotSupportedYet is returned if a spec can't be executed by TiDB.
+func AnalyzeOnlineDDL(stmt ast.DDLNode, tbInfo *model.TableInfo, rules OnlineDDLRules) ([]*OnlineDDL, error) {
+	var (
+		specs   []*ast.AlterTableSpec
+		lockAlg *ast.IndexLockAndAlgorithm
+	)
+	switch x := stmt.(type) {
+	case *ast.AlterTableStmt:
+		specs = x.Specs
+	case *ast.CreateIndexStmt:
+		cons := &ast.Constraint{Tp: ast.ConstraintIndex, Name: x.IndexName, Keys: x.IndexPartSpecifications, Option: x.IndexOption}
+		switch x.KeyType {
+		case ast.IndexKeyTypeUnique:
+			cons.Tp = ast.ConstraintUniqIndex
+		case ast.IndexKeyTypeFullText, ast.IndexKeyTypeSpatial:
+			cons.Tp = ast.ConstraintFulltext
+		}
+		specs = []*ast.AlterTableSpec{{Tp: ast.AlterTableAddConstraint, Constraint: cons}}
+		lockAlg = x.LockAlg
+	case *ast.DropIndexStmt:
+		specs = []*ast.AlterTableSpec{{Tp: ast.AlterTableDropIndex, Name: x.IndexName}}
+		lockAlg = x.LockAlg
+	default:
+		return nil, ErrNotSupportedYet.GenWithStackByArgs("online DDL analysis of the statement")
+	}
+
+	a := &onlineAnalyzer{tbInfo: tbInfo, rules: rules}
+	result := make([]*OnlineDDL, 0, len(specs))
+	algorithm, lock := ast.AlgorithmTypeDefault, ast.LockTypeDefault
+	if lockAlg != nil {
+		algorithm, lock = lockAlg.AlgorithmTp, lockAlg.LockTp
+	}
+	for _, spec := range specs {
+		switch spec.Tp {
+		case ast.AlterTableAlgorithm:
+			algorithm = spec.Algorithm
+		case ast.AlterTableLock:
+			lock = spec.LockType
+		}
+		d, err := a.analyze(spec)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	for _, d := range result {
+		if err := checkAlgorithmAndLock(d, algorithm, lock, rules); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// reasonInstantLock is the reason why MySQL rejects ALGORITHM=INSTANT with LOCK=SHARED or EXCLUSIVE.
+const reasonInstantLock = "Only LOCK=DEFAULT is permitted for operations using ALGORITHM=INSTANT"
+
+// checkAlgorithmAndLock checks whether a spec can be executed with the
+// requested algorithm and lock.
+func checkAlgorithmAndLock(d *OnlineDDL, algorithm ast.AlgorithmType, lock ast.LockType, rules OnlineDDLRules) error {
+	if algorithm == ast.AlgorithmTypeInstant && (lock == ast.LockTypeShared || lock == ast.LockTypeExclusive) && rules == MySQLRules {
+		return ErrAlterOperationNotSupportedReason.GenWithStackByArgs("LOCK=NONE/SHARED/EXCLUSIVE", reasonInstantLock, "LOCK=DEFAULT")
+	}
+	try := "ALGORITHM=" + d.Algorithm.String()
+	if algorithm == ast.AlgorithmTypeCopy && rules == TiDBRules {
+		return ErrAlterOperationNotSupported.GenWithStackByArgs("ALGORITHM=COPY", try)
+	}
+	// The algorithms are ordered from the most expensive to the cheapest, and
+	// MySQL can always execute a spec with a more expensive algorithm.
+	if algorithm != ast.AlgorithmTypeDefault && algorithm > d.Algorithm {
+		requested := "ALGORITHM=" + algorithm.String()
+		if d.Reason != "" {
+			return ErrAlterOperationNotSupportedReason.GenWithStackByArgs(requested, d.Reason, try)
+		}
+		return ErrAlterOperationNotSupported.GenWithStackByArgs(requested, try)
+	}
+	if lock == ast.LockTypeNone && d.BlocksWrites {
+		reason := d.Reason
+		if d.Algorithm == ast.AlgorithmTypeCopy {
+			reason = reasonMessage(mysql.ErrAlterOperationNotSupportedReasonCopy)
+		}
+		if reason != "" {
+			return ErrAlterOperationNotSupportedReason.GenWithStackByArgs("LOCK=NONE", reason, "LOCK=SHARED")
+		}
+		return ErrAlterOperationNotSupported.GenWithStackByArgs("LOCK=NONE", "LOCK=SHARED")
+	}
+	return nil
+}
+
+func reasonMessage(code uint16) string {
+	return mysql.MySQLErrName[code].Raw
+}
+
+type onlineAnalyzer struct {
+	tbInfo *model.TableInfo
+	rules  OnlineDDLRules
+}
+
+func (a *onlineAnalyzer) instant(spec *ast.AlterTableSpec, action model.ActionType) *OnlineDDL {
+	return &OnlineDDL{Spec: spec, Action: action, Algorithm: ast.AlgorithmTypeInstant}
+}
+
+// inplace returns an INPLACE operation which doesn't block writes.
+func (a *onlineAnalyzer) inplace(spec *ast.AlterTableSpec, action model.ActionType, reorg bool, reason uint16) *OnlineDDL {
+	d := &OnlineDDL{Spec: spec, Action: action, Algorithm: ast.AlgorithmTypeInplace, Reorg: reorg}
+	if reason != 0 {
+		d.Reason = reasonMessage(reason)
+	}
+	return d
+}
+
+// copying returns a COPY operation, which rebuilds the table and blocks writes.
+func (a *onlineAnalyzer) copying(spec *ast.AlterTableSpec, action model.ActionType, reason uint16) *OnlineDDL {
+	d := &OnlineDDL{Spec: spec, Action: action, Algorithm: ast.AlgorithmTypeCopy, BlocksWrites: true, Reorg: true}
+	if reason != 0 {
+		d.Reason = reasonMessage(reason)
+	}
+	return d
+}
+
+func (a *onlineAnalyzer) unsupported(spec *ast.AlterTableSpec) error {
+	text, _ := restoreText(spec)
+	return ErrNotSupportedYet.GenWithStackByArgs("ALTER TABLE " + text)
+}
+
+func (a *onlineAnalyzer) analyze(spec *ast.AlterTableSpec) (*OnlineDDL, error) {
+	tidb := a.rules == TiDBRules
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		return a.analyzeOptions(spec), nil
+	case ast.AlterTableAddColumns:
+		return a.analyzeAddColumns(spec)
+	case ast.AlterTableDropColumn:
+		return a.analyzeDropColumn(spec), nil
+	case ast.AlterTableModifyColumn:
+		return a.analyzeChangeColumn(spec, spec.NewColumns[0].Name.Name)
+	case ast.AlterTableChangeColumn:
+		return a.analyzeChangeColumn(spec, spec.OldColumnName.Name)
+	case ast.AlterTableRenameColumn:
+		if !tidb && a.inForeignKey(spec.OldColumnName.Name.L) {
+			return a.inplace(spec, model.ActionModifyColumn, false, mysql.ErrAlterOperationNotSupportedReasonFkRename), nil
+		}
+		return a.instant(spec, model.ActionModifyColumn), nil
+	case ast.AlterTableAlterColumn:
+		return a.instant(spec, model.ActionSetDefaultValue), nil
+	case ast.AlterTableAddConstraint:
+		return a.analyzeAddConstraint(spec), nil
+	case ast.AlterTableDropPrimaryKey:
+		if tidb {
+			return a.dropPrimaryKeyTiDB(spec)
+		}
+		return a.copying(spec, model.ActionDropPrimaryKey, mysql.ErrAlterOperationNotSupportedReasonNopk), nil
+	case ast.AlterTableDropIndex:
+		action := model.ActionDropIndex
+		if strings.EqualFold(spec.Name, mysql.PrimaryKeyName) || (a.tbInfo != nil && isPrimaryIndex(a.tbInfo, spec.Name)) {
+			action = model.ActionDropPrimaryKey
+			if !tidb {
+				return a.copying(spec, action, mysql.ErrAlterOperationNotSupportedReasonNopk), nil
+			}
+			return a.dropPrimaryKeyTiDB(spec)
+		}
+		if tidb {
+			return a.instant(spec, action), nil
+		}
+		return a.inplace(spec, action, false, 0), nil
+	case ast.AlterTableDropForeignKey:
+		if tidb {
+			return a.instant(spec, model.ActionDropForeignKey), nil
+		}
+		return a.inplace(spec, model.ActionDropForeignKey, false, 0), nil
+	case ast.AlterTableDropCheck:
+		return a.instant(spec, model.ActionDropCheckConstraint), nil
+	case ast.AlterTableAlterCheck:
+		if !tidb && spec.Constraint.Enforced {
+			return a.copying(spec, model.ActionAlterCheckConstraint, 0), nil
+		}
+		return a.instant(spec, model.ActionAlterCheckConstraint), nil
+	case ast.AlterTableRenameIndex:
+		return a.instant(spec, model.ActionRenameIndex), nil
+	case ast.AlterTableIndexInvisible:
+		return a.instant(spec, model.ActionAlterIndexVisibility), nil
+	case ast.AlterTableRenameTable:
+		return a.instant(spec, model.ActionRenameTable), nil
+	case ast.AlterTableSetTiFlashReplica:
+		return a.instant(spec, model.ActionSetTiFlashReplica), nil
+	case ast.AlterTableAttributes:
+		return a.instant(spec, model.ActionAlterTableAttributes), nil
+	case ast.AlterTablePartitionAttributes:
+		return a.instant(spec, model.ActionAlterTablePartitionAttributes), nil
+	case ast.AlterTableAlterPartition, ast.AlterTablePartitionOptions, ast.AlterTablePlacement:
+		return a.instant(spec, model.ActionNone), nil
+	case ast.AlterTableLock, ast.AlterTableAlgorithm, ast.AlterTableEnableKeys, ast.AlterTableDisableKeys,
+		ast.AlterTableWithValidation, ast.AlterTableWithoutValidation:
+		return a.instant(spec, model.ActionNone), nil
+	case ast.AlterTableForce:
+		if tidb {
+			return a.instant(spec, model.ActionNone), nil
+		}
+		return a.inplace(spec, model.ActionNone, true, 0), nil
+	case ast.AlterTableOrderByColumns:
+		if tidb {
+			return a.instant(spec, model.ActionNone), nil
+		}
+		return a.copying(spec, model.ActionNone, 0), nil
+	case ast.AlterTableAddPartitions:
+		if a.isRangeOrListPartitioned() {
+			if tidb {
+				return a.instant(spec, model.ActionAddTablePartition), nil
+			}
+			return a.inplace(spec, model.ActionAddTablePartition, false, 0), nil
+		}
+		if tidb {
+			return nil, a.unsupported(spec)
+		}
+		return a.partitionOperation(spec, model.ActionAddTablePartition, true), nil
+	case ast.AlterTableDropPartition:
+		if tidb {
+			return a.instant(spec, model.ActionDropTablePartition), nil
+		}
+		return a.partitionOperation(spec, model.ActionDropTablePartition, false), nil
+	case ast.AlterTableTruncatePartition:
+		if tidb {
+			return a.instant(spec, model.ActionTruncateTablePartition), nil
+		}
+		return a.partitionOperation(spec, model.ActionTruncateTablePartition, false), nil
+	case ast.AlterTableExchangePartition:
+		if tidb {
+			return a.instant(spec, model.ActionExchangeTablePartition), nil
+		}
+		return a.partitionOperation(spec, model.ActionExchangeTablePartition, false), nil
+	case ast.AlterTableCoalescePartitions, ast.AlterTableReorganizePartition, ast.AlterTableRebuildPartition:
+		if tidb {
+			return nil, a.unsupported(spec)
+		}
+		return a.partitionOperation(spec, model.ActionNone, true), nil
+	case ast.AlterTablePartition:
+		if tidb {
+			return nil, a.unsupported(spec)
+		}
+		return a.copying(spec, model.ActionAlterTablePartitioning, 0), nil
+	case ast.AlterTableRemovePartitioning:
+		if tidb {
+			return nil, a.unsupported(spec)
+		}
+		return a.copying(spec, model.ActionRemovePartitioning, 0), nil
+	}
+	if tidb {
+		return a.instant(spec, model.ActionNone), nil
+	}
+	// The other operations are assumed to rebuild the table.
+	return a.copying(spec, model.ActionNone, 0), nil
+}
+
+// partitionOperation returns an INPLACE partition operation of MySQL, which blocks writes.
+func (a *onlineAnalyzer) partitionOperation(spec *ast.AlterTableSpec, action model.ActionType, reorg bool) *OnlineDDL {
+	d := a.inplace(spec, action, reorg, mysql.ErrAlterOperationNotSupportedReasonPartition)
+	d.BlocksWrites = true
+	return d
+}
+
+func (a *onlineAnalyzer) isRangeOrListPartitioned() bool {
+	if a.tbInfo == nil || a.tbInfo.Partition == nil {
+		return false
+	}
+	tp := a.tbInfo.Partition.Type
+	return tp == model.PartitionTypeRange || tp == model.PartitionTypeList
+}
+
+func isPrimaryIndex(tbInfo *model.TableInfo, name string) bool {
+	idx := tbInfo.FindIndexByName(model.NewCIStr(name).L)
+	return idx != nil && idx.Primary
+}
+
+func (a *onlineAnalyzer) column(name string) *model.ColumnInfo {
+	if a.tbInfo == nil {
+		return nil
+	}
+	return model.FindColumnInfo(a.tbInfo.Columns, name)
+}
+
+func (a *onlineAnalyzer) inIndex(name string) bool {
+	if a.tbInfo == nil {
+		return false
+	}
+	if a.tbInfo.PKIsHandle {
+		if col := a.column(name); col != nil && mysql.HasPriKeyFlag(col.Flag) {
+			return true
+		}
+	}
+	for _, idx := range a.tbInfo.Indices {
+		for _, col := range idx.Columns {
+			if col.Name.L == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *onlineAnalyzer) inForeignKey(name string) bool {
+	if a.tbInfo == nil {
+		return false
+	}
+	for _, fk := range a.tbInfo.ForeignKeys {
+		for _, col := range fk.Cols {
+			if col.L == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// slower returns the more expensive one of two operations.
+func slower(d1, d2 *OnlineDDL) *OnlineDDL {
+	if d2.Algorithm < d1.Algorithm || (d2.Algorithm == d1.Algorithm && d2.BlocksWrites && !d1.BlocksWrites) {
+		return d2
+	}
+	return d1
+}
+
+// tableOptionAction returns the action of changing a table option. The
+// charset and the collation are handled by the caller, since they are changed together.
+func tableOptionAction(tp ast.TableOptionType) model.ActionType {
+	switch tp {
+	case ast.TableOptionComment:
+		return model.ActionModifyTableComment
+	case ast.TableOptionAutoIncrement:
+		return model.ActionRebaseAutoID
+	case ast.TableOptionAutoRandomBase:
+		return model.ActionRebaseAutoRandomBase
+	case ast.TableOptionAutoIdCache:
+		return model.ActionModifyTableAutoIdCache
+	case ast.TableOptionShardRowID:
+		return model.ActionShardRowID
+	}
+	if isPlacementOption(tp) {
+		return model.ActionAlterTablePlacement
+	}
+	return model.ActionNone
+}
+
+func (a *onlineAnalyzer) analyzeOptions(spec *ast.AlterTableSpec) *OnlineDDL {
+	tidb := a.rules == TiDBRules
+	result := a.instant(spec, model.ActionNone)
+	action := model.ActionNone
+	for _, op := range spec.Options {
+		var d *OnlineDDL
+		switch op.Tp {
+		case ast.TableOptionCharset, ast.TableOptionCollate:
+			action := model.ActionModifyTableCharsetAndCollate
+			switch {
+			case tidb:
+				d = a.instant(spec, action)
+			case op.Tp == ast.TableOptionCharset && op.UintValue == ast.TableOptionCharsetWithConvertTo:
+				d = a.copying(spec, action, 0)
+			default:
+				d = a.inplace(spec, action, true, 0)
+			}
+		case ast.TableOptionComment, ast.TableOptionAutoRandomBase, ast.TableOptionAutoIdCache,
+			ast.TableOptionShardRowID:
+			d = a.instant(spec, tableOptionAction(op.Tp))
+		case ast.TableOptionAutoIncrement:
+			if tidb {
+				d = a.instant(spec, model.ActionRebaseAutoID)
+			} else {
+				d = a.inplace(spec, model.ActionRebaseAutoID, false, 0)
+			}
+		case ast.TableOptionEngine, ast.TableOptionRowFormat, ast.TableOptionKeyBlockSize:
+			if tidb {
+				d = a.instant(spec, model.ActionNone)
+			} else {
+				d = a.inplace(spec, model.ActionNone, true, 0)
+			}
+		default:
+			if tidb {
+				d = a.instant(spec, tableOptionAction(op.Tp))
+			} else {
+				d = a.inplace(spec, tableOptionAction(op.Tp), false, 0)
+			}
+		}
+		// The action is the one of the most expensive option which has an action.
+		if slower(result, d) == d {
+			result = d
+			if d.Action != model.ActionNone {
+				action = d.Action
+			}
+		} else if action == model.ActionNone {
+			action = d.Action
+		}
+	}
+	result.Action = action
+	return result
+}
+
+// dropPrimaryKeyTiDB returns the DROP PRIMARY KEY of TiDB, which can't drop
+// a clustered primary key, including the one of an AUTO_RANDOM column.
+func (a *onlineAnalyzer) dropPrimaryKeyTiDB(spec *ast.AlterTableSpec) (*OnlineDDL, error) {
+	if a.tbInfo != nil && (a.tbInfo.PKIsHandle || a.tbInfo.IsCommonHandle) {
+		return nil, a.unsupported(spec)
+	}
+	return a.instant(spec, model.ActionDropPrimaryKey), nil
+}
+
+func (a *onlineAnalyzer) analyzeAddColumns(spec *ast.AlterTableSpec) (*OnlineDDL, error) {
+	tidb := a.rules == TiDBRules
+	action := model.ActionAddColumn
+	if len(spec.NewColumns) > 1 {
+		action = model.ActionAddColumns
+	}
+	result := a.instant(spec, action)
+	if len(spec.NewConstraints) > 0 {
+		result = a.inplace(spec, action, true, 0)
+	}
+	for _, def := range spec.NewColumns {
+		d := a.instant(spec, action)
+		for _, opt := range def.Options {
+			switch opt.Tp {
+			case ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey:
+				d = slower(d, a.inplace(spec, action, true, 0))
+			case ast.ColumnOptionAutoIncrement:
+				if !tidb {
+					auto := a.inplace(spec, action, true, mysql.ErrAlterOperationNotSupportedReasonAutoinc)
+					auto.BlocksWrites = true
+					d = slower(d, auto)
+				}
+			case ast.ColumnOptionGenerated:
+				if opt.Stored {
+					if tidb {
+						return nil, a.unsupported(spec)
+					}
+					d = slower(d, a.copying(spec, action, 0))
+				}
+			}
+		}
+		result = slower(result, d)
+	}
+	return result, nil
+}
+
+func (a *onlineAnalyzer) analyzeDropColumn(spec *ast.AlterTableSpec) *OnlineDDL {
+	if a.rules == TiDBRules {
+		return a.instant(spec, model.ActionDropColumn)
+	}
+	name := spec.OldColumnName.Name.L
+	col := a.column(name)
+	if a.inIndex(name) || (col != nil && col.IsGenerated() && col.GeneratedStored) {
+		return a.inplace(spec, model.ActionDropColumn, true, 0)
+	}
+	return a.instant(spec, model.ActionDropColumn)
+}
+
+func (a *onlineAnalyzer) analyzeAddConstraint(spec *ast.AlterTableSpec) *OnlineDDL {
+	tidb := a.rules == TiDBRules
+	switch spec.Constraint.Tp {
+	case ast.ConstraintPrimaryKey:
+		return a.inplace(spec, model.ActionAddPrimaryKey, true, 0)
+	case ast.ConstraintFulltext:
+		d := a.inplace(spec, model.ActionAddIndex, true, mysql.ErrAlterOperationNotSupportedReasonFts)
+		d.BlocksWrites = !tidb
+		return d
+	case ast.ConstraintForeignKey:
+		if tidb {
+			return a.instant(spec, model.ActionAddForeignKey)
+		}
+		return a.copying(spec, model.ActionAddForeignKey, mysql.ErrAlterOperationNotSupportedReasonFkCheck)
+	case ast.ConstraintCheck:
+		if tidb || !spec.Constraint.Enforced {
+			return a.instant(spec, model.ActionAddCheckConstraint)
+		}
+		return a.copying(spec, model.ActionAddCheckConstraint, 0)
+	}
+	return a.inplace(spec, model.ActionAddIndex, true, 0)
+}
+
+// analyzeChangeColumn analyzes MODIFY COLUMN and CHANGE COLUMN.
+func (a *onlineAnalyzer) analyzeChangeColumn(spec *ast.AlterTableSpec, oldName model.CIStr) (*OnlineDDL, error) {
+	old := a.column(oldName.L)
+	if old == nil {
+		// The column is added by a previous spec of the statement, which
+		// decides how the statement is executed.
+		return a.instant(spec, model.ActionModifyColumn), nil
+	}
+	b := &tableBuilder{opts: &BuildOptions{}, tbInfo: &model.TableInfo{Charset: a.tbInfo.Charset, Collate: a.tbInfo.Collate}}
+	col, _, err := b.buildColumn(spec.NewColumns[0])
+	if err != nil {
+		return nil, err
+	}
+	moved := spec.Position != nil && spec.Position.Tp != ast.ColumnPositionNone
+	if a.rules == TiDBRules {
+		if (old.GeneratedStored || col.GeneratedStored) &&
+			(old.GeneratedExprString != col.GeneratedExprString || old.GeneratedStored != col.GeneratedStored) {
+			return nil, a.unsupported(spec)
+		}
+		if needReorgToChangeColumn(old, col) {
+			return a.inplace(spec, model.ActionModifyColumn, true, 0), nil
+		}
+		return a.instant(spec, model.ActionModifyColumn), nil
+	}
+
+	if old.GeneratedExprString != col.GeneratedExprString || old.GeneratedStored != col.GeneratedStored {
+		if old.GeneratedStored || col.GeneratedStored {
+			return a.copying(spec, model.ActionModifyColumn, 0), nil
+		}
+		return a.inplace(spec, model.ActionModifyColumn, false, 0), nil
+	}
+	var d *OnlineDDL
+	switch {
+	case sameColumnType(&old.FieldType, &col.FieldType), isEnumOrSetAppended(&old.FieldType, &col.FieldType):
+		d = a.instant(spec, model.ActionModifyColumn)
+	case isVarcharExtended(&old.FieldType, &col.FieldType):
+		d = a.inplace(spec, model.ActionModifyColumn, false, 0)
+	default:
+		return a.copying(spec, model.ActionModifyColumn, mysql.ErrAlterOperationNotSupportedReasonColumnType), nil
+	}
+	if moved || mysql.HasNotNullFlag(old.Flag) != mysql.HasNotNullFlag(col.Flag) {
+		d = slower(d, a.inplace(spec, model.ActionModifyColumn, true, 0))
+	}
+	return d, nil
+}
+
+// storageFlags are the flags which change how a column is stored.
+const storageFlags = mysql.UnsignedFlag | mysql.ZerofillFlag | mysql.BinaryFlag
+
+func sameColumnType(from, to *types.FieldType) bool {
+	return from.Tp == to.Tp && from.Flen == to.Flen && from.Decimal == to.Decimal &&
+		from.Charset == to.Charset && from.Collate == to.Collate &&
+		from.Flag&storageFlags == to.Flag&storageFlags && equalElems(from.Elems, to.Elems)
+}
+
+func equalElems(elems1, elems2 []string) bool {
+	if len(elems1) != len(elems2) {
+		return false
+	}
+	for i := range elems1 {
+		if elems1[i] != elems2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isEnumOrSetAppended reports whether members are appended to an ENUM or SET
+// column without changing its storage size.
+func isEnumOrSetAppended(from, to *types.FieldType) bool {
+	if from.Tp != to.Tp || (from.Tp != mysql.TypeEnum && from.Tp != mysql.TypeSet) ||
+		from.Charset != to.Charset || from.Collate != to.Collate || len(to.Elems) < len(from.Elems) ||
+		!equalElems(from.Elems, to.Elems[:len(from.Elems)]) {
+		return false
+	}
+	if from.Tp == mysql.TypeEnum {
+		return (len(from.Elems) <= 255) == (len(to.Elems) <= 255)
+	}
+	return (len(from.Elems)+7)/8 == (len(to.Elems)+7)/8
+}
+
+// isVarcharExtended reports whether a VARCHAR column is extended without
+// changing the number of its length bytes.
+func isVarcharExtended(from, to *types.FieldType) bool {
+	if from.Tp != mysql.TypeVarchar || to.Tp != mysql.TypeVarchar || from.Charset != to.Charset ||
+		from.Collate != to.Collate || from.Flag&storageFlags != to.Flag&storageFlags || to.Flen < from.Flen {
+		return false
+	}
+	maxLen := 1
+	if cs, err := charset.GetCharsetInfo(from.Charset); err == nil {
+		maxLen = cs.Maxlen
+	}
+	return (from.Flen*maxLen <= 255) == (to.Flen*maxLen <= 255)
+}
+
+func isIntegerType(tp byte) bool {
+	switch tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		return true
+	}
+	return false
+}
+
+func isStringType(tp byte) bool {
+	switch tp {
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString, mysql.TypeBlob,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return true
+	}
+	return false
+}
+
+// needReorgToChangeColumn reports whether TiDB needs to reorganize the data to
+// change the type of a column.
+func needReorgToChangeColumn(from, to *model.ColumnInfo) bool {
+	toggleSign := mysql.HasUnsignedFlag(from.Flag) != mysql.HasUnsignedFlag(to.Flag)
+	if from.Charset != to.Charset && !(from.Charset == charset.CharsetUTF8 && to.Charset == charset.CharsetUTF8MB4) {
+		return true
+	}
+	if from.Tp == to.Tp {
+		switch from.Tp {
+		case mysql.TypeNewDecimal:
+			return from.Flen != to.Flen || from.Decimal != to.Decimal || toggleSign
+		case mysql.TypeEnum, mysql.TypeSet:
+			return len(to.Elems) < len(from.Elems) || !equalElems(from.Elems, to.Elems[:len(from.Elems)])
+		case mysql.TypeString:
+			// Binary strings are padded to the length.
+			if mysql.HasBinaryFlag(from.Flag) && from.Flen != to.Flen {
+				return true
+			}
+		}
+		if isIntegerType(from.Tp) {
+			return toggleSign
+		}
+		return to.Flen < from.Flen || toggleSign
+	}
+	// CHAR and VARCHAR columns are padded differently.
+	if (from.Tp == mysql.TypeString) != (to.Tp == mysql.TypeString) && isStringType(from.Tp) && isStringType(to.Tp) &&
+		(from.Tp == mysql.TypeVarchar || to.Tp == mysql.TypeVarchar) {
+		return true
+	}
+	if isStringType(from.Tp) && isStringType(to.Tp) {
+		return to.Flen < from.Flen || toggleSign
+	}
+	if isIntegerType(from.Tp) && isIntegerType(to.Tp) {
+		oldFlen, _ := mysql.GetDefaultFieldLengthAndDecimal(from.Tp)
+		newFlen, _ := mysql.GetDefaultFieldLengthAndDecimal(to.Tp)
+		return newFlen < oldFlen || toggleSign
+	}
+	return true
+}
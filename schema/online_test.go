@@ -0,0 +1,225 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/schema"
+	"github.com/pingcap/parser/terror"
+)
+
+var _ = Suite(&testOnlineDDLSuite{})
+
+type testOnlineDDLSuite struct {
+	*parser.Parser
+	tbInfo *model.TableInfo
+}
+
+func (s *testOnlineDDLSuite) SetUpSuite(c *C) {
+	s.Parser = parser.New()
+	var err error
+	s.tbInfo, err = buildTableInfo(c, s.Parser, `create table t (
+		id int primary key,
+		a int,
+		b varchar(10),
+		c varchar(100),
+		e enum('x', 'y'),
+		p int,
+		g int as (a + 1) stored,
+		key idx_a (a),
+		constraint fk foreign key (p) references parent (id)
+	)`, nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *testOnlineDDLSuite) analyze(c *C, sql string, rules schema.OnlineDDLRules) ([]*schema.OnlineDDL, error) {
+	stmt, err := s.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil, Commentf("%s", sql))
+	return schema.AnalyzeOnlineDDL(stmt.(ast.DDLNode), s.tbInfo, rules)
+}
+
+func (s *testOnlineDDLSuite) TestMySQLRules(c *C) {
+	cases := []struct {
+		sql          string
+		action       model.ActionType
+		algorithm    ast.AlgorithmType
+		blocksWrites bool
+		reorg        bool
+	}{
+		{"alter table t add column x int", model.ActionAddColumn, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t add column (x int, y int)", model.ActionAddColumns, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t add column x int auto_increment unique", model.ActionAddColumn, ast.AlgorithmTypeInplace, true, true},
+		{"alter table t add column x int as (a) stored", model.ActionAddColumn, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t drop column b", model.ActionDropColumn, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t drop column a", model.ActionDropColumn, ast.AlgorithmTypeInplace, false, true},
+		{"alter table t rename column b to b2", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t rename column p to p2", model.ActionModifyColumn, ast.AlgorithmTypeInplace, false, false},
+		{"alter table t modify column b varchar(10) comment 'x'", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t modify column b varchar(20)", model.ActionModifyColumn, ast.AlgorithmTypeInplace, false, false},
+		{"alter table t modify column b varchar(100)", model.ActionModifyColumn, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t modify column c varchar(50)", model.ActionModifyColumn, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t modify column a bigint", model.ActionModifyColumn, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t modify column a int not null", model.ActionModifyColumn, ast.AlgorithmTypeInplace, false, true},
+		{"alter table t modify column a int first", model.ActionModifyColumn, ast.AlgorithmTypeInplace, false, true},
+		{"alter table t modify column e enum('x', 'y', 'z')", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t modify column e enum('y', 'x')", model.ActionModifyColumn, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t change column a a2 int", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t alter column a set default 1", model.ActionSetDefaultValue, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t add index idx_b (b)", model.ActionAddIndex, ast.AlgorithmTypeInplace, false, true},
+		{"create unique index idx_b on t (b)", model.ActionAddIndex, ast.AlgorithmTypeInplace, false, true},
+		{"create fulltext index idx_b on t (b)", model.ActionAddIndex, ast.AlgorithmTypeInplace, true, true},
+		{"alter table t drop index idx_a", model.ActionDropIndex, ast.AlgorithmTypeInplace, false, false},
+		{"drop index idx_a on t", model.ActionDropIndex, ast.AlgorithmTypeInplace, false, false},
+		{"alter table t drop primary key", model.ActionDropPrimaryKey, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t rename index idx_a to idx_a2", model.ActionRenameIndex, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t alter index idx_a invisible", model.ActionAlterIndexVisibility, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t add constraint fk2 foreign key (a) references parent (id)", model.ActionAddForeignKey, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t drop foreign key fk", model.ActionDropForeignKey, ast.AlgorithmTypeInplace, false, false},
+		{"alter table t add constraint c1 check (a > 0)", model.ActionAddCheckConstraint, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t comment 'x'", model.ActionModifyTableComment, ast.AlgorithmTypeInstant, false, false},
+		{"alter table t auto_increment 100", model.ActionRebaseAutoID, ast.AlgorithmTypeInplace, false, false},
+		{"alter table t comment 'x' row_format compact", model.ActionModifyTableComment, ast.AlgorithmTypeInplace, false, true},
+		{"alter table t convert to character set latin1", model.ActionModifyTableCharsetAndCollate, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t force", model.ActionNone, ast.AlgorithmTypeInplace, false, true},
+		{"alter table t partition by hash (id) partitions 4", model.ActionAlterTablePartitioning, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t remove partitioning", model.ActionRemovePartitioning, ast.AlgorithmTypeCopy, true, true},
+		{"alter table t truncate partition p0", model.ActionTruncateTablePartition, ast.AlgorithmTypeInplace, true, false},
+		{"alter table t rename to t2", model.ActionRenameTable, ast.AlgorithmTypeInstant, false, false},
+	}
+	for _, ca := range cases {
+		result, err := s.analyze(c, ca.sql, schema.MySQLRules)
+		c.Assert(err, IsNil, Commentf("%s", ca.sql))
+		c.Assert(result, HasLen, 1, Commentf("%s", ca.sql))
+		d := result[0]
+		c.Assert(d.Action, Equals, ca.action, Commentf("%s", ca.sql))
+		c.Assert(d.Algorithm, Equals, ca.algorithm, Commentf("%s", ca.sql))
+		c.Assert(d.BlocksWrites, Equals, ca.blocksWrites, Commentf("%s", ca.sql))
+		c.Assert(d.Reorg, Equals, ca.reorg, Commentf("%s", ca.sql))
+	}
+}
+
+func (s *testOnlineDDLSuite) TestTiDBRules(c *C) {
+	cases := []struct {
+		sql       string
+		action    model.ActionType
+		algorithm ast.AlgorithmType
+		reorg     bool
+	}{
+		{"alter table t add column x int as (a) virtual", model.ActionAddColumn, ast.AlgorithmTypeInstant, false},
+		{"alter table t drop column a", model.ActionDropColumn, ast.AlgorithmTypeInstant, false},
+		{"alter table t modify column a bigint", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false},
+		{"alter table t modify column a int unsigned", model.ActionModifyColumn, ast.AlgorithmTypeInplace, true},
+		{"alter table t modify column c varchar(50)", model.ActionModifyColumn, ast.AlgorithmTypeInplace, true},
+		{"alter table t modify column b varchar(100)", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false},
+		{"alter table t modify column b char(10)", model.ActionModifyColumn, ast.AlgorithmTypeInplace, true},
+		{"alter table t modify column a int not null", model.ActionModifyColumn, ast.AlgorithmTypeInstant, false},
+		{"alter table t add index idx_b (b)", model.ActionAddIndex, ast.AlgorithmTypeInplace, true},
+		{"alter table t add constraint fk2 foreign key (a) references parent (id)", model.ActionAddForeignKey, ast.AlgorithmTypeInstant, false},
+		{"alter table t convert to character set utf8mb4", model.ActionModifyTableCharsetAndCollate, ast.AlgorithmTypeInstant, false},
+		{"alter table t truncate partition p0", model.ActionTruncateTablePartition, ast.AlgorithmTypeInstant, false},
+		{"alter table t placement policy p1", model.ActionAlterTablePlacement, ast.AlgorithmTypeInstant, false},
+	}
+	for _, ca := range cases {
+		result, err := s.analyze(c, ca.sql, schema.TiDBRules)
+		c.Assert(err, IsNil, Commentf("%s", ca.sql))
+		c.Assert(result, HasLen, 1, Commentf("%s", ca.sql))
+		d := result[0]
+		c.Assert(d.Action, Equals, ca.action, Commentf("%s", ca.sql))
+		c.Assert(d.Algorithm, Equals, ca.algorithm, Commentf("%s", ca.sql))
+		c.Assert(d.BlocksWrites, IsFalse, Commentf("%s", ca.sql))
+		c.Assert(d.Reorg, Equals, ca.reorg, Commentf("%s", ca.sql))
+	}
+
+	for _, sql := range []string{
+		"alter table t partition by hash (id) partitions 4",
+		"alter table t add column x int as (a) stored",
+		"alter table t modify column g int as (a + 2) stored",
+		"alter table t modify column a int as (id) stored",
+		"alter table t drop primary key",
+		"drop index `primary` on t",
+	} {
+		_, err := s.analyze(c, sql, schema.TiDBRules)
+		c.Assert(terror.ErrorEqual(err, schema.ErrNotSupportedYet), IsTrue, Commentf("%s: %v", sql, err))
+	}
+
+	// A nonclustered primary key can be dropped.
+	tbInfo, err := buildTableInfo(c, s.Parser, "create table t (id bigint primary key nonclustered, a int)", nil)
+	c.Assert(err, IsNil)
+	stmt, err := s.ParseOneStmt("alter table t drop primary key", "", "")
+	c.Assert(err, IsNil)
+	result, err := schema.AnalyzeOnlineDDL(stmt.(ast.DDLNode), tbInfo, schema.TiDBRules)
+	c.Assert(err, IsNil)
+	c.Assert(result[0].Action, Equals, model.ActionDropPrimaryKey)
+	c.Assert(result[0].Algorithm, Equals, ast.AlgorithmTypeInstant)
+}
+
+func (s *testOnlineDDLSuite) TestAlgorithmAndLock(c *C) {
+	cases := []struct {
+		sql   string
+		rules schema.OnlineDDLRules
+		err   *terror.Error
+		msg   string
+	}{
+		{"alter table t add column x int, algorithm = instant, lock = none", schema.MySQLRules, nil, ""},
+		{"alter table t add index idx_b (b), algorithm = inplace, lock = none", schema.MySQLRules, nil, ""},
+		{"alter table t add index idx_b (b), algorithm = copy", schema.MySQLRules, nil, ""},
+		{"alter table t add column x int, algorithm = inplace", schema.TiDBRules, nil, ""},
+		{
+			"alter table t add index idx_b (b), algorithm = instant", schema.MySQLRules,
+			schema.ErrAlterOperationNotSupported, "[ddl:1845]ALGORITHM=INSTANT is not supported for this operation. Try ALGORITHM=INPLACE.",
+		},
+		{
+			"alter table t modify column a bigint, algorithm = inplace", schema.MySQLRules,
+			schema.ErrAlterOperationNotSupportedReason, "[ddl:1846]ALGORITHM=INPLACE is not supported. Reason: Cannot change column type INPLACE. Try ALGORITHM=COPY.",
+		},
+		{
+			"alter table t modify column a bigint, lock = none", schema.MySQLRules,
+			schema.ErrAlterOperationNotSupportedReason, "[ddl:1846]LOCK=NONE is not supported. Reason: COPY algorithm requires a lock. Try LOCK=SHARED.",
+		},
+		{
+			"create fulltext index idx_b on t (b) lock = none", schema.MySQLRules,
+			schema.ErrAlterOperationNotSupportedReason, "[ddl:1846]LOCK=NONE is not supported. Reason: Fulltext index creation requires a lock. Try LOCK=SHARED.",
+		},
+		{"alter table t add column x int, algorithm = instant, lock = shared", schema.TiDBRules, nil, ""},
+		{
+			"alter table t add column x int, algorithm = instant, lock = shared", schema.MySQLRules,
+			schema.ErrAlterOperationNotSupportedReason, "[ddl:1846]LOCK=NONE/SHARED/EXCLUSIVE is not supported. Reason: Only LOCK=DEFAULT is permitted for operations using ALGORITHM=INSTANT. Try LOCK=DEFAULT.",
+		},
+		{
+			"alter table t rename index idx_a to idx_a2, algorithm = instant, lock = exclusive", schema.MySQLRules,
+			schema.ErrAlterOperationNotSupportedReason, "[ddl:1846]LOCK=NONE/SHARED/EXCLUSIVE is not supported. Reason: Only LOCK=DEFAULT is permitted for operations using ALGORITHM=INSTANT. Try LOCK=DEFAULT.",
+		},
+		{
+			"alter table t add column x int, algorithm = copy", schema.TiDBRules,
+			schema.ErrAlterOperationNotSupported, "[ddl:1845]ALGORITHM=COPY is not supported for this operation. Try ALGORITHM=INSTANT.",
+		},
+		{
+			"create index idx_b on t (b) algorithm = instant", schema.TiDBRules,
+			schema.ErrAlterOperationNotSupported, "[ddl:1845]ALGORITHM=INSTANT is not supported for this operation. Try ALGORITHM=INPLACE.",
+		},
+	}
+	for _, ca := range cases {
+		result, err := s.analyze(c, ca.sql, ca.rules)
+		if ca.err == nil {
+			c.Assert(err, IsNil, Commentf("%s", ca.sql))
+			c.Assert(result, Not(HasLen), 0)
+			continue
+		}
+		c.Assert(terror.ErrorEqual(err, ca.err), IsTrue, Commentf("%s: %v", ca.sql, err))
+		c.Assert(err.Error(), Equals, ca.msg)
+	}
+}
@@ -0,0 +1,701 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/opcode"
+)
+
+// PrunePartitions returns the partitions of a table which may contain the rows
+// matching a WHERE condition, in the order of the partition definitions. It
+// returns nil if the table isn't partitioned, and all the partitions if the
+// condition is nil.
+//
+// The columns of the table are referred to by their names, qualified by alias
+// if it isn't empty, or by the name of the table otherwise. The columns of
+// other tables are ignored, so the condition of a join can be given as it is.
+//
+// Pruning is conservative: a condition which can't be analyzed matches every
+// partition, so a partition is only left out if none of its rows can match.
+// The conditions which are analyzed are comparisons, IN, BETWEEN and IS NULL
+// between a partition column and constants, combined with AND and OR:
+//   - RANGE and RANGE COLUMNS partitions are pruned by the first partition
+//     column.
+//   - LIST and LIST COLUMNS partitions are pruned by any partition column.
+//   - HASH partitions are pruned by equal conditions and small ranges.
+//
+// The partition expression of RANGE, LIST and HASH partitioning must be a
+// column. The partitions of KEY partitioning are never pruned, since the hash
+// function is defined by the storage engine. Only the strings of binary
+// collations and the dates in the 'YYYY-MM-DD hh:mm:ss' format are compared.
+//
+// The partition bounds are parsed by the parser, so a parser driver (e.g.
+// github.com/pingcap/parser/test_driver) must be imported.
+func PrunePartitions(tbInfo *model.TableInfo, alias model.CIStr, where ast.ExprNode) []model.PartitionDefinition {
+	pi := tbInfo.Partition
+	if pi == nil {
+		return nil
+	}
+	p := newPartitionPruner(tbInfo, alias)
+	set := newPartitionSet(len(pi.Definitions), true)
+	if where != nil && p.cols != nil {
+		set = p.eval(where)
+	}
+	defs := make([]model.PartitionDefinition, 0, len(pi.Definitions))
+	for i, def := range pi.Definitions {
+		if set[i] {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// partitionSet is the set of the partitions a condition may match.
+type partitionSet []bool
+
+func newPartitionSet(n int, all bool) partitionSet {
+	s := make(partitionSet, n)
+	if all {
+		for i := range s {
+			s[i] = true
+		}
+	}
+	return s
+}
+
+func (s partitionSet) intersect(o partitionSet) partitionSet {
+	for i := range s {
+		s[i] = s[i] && o[i]
+	}
+	return s
+}
+
+func (s partitionSet) union(o partitionSet) partitionSet {
+	for i := range s {
+		s[i] = s[i] || o[i]
+	}
+	return s
+}
+
+// pruneValue is a non-NULL value of a partition column. The values of integer
+// columns are compared as integers, and the values of the other columns are
+// compared as strings.
+type pruneValue struct {
+	i *big.Int
+	s string
+}
+
+func (v *pruneValue) compare(o *pruneValue) int {
+	if v.i != nil {
+		return v.i.Cmp(o.i)
+	}
+	return strings.Compare(v.s, o.s)
+}
+
+// valueRange is a range of the values of a partition column. A nil bound is
+// unbounded. The bounds of integer ranges are always inclusive.
+type valueRange struct {
+	low, high         *pruneValue
+	lowExcl, highExcl bool
+}
+
+func newValueRange(low, high *pruneValue, lowExcl, highExcl bool) valueRange {
+	r := valueRange{low: low, high: high, lowExcl: lowExcl, highExcl: highExcl}
+	if low != nil && low.i != nil && lowExcl {
+		r.low, r.lowExcl = &pruneValue{i: new(big.Int).Add(low.i, big.NewInt(1))}, false
+	}
+	if high != nil && high.i != nil && highExcl {
+		r.high, r.highExcl = &pruneValue{i: new(big.Int).Sub(high.i, big.NewInt(1))}, false
+	}
+	return r
+}
+
+func (r valueRange) isEmpty() bool {
+	if r.low == nil || r.high == nil {
+		return false
+	}
+	cmp := r.low.compare(r.high)
+	return cmp > 0 || (cmp == 0 && (r.lowExcl || r.highExcl))
+}
+
+func (r valueRange) intersect(o valueRange) valueRange {
+	if o.low != nil {
+		if r.low == nil {
+			r.low, r.lowExcl = o.low, o.lowExcl
+		} else if cmp := o.low.compare(r.low); cmp > 0 || (cmp == 0 && o.lowExcl) {
+			r.low, r.lowExcl = o.low, o.lowExcl
+		}
+	}
+	if o.high != nil {
+		if r.high == nil {
+			r.high, r.highExcl = o.high, o.highExcl
+		} else if cmp := o.high.compare(r.high); cmp < 0 || (cmp == 0 && o.highExcl) {
+			r.high, r.highExcl = o.high, o.highExcl
+		}
+	}
+	return r
+}
+
+func (r valueRange) contains(v *pruneValue) bool {
+	return !r.intersect(valueRange{low: v, high: v}).isEmpty()
+}
+
+// intersectRanges intersects two unions of ranges.
+func intersectRanges(a, b []valueRange) []valueRange {
+	ranges := make([]valueRange, 0, len(a))
+	for _, ra := range a {
+		for _, rb := range b {
+			if r := ra.intersect(rb); !r.isEmpty() {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return ranges
+}
+
+// columnCondition is a condition on a partition column: the column is NULL, or
+// its value is in one of the ranges. An empty list of ranges matches nothing.
+type columnCondition struct {
+	col    int
+	isNull bool
+	ranges []valueRange
+}
+
+type partitionPruner struct {
+	pi     *model.PartitionInfo
+	alias  model.CIStr
+	parser *parser.Parser
+	// cols are the partition columns. It is nil if the partitions can't be
+	// pruned.
+	cols []*model.ColumnInfo
+	// ranges are the ranges of the first partition column in the RANGE
+	// partitions. It is nil if a bound can't be analyzed.
+	ranges []valueRange
+	// lists are the values in the LIST partitions, NULL values are nil.
+	// A partition whose values can't be analyzed is nil.
+	lists [][][]*pruneValue
+}
+
+func newPartitionPruner(tbInfo *model.TableInfo, alias model.CIStr) *partitionPruner {
+	p := &partitionPruner{
+		pi:     tbInfo.Partition,
+		alias:  alias,
+		parser: parser.New(),
+	}
+	if p.alias.L == "" {
+		p.alias = tbInfo.Name
+	}
+	switch p.pi.Type {
+	case model.PartitionTypeRange, model.PartitionTypeList, model.PartitionTypeHash:
+	default:
+		return p
+	}
+	var cols []*model.ColumnInfo
+	if len(p.pi.Columns) > 0 {
+		for _, name := range p.pi.Columns {
+			col := model.FindColumnInfo(tbInfo.Columns, name.L)
+			if col == nil {
+				return p
+			}
+			cols = append(cols, col)
+		}
+	} else {
+		expr, err := p.parseExpr(p.pi.Expr)
+		if err != nil {
+			return p
+		}
+		colExpr, ok := expr.(*ast.ColumnNameExpr)
+		if !ok {
+			return p
+		}
+		col := model.FindColumnInfo(tbInfo.Columns, colExpr.Name.Name.L)
+		if col == nil {
+			return p
+		}
+		cols = append(cols, col)
+	}
+	switch p.pi.Type {
+	case model.PartitionTypeRange:
+		p.ranges = p.buildRanges(cols)
+	case model.PartitionTypeList:
+		p.lists = p.buildLists(cols)
+	case model.PartitionTypeHash:
+		if !mysql.IsIntegerType(cols[0].Tp) || len(p.pi.Definitions) == 0 {
+			return p
+		}
+	}
+	p.cols = cols
+	return p
+}
+
+func (p *partitionPruner) parseExpr(expr string) (ast.ExprNode, error) {
+	stmt, err := p.parser.ParseOneStmt("SELECT "+expr, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return stmt.(*ast.SelectStmt).Fields.Fields[0].Expr, nil
+}
+
+// boundValue converts a partition bound to a value of a partition column.
+// MAXVALUE is converted into a nil value.
+func (p *partitionPruner) boundValue(bound string, col *model.ColumnInfo) (v *pruneValue, isNull bool, ok bool) {
+	if strings.EqualFold(strings.TrimSpace(bound), "MAXVALUE") {
+		return nil, false, true
+	}
+	expr, err := p.parseExpr(bound)
+	if err != nil {
+		return nil, false, false
+	}
+	return constantValue(expr, col)
+}
+
+// buildRanges builds the ranges of the first partition column in the RANGE
+// partitions. The range of a partition ends at its bound and begins at the
+// bound of the previous one. If there are several partition columns, the
+// partitions are ordered by the tuple of the columns, so both ends are
+// inclusive for the first column.
+func (p *partitionPruner) buildRanges(cols []*model.ColumnInfo) []valueRange {
+	ranges := make([]valueRange, 0, len(p.pi.Definitions))
+	var low *pruneValue
+	for _, def := range p.pi.Definitions {
+		if len(def.LessThan) != len(cols) {
+			return nil
+		}
+		high, isNull, ok := p.boundValue(def.LessThan[0], cols[0])
+		if !ok || isNull {
+			return nil
+		}
+		ranges = append(ranges, newValueRange(low, high, false, len(cols) == 1))
+		low = high
+	}
+	return ranges
+}
+
+func (p *partitionPruner) buildLists(cols []*model.ColumnInfo) [][][]*pruneValue {
+	lists := make([][][]*pruneValue, 0, len(p.pi.Definitions))
+	for _, def := range p.pi.Definitions {
+		var list [][]*pruneValue
+		for _, values := range def.InValues {
+			if len(values) != len(cols) {
+				list = nil
+				break
+			}
+			tuple := make([]*pruneValue, 0, len(values))
+			for i, s := range values {
+				v, isNull, ok := p.boundValue(s, cols[i])
+				if !ok || (v == nil && !isNull) {
+					tuple = nil
+					break
+				}
+				tuple = append(tuple, v)
+			}
+			if tuple == nil {
+				list = nil
+				break
+			}
+			list = append(list, tuple)
+		}
+		lists = append(lists, list)
+	}
+	return lists
+}
+
+// constantValue converts a constant to a value of a column. It fails if the
+// constant can't be compared with the values of the column the way the server
+// does.
+func constantValue(expr ast.ExprNode, col *model.ColumnInfo) (v *pruneValue, isNull bool, ok bool) {
+	neg := false
+	if unary, ok := expr.(*ast.UnaryOperationExpr); ok && unary.Op == opcode.Minus {
+		neg, expr = true, unary.V
+	}
+	valueExpr, ok := expr.(ast.ValueExpr)
+	if !ok {
+		return nil, false, false
+	}
+	if _, ok := expr.(ast.ParamMarkerExpr); ok {
+		return nil, false, false
+	}
+	value := valueExpr.GetValue()
+	if value == nil {
+		return nil, !neg, !neg
+	}
+	switch {
+	case mysql.IsIntegerType(col.Tp):
+		i := new(big.Int)
+		switch x := value.(type) {
+		case int64:
+			i.SetInt64(x)
+		case uint64:
+			i.SetUint64(x)
+		case string:
+			if _, ok := i.SetString(strings.TrimSpace(x), 10); !ok {
+				return nil, false, false
+			}
+		default:
+			return nil, false, false
+		}
+		if neg {
+			i.Neg(i)
+		}
+		return &pruneValue{i: i}, false, true
+	case neg:
+		return nil, false, false
+	case col.Tp == mysql.TypeDate || col.Tp == mysql.TypeDatetime:
+		s, ok := value.(string)
+		if !ok {
+			return nil, false, false
+		}
+		s, ok = normalizeDatetime(s)
+		return &pruneValue{s: s}, false, ok
+	case col.Tp == mysql.TypeVarchar || col.Tp == mysql.TypeString || col.Tp == mysql.TypeVarString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, false, false
+		}
+		switch {
+		case col.Collate == "binary":
+		case strings.HasSuffix(col.Collate, "_bin"):
+			// The binary collations of the character sets pad spaces.
+			s = strings.TrimRight(s, " ")
+		default:
+			return nil, false, false
+		}
+		return &pruneValue{s: s}, false, true
+	}
+	return nil, false, false
+}
+
+// normalizeDatetime formats a date or a datetime in the 'YYYY-MM-DD hh:mm:ss'
+// format with any punctuation as the delimiters, so that it can be compared
+// with the other ones as a string.
+func normalizeDatetime(s string) (string, bool) {
+	parts := strings.FieldsFunc(strings.TrimSpace(s), func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if len(parts) != 3 && len(parts) != 6 && len(parts) != 7 {
+		return "", false
+	}
+	var fields [7]int
+	for i, part := range parts {
+		if len(part) > 6 {
+			return "", false
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return "", false
+		}
+		if i == 6 {
+			// The fractional part is in microseconds.
+			n *= int(math.Pow10(6 - len(part)))
+		}
+		fields[i] = n
+	}
+	if len(parts[0]) <= 2 {
+		// A two-digit year is in 2000-2069 if it's less than 70, and in 1970-1999 otherwise.
+		if fields[0] < 70 {
+			fields[0] += 2000
+		} else {
+			fields[0] += 1900
+		}
+	}
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d.%06d",
+		fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6]), true
+}
+
+func (p *partitionPruner) all() partitionSet {
+	return newPartitionSet(len(p.pi.Definitions), true)
+}
+
+func (p *partitionPruner) eval(expr ast.ExprNode) partitionSet {
+	switch x := expr.(type) {
+	case *ast.ParenthesesExpr:
+		return p.eval(x.Expr)
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.LogicAnd:
+			return p.evalAnd(flattenAnd(x, nil))
+		case opcode.LogicOr:
+			return p.eval(x.L).union(p.eval(x.R))
+		}
+	}
+	return p.evalAnd([]ast.ExprNode{expr})
+}
+
+func flattenAnd(expr ast.ExprNode, conds []ast.ExprNode) []ast.ExprNode {
+	switch x := expr.(type) {
+	case *ast.ParenthesesExpr:
+		return flattenAnd(x.Expr, conds)
+	case *ast.BinaryOperationExpr:
+		if x.Op == opcode.LogicAnd {
+			return flattenAnd(x.R, flattenAnd(x.L, conds))
+		}
+	}
+	return append(conds, expr)
+}
+
+// evalAnd evaluates the conjunction of conditions. The conditions on the same
+// partition column are combined before the partitions are pruned, e.g.
+// `a >= 1 AND a < 3` matches the range [1, 3) of a.
+func (p *partitionPruner) evalAnd(conds []ast.ExprNode) partitionSet {
+	set := p.all()
+	var colConds []*columnCondition
+	for _, cond := range conds {
+		c := p.columnCondition(cond)
+		if c == nil {
+			if x, ok := cond.(*ast.BinaryOperationExpr); ok && x.Op == opcode.LogicOr {
+				set.intersect(p.eval(x))
+			}
+			continue
+		}
+		var merged *columnCondition
+		for _, cc := range colConds {
+			if cc.col == c.col {
+				merged = cc
+				break
+			}
+		}
+		if merged == nil {
+			colConds = append(colConds, c)
+			continue
+		}
+		switch {
+		case merged.isNull && c.isNull:
+		case merged.isNull || c.isNull:
+			// NULL is in no range.
+			merged.isNull, merged.ranges = false, []valueRange{}
+		default:
+			merged.ranges = intersectRanges(merged.ranges, c.ranges)
+		}
+	}
+	for _, c := range colConds {
+		if c.isNull {
+			set.intersect(p.nullPartitions(c.col))
+		} else {
+			set.intersect(p.rangePartitions(c.col, c.ranges))
+		}
+	}
+	return set
+}
+
+// partitionColumn returns the offset of a partition column in the partition
+// columns, or -1 if the expression isn't a partition column.
+func (p *partitionPruner) partitionColumn(expr ast.ExprNode) int {
+	colExpr, ok := expr.(*ast.ColumnNameExpr)
+	if !ok {
+		return -1
+	}
+	name := colExpr.Name
+	if name.Table.L != "" && name.Table.L != p.alias.L {
+		return -1
+	}
+	for i, col := range p.cols {
+		if col.Name.L == name.Name.L {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnCondition converts a condition between a partition column and
+// constants. It returns nil if the condition isn't such one.
+func (p *partitionPruner) columnCondition(expr ast.ExprNode) *columnCondition {
+	switch x := expr.(type) {
+	case *ast.BinaryOperationExpr:
+		op := x.Op
+		col, constant := p.partitionColumn(x.L), x.R
+		if col < 0 {
+			col, constant = p.partitionColumn(x.R), x.L
+			switch op {
+			case opcode.LT:
+				op = opcode.GT
+			case opcode.LE:
+				op = opcode.GE
+			case opcode.GT:
+				op = opcode.LT
+			case opcode.GE:
+				op = opcode.LE
+			}
+		}
+		if col < 0 {
+			return nil
+		}
+		v, isNull, ok := constantValue(constant, p.cols[col])
+		if !ok {
+			return nil
+		}
+		if isNull {
+			if op == opcode.NullEQ {
+				return &columnCondition{col: col, isNull: true}
+			}
+			switch op {
+			case opcode.EQ, opcode.LT, opcode.LE, opcode.GT, opcode.GE:
+				// Nothing is compared with NULL.
+				return &columnCondition{col: col, ranges: []valueRange{}}
+			}
+			return nil
+		}
+		var r valueRange
+		switch op {
+		case opcode.EQ, opcode.NullEQ:
+			r = newValueRange(v, v, false, false)
+		case opcode.LT:
+			r = newValueRange(nil, v, false, true)
+		case opcode.LE:
+			r = newValueRange(nil, v, false, false)
+		case opcode.GT:
+			r = newValueRange(v, nil, true, false)
+		case opcode.GE:
+			r = newValueRange(v, nil, false, false)
+		default:
+			return nil
+		}
+		return &columnCondition{col: col, ranges: []valueRange{r}}
+	case *ast.PatternInExpr:
+		col := p.partitionColumn(x.Expr)
+		if x.Not || x.Sel != nil || col < 0 {
+			return nil
+		}
+		c := &columnCondition{col: col, ranges: []valueRange{}}
+		for _, item := range x.List {
+			v, isNull, ok := constantValue(item, p.cols[col])
+			if !ok {
+				return nil
+			}
+			if !isNull {
+				c.ranges = append(c.ranges, newValueRange(v, v, false, false))
+			}
+		}
+		return c
+	case *ast.BetweenExpr:
+		col := p.partitionColumn(x.Expr)
+		if x.Not || col < 0 {
+			return nil
+		}
+		low, lowNull, ok1 := constantValue(x.Left, p.cols[col])
+		high, highNull, ok2 := constantValue(x.Right, p.cols[col])
+		if !ok1 || !ok2 {
+			return nil
+		}
+		if lowNull || highNull {
+			return &columnCondition{col: col, ranges: []valueRange{}}
+		}
+		return &columnCondition{col: col, ranges: []valueRange{newValueRange(low, high, false, false)}}
+	case *ast.IsNullExpr:
+		col := p.partitionColumn(x.Expr)
+		if x.Not || col < 0 {
+			return nil
+		}
+		return &columnCondition{col: col, isNull: true}
+	}
+	return nil
+}
+
+// nullPartitions returns the partitions which may contain NULL in a partition
+// column. NULL is less than any value in RANGE partitioning, and it is hashed
+// as 0 in HASH partitioning.
+func (p *partitionPruner) nullPartitions(col int) partitionSet {
+	set := newPartitionSet(len(p.pi.Definitions), false)
+	switch p.pi.Type {
+	case model.PartitionTypeRange:
+		if col != 0 || p.ranges == nil {
+			return p.all()
+		}
+		set[0] = true
+	case model.PartitionTypeList:
+		for i, list := range p.lists {
+			set[i] = list == nil
+			for _, tuple := range list {
+				set[i] = set[i] || tuple[col] == nil
+			}
+		}
+	case model.PartitionTypeHash:
+		set[0] = true
+	}
+	return set
+}
+
+// maxHashRange is the maximum number of values in a range which are hashed one
+// by one to prune HASH partitions.
+const maxHashRange = 256
+
+// rangePartitions returns the partitions which may contain a value of a
+// partition column in one of the ranges.
+func (p *partitionPruner) rangePartitions(col int, ranges []valueRange) partitionSet {
+	set := newPartitionSet(len(p.pi.Definitions), false)
+	for _, r := range ranges {
+		switch p.pi.Type {
+		case model.PartitionTypeRange:
+			if col != 0 || p.ranges == nil {
+				return p.all()
+			}
+			for i, pr := range p.ranges {
+				set[i] = set[i] || !pr.intersect(r).isEmpty()
+			}
+		case model.PartitionTypeList:
+			for i, list := range p.lists {
+				set[i] = set[i] || list == nil
+				for _, tuple := range list {
+					set[i] = set[i] || (tuple[col] != nil && r.contains(tuple[col]))
+				}
+			}
+		case model.PartitionTypeHash:
+			if r.low == nil || r.high == nil {
+				return p.all()
+			}
+			n := new(big.Int).Sub(r.high.i, r.low.i)
+			if n.Cmp(big.NewInt(maxHashRange)) >= 0 {
+				return p.all()
+			}
+			unsigned := mysql.HasUnsignedFlag(p.cols[0].Flag)
+			for i := new(big.Int).Set(r.low.i); i.Cmp(r.high.i) <= 0; i.Add(i, big.NewInt(1)) {
+				if unsigned && i.Sign() < 0 {
+					// An unsigned column has no negative value.
+					continue
+				}
+				set[hashPartition(i, len(p.pi.Definitions), unsigned)] = true
+			}
+		}
+	}
+	return set
+}
+
+// hashPartition returns the HASH partition of a value like the server:
+// ABS(MOD(v, n)), where v is converted into a signed integer, or MOD(v, n)
+// for the values of an unsigned column.
+func hashPartition(v *big.Int, n int, unsigned bool) int {
+	if unsigned {
+		return int(v.Uint64() % uint64(n))
+	}
+	var i int64
+	if v.IsInt64() {
+		i = v.Int64()
+	} else {
+		i = int64(v.Uint64())
+	}
+	idx := i % int64(n)
+	if idx < 0 {
+		idx = -idx
+	}
+	return int(idx)
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/schema"
+)
+
+var _ = Suite(&testPruneSuite{})
+
+type testPruneSuite struct {
+	*parser.Parser
+}
+
+func (s *testPruneSuite) SetUpSuite(c *C) {
+	s.Parser = parser.New()
+}
+
+type pruneTestCase struct {
+	where      string
+	partitions string
+}
+
+func (s *testPruneSuite) where(c *C, sql string) ast.ExprNode {
+	stmt, err := s.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil, Commentf("%s", sql))
+	switch x := stmt.(type) {
+	case *ast.SelectStmt:
+		return x.Where
+	case *ast.UpdateStmt:
+		return x.Where
+	case *ast.DeleteStmt:
+		return x.Where
+	}
+	c.Fatalf("unexpected statement %s", sql)
+	return nil
+}
+
+func (s *testPruneSuite) runTests(c *C, createTable string, tests []pruneTestCase) {
+	tbl, err := buildTableInfo(c, s.Parser, createTable, nil)
+	c.Assert(err, IsNil)
+	for _, t := range tests {
+		comment := Commentf("%s", t.where)
+		where := s.where(c, "select * from t where "+t.where)
+		var names []string
+		for _, def := range schema.PrunePartitions(tbl, model.CIStr{}, where) {
+			names = append(names, def.Name.O)
+		}
+		c.Assert(strings.Join(names, ","), Equals, t.partitions, comment)
+	}
+}
+
+func (s *testPruneSuite) TestRange(c *C) {
+	s.runTests(c, "create table t (a int, b varchar(10)) partition by range (a) ("+
+		"partition p0 values less than (0), partition p1 values less than (10), "+
+		"partition p2 values less than (20), partition p3 values less than maxvalue)", []pruneTestCase{
+		{"a = 5", "p1"},
+		{"5 = a", "p1"},
+		{"a = -5", "p0"},
+		{"a = 10", "p2"},
+		{"a < 10", "p0,p1"},
+		{"a <= 10", "p0,p1,p2"},
+		{"10 > a", "p0,p1"},
+		{"a > 9", "p2,p3"},
+		{"a >= 20", "p3"},
+		{"a > 5 and a < 8", "p1"},
+		{"a >= 5 and (a < 15 and b = 'x')", "p1,p2"},
+		{"a > 15 and a < 5", ""},
+		{"a = 5 or a = 25", "p1,p3"},
+		{"(a = 5 or a = 25) and a > 10", "p3"},
+		{"a in (1, 2, 15)", "p1,p2"},
+		{"a in (1, null)", "p1"},
+		{"a between 8 and 12", "p1,p2"},
+		{"a is null", "p0"},
+		{"a <=> null", "p0"},
+		{"a = null", ""},
+		{"a is null and a = 1", ""},
+		{"a = '15'", "p2"},
+		{"a = 18446744073709551615", "p3"},
+		{"t.a = 5", "p1"},
+		{"test.t.a = 5", "p1"},
+		// The conditions which can't be analyzed match every partition.
+		{"t2.a = 5", "p0,p1,p2,p3"},
+		{"a != 5", "p0,p1,p2,p3"},
+		{"a not in (1, 2)", "p0,p1,p2,p3"},
+		{"not a = 5", "p0,p1,p2,p3"},
+		{"a is not null", "p0,p1,p2,p3"},
+		{"a = 1.5", "p0,p1,p2,p3"},
+		{"a = ?", "p0,p1,p2,p3"},
+		{"a = b", "p0,p1,p2,p3"},
+		{"a + 1 = 5", "p0,p1,p2,p3"},
+		{"b = 'x'", "p0,p1,p2,p3"},
+		{"a = 5 or b = 'x'", "p0,p1,p2,p3"},
+		{"a = 'x'", "p0,p1,p2,p3"},
+	})
+
+	// Only the first column is used to prune RANGE COLUMNS partitions.
+	s.runTests(c, "create table t (a varchar(10) collate utf8mb4_bin, b date) partition by range columns (a, b) ("+
+		"partition p0 values less than ('g', '2020-01-01'), partition p1 values less than ('p', maxvalue), "+
+		"partition p2 values less than (maxvalue, maxvalue))", []pruneTestCase{
+		{"a = 'a'", "p0"},
+		{"a = 'g'", "p0,p1"},
+		{"a = 'g   '", "p0,p1"},
+		{"a > 'h'", "p1,p2"},
+		{"a >= 'x'", "p2"},
+		{"b = '2020-01-01'", "p0,p1,p2"},
+	})
+
+	s.runTests(c, "create table t (d datetime) partition by range columns (d) ("+
+		"partition p2019 values less than ('2020-01-01'), partition p2020 values less than ('2021-01-01'), "+
+		"partition pmax values less than (maxvalue))", []pruneTestCase{
+		{"d = '2020-06-01 10:00:00'", "p2020"},
+		{"d = '2020/6/1'", "p2020"},
+		{"d = '20-06-01'", "p2020"},
+		{"d = '69-06-01'", "pmax"},
+		{"d = '99-12-31 23:59:59'", "p2019"},
+		{"d >= '21-01-01'", "pmax"},
+		{"d < '2020-01-01 00:00:00.000001'", "p2019,p2020"},
+		{"d between '2019-12-01' and '2020-02-01'", "p2019,p2020"},
+		{"d >= '2021-01-01'", "pmax"},
+		{"d = 'yesterday'", "p2019,p2020,pmax"},
+	})
+
+	// Strings of other collations aren't compared.
+	s.runTests(c, "create table t (a varchar(10) collate utf8mb4_general_ci) partition by range columns (a) ("+
+		"partition p0 values less than ('g'), partition p1 values less than (maxvalue))", []pruneTestCase{
+		{"a = 'A'", "p0,p1"},
+	})
+
+	// The partition expression must be a column.
+	s.runTests(c, "create table t (a int) partition by range (a div 10) ("+
+		"partition p0 values less than (1), partition p1 values less than (maxvalue))", []pruneTestCase{
+		{"a = 5", "p0,p1"},
+	})
+}
+
+func (s *testPruneSuite) TestList(c *C) {
+	s.runTests(c, "create table t (a int) partition by list (a) ("+
+		"partition p0 values in (1, 3, 5), partition p1 values in (2, 4, 6), partition p2 values in (null, 10))", []pruneTestCase{
+		{"a = 3", "p0"},
+		{"a = 7", ""},
+		{"a in (1, 2)", "p0,p1"},
+		{"a > 4", "p0,p1,p2"},
+		{"a between 5 and 6", "p0,p1"},
+		{"a >= 7", "p2"},
+		{"a is null", "p2"},
+		{"a = 1 or a is null", "p0,p2"},
+	})
+
+	s.runTests(c, "create table t (a int, b varchar(10) collate utf8mb4_bin) partition by list columns (a, b) ("+
+		"partition p0 values in ((1, 'a'), (2, 'b')), partition p1 values in ((1, 'b'), (3, 'c')))", []pruneTestCase{
+		{"a = 1", "p0,p1"},
+		{"a = 1 and b = 'a'", "p0"},
+		{"b = 'c'", "p1"},
+		{"b = 'b' and a = 2", "p0"},
+		{"a = 3 and b = 'a'", ""},
+	})
+}
+
+func (s *testPruneSuite) TestHash(c *C) {
+	s.runTests(c, "create table t (a bigint unsigned) partition by hash (a) partitions 4", []pruneTestCase{
+		{"a = 6", "p2"},
+		{"a in (1, 5, 3)", "p1,p3"},
+		{"a is null", "p0"},
+		{"a between 5 and 6", "p1,p2"},
+		{"a > 4 and a <= 6", "p1,p2"},
+		{"a >= 0 and a < 100000", "p0,p1,p2,p3"},
+		{"a > 4", "p0,p1,p2,p3"},
+		{"a = 18446744073709551615", "p3"},
+		{"a = 9223372036854775808", "p0"},
+		{"a = -1", ""},
+	})
+	s.runTests(c, "create table t (a int) partition by hash (a) partitions 4", []pruneTestCase{
+		{"a = -6", "p2"},
+		{"a = -1", "p1"},
+	})
+
+	// The hash function of KEY partitioning isn't known.
+	s.runTests(c, "create table t (a int) partition by key (a) partitions 2", []pruneTestCase{
+		{"a = 1", "p0,p1"},
+	})
+}
+
+func (s *testPruneSuite) TestAlias(c *C) {
+	tbl, err := buildTableInfo(c, s.Parser, "create table t (a int) partition by hash (a) partitions 4", nil)
+	c.Assert(err, IsNil)
+	where := s.where(c, "delete t1 from t as t1 join t as t2 where t1.a = 1 and t2.a = 2")
+	c.Assert(schema.PrunePartitions(tbl, model.NewCIStr("t1"), where), HasLen, 1)
+	c.Assert(schema.PrunePartitions(tbl, model.NewCIStr("t1"), where)[0].Name.O, Equals, "p1")
+	c.Assert(schema.PrunePartitions(tbl, model.NewCIStr("T2"), where)[0].Name.O, Equals, "p2")
+	c.Assert(schema.PrunePartitions(tbl, model.CIStr{}, where), HasLen, 4)
+
+	c.Assert(schema.PrunePartitions(tbl, model.CIStr{}, nil), HasLen, 4)
+	where = s.where(c, "update t set a = 1 where a = 3")
+	c.Assert(schema.PrunePartitions(tbl, model.CIStr{}, where)[0].Name.O, Equals, "p3")
+
+	tbl, err = buildTableInfo(c, s.Parser, "create table t (a int)", nil)
+	c.Assert(err, IsNil)
+	c.Assert(schema.PrunePartitions(tbl, model.CIStr{}, where), IsNil)
+}
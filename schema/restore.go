@@ -0,0 +1,510 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/types"
+)
+
+// BuildCreateTableStmt builds the CREATE TABLE statement of a table, which is
+// what SHOW CREATE TABLE shows. Only the public columns, indexes and constraints
+// are included.
+// The expressions saved as strings in the table info are parsed again, so a
+// parser driver (e.g. github.com/pingcap/parser/test_driver) must be imported.
+// The TiDB specific parts, such as the clustered index, AUTO_RANDOM and the
+// placement options, are written as special comments when the statement is
+// restored with format.RestoreTiDBSpecialComment.
+func BuildCreateTableStmt(tbInfo *model.TableInfo) (*ast.CreateTableStmt, error) {
+	b := &stmtBuilder{tbInfo: tbInfo, parser: parser.New()}
+	stmt, err := b.build()
+	return stmt, errors.Trace(err)
+}
+
+// BuildTiFlashReplicaStmt builds the ALTER TABLE statement setting the TiFlash
+// replica of a table. It returns nil if the table has no TiFlash replica.
+func BuildTiFlashReplicaStmt(tbInfo *model.TableInfo) *ast.AlterTableStmt {
+	if tbInfo.TiFlashReplica == nil || tbInfo.TiFlashReplica.Count == 0 {
+		return nil
+	}
+	return &ast.AlterTableStmt{
+		Table: &ast.TableName{Name: tbInfo.Name},
+		Specs: []*ast.AlterTableSpec{{
+			Tp: ast.AlterTableSetTiFlashReplica,
+			TiFlashReplica: &ast.TiFlashReplicaSpec{
+				Count:  tbInfo.TiFlashReplica.Count,
+				Labels: tbInfo.TiFlashReplica.LocationLabels,
+			},
+		}},
+	}
+}
+
+// RestoreTableInfo writes the statements creating a table to ctx: the CREATE
+// TABLE statement, followed by the ALTER TABLE statement setting the TiFlash
+// replica if there is one. The statements are separated by "; ".
+func RestoreTableInfo(ctx *format.RestoreCtx, tbInfo *model.TableInfo) error {
+	stmt, err := BuildCreateTableStmt(tbInfo)
+	if err != nil {
+		return err
+	}
+	if err := stmt.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore CreateTableStmt")
+	}
+	if alter := BuildTiFlashReplicaStmt(tbInfo); alter != nil {
+		ctx.WritePlain("; ")
+		if err := alter.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore AlterTableStmt")
+		}
+	}
+	return nil
+}
+
+// BuildColumnDef builds the definition of a column of a table as it is written
+// in the CREATE TABLE statement of the table.
+func BuildColumnDef(tbInfo *model.TableInfo, col *model.ColumnInfo) (*ast.ColumnDef, error) {
+	b := &stmtBuilder{tbInfo: tbInfo, parser: parser.New()}
+	def, err := b.buildColumnDef(col)
+	return def, errors.Trace(err)
+}
+
+// BuildPartitionOptions builds the PARTITION BY clause of a table. The
+// partition definitions are left out if they are the default ones of a HASH or
+// KEY partitioned table.
+func BuildPartitionOptions(tbInfo *model.TableInfo, pi *model.PartitionInfo) (*ast.PartitionOptions, error) {
+	b := &stmtBuilder{tbInfo: tbInfo, parser: parser.New()}
+	part, err := b.buildPartitionOptions(pi)
+	return part, errors.Trace(err)
+}
+
+type stmtBuilder struct {
+	tbInfo *model.TableInfo
+	parser *parser.Parser
+}
+
+// parseExpr parses an expression saved in the table info.
+func (b *stmtBuilder) parseExpr(expr string) (ast.ExprNode, error) {
+	if strings.EqualFold(strings.TrimSpace(expr), "MAXVALUE") {
+		return &ast.MaxValueExpr{}, nil
+	}
+	stmt, err := b.parser.ParseOneStmt("SELECT "+expr, "", "")
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid expression %q", expr)
+	}
+	return stmt.(*ast.SelectStmt).Fields.Fields[0].Expr, nil
+}
+
+func (b *stmtBuilder) build() (*ast.CreateTableStmt, error) {
+	tbInfo := b.tbInfo
+	stmt := &ast.CreateTableStmt{
+		Table: &ast.TableName{Name: tbInfo.Name},
+	}
+	for _, col := range tbInfo.Columns {
+		if col.State != model.StatePublic || col.Hidden {
+			continue
+		}
+		def, err := b.buildColumnDef(col)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Cols = append(stmt.Cols, def)
+	}
+
+	if tbInfo.PKIsHandle {
+		if col := tbInfo.GetPkColInfo(); col != nil {
+			stmt.Constraints = append(stmt.Constraints, &ast.Constraint{
+				Tp:     ast.ConstraintPrimaryKey,
+				Keys:   []*ast.IndexPartSpecification{{Column: &ast.ColumnName{Name: col.Name}, Length: types.UnspecifiedLength}},
+				Option: &ast.IndexOption{PrimaryKeyTp: model.PrimaryKeyTypeClustered},
+			})
+		}
+	}
+	for _, idx := range tbInfo.Indices {
+		if idx.State != model.StatePublic {
+			continue
+		}
+		cons, err := b.buildIndexConstraint(idx)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Constraints = append(stmt.Constraints, cons)
+	}
+	for _, fk := range tbInfo.ForeignKeys {
+		if fk.State != model.StatePublic {
+			continue
+		}
+		stmt.Constraints = append(stmt.Constraints, BuildForeignKeyConstraint(fk))
+	}
+	for _, cst := range tbInfo.Constraints {
+		if cst.State != model.StatePublic {
+			continue
+		}
+		expr, err := b.parseExpr(cst.ExprString)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Constraints = append(stmt.Constraints, &ast.Constraint{
+			Tp:       ast.ConstraintCheck,
+			Name:     cst.Name.O,
+			Expr:     expr,
+			Enforced: cst.Enforced,
+		})
+	}
+
+	stmt.Options = b.buildTableOptions()
+	if tbInfo.Partition != nil {
+		part, err := b.buildPartitionOptions(tbInfo.Partition)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Partition = part
+	}
+	return stmt, nil
+}
+
+// restoreFieldType returns the field type written in the column definition,
+// leaving out the charset and collation inherited from the table.
+func (b *stmtBuilder) restoreFieldType(col *model.ColumnInfo) *types.FieldType {
+	tp := &types.FieldType{
+		Tp:      col.Tp,
+		Flag:    col.Flag & (mysql.UnsignedFlag | mysql.ZerofillFlag | mysql.BinaryFlag),
+		Flen:    col.Flen,
+		Decimal: col.Decimal,
+		Elems:   col.Elems,
+	}
+	switch col.Tp {
+	case mysql.TypeDate:
+		tp.Flen, tp.Decimal = types.UnspecifiedLength, types.UnspecifiedLength
+	case mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDuration:
+		if tp.Decimal == 0 {
+			tp.Decimal = types.UnspecifiedLength
+		}
+	case mysql.TypeFloat, mysql.TypeDouble:
+		if defaultFlen, _ := mysql.GetDefaultFieldLengthAndDecimal(col.Tp); tp.Decimal == types.UnspecifiedLength && tp.Flen == defaultFlen {
+			tp.Flen = types.UnspecifiedLength
+		}
+	case mysql.TypeEnum, mysql.TypeSet, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeJSON:
+		tp.Flen = types.UnspecifiedLength
+	case mysql.TypeBlob:
+		if defaultFlen, _ := mysql.GetDefaultFieldLengthAndDecimal(col.Tp); tp.Flen == defaultFlen {
+			tp.Flen = types.UnspecifiedLength
+		}
+	}
+	if !types.HasCharset(&col.FieldType) {
+		tp.Charset = col.Charset
+		return tp
+	}
+	if col.Charset == b.tbInfo.Charset && col.Collate == b.tbInfo.Collate {
+		return tp
+	}
+	tp.Charset = col.Charset
+	if defaultCollate, err := charset.GetDefaultCollation(col.Charset); err != nil || defaultCollate != col.Collate {
+		tp.Collate = col.Collate
+	}
+	return tp
+}
+
+func (b *stmtBuilder) buildColumnDef(col *model.ColumnInfo) (*ast.ColumnDef, error) {
+	def := &ast.ColumnDef{
+		Name: &ast.ColumnName{Name: col.Name},
+		Tp:   b.restoreFieldType(col),
+	}
+	addOption := func(opt *ast.ColumnOption) {
+		def.Options = append(def.Options, opt)
+	}
+	if col.IsGenerated() {
+		expr, err := b.parseExpr(col.GeneratedExprString)
+		if err != nil {
+			return nil, err
+		}
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionGenerated, Expr: expr, Stored: col.GeneratedStored})
+	}
+	if mysql.HasNotNullFlag(col.Flag) {
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionNotNull})
+	}
+	if b.tbInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) && b.tbInfo.AutoRandomBits > 0 {
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionAutoRandom, AutoRandomBitLength: int(b.tbInfo.AutoRandomBits)})
+	}
+	if mysql.HasAutoIncrementFlag(col.Flag) {
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionAutoIncrement})
+	}
+
+	defaultValue := col.GetDefaultValue()
+	switch {
+	case col.IsGenerated() || mysql.HasAutoIncrementFlag(col.Flag):
+	case defaultValue != nil:
+		expr, err := b.buildDefaultExpr(col, defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionDefaultValue, Expr: expr})
+	case !mysql.HasNotNullFlag(col.Flag) && !mysql.HasNoDefaultValueFlag(col.Flag):
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionDefaultValue, Expr: ast.NewValueExpr(nil, "", "")})
+	}
+	if mysql.HasOnUpdateNowFlag(col.Flag) {
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionOnUpdate, Expr: currentTimestampExpr(col.Decimal)})
+	}
+	if col.Comment != "" {
+		addOption(&ast.ColumnOption{Tp: ast.ColumnOptionComment, Expr: ast.NewValueExpr(col.Comment, "", "")})
+	}
+	return def, nil
+}
+
+func currentTimestampExpr(fsp int) *ast.FuncCallExpr {
+	expr := &ast.FuncCallExpr{FnName: model.NewCIStr(strings.ToUpper(ast.CurrentTimestamp))}
+	if fsp > 0 {
+		expr.Args = []ast.ExprNode{ast.NewValueExpr(int64(fsp), "", "")}
+	}
+	return expr
+}
+
+func (b *stmtBuilder) buildDefaultExpr(col *model.ColumnInfo, value interface{}) (ast.ExprNode, error) {
+	str, ok := value.(string)
+	if !ok {
+		return ast.NewValueExpr(value, "", ""), nil
+	}
+	switch {
+	case col.DefaultIsExpr:
+		return b.parseExpr(str)
+	case strings.HasPrefix(strings.ToUpper(str), strings.ToUpper(ast.CurrentTimestamp)) &&
+		(col.Tp == mysql.TypeTimestamp || col.Tp == mysql.TypeDatetime):
+		return currentTimestampExpr(col.Decimal), nil
+	case col.Tp == mysql.TypeBit:
+		// The default value of a BIT column is saved as raw bytes.
+		var sb strings.Builder
+		for _, c := range []byte(str) {
+			sb.WriteString(fmt.Sprintf("%08b", c))
+		}
+		bits := strings.TrimLeft(sb.String(), "0")
+		if bits == "" {
+			bits = "0"
+		}
+		return b.parseExpr("b'" + bits + "'")
+	}
+	return ast.NewValueExpr(str, "", ""), nil
+}
+
+// buildIndexConstraint builds the constraint of an index. The part over a hidden
+// column is an expression index part given by the expression of the column.
+func (b *stmtBuilder) buildIndexConstraint(idx *model.IndexInfo) (*ast.Constraint, error) {
+	cons := &ast.Constraint{Name: idx.Name.O}
+	switch {
+	case idx.Primary:
+		cons.Tp = ast.ConstraintPrimaryKey
+		cons.Name = ""
+	case idx.Unique:
+		cons.Tp = ast.ConstraintUniqKey
+	default:
+		cons.Tp = ast.ConstraintKey
+	}
+	for _, col := range idx.Columns {
+		if colInfo := model.FindColumnInfo(b.tbInfo.Columns, col.Name.L); colInfo != nil && colInfo.Hidden {
+			expr, err := b.parseExpr(colInfo.GeneratedExprString)
+			if err != nil {
+				return nil, err
+			}
+			cons.Keys = append(cons.Keys, &ast.IndexPartSpecification{Expr: expr})
+			continue
+		}
+		cons.Keys = append(cons.Keys, &ast.IndexPartSpecification{
+			Column: &ast.ColumnName{Name: col.Name},
+			Length: col.Length,
+		})
+	}
+	option := &ast.IndexOption{Comment: idx.Comment}
+	if idx.Tp != model.IndexTypeBtree {
+		option.Tp = idx.Tp
+	}
+	if idx.Invisible {
+		option.Visibility = ast.IndexVisibilityInvisible
+	}
+	if idx.Primary {
+		option.PrimaryKeyTp = model.PrimaryKeyTypeNonClustered
+		if b.tbInfo.IsCommonHandle {
+			option.PrimaryKeyTp = model.PrimaryKeyTypeClustered
+		}
+	}
+	if *option != (ast.IndexOption{}) {
+		cons.Option = option
+	}
+	return cons, nil
+}
+
+// BuildForeignKeyConstraint builds the FOREIGN KEY constraint of a foreign key.
+func BuildForeignKeyConstraint(fk *model.FKInfo) *ast.Constraint {
+	cons := &ast.Constraint{
+		Tp:   ast.ConstraintForeignKey,
+		Name: fk.Name.O,
+		Refer: &ast.ReferenceDef{
+			Table:    &ast.TableName{Name: fk.RefTable},
+			OnDelete: &ast.OnDeleteOpt{ReferOpt: ast.ReferOptionType(fk.OnDelete)},
+			OnUpdate: &ast.OnUpdateOpt{ReferOpt: ast.ReferOptionType(fk.OnUpdate)},
+		},
+	}
+	for _, col := range fk.Cols {
+		cons.Keys = append(cons.Keys, &ast.IndexPartSpecification{Column: &ast.ColumnName{Name: col}, Length: types.UnspecifiedLength})
+	}
+	for _, col := range fk.RefCols {
+		cons.Refer.IndexPartSpecifications = append(cons.Refer.IndexPartSpecifications,
+			&ast.IndexPartSpecification{Column: &ast.ColumnName{Name: col}, Length: types.UnspecifiedLength})
+	}
+	return cons
+}
+
+func (b *stmtBuilder) buildTableOptions() []*ast.TableOption {
+	tbInfo := b.tbInfo
+	var opts []*ast.TableOption
+	if tbInfo.Charset != "" {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionCharset, StrValue: tbInfo.Charset})
+	}
+	if tbInfo.Collate != "" {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionCollate, StrValue: tbInfo.Collate})
+	}
+	if tbInfo.AutoIncID > 1 {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionAutoIncrement, UintValue: uint64(tbInfo.AutoIncID)})
+	}
+	if tbInfo.AutoIdCache > 0 {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionAutoIdCache, UintValue: uint64(tbInfo.AutoIdCache)})
+	}
+	if tbInfo.AutoRandID > 0 {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionAutoRandomBase, UintValue: uint64(tbInfo.AutoRandID)})
+	}
+	if tbInfo.Comment != "" {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionComment, StrValue: tbInfo.Comment})
+	}
+	if tbInfo.Compression != "" {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionCompression, StrValue: tbInfo.Compression})
+	}
+	if tbInfo.ShardRowIDBits > 0 {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionShardRowID, UintValue: tbInfo.ShardRowIDBits})
+	}
+	if tbInfo.PreSplitRegions > 0 {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionPreSplitRegion, UintValue: tbInfo.PreSplitRegions})
+	}
+	return append(opts, buildPlacementOptions(tbInfo.PlacementPolicyRef, tbInfo.DirectPlacementOpts)...)
+}
+
+// buildPlacementOptions is the reverse of setPlacementOption.
+func buildPlacementOptions(ref *model.PolicyRefInfo, settings *model.PlacementSettings) []*ast.TableOption {
+	var opts []*ast.TableOption
+	if ref != nil {
+		opts = append(opts, &ast.TableOption{Tp: ast.TableOptionPlacementPolicy, StrValue: ref.Name.O})
+	}
+	if settings == nil {
+		return opts
+	}
+	addString := func(tp ast.TableOptionType, value string) {
+		if value != "" {
+			opts = append(opts, &ast.TableOption{Tp: tp, StrValue: value})
+		}
+	}
+	addUint := func(tp ast.TableOptionType, value uint64) {
+		if value != 0 {
+			opts = append(opts, &ast.TableOption{Tp: tp, UintValue: value})
+		}
+	}
+	addString(ast.TableOptionPlacementPrimaryRegion, settings.PrimaryRegion)
+	addString(ast.TableOptionPlacementRegions, settings.Regions)
+	addUint(ast.TableOptionPlacementFollowerCount, settings.Followers)
+	addUint(ast.TableOptionPlacementVoterCount, settings.Voters)
+	addUint(ast.TableOptionPlacementLearnerCount, settings.Learners)
+	addString(ast.TableOptionPlacementSchedule, settings.Schedule)
+	addString(ast.TableOptionPlacementConstraints, settings.Constraints)
+	addString(ast.TableOptionPlacementLeaderConstraints, settings.LeaderConstraints)
+	addString(ast.TableOptionPlacementFollowerConstraints, settings.FollowerConstraints)
+	addString(ast.TableOptionPlacementVoterConstraints, settings.VoterConstraints)
+	addString(ast.TableOptionPlacementLearnerConstraints, settings.LearnerConstraints)
+	return opts
+}
+
+// hasDefaultPartitionDefinitions checks whether the partitions of a HASH or KEY
+// partitioned table are the default ones given by PARTITIONS n.
+func hasDefaultPartitionDefinitions(pi *model.PartitionInfo) bool {
+	if pi.Type != model.PartitionTypeHash && pi.Type != model.PartitionTypeKey {
+		return false
+	}
+	for i, def := range pi.Definitions {
+		if def.Name.L != fmt.Sprintf("p%d", i) || def.Comment != "" ||
+			def.PlacementPolicyRef != nil || def.DirectPlacementOpts != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *stmtBuilder) buildPartitionOptions(pi *model.PartitionInfo) (*ast.PartitionOptions, error) {
+	part := &ast.PartitionOptions{
+		PartitionMethod: ast.PartitionMethod{Tp: pi.Type},
+	}
+	if pi.Expr != "" {
+		expr, err := b.parseExpr(pi.Expr)
+		if err != nil {
+			return nil, err
+		}
+		part.Expr = expr
+	}
+	for _, col := range pi.Columns {
+		part.ColumnNames = append(part.ColumnNames, &ast.ColumnName{Name: col})
+	}
+	if hasDefaultPartitionDefinitions(pi) {
+		part.Num = uint64(len(pi.Definitions))
+		return part, nil
+	}
+	for _, def := range pi.Definitions {
+		pd := &ast.PartitionDefinition{
+			Name:   def.Name,
+			Clause: &ast.PartitionDefinitionClauseNone{},
+		}
+		switch pi.Type {
+		case model.PartitionTypeRange:
+			clause := &ast.PartitionDefinitionClauseLessThan{}
+			for _, s := range def.LessThan {
+				expr, err := b.parseExpr(s)
+				if err != nil {
+					return nil, err
+				}
+				clause.Exprs = append(clause.Exprs, expr)
+			}
+			pd.Clause = clause
+		case model.PartitionTypeList:
+			clause := &ast.PartitionDefinitionClauseIn{}
+			for _, values := range def.InValues {
+				exprs := make([]ast.ExprNode, 0, len(values))
+				for _, s := range values {
+					expr, err := b.parseExpr(s)
+					if err != nil {
+						return nil, err
+					}
+					exprs = append(exprs, expr)
+				}
+				clause.Values = append(clause.Values, exprs)
+			}
+			pd.Clause = clause
+		}
+		if def.Comment != "" {
+			pd.Options = append(pd.Options, &ast.TableOption{Tp: ast.TableOptionComment, StrValue: def.Comment})
+		}
+		pd.Options = append(pd.Options, buildPlacementOptions(def.PlacementPolicyRef, def.DirectPlacementOpts)...)
+		part.Definitions = append(part.Definitions, pd)
+	}
+	return part, nil
+}
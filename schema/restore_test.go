@@ -0,0 +1,156 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/schema"
+	"github.com/pingcap/parser/types"
+)
+
+var _ = Suite(&testRestoreSuite{})
+
+type testRestoreSuite struct {
+	*parser.Parser
+}
+
+func (s *testRestoreSuite) SetUpSuite(c *C) {
+	s.Parser = parser.New()
+}
+
+// buildTableInfo builds the table info of a CREATE TABLE statement for the suites
+// that need a table to work on.
+func buildTableInfo(c *C, p *parser.Parser, sql string, opts *schema.BuildOptions) (*model.TableInfo, error) {
+	stmt, err := p.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil, Commentf("%s", sql))
+	return schema.BuildTableInfo(stmt.(*ast.CreateTableStmt), opts)
+}
+
+func (s *testRestoreSuite) restoreTableInfo(c *C, tbInfo *model.TableInfo, flags format.RestoreFlags) string {
+	var sb strings.Builder
+	err := schema.RestoreTableInfo(format.NewRestoreCtx(flags, &sb), tbInfo)
+	c.Assert(err, IsNil)
+	return sb.String()
+}
+
+func (s *testRestoreSuite) TestRestoreTableInfo(c *C) {
+	const flags = format.DefaultRestoreFlags | format.RestoreStringWithoutDefaultCharset
+	cases := []struct {
+		src    string
+		expect string
+	}{
+		{
+			"create table t (a int primary key auto_increment, b varchar(10) default 'x' comment 'bb', c timestamp(3) default current_timestamp(3) on update current_timestamp(3), d double not null, e blob)",
+			"CREATE TABLE `t` (`a` INT(11) NOT NULL AUTO_INCREMENT,`b` VARCHAR(10) DEFAULT 'x' COMMENT 'bb',`c` TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),`d` DOUBLE NOT NULL,`e` BLOB DEFAULT NULL,PRIMARY KEY(`a`) CLUSTERED) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN",
+		},
+		{
+			"create table t (a varchar(10) charset latin1, b varchar(10) collate utf8mb4_general_ci, c int, primary key (a, c), unique key uk (b(5)) invisible comment 'x', key (c) using hash) charset utf8",
+			"CREATE TABLE `t` (`a` VARCHAR(10) CHARACTER SET LATIN1 NOT NULL,`b` VARCHAR(10) CHARACTER SET UTF8MB4 COLLATE utf8mb4_general_ci DEFAULT NULL,`c` INT(11) NOT NULL,PRIMARY KEY(`a`, `c`) NONCLUSTERED,UNIQUE KEY `uk`(`b`(5)) COMMENT 'x' INVISIBLE,KEY `c`(`c`) USING HASH) DEFAULT CHARACTER SET = UTF8 DEFAULT COLLATE = UTF8_BIN",
+		},
+		{
+			"create table t (a int, b int as (a + 1) stored, constraint fk foreign key (a) references p (id) on delete cascade, constraint c1 check (a > 0) not enforced)",
+			"CREATE TABLE `t` (`a` INT(11) DEFAULT NULL,`b` INT(11) GENERATED ALWAYS AS(`a`+1) STORED,CONSTRAINT `fk` FOREIGN KEY (`a`) REFERENCES `p`(`id`) ON DELETE CASCADE,CONSTRAINT `c1` CHECK(`a`>0) NOT ENFORCED) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN",
+		},
+		{
+			"create table t (a bigint primary key auto_random(3)) auto_id_cache 10 shard_row_id_bits 2 placement policy p1",
+			"CREATE TABLE `t` (`a` BIGINT(20) NOT NULL AUTO_RANDOM(3),PRIMARY KEY(`a`) CLUSTERED) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN AUTO_ID_CACHE = 10 SHARD_ROW_ID_BITS = 2 PLACEMENT POLICY = `p1`",
+		},
+		{
+			"create table t (a int, b varchar(10)) partition by range columns (a) (partition p0 values less than (10) comment 'c', partition p1 values less than (maxvalue))",
+			"CREATE TABLE `t` (`a` INT(11) DEFAULT NULL,`b` VARCHAR(10) DEFAULT NULL) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN PARTITION BY RANGE COLUMNS (`a`) (PARTITION `p0` VALUES LESS THAN (10) COMMENT = 'c',PARTITION `p1` VALUES LESS THAN (MAXVALUE))",
+		},
+		{
+			"create table t (a bit(8) default b'101', b enum('x','y') default 'y', c datetime not null default current_timestamp, d decimal(5,2) default -1.5, e date default '2020-01-01')",
+			"CREATE TABLE `t` (`a` BIT(8) DEFAULT b'101',`b` ENUM('x','y') DEFAULT 'y',`c` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP(),`d` DECIMAL(5,2) DEFAULT '-1.5',`e` DATE DEFAULT '2020-01-01') DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"CREATE TABLE `t` (`a` INT(11) DEFAULT NULL) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN PARTITION BY HASH (`a`) PARTITIONS 4",
+		},
+		{
+			"create table t (a int, b varchar(10)) partition by list (a) (partition p0 values in (1, 2), partition p1 values in (3))",
+			"CREATE TABLE `t` (`a` INT(11) DEFAULT NULL,`b` VARCHAR(10) DEFAULT NULL) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN PARTITION BY LIST (`a`) (PARTITION `p0` VALUES IN (1, 2),PARTITION `p1` VALUES IN (3))",
+		},
+	}
+	for _, ca := range cases {
+		tbInfo, err := buildTableInfo(c, s.Parser, ca.src, nil)
+		c.Assert(err, IsNil, Commentf("%s", ca.src))
+		restored := s.restoreTableInfo(c, tbInfo, flags)
+		c.Assert(restored, Equals, ca.expect, Commentf("%s", ca.src))
+
+		// The restored statement builds the same table.
+		tbInfo2, err := buildTableInfo(c, s.Parser, restored, nil)
+		c.Assert(err, IsNil, Commentf("%s", restored))
+		c.Assert(s.restoreTableInfo(c, tbInfo2, flags), Equals, restored)
+	}
+}
+
+func (s *testRestoreSuite) TestRestoreSpecialComment(c *C) {
+	tbInfo, err := buildTableInfo(c, s.Parser, "create table t (a bigint primary key auto_random, b varchar(10)) shard_row_id_bits 2 placement policy p1", nil)
+	c.Assert(err, IsNil)
+	tbInfo.TiFlashReplica = &model.TiFlashReplicaInfo{Count: 2, LocationLabels: []string{"zone"}}
+	restored := s.restoreTableInfo(c, tbInfo, format.DefaultRestoreFlags|format.RestoreTiDBSpecialComment|format.RestoreStringWithoutDefaultCharset)
+	c.Assert(restored, Equals, "CREATE TABLE `t` (`a` BIGINT(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */,`b` VARCHAR(10) DEFAULT NULL,"+
+		"PRIMARY KEY(`a`) /*T![clustered_index] CLUSTERED */) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN "+
+		"/*T! SHARD_ROW_ID_BITS = 2 */ /*T![placement] PLACEMENT POLICY = `p1` */; ALTER TABLE `t` SET TIFLASH REPLICA 2 LOCATION LABELS 'zone'")
+
+	restored = s.restoreTableInfo(c, tbInfo, format.DefaultRestoreFlags|format.RestoreStringWithoutDefaultCharset)
+	c.Assert(strings.Contains(restored, "/*T!"), IsFalse, Commentf("%s", restored))
+	c.Assert(strings.Contains(restored, "AUTO_RANDOM(5)"), IsTrue, Commentf("%s", restored))
+
+	c.Assert(schema.BuildTiFlashReplicaStmt(&model.TableInfo{}), IsNil)
+}
+
+func (s *testRestoreSuite) TestRestoreExpressionIndex(c *C) {
+	tbInfo, err := buildTableInfo(c, s.Parser, "create table t (a int, b varchar(10))", nil)
+	c.Assert(err, IsNil)
+	// An expression index part is saved as a hidden virtual column.
+	hidden := &model.ColumnInfo{
+		ID:                  3,
+		Name:                model.NewCIStr("_V$_idx_0"),
+		Offset:              2,
+		State:               model.StatePublic,
+		Hidden:              true,
+		GeneratedExprString: "lower(`b`)",
+		FieldType:           *tbInfo.Columns[1].FieldType.Clone(),
+	}
+	tbInfo.Columns = append(tbInfo.Columns, hidden)
+	tbInfo.Indices = append(tbInfo.Indices, &model.IndexInfo{
+		ID:    1,
+		Name:  model.NewCIStr("idx"),
+		State: model.StatePublic,
+		Tp:    model.IndexTypeBtree,
+		Columns: []*model.IndexColumn{
+			{Name: hidden.Name, Offset: hidden.Offset, Length: types.UnspecifiedLength},
+			{Name: model.NewCIStr("a"), Offset: 0, Length: types.UnspecifiedLength},
+		},
+	})
+	restored := s.restoreTableInfo(c, tbInfo, format.DefaultRestoreFlags|format.RestoreStringWithoutDefaultCharset)
+	c.Assert(restored, Equals, "CREATE TABLE `t` (`a` INT(11) DEFAULT NULL,`b` VARCHAR(10) DEFAULT NULL,"+
+		"KEY `idx`((LOWER(`b`)), `a`)) DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN")
+
+	// The restored expression index is built to the same hidden column.
+	rebuilt, err := buildTableInfo(c, s.Parser, restored, nil)
+	c.Assert(err, IsNil)
+	c.Assert(rebuilt.Columns, HasLen, 3)
+	c.Assert(rebuilt.Columns[2].Name.O, Equals, hidden.Name.O)
+	c.Assert(rebuilt.Columns[2].GeneratedExprString, Equals, hidden.GeneratedExprString)
+	c.Assert(s.restoreTableInfo(c, rebuilt, format.DefaultRestoreFlags|format.RestoreStringWithoutDefaultCharset), Equals, restored)
+}
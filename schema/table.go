@@ -0,0 +1,1196 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/opcode"
+	"github.com/pingcap/parser/terror"
+	"github.com/pingcap/parser/types"
+)
+
+const (
+	// maxColumnCount is the maximum number of columns of a table.
+	maxColumnCount = 4096
+	// defaultAutoRandomBits is the shard bits of an AUTO_RANDOM column without explicit length.
+	defaultAutoRandomBits = 5
+	// expressionIndexPrefix is the prefix of the hidden columns of the expression indexes.
+	expressionIndexPrefix = "_V$"
+	// The reasons of ErrInvalidAutoRandom, which are the same as TiDB.
+	autoRandomOnNonBigIntColumn = "auto_random option must be defined on `bigint` column, but not on `%s` column"
+	autoRandomPKIsNotHandle     = "column %s is not the integer primary key, or the primary key is clustered"
+)
+
+// restoreFlags is used to restore the expressions saved in the schema objects,
+// e.g. generated columns, check constraints and partition bounds.
+const restoreFlags = format.RestoreStringSingleQuotes | format.RestoreKeyWordLowercase |
+	format.RestoreNameBackQuotes | format.RestoreSpacesAroundBinaryOperation | format.RestoreStringWithoutCharset
+
+// BuildOptions is the environment a CREATE TABLE statement is built in.
+type BuildOptions struct {
+	// Charset and Collate are the defaults of the database the table belongs to.
+	// The server defaults are used if both are empty.
+	Charset string
+	Collate string
+	// AllocID allocates the global IDs of the table and its partitions.
+	// IDs are allocated sequentially from 1 if it is nil.
+	AllocID func() int64
+	// EnableClusteredIndex makes a primary key without CLUSTERED or NONCLUSTERED
+	// clustered even if it isn't a single integer column.
+	EnableClusteredIndex bool
+}
+
+// BuildTableInfo builds the table info of a CREATE TABLE statement.
+// It assigns the column, index and constraint IDs, fills in the default charset,
+// collation and field lengths, computes the column flags and the handle kind,
+// and reports invalid definitions with the same errors as MySQL.
+// CREATE TABLE ... LIKE and CREATE TABLE ... SELECT are not supported, since
+// the result depends on other tables.
+func BuildTableInfo(stmt *ast.CreateTableStmt, opts *BuildOptions) (*model.TableInfo, error) {
+	if opts == nil {
+		opts = &BuildOptions{}
+	}
+	if stmt.ReferTable != nil {
+		return nil, ErrNotSupportedYet.GenWithStackByArgs("CREATE TABLE ... LIKE")
+	}
+	if stmt.Select != nil {
+		return nil, ErrNotSupportedYet.GenWithStackByArgs("CREATE TABLE ... SELECT")
+	}
+	b := &tableBuilder{opts: opts}
+	tbInfo, err := b.build(stmt)
+	return tbInfo, errors.Trace(err)
+}
+
+type tableBuilder struct {
+	opts   *BuildOptions
+	lastID int64
+	tbInfo *model.TableInfo
+	// defs keeps the definitions of the columns by offset.
+	defs []*ast.ColumnDef
+	// autoRandomCol is the column with AUTO_RANDOM.
+	autoRandomCol *model.ColumnInfo
+}
+
+func (b *tableBuilder) allocID() int64 {
+	if b.opts.AllocID != nil {
+		return b.opts.AllocID()
+	}
+	b.lastID++
+	return b.lastID
+}
+
+func (b *tableBuilder) build(stmt *ast.CreateTableStmt) (*model.TableInfo, error) {
+	name := stmt.Table.Name
+	if err := checkIdent(name.O, mysql.MaxTableNameLength, ErrWrongTableName); err != nil {
+		return nil, err
+	}
+	b.tbInfo = &model.TableInfo{
+		ID:      b.allocID(),
+		Name:    name,
+		State:   model.StatePublic,
+		Version: model.CurrLatestTableInfoVersion,
+	}
+	if err := b.buildTableOptions(stmt.Options); err != nil {
+		return nil, err
+	}
+
+	if len(stmt.Cols) == 0 {
+		return nil, ErrTableMustHaveColumns
+	}
+	if len(stmt.Cols) > maxColumnCount {
+		return nil, ErrTooManyFields
+	}
+	// Column level constraints are handled after the table level ones.
+	constraints := append([]*ast.Constraint(nil), stmt.Constraints...)
+	names := make(map[string]struct{}, len(stmt.Cols))
+	for _, def := range stmt.Cols {
+		lowerName := def.Name.Name.L
+		if _, ok := names[lowerName]; ok {
+			return nil, ErrDupFieldName.GenWithStackByArgs(def.Name.Name.O)
+		}
+		names[lowerName] = struct{}{}
+		col, cons, err := b.buildColumn(def)
+		if err != nil {
+			return nil, err
+		}
+		b.tbInfo.Columns = append(b.tbInfo.Columns, col)
+		b.defs = append(b.defs, def)
+		constraints = append(constraints, cons...)
+	}
+	if err := b.buildGeneratedColumns(); err != nil {
+		return nil, err
+	}
+	if err := b.buildConstraints(constraints); err != nil {
+		return nil, err
+	}
+	if err := b.checkAutoIncrement(); err != nil {
+		return nil, err
+	}
+	if err := b.checkAutoRandom(); err != nil {
+		return nil, err
+	}
+	if stmt.Partition != nil {
+		if err := b.buildPartitionInfo(stmt.Partition); err != nil {
+			return nil, err
+		}
+	}
+	return b.tbInfo, nil
+}
+
+// checkIdent checks the length of an identifier, and whether it is empty or ends with a space.
+func checkIdent(name string, maxLen int, errWrongName *terror.Error) error {
+	if len(name) > maxLen {
+		return ErrTooLongIdent.GenWithStackByArgs(name)
+	}
+	if len(name) == 0 || name[len(name)-1] == ' ' {
+		return errWrongName.GenWithStackByArgs(name)
+	}
+	return nil
+}
+
+func (b *tableBuilder) buildTableOptions(options []*ast.TableOption) error {
+	var cs, co string
+	tbInfo := b.tbInfo
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionCharset:
+			cs = op.StrValue
+		case ast.TableOptionCollate:
+			co = op.StrValue
+		case ast.TableOptionComment:
+			tbInfo.Comment = op.StrValue
+		case ast.TableOptionAutoIncrement:
+			tbInfo.AutoIncID = int64(op.UintValue)
+		case ast.TableOptionAutoIdCache:
+			tbInfo.AutoIdCache = int64(op.UintValue)
+		case ast.TableOptionAutoRandomBase:
+			tbInfo.AutoRandID = int64(op.UintValue)
+		case ast.TableOptionShardRowID:
+			tbInfo.ShardRowIDBits = op.UintValue
+			tbInfo.MaxShardRowIDBits = op.UintValue
+		case ast.TableOptionPreSplitRegion:
+			tbInfo.PreSplitRegions = op.UintValue
+		case ast.TableOptionCompression:
+			tbInfo.Compression = op.StrValue
+		case ast.TableOptionPlacementPolicy:
+			tbInfo.PlacementPolicyRef = &model.PolicyRefInfo{Name: model.NewCIStr(op.StrValue)}
+		default:
+			setPlacementOption(&tbInfo.DirectPlacementOpts, op)
+		}
+	}
+	if cs == "" && co == "" {
+		cs, co = b.opts.Charset, b.opts.Collate
+	}
+	var err error
+	tbInfo.Charset, tbInfo.Collate, err = resolveCharsetAndCollate(cs, co)
+	return err
+}
+
+// isPlacementOption reports whether a table option is PLACEMENT POLICY or a direct placement option.
+func isPlacementOption(tp ast.TableOptionType) bool {
+	switch tp {
+	case ast.TableOptionPlacementPolicy, ast.TableOptionPlacementPrimaryRegion, ast.TableOptionPlacementRegions,
+		ast.TableOptionPlacementFollowerCount, ast.TableOptionPlacementVoterCount,
+		ast.TableOptionPlacementLearnerCount, ast.TableOptionPlacementSchedule,
+		ast.TableOptionPlacementConstraints, ast.TableOptionPlacementLeaderConstraints,
+		ast.TableOptionPlacementLearnerConstraints, ast.TableOptionPlacementFollowerConstraints,
+		ast.TableOptionPlacementVoterConstraints:
+		return true
+	}
+	return false
+}
+
+// setPlacementOption sets a direct placement option on the settings,
+// allocating them on the first option. Other options are ignored.
+func setPlacementOption(settings **model.PlacementSettings, op *ast.TableOption) {
+	s := *settings
+	if s == nil {
+		s = &model.PlacementSettings{}
+	}
+	switch op.Tp {
+	case ast.TableOptionPlacementPrimaryRegion:
+		s.PrimaryRegion = op.StrValue
+	case ast.TableOptionPlacementRegions:
+		s.Regions = op.StrValue
+	case ast.TableOptionPlacementFollowerCount:
+		s.Followers = op.UintValue
+	case ast.TableOptionPlacementVoterCount:
+		s.Voters = op.UintValue
+	case ast.TableOptionPlacementLearnerCount:
+		s.Learners = op.UintValue
+	case ast.TableOptionPlacementSchedule:
+		s.Schedule = op.StrValue
+	case ast.TableOptionPlacementConstraints:
+		s.Constraints = op.StrValue
+	case ast.TableOptionPlacementLeaderConstraints:
+		s.LeaderConstraints = op.StrValue
+	case ast.TableOptionPlacementLearnerConstraints:
+		s.LearnerConstraints = op.StrValue
+	case ast.TableOptionPlacementFollowerConstraints:
+		s.FollowerConstraints = op.StrValue
+	case ast.TableOptionPlacementVoterConstraints:
+		s.VoterConstraints = op.StrValue
+	default:
+		return
+	}
+	*settings = s
+}
+
+// resolveCharsetAndCollate fills in the missing one of a charset and collation pair,
+// falling back to the server defaults if both are empty.
+func resolveCharsetAndCollate(cs, co string) (string, string, error) {
+	if cs == "" && co == "" {
+		cs, co = charset.GetDefaultCharsetAndCollate()
+		return cs, co, nil
+	}
+	if co != "" {
+		coll, err := charset.GetCollationByName(co)
+		if err != nil {
+			return "", "", err
+		}
+		if cs == "" {
+			return coll.CharsetName, coll.Name, nil
+		}
+		if !charset.ValidCharsetAndCollation(cs, coll.Name) {
+			return "", "", charset.ErrCollationCharsetMismatch.GenWithStackByArgs(coll.Name, cs)
+		}
+		return strings.ToLower(cs), coll.Name, nil
+	}
+	co, err := charset.GetDefaultCollation(cs)
+	if err != nil {
+		return "", "", ErrUnknownCharacterSet.GenWithStackByArgs(cs)
+	}
+	return strings.ToLower(cs), co, nil
+}
+
+// buildColumn builds the column info of a column definition, and returns the
+// constraints given as column options.
+func (b *tableBuilder) buildColumn(def *ast.ColumnDef) (*model.ColumnInfo, []*ast.Constraint, error) {
+	name := def.Name.Name
+	if err := checkIdent(name.O, mysql.MaxColumnNameLength, ErrWrongColumnName); err != nil {
+		return nil, nil, err
+	}
+	col := &model.ColumnInfo{
+		ID:     b.tbInfo.MaxColumnID + 1,
+		Name:   name,
+		Offset: len(b.tbInfo.Columns),
+		State:  model.StatePublic,
+	}
+	b.tbInfo.MaxColumnID = col.ID
+	col.FieldType = *def.Tp.Clone()
+	if err := b.buildFieldType(col, def); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		constraints []*ast.Constraint
+		defaultExpr ast.ExprNode
+		hasDefault  bool
+	)
+	for _, opt := range def.Options {
+		switch opt.Tp {
+		case ast.ColumnOptionNotNull:
+			col.Flag |= mysql.NotNullFlag
+		case ast.ColumnOptionNull:
+			col.Flag &^= mysql.NotNullFlag
+		case ast.ColumnOptionAutoIncrement:
+			col.Flag |= mysql.AutoIncrementFlag | mysql.NotNullFlag
+		case ast.ColumnOptionAutoRandom:
+			b.autoRandomCol = col
+			if opt.AutoRandomBitLength == types.UnspecifiedLength {
+				b.tbInfo.AutoRandomBits = defaultAutoRandomBits
+			} else {
+				b.tbInfo.AutoRandomBits = uint64(opt.AutoRandomBitLength)
+			}
+		case ast.ColumnOptionPrimaryKey:
+			constraints = append(constraints, &ast.Constraint{
+				Tp:     ast.ConstraintPrimaryKey,
+				Keys:   []*ast.IndexPartSpecification{{Column: def.Name, Length: types.UnspecifiedLength}},
+				Option: &ast.IndexOption{PrimaryKeyTp: opt.PrimaryKeyTp},
+			})
+		case ast.ColumnOptionUniqKey:
+			constraints = append(constraints, &ast.Constraint{
+				Tp:   ast.ConstraintUniqKey,
+				Keys: []*ast.IndexPartSpecification{{Column: def.Name, Length: types.UnspecifiedLength}},
+			})
+		case ast.ColumnOptionCheck:
+			constraints = append(constraints, &ast.Constraint{
+				Tp:       ast.ConstraintCheck,
+				Name:     opt.ConstraintName,
+				Expr:     opt.Expr,
+				Enforced: opt.Enforced,
+				InColumn: true,
+			})
+		case ast.ColumnOptionDefaultValue:
+			defaultExpr, hasDefault = opt.Expr, true
+		case ast.ColumnOptionOnUpdate:
+			if !isCurrentTimestamp(opt.Expr) || (col.Tp != mysql.TypeTimestamp && col.Tp != mysql.TypeDatetime) {
+				return nil, nil, ErrInvalidOnUpdate.GenWithStackByArgs(name.O)
+			}
+			col.Flag |= mysql.OnUpdateNowFlag
+		case ast.ColumnOptionComment:
+			col.Comment = valueToString(opt.Expr.(ast.ValueExpr).GetValue())
+		case ast.ColumnOptionGenerated:
+			col.GeneratedStored = opt.Stored
+			exprStr, err := restoreExpr(opt.Expr)
+			if err != nil {
+				return nil, nil, err
+			}
+			col.GeneratedExprString = exprStr
+		}
+	}
+	if col.IsGenerated() {
+		for _, opt := range def.Options {
+			switch opt.Tp {
+			case ast.ColumnOptionDefaultValue:
+				return nil, nil, ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("DEFAULT")
+			case ast.ColumnOptionAutoIncrement:
+				return nil, nil, ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("AUTO_INCREMENT")
+			case ast.ColumnOptionOnUpdate:
+				return nil, nil, ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("ON UPDATE")
+			}
+		}
+	}
+	if hasDefault {
+		if err := setDefaultValue(col, defaultExpr); err != nil {
+			return nil, nil, err
+		}
+	} else if mysql.HasNotNullFlag(col.Flag) && !mysql.HasAutoIncrementFlag(col.Flag) &&
+		!col.IsGenerated() && col.Tp != mysql.TypeTimestamp {
+		col.Flag |= mysql.NoDefaultValueFlag
+	}
+	return col, constraints, nil
+}
+
+// buildFieldType fills in the default length, decimal, charset and collation of a column.
+func (b *tableBuilder) buildFieldType(col *model.ColumnInfo, def *ast.ColumnDef) error {
+	tp := &col.FieldType
+	defaultFlen, defaultDecimal := mysql.GetDefaultFieldLengthAndDecimal(tp.Tp)
+	if tp.Flen == types.UnspecifiedLength {
+		tp.Flen = defaultFlen
+	}
+	if tp.Decimal == types.UnspecifiedLength {
+		tp.Decimal = defaultDecimal
+	}
+
+	if !types.HasCharset(tp) {
+		tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+		tp.Flag |= mysql.BinaryFlag
+		return nil
+	}
+	cs, co := tp.Charset, tp.Collate
+	for _, opt := range def.Options {
+		if opt.Tp == ast.ColumnOptionCollate {
+			co = opt.StrValue
+		}
+	}
+	if cs == "" && co == "" {
+		cs, co = b.tbInfo.Charset, b.tbInfo.Collate
+	}
+	var err error
+	tp.Charset, tp.Collate, err = resolveCharsetAndCollate(cs, co)
+	if tp.Charset == charset.CharsetBin {
+		tp.Flag |= mysql.BinaryFlag
+	}
+	return err
+}
+
+func isCurrentTimestamp(expr ast.ExprNode) bool {
+	fn, ok := expr.(*ast.FuncCallExpr)
+	return ok && fn.FnName.L == ast.CurrentTimestamp
+}
+
+// setDefaultValue sets the default value of a column from its DEFAULT option.
+func setDefaultValue(col *model.ColumnInfo, expr ast.ExprNode) error {
+	var value interface{}
+	switch x := expr.(type) {
+	case ast.ValueExpr:
+		value = x.GetValue()
+		if value == nil {
+			if mysql.HasNotNullFlag(col.Flag) {
+				return ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+			}
+			break
+		}
+		if err := checkDefaultValue(col, value, false); err != nil {
+			return err
+		}
+		value = valueToString(value)
+	case *ast.UnaryOperationExpr:
+		v, ok := x.V.(ast.ValueExpr)
+		if !ok || (x.Op != opcode.Minus && x.Op != opcode.Plus) {
+			return ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+		}
+		if err := checkDefaultValue(col, v.GetValue(), x.Op == opcode.Minus); err != nil {
+			return err
+		}
+		value = valueToString(v.GetValue())
+		if x.Op == opcode.Minus {
+			value = "-" + value.(string)
+		}
+	case *ast.FuncCallExpr:
+		if x.FnName.L != ast.CurrentTimestamp {
+			exprStr, err := restoreExpr(x)
+			if err != nil {
+				return err
+			}
+			col.DefaultIsExpr = true
+			value = exprStr
+			break
+		}
+		if col.Tp != mysql.TypeTimestamp && col.Tp != mysql.TypeDatetime {
+			return ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+		}
+		value = strings.ToUpper(ast.CurrentTimestamp)
+		if len(x.Args) == 1 {
+			value = fmt.Sprintf("%s(%s)", value, valueToString(x.Args[0].(ast.ValueExpr).GetValue()))
+		}
+	default:
+		exprStr, err := restoreExpr(x)
+		if err != nil {
+			return err
+		}
+		col.DefaultIsExpr = true
+		value = exprStr
+	}
+	if err := col.SetOriginDefaultValue(value); err != nil {
+		return ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+	}
+	if err := col.SetDefaultValue(value); err != nil {
+		return ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+	}
+	return nil
+}
+
+// checkDefaultValue checks that a literal default value, negated if negative is set,
+// fits the type and range of the column. Binary literals and temporal values aren't checked.
+func checkDefaultValue(col *model.ColumnInfo, value interface{}, negative bool) error {
+	if _, ok := value.(interface{ ToString() string }); ok {
+		return nil
+	}
+	str := valueToString(value)
+	if negative {
+		str = "-" + str
+	}
+	_, isString := value.(string)
+	invalid := ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+	unsigned := mysql.HasUnsignedFlag(col.Flag)
+	switch col.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		if col.Tp == mysql.TypeYear {
+			f, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+			if f = math.Round(f); err != nil || (f != 0 && (f < 1 || f > 99) && (f < 1901 || f > 2155)) {
+				return invalid
+			}
+			return nil
+		}
+		if !fitsInteger(strings.TrimSpace(str), col.Tp, unsigned) {
+			return invalid
+		}
+	case mysql.TypeFloat, mysql.TypeDouble, mysql.TypeNewDecimal:
+		f, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		if err != nil || (unsigned && f < 0) {
+			return invalid
+		}
+		if col.Tp == mysql.TypeNewDecimal && col.Flen > 0 {
+			// The integral part must fit into the digits left by the fraction.
+			if math.Abs(f) >= math.Pow10(col.Flen-col.Decimal) {
+				return invalid
+			}
+		}
+	case mysql.TypeBit:
+		n, err := strconv.ParseUint(str, 10, 64)
+		if err != nil || (col.Flen < 64 && n >= 1<<uint(col.Flen)) {
+			return invalid
+		}
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString:
+		length := len(str)
+		if col.Charset != charset.CharsetBin {
+			length = utf8.RuneCountInString(str)
+		}
+		if col.Flen >= 0 && length > col.Flen {
+			return invalid
+		}
+	case mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob, mysql.TypeJSON, mysql.TypeGeometry:
+		return ErrBlobCantHaveDefault.GenWithStackByArgs(col.Name.O)
+	case mysql.TypeEnum:
+		if !isString {
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil || n < 1 || n > uint64(len(col.Elems)) {
+				return invalid
+			}
+			return nil
+		}
+		if !containsElem(col.Elems, str) {
+			return invalid
+		}
+	case mysql.TypeSet:
+		if !isString {
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil || (len(col.Elems) < 64 && n >= 1<<uint(len(col.Elems))) {
+				return invalid
+			}
+			return nil
+		}
+		if str == "" {
+			return nil
+		}
+		for _, elem := range strings.Split(str, ",") {
+			if !containsElem(col.Elems, elem) {
+				return invalid
+			}
+		}
+	}
+	return nil
+}
+
+// fitsInteger reports whether a number fits into an integer type after rounding.
+func fitsInteger(str string, tp byte, unsigned bool) bool {
+	var bits uint
+	switch tp {
+	case mysql.TypeTiny:
+		bits = 8
+	case mysql.TypeShort:
+		bits = 16
+	case mysql.TypeInt24:
+		bits = 24
+	case mysql.TypeLong:
+		bits = 32
+	default:
+		bits = 64
+	}
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		if unsigned {
+			return i >= 0 && (bits == 64 || i < 1<<bits)
+		}
+		return bits == 64 || (i >= -1<<(bits-1) && i < 1<<(bits-1))
+	}
+	if _, err := strconv.ParseUint(str, 10, 64); err == nil {
+		// The value is larger than math.MaxInt64.
+		return unsigned && bits == 64
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return false
+	}
+	f = math.Round(f)
+	if unsigned {
+		return f >= 0 && f < math.Exp2(float64(bits))
+	}
+	return f >= -math.Exp2(float64(bits-1)) && f < math.Exp2(float64(bits-1))
+}
+
+// containsElem reports whether an ENUM or SET element is in elems, ignoring the trailing spaces and case.
+func containsElem(elems []string, elem string) bool {
+	elem = strings.TrimRight(elem, " ")
+	for _, e := range elems {
+		if strings.EqualFold(strings.TrimRight(e, " "), elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueToString converts a literal value to the string saved in the schema objects.
+func valueToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case interface{ ToString() string }:
+		// Binary literals are saved as their raw bytes.
+		return v.ToString()
+	case fmt.Stringer:
+		return v.String()
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func restoreExpr(expr ast.ExprNode) (string, error) {
+	var sb strings.Builder
+	if err := expr.Restore(format.NewRestoreCtx(restoreFlags, &sb)); err != nil {
+		return "", errors.Trace(err)
+	}
+	return sb.String(), nil
+}
+
+// columnNameCollector collects the names of the columns referred by an expression.
+type columnNameCollector struct {
+	names []model.CIStr
+}
+
+func (c *columnNameCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if col, ok := n.(*ast.ColumnName); ok {
+		c.names = append(c.names, col.Name)
+	}
+	return n, false
+}
+
+func (c *columnNameCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+func referredColumns(expr ast.ExprNode) []model.CIStr {
+	c := &columnNameCollector{}
+	expr.Accept(c)
+	return c.names
+}
+
+func (b *tableBuilder) findColumn(name model.CIStr) *model.ColumnInfo {
+	for _, col := range b.tbInfo.Columns {
+		if col.Name.L == name.L {
+			return col
+		}
+	}
+	return nil
+}
+
+// buildGeneratedColumns fills in the dependences of the generated columns.
+// A generated column can only refer to the normal columns and the generated
+// columns defined prior to it, and it can't refer to an auto-increment column.
+func (b *tableBuilder) buildGeneratedColumns() error {
+	for i, col := range b.tbInfo.Columns {
+		if !col.IsGenerated() {
+			continue
+		}
+		var expr ast.ExprNode
+		for _, opt := range b.defs[i].Options {
+			if opt.Tp == ast.ColumnOptionGenerated {
+				expr = opt.Expr
+			}
+		}
+		col.Dependences = make(map[string]struct{})
+		for _, name := range referredColumns(expr) {
+			dep := b.findColumn(name)
+			if dep == nil {
+				return ErrBadField.GenWithStackByArgs(name.O, "generated column function")
+			}
+			if mysql.HasAutoIncrementFlag(dep.Flag) {
+				return ErrGeneratedColumnRefAutoInc.GenWithStackByArgs(col.Name.O)
+			}
+			if dep.IsGenerated() && dep.Offset >= col.Offset {
+				return ErrGeneratedColumnNonPrior
+			}
+			col.Dependences[dep.Name.L] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (b *tableBuilder) buildConstraints(constraints []*ast.Constraint) error {
+	var pk *ast.Constraint
+	for _, cons := range constraints {
+		if cons.Tp != ast.ConstraintPrimaryKey {
+			continue
+		}
+		if pk != nil {
+			return ErrMultiplePriKey
+		}
+		pk = cons
+	}
+	if pk != nil {
+		if err := b.buildPrimaryKey(pk); err != nil {
+			return err
+		}
+	}
+	for _, cons := range constraints {
+		var err error
+		switch cons.Tp {
+		case ast.ConstraintKey, ast.ConstraintIndex:
+			_, err = b.buildIndex(cons, false)
+		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+			_, err = b.buildIndex(cons, true)
+		case ast.ConstraintForeignKey:
+			err = b.buildForeignKey(cons)
+		case ast.ConstraintCheck:
+			err = b.buildCheck(cons)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *tableBuilder) buildPrimaryKey(cons *ast.Constraint) error {
+	pkTp := model.PrimaryKeyTypeDefault
+	if cons.Option != nil {
+		pkTp = cons.Option.PrimaryKeyTp
+	}
+	for _, key := range cons.Keys {
+		if key.Expr != nil {
+			return ErrFunctionalIndexPrimaryKey
+		}
+		col := b.findColumn(key.Column.Name)
+		if col == nil {
+			return ErrKeyColumnDoesNotExist.GenWithStackByArgs(key.Column.Name.O)
+		}
+		for _, opt := range b.defs[col.Offset].Options {
+			if opt.Tp == ast.ColumnOptionNull {
+				return ErrPrimaryCantHaveNull
+			}
+		}
+		col.Flag |= mysql.PriKeyFlag | mysql.NotNullFlag
+	}
+
+	if len(cons.Keys) == 1 && pkTp != model.PrimaryKeyTypeNonClustered {
+		col := b.findColumn(cons.Keys[0].Column.Name)
+		if mysql.IsIntegerType(col.Tp) {
+			b.tbInfo.PKIsHandle = true
+			// An integer handle has no index. The flags are set on the column.
+			return nil
+		}
+	}
+	idxInfo, err := b.buildIndex(cons, true)
+	if err != nil {
+		return err
+	}
+	idxInfo.Primary = true
+	if pkTp == model.PrimaryKeyTypeClustered || (pkTp == model.PrimaryKeyTypeDefault && b.opts.EnableClusteredIndex) {
+		b.tbInfo.IsCommonHandle = true
+		b.tbInfo.CommonHandleVersion = 1
+	}
+	return nil
+}
+
+func (b *tableBuilder) indexNameExists(name string) bool {
+	for _, idx := range b.tbInfo.Indices {
+		if idx.Name.L == name {
+			return true
+		}
+	}
+	return false
+}
+
+// indexName returns the name of an index, deriving it from the first column if it isn't given.
+func (b *tableBuilder) indexName(cons *ast.Constraint) (model.CIStr, error) {
+	if cons.Tp == ast.ConstraintPrimaryKey {
+		return model.NewCIStr(mysql.PrimaryKeyName), nil
+	}
+	if cons.Name != "" {
+		if strings.EqualFold(cons.Name, mysql.PrimaryKeyName) {
+			return model.CIStr{}, ErrWrongNameForIndex.GenWithStackByArgs(cons.Name)
+		}
+		if len(cons.Name) > mysql.MaxIndexIdentifierLen {
+			return model.CIStr{}, ErrTooLongIdent.GenWithStackByArgs(cons.Name)
+		}
+		if b.indexNameExists(strings.ToLower(cons.Name)) {
+			return model.CIStr{}, ErrDupKeyName.GenWithStackByArgs(cons.Name)
+		}
+		return model.NewCIStr(cons.Name), nil
+	}
+	base := "expression_index"
+	if cons.Keys[0].Column != nil {
+		base = cons.Keys[0].Column.Name.O
+	}
+	name := base
+	for i := 2; b.indexNameExists(strings.ToLower(name)) || strings.EqualFold(name, mysql.PrimaryKeyName); i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	return model.NewCIStr(name), nil
+}
+
+func (b *tableBuilder) buildIndex(cons *ast.Constraint, unique bool) (*model.IndexInfo, error) {
+	if len(cons.Keys) > mysql.MaxKeyParts {
+		return nil, ErrTooManyKeyParts.GenWithStackByArgs(mysql.MaxKeyParts)
+	}
+	name, err := b.indexName(cons)
+	if err != nil {
+		return nil, err
+	}
+	idxInfo := &model.IndexInfo{
+		ID:     b.tbInfo.MaxIndexID + 1,
+		Name:   name,
+		Table:  b.tbInfo.Name,
+		State:  model.StatePublic,
+		Tp:     model.IndexTypeBtree,
+		Unique: unique,
+	}
+	if opt := cons.Option; opt != nil {
+		if opt.Tp != model.IndexTypeInvalid {
+			idxInfo.Tp = opt.Tp
+		}
+		idxInfo.Comment = opt.Comment
+		idxInfo.Invisible = opt.Visibility == ast.IndexVisibilityInvisible
+	}
+	cols := make([]*model.ColumnInfo, 0, len(cons.Keys))
+	for i, key := range cons.Keys {
+		if key.Expr != nil {
+			col, err := b.buildHiddenColumn(name, i, key.Expr)
+			if err != nil {
+				return nil, err
+			}
+			idxInfo.Columns = append(idxInfo.Columns, &model.IndexColumn{
+				Name:   col.Name,
+				Offset: col.Offset,
+				Length: types.UnspecifiedLength,
+			})
+			cols = append(cols, col)
+			continue
+		}
+		col := b.findColumn(key.Column.Name)
+		if col == nil {
+			return nil, ErrKeyColumnDoesNotExist.GenWithStackByArgs(key.Column.Name.O)
+		}
+		if col.Tp == mysql.TypeJSON {
+			return nil, ErrJSONUsedAsKey.GenWithStackByArgs(col.Name.O)
+		}
+		length := key.Length
+		if length == 0 {
+			length = types.UnspecifiedLength
+		}
+		if types.IsTypeBlob(col.Tp) && length == types.UnspecifiedLength {
+			return nil, ErrBlobKeyWithoutLength.GenWithStackByArgs(col.Name.O)
+		}
+		if length != types.UnspecifiedLength && !isValidPrefixLength(col, length) {
+			return nil, ErrWrongSubKey
+		}
+		idxInfo.Columns = append(idxInfo.Columns, &model.IndexColumn{
+			Name:   col.Name,
+			Offset: col.Offset,
+			Length: length,
+		})
+		cols = append(cols, col)
+	}
+	b.tbInfo.MaxIndexID = idxInfo.ID
+	b.tbInfo.Indices = append(b.tbInfo.Indices, idxInfo)
+
+	if cons.Tp != ast.ConstraintPrimaryKey {
+		if unique && len(cols) == 1 {
+			cols[0].Flag |= mysql.UniqueKeyFlag
+		} else {
+			cols[0].Flag |= mysql.MultipleKeyFlag
+		}
+	}
+	return idxInfo, nil
+}
+
+// isValidPrefixLength reports whether an index prefix length is valid for a
+// column: the column must be a string and not shorter than the prefix.
+func isValidPrefixLength(col *model.ColumnInfo, length int) bool {
+	switch col.Tp {
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString:
+		return length <= col.Flen
+	case mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return true
+	}
+	return false
+}
+
+// buildHiddenColumn builds the hidden virtual column of an expression index
+// part, which is named like TiDB does. The type of the expression isn't
+// inferred, so the column has no type.
+func (b *tableBuilder) buildHiddenColumn(idxName model.CIStr, i int, expr ast.ExprNode) (*model.ColumnInfo, error) {
+	exprStr, err := restoreExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	col := &model.ColumnInfo{
+		ID:                  b.tbInfo.MaxColumnID + 1,
+		Name:                model.NewCIStr(fmt.Sprintf("%s_%s_%d", expressionIndexPrefix, idxName.O, i)),
+		Offset:              len(b.tbInfo.Columns),
+		State:               model.StatePublic,
+		Hidden:              true,
+		FieldType:           *types.NewFieldType(mysql.TypeUnspecified),
+		GeneratedExprString: exprStr,
+		Dependences:         make(map[string]struct{}),
+	}
+	for _, name := range referredColumns(expr) {
+		dep := b.findColumn(name)
+		if dep == nil {
+			return nil, ErrBadField.GenWithStackByArgs(name.O, "expression index")
+		}
+		if mysql.HasAutoIncrementFlag(dep.Flag) {
+			return nil, ErrFunctionalIndexRefAutoIncrement.GenWithStackByArgs(idxName.O)
+		}
+		col.Dependences[dep.Name.L] = struct{}{}
+	}
+	b.tbInfo.MaxColumnID = col.ID
+	b.tbInfo.Columns = append(b.tbInfo.Columns, col)
+	return col, nil
+}
+
+func (b *tableBuilder) buildForeignKey(cons *ast.Constraint) error {
+	fkInfo := &model.FKInfo{
+		ID:       b.tbInfo.MaxForeignKeyID + 1,
+		Name:     model.NewCIStr(cons.Name),
+		RefTable: cons.Refer.Table.Name,
+		State:    model.StatePublic,
+	}
+	if cons.Name == "" {
+		fkInfo.Name = model.NewCIStr(fmt.Sprintf("fk_%d", fkInfo.ID))
+	}
+	for _, fk := range b.tbInfo.ForeignKeys {
+		if fk.Name.L == fkInfo.Name.L {
+			return ErrFkDupName.GenWithStackByArgs(fkInfo.Name.O)
+		}
+	}
+	if len(cons.Keys) != len(cons.Refer.IndexPartSpecifications) {
+		return ErrWrongFkDef.GenWithStackByArgs(fkInfo.Name.O, "Key reference and table reference don't match")
+	}
+	for _, key := range cons.Keys {
+		col := b.findColumn(key.Column.Name)
+		if col == nil {
+			return ErrKeyColumnDoesNotExist.GenWithStackByArgs(key.Column.Name.O)
+		}
+		fkInfo.Cols = append(fkInfo.Cols, col.Name)
+	}
+	for _, key := range cons.Refer.IndexPartSpecifications {
+		fkInfo.RefCols = append(fkInfo.RefCols, key.Column.Name)
+	}
+	if cons.Refer.OnDelete != nil {
+		fkInfo.OnDelete = int(cons.Refer.OnDelete.ReferOpt)
+	}
+	if cons.Refer.OnUpdate != nil {
+		fkInfo.OnUpdate = int(cons.Refer.OnUpdate.ReferOpt)
+	}
+	b.tbInfo.MaxForeignKeyID = fkInfo.ID
+	b.tbInfo.ForeignKeys = append(b.tbInfo.ForeignKeys, fkInfo)
+	return nil
+}
+
+func (b *tableBuilder) buildCheck(cons *ast.Constraint) error {
+	cst := &model.ConstraintInfo{
+		ID:       b.tbInfo.MaxConstraintID + 1,
+		Name:     model.NewCIStr(cons.Name),
+		Table:    b.tbInfo.Name,
+		Enforced: cons.Enforced,
+		InColumn: cons.InColumn,
+		State:    model.StatePublic,
+	}
+	if cons.Name == "" {
+		cst.Name = model.NewCIStr(fmt.Sprintf("%s_chk_%d", b.tbInfo.Name.O, cst.ID))
+	}
+	if b.tbInfo.FindConstraintInfoByName(cst.Name.L) != nil {
+		return ErrCheckConstraintDupName.GenWithStackByArgs(cst.Name.O)
+	}
+	exprStr, err := restoreExpr(cons.Expr)
+	if err != nil {
+		return err
+	}
+	cst.ExprString = exprStr
+	seen := make(map[string]struct{})
+	for _, name := range referredColumns(cons.Expr) {
+		col := b.findColumn(name)
+		if col == nil {
+			return ErrBadField.GenWithStackByArgs(name.O, "check constraint "+cst.Name.O+" expression")
+		}
+		if _, ok := seen[col.Name.L]; !ok {
+			seen[col.Name.L] = struct{}{}
+			cst.ConstraintCols = append(cst.ConstraintCols, col.Name)
+		}
+	}
+	b.tbInfo.MaxConstraintID = cst.ID
+	b.tbInfo.Constraints = append(b.tbInfo.Constraints, cst)
+	return nil
+}
+
+// checkAutoIncrement checks that there is at most one auto-increment column and it is a key.
+func (b *tableBuilder) checkAutoIncrement() error {
+	var autoCol *model.ColumnInfo
+	for _, col := range b.tbInfo.Columns {
+		if !mysql.HasAutoIncrementFlag(col.Flag) {
+			continue
+		}
+		if autoCol != nil {
+			return ErrWrongAutoKey
+		}
+		autoCol = col
+	}
+	if autoCol == nil || (b.tbInfo.PKIsHandle && mysql.HasPriKeyFlag(autoCol.Flag)) {
+		return nil
+	}
+	for _, idx := range b.tbInfo.Indices {
+		if idx.Columns[0].Offset == autoCol.Offset {
+			return nil
+		}
+	}
+	return ErrWrongAutoKey
+}
+
+// checkAutoRandom checks that the AUTO_RANDOM column is a BIGINT primary key
+// which is the handle of the table.
+func (b *tableBuilder) checkAutoRandom() error {
+	col := b.autoRandomCol
+	if col == nil {
+		return nil
+	}
+	if col.Tp != mysql.TypeLonglong {
+		return ErrInvalidAutoRandom.GenWithStackByArgs(fmt.Sprintf(autoRandomOnNonBigIntColumn, types.TypeStr(col.Tp)))
+	}
+	if !b.tbInfo.PKIsHandle || !mysql.HasPriKeyFlag(col.Flag) {
+		return ErrInvalidAutoRandom.GenWithStackByArgs(fmt.Sprintf(autoRandomPKIsNotHandle, col.Name.O))
+	}
+	return nil
+}
+
+func (b *tableBuilder) buildPartitionInfo(opts *ast.PartitionOptions) error {
+	pi := &model.PartitionInfo{
+		Type:   opts.Tp,
+		Enable: true,
+		Num:    opts.Num,
+	}
+	if opts.Expr != nil {
+		exprStr, err := restoreExpr(opts.Expr)
+		if err != nil {
+			return err
+		}
+		pi.Expr = exprStr
+		for _, name := range referredColumns(opts.Expr) {
+			if b.findColumn(name) == nil {
+				return ErrBadField.GenWithStackByArgs(name.O, "partition function")
+			}
+		}
+	}
+	for _, cn := range opts.ColumnNames {
+		col := b.findColumn(cn.Name)
+		if col == nil {
+			return ErrFieldNotFoundPart
+		}
+		pi.Columns = append(pi.Columns, col.Name)
+	}
+
+	if len(opts.Definitions) == 0 {
+		// HASH and KEY partitions may only give the number of partitions.
+		for i := uint64(0); i < opts.Num; i++ {
+			pi.Definitions = append(pi.Definitions, model.PartitionDefinition{
+				ID:   b.allocID(),
+				Name: model.NewCIStr(fmt.Sprintf("p%d", i)),
+			})
+		}
+	}
+	rangeCols := b.rangeColumns(opts)
+	var prevBound []ast.ExprNode
+	names := make(map[string]struct{}, len(opts.Definitions))
+	for _, def := range opts.Definitions {
+		if _, ok := names[def.Name.L]; ok {
+			return ErrSameNamePartition.GenWithStackByArgs(def.Name.O)
+		}
+		names[def.Name.L] = struct{}{}
+		pd := model.PartitionDefinition{
+			ID:   b.allocID(),
+			Name: def.Name,
+		}
+		pd.Comment, _ = def.Comment()
+		switch clause := def.Clause.(type) {
+		case *ast.PartitionDefinitionClauseLessThan:
+			if prevBound != nil && compareRangeBounds(prevBound, clause.Exprs, rangeCols) >= 0 {
+				return ErrRangeNotIncreasing
+			}
+			prevBound = clause.Exprs
+			for _, expr := range clause.Exprs {
+				s, err := restoreExpr(expr)
+				if err != nil {
+					return err
+				}
+				pd.LessThan = append(pd.LessThan, s)
+			}
+		case *ast.PartitionDefinitionClauseIn:
+			for _, values := range clause.Values {
+				strs := make([]string, 0, len(values))
+				for _, expr := range values {
+					s, err := restoreExpr(expr)
+					if err != nil {
+						return err
+					}
+					strs = append(strs, s)
+				}
+				pd.InValues = append(pd.InValues, strs)
+			}
+		}
+		for _, op := range def.Options {
+			switch op.Tp {
+			case ast.TableOptionPlacementPolicy:
+				pd.PlacementPolicyRef = &model.PolicyRefInfo{Name: model.NewCIStr(op.StrValue)}
+			default:
+				setPlacementOption(&pd.DirectPlacementOpts, op)
+			}
+		}
+		pi.Definitions = append(pi.Definitions, pd)
+	}
+	pi.Num = uint64(len(pi.Definitions))
+	b.tbInfo.Partition = pi
+	return nil
+}
+
+// rangeColumns returns the columns which the bounds of the RANGE partitions
+// are compared as. The bounds of a partitioning expression are integers.
+func (b *tableBuilder) rangeColumns(opts *ast.PartitionOptions) []*model.ColumnInfo {
+	if opts.Tp != model.PartitionTypeRange {
+		return nil
+	}
+	if opts.Expr == nil {
+		cols := make([]*model.ColumnInfo, 0, len(opts.ColumnNames))
+		for _, cn := range opts.ColumnNames {
+			cols = append(cols, b.findColumn(cn.Name))
+		}
+		return cols
+	}
+	if colExpr, ok := opts.Expr.(*ast.ColumnNameExpr); ok {
+		if col := b.findColumn(colExpr.Name.Name); col != nil && mysql.IsIntegerType(col.Tp) {
+			return []*model.ColumnInfo{col}
+		}
+	}
+	return []*model.ColumnInfo{{FieldType: *types.NewFieldType(mysql.TypeLonglong)}}
+}
+
+// compareRangeBounds compares the bounds of two RANGE partitions as tuples,
+// where MAXVALUE is greater than any value. The bounds which can't be compared
+// are regarded as increasing.
+func compareRangeBounds(a, b []ast.ExprNode, cols []*model.ColumnInfo) int {
+	if len(a) != len(cols) || len(b) != len(cols) {
+		return -1
+	}
+	for i, col := range cols {
+		_, aMax := a[i].(*ast.MaxValueExpr)
+		_, bMax := b[i].(*ast.MaxValueExpr)
+		switch {
+		case aMax && bMax:
+			continue
+		case aMax:
+			return 1
+		case bMax:
+			return -1
+		}
+		av, aNull, aOK := constantValue(a[i], col)
+		bv, bNull, bOK := constantValue(b[i], col)
+		if !aOK || !bOK || aNull || bNull {
+			return -1
+		}
+		if c := av.compare(bv); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,335 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/schema"
+	"github.com/pingcap/parser/terror"
+	_ "github.com/pingcap/parser/test_driver"
+	"github.com/pingcap/parser/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTableSuite{})
+
+type testTableSuite struct {
+	*parser.Parser
+}
+
+func (s *testTableSuite) SetUpSuite(c *C) {
+	s.Parser = parser.New()
+}
+
+func (s *testTableSuite) buildTableInfo(c *C, sql string, opts *schema.BuildOptions) (*model.TableInfo, error) {
+	stmt, err := s.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil, Commentf("%s", sql))
+	return schema.BuildTableInfo(stmt.(*ast.CreateTableStmt), opts)
+}
+
+func (s *testTableSuite) TestBuildColumns(c *C) {
+	tbl, err := s.buildTableInfo(c, "create table t ("+
+		"a int not null auto_increment primary key, "+
+		"b varchar(10) default 'x' comment 'bb', "+
+		"c char, "+
+		"d decimal, "+
+		"e timestamp(3) default current_timestamp(3) on update current_timestamp(3), "+
+		"f int not null default -1, "+
+		"g blob, "+
+		"h varchar(20) character set latin1, "+
+		"i varchar(20) collate utf8mb4_general_ci, "+
+		"j int not null, "+
+		"k varchar(20) character set binary"+
+		") charset utf8 comment 'tt' auto_increment = 10 shard_row_id_bits = 4", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.ID, Equals, int64(1))
+	c.Assert(tbl.Name.O, Equals, "t")
+	c.Assert(tbl.State, Equals, model.StatePublic)
+	c.Assert(tbl.Charset, Equals, "utf8")
+	c.Assert(tbl.Collate, Equals, "utf8_bin")
+	c.Assert(tbl.Comment, Equals, "tt")
+	c.Assert(tbl.AutoIncID, Equals, int64(10))
+	c.Assert(tbl.ShardRowIDBits, Equals, uint64(4))
+	c.Assert(tbl.MaxColumnID, Equals, int64(11))
+	c.Assert(tbl.PKIsHandle, IsTrue)
+	c.Assert(tbl.IsCommonHandle, IsFalse)
+	c.Assert(tbl.Indices, HasLen, 0)
+	c.Assert(tbl.Columns, HasLen, 11)
+	for i, col := range tbl.Columns {
+		c.Assert(col.ID, Equals, int64(i+1))
+		c.Assert(col.Offset, Equals, i)
+		c.Assert(col.State, Equals, model.StatePublic)
+	}
+
+	a := tbl.Columns[0]
+	c.Assert(a.Flen, Equals, 11)
+	c.Assert(a.Charset, Equals, "binary")
+	c.Assert(a.Flag, Equals, mysql.NotNullFlag|mysql.PriKeyFlag|mysql.AutoIncrementFlag|mysql.BinaryFlag)
+	c.Assert(tbl.GetPkColInfo(), Equals, a)
+
+	b := tbl.Columns[1]
+	c.Assert(b.Charset, Equals, "utf8")
+	c.Assert(b.Collate, Equals, "utf8_bin")
+	c.Assert(b.GetDefaultValue(), Equals, "x")
+	c.Assert(b.GetOriginDefaultValue(), Equals, "x")
+	c.Assert(b.Comment, Equals, "bb")
+	c.Assert(b.Flag, Equals, uint(0))
+
+	c.Assert(tbl.Columns[2].Flen, Equals, 1)
+	c.Assert(tbl.Columns[3].Flen, Equals, 10)
+	c.Assert(tbl.Columns[3].Decimal, Equals, 0)
+
+	e := tbl.Columns[4]
+	c.Assert(e.Decimal, Equals, 3)
+	c.Assert(e.GetDefaultValue(), Equals, "CURRENT_TIMESTAMP(3)")
+	c.Assert(mysql.HasOnUpdateNowFlag(e.Flag), IsTrue)
+
+	c.Assert(tbl.Columns[5].GetDefaultValue(), Equals, "-1")
+	c.Assert(tbl.Columns[6].Charset, Equals, "binary")
+	c.Assert(tbl.Columns[7].Charset, Equals, "latin1")
+	c.Assert(tbl.Columns[7].Collate, Equals, "latin1_bin")
+	c.Assert(tbl.Columns[8].Charset, Equals, "utf8mb4")
+	c.Assert(tbl.Columns[8].Collate, Equals, "utf8mb4_general_ci")
+	c.Assert(mysql.HasNoDefaultValueFlag(tbl.Columns[9].Flag), IsTrue)
+	c.Assert(tbl.Columns[10].Charset, Equals, "binary")
+	c.Assert(mysql.HasBinaryFlag(tbl.Columns[10].Flag), IsTrue)
+
+	tbl, err = s.buildTableInfo(c, "create table t (a varchar(10))", &schema.BuildOptions{Charset: "latin1"})
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Charset, Equals, "latin1")
+	c.Assert(tbl.Columns[0].Collate, Equals, "latin1_bin")
+	tbl, err = s.buildTableInfo(c, "create table t (a varchar(10))", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Charset, Equals, mysql.DefaultCharset)
+	c.Assert(tbl.Collate, Equals, mysql.DefaultCollationName)
+}
+
+func (s *testTableSuite) TestBuildIndices(c *C) {
+	tbl, err := s.buildTableInfo(c, "create table t ("+
+		"a varchar(10), b int unique, c int, d text, "+
+		"primary key (a, c) clustered, "+
+		"key (c), key (c, b), unique key uk (d(10)) comment 'x' invisible, index idx using hash (b))", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.PKIsHandle, IsFalse)
+	c.Assert(tbl.IsCommonHandle, IsTrue)
+	c.Assert(tbl.MaxIndexID, Equals, int64(6))
+
+	names := make([]string, 0, len(tbl.Indices))
+	for i, idx := range tbl.Indices {
+		c.Assert(idx.ID, Equals, int64(i+1))
+		c.Assert(idx.Table.O, Equals, "t")
+		names = append(names, idx.Name.O)
+	}
+	c.Assert(names, DeepEquals, []string{"PRIMARY", "c", "c_2", "uk", "idx", "b"})
+
+	pk := tbl.Indices[0]
+	c.Assert(pk.Primary, IsTrue)
+	c.Assert(pk.Unique, IsTrue)
+	c.Assert(pk.Columns, HasLen, 2)
+	c.Assert(pk.Columns[1].Offset, Equals, 2)
+	c.Assert(pk.Columns[1].Length, Equals, types.UnspecifiedLength)
+	uk := tbl.Indices[3]
+	c.Assert(uk.Unique, IsTrue)
+	c.Assert(uk.Invisible, IsTrue)
+	c.Assert(uk.Comment, Equals, "x")
+	c.Assert(uk.Columns[0].Length, Equals, 10)
+	c.Assert(tbl.Indices[4].Tp, Equals, model.IndexTypeHash)
+	c.Assert(tbl.Indices[1].Tp, Equals, model.IndexTypeBtree)
+
+	c.Assert(mysql.HasPriKeyFlag(tbl.Columns[0].Flag), IsTrue)
+	c.Assert(mysql.HasNotNullFlag(tbl.Columns[0].Flag), IsTrue)
+	c.Assert(mysql.HasUniKeyFlag(tbl.Columns[1].Flag), IsTrue)
+	c.Assert(mysql.HasMultipleKeyFlag(tbl.Columns[2].Flag), IsTrue)
+	c.Assert(mysql.HasUniKeyFlag(tbl.Columns[3].Flag), IsTrue)
+
+	// An expression index part is saved as a hidden virtual column.
+	tbl, err = s.buildTableInfo(c, "create table t (a int, b varchar(10), key idx ((lower(b)), a))", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Columns, HasLen, 3)
+	hidden := tbl.Columns[2]
+	c.Assert(hidden.Name.O, Equals, "_V$_idx_0")
+	c.Assert(hidden.Hidden, IsTrue)
+	c.Assert(hidden.GeneratedExprString, Equals, "lower(`b`)")
+	c.Assert(hidden.Dependences, DeepEquals, map[string]struct{}{"b": {}})
+	c.Assert(tbl.Indices[0].Columns[0].Name.O, Equals, "_V$_idx_0")
+	c.Assert(tbl.Indices[0].Columns[0].Offset, Equals, 2)
+	c.Assert(tbl.Indices[0].Columns[1].Name.O, Equals, "a")
+
+	tbl, err = s.buildTableInfo(c, "create table t (a int primary key nonclustered)", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.PKIsHandle, IsFalse)
+	c.Assert(tbl.IsCommonHandle, IsFalse)
+	c.Assert(tbl.Indices[0].Primary, IsTrue)
+
+	tbl, err = s.buildTableInfo(c, "create table t (a varchar(10) primary key)", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.IsCommonHandle, IsFalse)
+	tbl, err = s.buildTableInfo(c, "create table t (a varchar(10) primary key)", &schema.BuildOptions{EnableClusteredIndex: true})
+	c.Assert(err, IsNil)
+	c.Assert(tbl.IsCommonHandle, IsTrue)
+}
+
+func (s *testTableSuite) TestBuildDefaultValues(c *C) {
+	tbl, err := s.buildTableInfo(c, "create table t ("+
+		"a tinyint default -128, b tinyint unsigned default '255', c bigint unsigned default 18446744073709551615, "+
+		"d decimal(4, 2) default -99.99, e varchar(2) default 'éé', f enum('x', 'y') default 'Y', g enum('x', 'y') default 2, "+
+		"h set('x', 'y') default 'y,x', i bit(2) default 3, j year default 2155, k binary(2) default 0x616263)", nil)
+	c.Assert(err, IsNil)
+	defaults := make([]interface{}, 0, len(tbl.Columns))
+	for _, col := range tbl.Columns {
+		defaults = append(defaults, col.GetDefaultValue())
+	}
+	c.Assert(defaults, DeepEquals, []interface{}{"-128", "255", "18446744073709551615", "-99.99", "éé", "Y", "2", "y,x", "3", "2155", "abc"})
+}
+
+func (s *testTableSuite) TestBuildConstraints(c *C) {
+	tbl, err := s.buildTableInfo(c, "create table t ("+
+		"a int check (a > 0), b int, c int as (a + b) virtual, d int as (c * 2) stored, "+
+		"constraint fk foreign key (b) references p (id) on delete cascade, "+
+		"foreign key (a, b) references q (x, y), "+
+		"check (b < a))", nil)
+	c.Assert(err, IsNil)
+
+	c.Assert(tbl.Columns[2].GeneratedExprString, Equals, "`a` + `b`")
+	c.Assert(tbl.Columns[2].GeneratedStored, IsFalse)
+	c.Assert(tbl.Columns[2].Dependences, DeepEquals, map[string]struct{}{"a": {}, "b": {}})
+	c.Assert(tbl.Columns[3].GeneratedStored, IsTrue)
+	c.Assert(tbl.Columns[3].Dependences, DeepEquals, map[string]struct{}{"c": {}})
+
+	c.Assert(tbl.ForeignKeys, HasLen, 2)
+	fk := tbl.ForeignKeys[0]
+	c.Assert(fk.Name.O, Equals, "fk")
+	c.Assert(fk.RefTable.O, Equals, "p")
+	c.Assert(fk.Cols, DeepEquals, []model.CIStr{model.NewCIStr("b")})
+	c.Assert(fk.RefCols, DeepEquals, []model.CIStr{model.NewCIStr("id")})
+	c.Assert(fk.OnDelete, Equals, int(ast.ReferOptionCascade))
+	c.Assert(tbl.ForeignKeys[1].Name.O, Equals, "fk_2")
+	c.Assert(tbl.ForeignKeys[1].ID, Equals, int64(2))
+	c.Assert(tbl.MaxForeignKeyID, Equals, int64(2))
+
+	c.Assert(tbl.Constraints, HasLen, 2)
+	c.Assert(tbl.MaxConstraintID, Equals, int64(2))
+	cst := tbl.Constraints[0]
+	c.Assert(cst.Name.O, Equals, "t_chk_1")
+	c.Assert(cst.ExprString, Equals, "`b` < `a`")
+	c.Assert(cst.ConstraintCols, DeepEquals, []model.CIStr{model.NewCIStr("b"), model.NewCIStr("a")})
+	c.Assert(cst.Enforced, IsTrue)
+	c.Assert(cst.InColumn, IsFalse)
+	c.Assert(tbl.Constraints[1].InColumn, IsTrue)
+	c.Assert(tbl.Constraints[1].ExprString, Equals, "`a` > 0")
+}
+
+func (s *testTableSuite) TestBuildPartition(c *C) {
+	var id int64 = 100
+	opts := &schema.BuildOptions{AllocID: func() int64 { id++; return id }}
+	tbl, err := s.buildTableInfo(c, "create table t (a int, b varchar(10)) partition by range (a) ("+
+		"partition p0 values less than (10) comment 'first', "+
+		"partition p1 values less than (maxvalue) placement policy = x)", opts)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.ID, Equals, int64(101))
+	pi := tbl.Partition
+	c.Assert(pi.Type, Equals, model.PartitionTypeRange)
+	c.Assert(pi.Enable, IsTrue)
+	c.Assert(pi.Expr, Equals, "`a`")
+	c.Assert(pi.Num, Equals, uint64(2))
+	c.Assert(pi.Definitions[0].ID, Equals, int64(102))
+	c.Assert(pi.Definitions[0].LessThan, DeepEquals, []string{"10"})
+	c.Assert(pi.Definitions[0].Comment, Equals, "first")
+	c.Assert(pi.Definitions[1].LessThan, DeepEquals, []string{"maxvalue"})
+	c.Assert(pi.Definitions[1].PlacementPolicyRef.Name.O, Equals, "x")
+
+	tbl, err = s.buildTableInfo(c, "create table t (a int, b varchar(10)) partition by list columns (b) ("+
+		"partition p0 values in ('x', 'y'), partition p1 values in ('z'))", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Partition.Columns, DeepEquals, []model.CIStr{model.NewCIStr("b")})
+	c.Assert(tbl.Partition.Definitions[0].InValues, DeepEquals, [][]string{{"'x'"}, {"'y'"}})
+
+	tbl, err = s.buildTableInfo(c, "create table t (a int) partition by hash (a) partitions 3", nil)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Partition.Definitions, HasLen, 3)
+	c.Assert(tbl.Partition.Definitions[2].Name.O, Equals, "p2")
+	c.Assert(tbl.Partition.Definitions[2].ID, Equals, int64(4))
+}
+
+func (s *testTableSuite) TestBuildErrors(c *C) {
+	cases := []struct {
+		sql string
+		err *terror.Error
+	}{
+		{"create table t (a int, A int)", schema.ErrDupFieldName},
+		{"create table t (a int, key k (a), key K (a))", schema.ErrDupKeyName},
+		{"create table t (a int primary key, b int primary key)", schema.ErrMultiplePriKey},
+		{"create table t (a int, primary key (a), primary key (a))", schema.ErrMultiplePriKey},
+		{"create table t (a int, key (b))", schema.ErrKeyColumnDoesNotExist},
+		{"create table t (a int auto_increment)", schema.ErrWrongAutoKey},
+		{"create table t (a int auto_increment key, b int auto_increment unique)", schema.ErrWrongAutoKey},
+		{"create table t (a int not null default null)", schema.ErrInvalidDefault},
+		{"create table t (a int default current_timestamp)", schema.ErrInvalidDefault},
+		{"create table t (f int unsigned not null default -1)", schema.ErrInvalidDefault},
+		{"create table t (a tinyint default 128)", schema.ErrInvalidDefault},
+		{"create table t (a tinyint unsigned default '256')", schema.ErrInvalidDefault},
+		{"create table t (a int default 'x')", schema.ErrInvalidDefault},
+		{"create table t (a bigint default 9223372036854775808)", schema.ErrInvalidDefault},
+		{"create table t (a decimal(4, 2) default 100)", schema.ErrInvalidDefault},
+		{"create table t (a double unsigned default -1.5)", schema.ErrInvalidDefault},
+		{"create table t (a varchar(2) default 'abc')", schema.ErrInvalidDefault},
+		{"create table t (a enum('x', 'y') default 'z')", schema.ErrInvalidDefault},
+		{"create table t (a set('x', 'y') default 'x,z')", schema.ErrInvalidDefault},
+		{"create table t (a bit(2) default 4)", schema.ErrInvalidDefault},
+		{"create table t (a year default 1900)", schema.ErrInvalidDefault},
+		{"create table t (a text default 'x')", schema.ErrBlobCantHaveDefault},
+		{"create table t (a int, constraint c check (a > 0), constraint C check (a < 10))", schema.ErrCheckConstraintDupName},
+		{"create table t (a int on update current_timestamp)", schema.ErrInvalidOnUpdate},
+		{"create table t (a text, key (a))", schema.ErrBlobKeyWithoutLength},
+		{"create table t (a json, key (a))", schema.ErrJSONUsedAsKey},
+		{"create table t (a int, key `primary` (a))", schema.ErrWrongNameForIndex},
+		{"create table t (a varchar(5), key (a(10)))", schema.ErrWrongSubKey},
+		{"create table t (a int, key (a(2)))", schema.ErrWrongSubKey},
+		{"create table t (a int, primary key ((a + 1)))", schema.ErrFunctionalIndexPrimaryKey},
+		{"create table t (a int, key ((b + 1)))", schema.ErrBadField},
+		{"create table t (a int auto_increment key, key ((a + 1)))", schema.ErrFunctionalIndexRefAutoIncrement},
+		{"create table t (a int primary key auto_random)", schema.ErrInvalidAutoRandom},
+		{"create table t (a bigint auto_random, b int primary key)", schema.ErrInvalidAutoRandom},
+		{"create table t (a bigint primary key nonclustered auto_random)", schema.ErrInvalidAutoRandom},
+		{"create table t (a int null primary key)", schema.ErrPrimaryCantHaveNull},
+		{"create table t (a int as (b))", schema.ErrBadField},
+		{"create table t (a int as (b), b int as (1))", schema.ErrGeneratedColumnNonPrior},
+		{"create table t (a int auto_increment key, b int as (a))", schema.ErrGeneratedColumnRefAutoInc},
+		{"create table t (a int, foreign key (a) references p (x, y))", schema.ErrWrongFkDef},
+		{"create table t (a int, constraint f foreign key (a) references p (x), constraint f foreign key (a) references q (x))", schema.ErrFkDupName},
+		{"create table t (a int) partition by range columns (b) (partition p0 values less than (1))", schema.ErrFieldNotFoundPart},
+		{"create table t (a int) partition by range (a) (partition p0 values less than (1), partition P0 values less than (2))", schema.ErrSameNamePartition},
+		{"create table t (a varchar(10) charset utf8 collate latin1_bin)", charset.ErrCollationCharsetMismatch},
+		{"create table t like t1", schema.ErrNotSupportedYet},
+		{"create table t select 1", schema.ErrNotSupportedYet},
+		{"create table t (`aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa` int)", schema.ErrTooLongIdent},
+	}
+	for _, ca := range cases {
+		_, err := s.buildTableInfo(c, ca.sql, nil)
+		c.Assert(terror.ErrorEqual(err, ca.err), IsTrue, Commentf("%s: %v", ca.sql, err))
+	}
+	_, err := s.buildTableInfo(c, "create table t (a int)", &schema.BuildOptions{Charset: "xxx"})
+	c.Assert(terror.ErrorEqual(err, schema.ErrUnknownCharacterSet), IsTrue)
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terror
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+)
+
+// Catalogue holds the localized message templates of the MySQL error codes.
+// The English messages are the ones of mysql.MySQLErrName, and the other
+// locales are added with Add or Load. A Catalogue is safe for concurrent use.
+type Catalogue struct {
+	mu       sync.RWMutex
+	messages map[string]map[uint16]string
+}
+
+// DefaultCatalogue is the catalogue used by ToLocalizedSQLError.
+var DefaultCatalogue = NewCatalogue()
+
+// NewCatalogue creates a catalogue without localized messages.
+func NewCatalogue() *Catalogue {
+	return &Catalogue{messages: make(map[string]map[uint16]string)}
+}
+
+// normalizeLocale formats the locale like MySQL lc_messages, e.g. "zh_CN".
+func normalizeLocale(locale string) string {
+	locale = strings.Replace(locale, "-", "_", -1)
+	if i := strings.IndexByte(locale, '_'); i >= 0 {
+		return strings.ToLower(locale[:i]) + "_" + strings.ToUpper(locale[i+1:])
+	}
+	return strings.ToLower(locale)
+}
+
+// Add adds the message templates of a locale, like "zh_CN" or "ja". The
+// templates have the same printf verbs as the English ones, and they may use
+// explicit argument indexes like "%[2]s" to reorder the arguments.
+func (c *Catalogue) Add(locale string, messages map[uint16]string) {
+	locale = normalizeLocale(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.messages[locale]
+	if !ok {
+		m = make(map[uint16]string, len(messages))
+		c.messages[locale] = m
+	}
+	for code, msg := range messages {
+		m[code] = msg
+	}
+}
+
+// Load adds the message templates of a locale from a JSON object which maps
+// the error codes to the templates, like {"1062": "..."}. The files can be
+// embedded in the program, e.g. with go:embed.
+func (c *Catalogue) Load(locale string, r io.Reader) error {
+	var raw map[string]string
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return errors.Annotatef(err, "failed to load the error messages of '%s'", locale)
+	}
+	messages := make(map[uint16]string, len(raw))
+	for key, msg := range raw {
+		code, err := strconv.ParseUint(key, 10, 16)
+		if err != nil {
+			return errors.Errorf("failed to load the error messages of '%s': invalid error code '%s'", locale, key)
+		}
+		messages[uint16(code)] = msg
+	}
+	c.Add(locale, messages)
+	return nil
+}
+
+// Locales returns the locales which have messages in order.
+func (c *Catalogue) Locales() []string {
+	c.mu.RLock()
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	c.mu.RUnlock()
+	sort.Strings(locales)
+	return locales
+}
+
+// Message returns the message template of an error code in a locale. If the
+// locale has no message for the code, the one of its language is used, e.g.
+// "zh" for "zh_TW", and then the English one.
+func (c *Catalogue) Message(locale string, code uint16) (string, bool) {
+	locale = normalizeLocale(locale)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if msg, ok := c.messages[locale][code]; ok {
+		return msg, true
+	}
+	if i := strings.IndexByte(locale, '_'); i >= 0 {
+		if msg, ok := c.messages[locale[:i]][code]; ok {
+			return msg, true
+		}
+	}
+	if msg, ok := mysql.MySQLErrName[code]; ok {
+		return msg.Raw, true
+	}
+	return "", false
+}
+
+// Verify checks that a locale has a message for every error code of
+// mysql.MySQLErrName, and that the messages have the same arguments as the
+// English ones. It returns the codes that fail the check in order.
+func (c *Catalogue) Verify(locale string) (missing, mismatched []uint16) {
+	locale = normalizeLocale(locale)
+	c.mu.RLock()
+	messages := c.messages[locale]
+	c.mu.RUnlock()
+	for code, en := range mysql.MySQLErrName {
+		msg, ok := messages[code]
+		if !ok {
+			missing = append(missing, code)
+			continue
+		}
+		if !sameVerbs(en.Raw, msg) {
+			mismatched = append(mismatched, code)
+		}
+	}
+	sortCodes(missing)
+	sortCodes(mismatched)
+	return missing, mismatched
+}
+
+func sortCodes(codes []uint16) {
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+}
+
+// Localize returns the message of the error in a locale. The error keeps its
+// message if it isn't created from the standard template of its code, or the
+// locale has no message for the code.
+func (c *Catalogue) Localize(e *Error, locale string) string {
+	msg := e.GetMsg()
+	code := uint16(e.Code())
+	std, ok := mysql.MySQLErrName[code]
+	if !ok || e.MessageTemplate() != std.Raw {
+		return msg
+	}
+	localized, ok := c.Message(locale, code)
+	if !ok || localized == std.Raw {
+		return msg
+	}
+	args, ok := extractArgs(std.Raw, msg)
+	if !ok {
+		return msg
+	}
+	return formatArgs(localized, args)
+}
+
+// ToLocalizedSQLError converts Error to mysql.SQLError with the message in a
+// locale of DefaultCatalogue.
+func ToLocalizedSQLError(e *Error, locale string) *mysql.SQLError {
+	code := getMySQLErrorCode(e)
+	return mysql.NewErrf(code, "%s", nil, DefaultCatalogue.Localize(e, locale))
+}
+
+// verbRegexp matches the printf verbs of a message template.
+var verbRegexp = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d*)?[a-zA-Z%]`)
+
+// templateVerbs returns the printf verbs of a template, and the argument
+// indexes (1-based) which the verbs refer to.
+func templateVerbs(template string) (locs [][]int, indexes []int) {
+	next := 1
+	for _, loc := range verbRegexp.FindAllStringSubmatchIndex(template, -1) {
+		if template[loc[1]-1] == '%' {
+			continue
+		}
+		index := next
+		if loc[2] >= 0 {
+			index, _ = strconv.Atoi(template[loc[2]+1 : loc[3]-1])
+		}
+		next = index + 1
+		locs = append(locs, loc[:2])
+		indexes = append(indexes, index)
+	}
+	return locs, indexes
+}
+
+// sameVerbs reports whether the localized template uses the same arguments
+// as the English one.
+func sameVerbs(en, localized string) bool {
+	_, enIndexes := templateVerbs(en)
+	_, indexes := templateVerbs(localized)
+	used := make(map[int]bool, len(indexes))
+	for _, index := range indexes {
+		if index < 1 || index > len(enIndexes) {
+			return false
+		}
+		used[index] = true
+	}
+	return len(used) == len(enIndexes)
+}
+
+// extractArgs extracts the formatted arguments of a message from its
+// template.
+func extractArgs(template, msg string) ([]string, bool) {
+	locs, _ := templateVerbs(template)
+	if len(locs) == 0 {
+		return nil, template == msg
+	}
+	var pattern strings.Builder
+	pattern.WriteString("(?s)^")
+	last := 0
+	for _, loc := range locs {
+		pattern.WriteString(regexp.QuoteMeta(strings.Replace(template[last:loc[0]], "%%", "%", -1)))
+		pattern.WriteString("(.*?)")
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(strings.Replace(template[last:], "%%", "%", -1)))
+	pattern.WriteString("$")
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, false
+	}
+	match := re.FindStringSubmatch(msg)
+	if match == nil {
+		return nil, false
+	}
+	return match[1:], true
+}
+
+// formatArgs formats a template with the arguments, which are already
+// formatted by the verbs of the English template.
+func formatArgs(template string, args []string) string {
+	locs, indexes := templateVerbs(template)
+	var b strings.Builder
+	last := 0
+	for i, loc := range locs {
+		b.WriteString(strings.Replace(template[last:loc[0]], "%%", "%", -1))
+		if index := indexes[i]; index >= 1 && index <= len(args) {
+			b.WriteString(args[index-1])
+		}
+		last = loc[1]
+	}
+	b.WriteString(strings.Replace(template[last:], "%%", "%", -1))
+	return b.String()
+}
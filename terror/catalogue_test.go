@@ -0,0 +1,104 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terror
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+)
+
+var _ = Suite(&testCatalogueSuite{})
+
+type testCatalogueSuite struct {
+}
+
+func (s *testCatalogueSuite) TestLocalize(c *C) {
+	catalogue := NewCatalogue()
+	catalogue.Add("zh-cn", map[uint16]string{
+		mysql.ErrDupEntry:    "键 '%[2]s' 的值 '%[1]s' 重复",
+		mysql.ErrNoSuchTable: "表 '%s.%s' 不存在",
+	})
+	err := catalogue.Load("ja", strings.NewReader(`{"1146": "テーブル '%s.%s' は存在しません", "1105": "不明なエラー: %s"}`))
+	c.Assert(err, IsNil)
+	c.Assert(catalogue.Locales(), DeepEquals, []string{"ja", "zh_CN"})
+
+	errDupEntry := ClassExecutor.NewStd(mysql.ErrDupEntry)
+	errNoSuchTable := ClassSchema.NewStd(mysql.ErrNoSuchTable)
+	tests := []struct {
+		err    error
+		locale string
+		msg    string
+	}{
+		{errDupEntry.GenWithStackByArgs("1-a", "PRIMARY"), "zh_CN", "键 'PRIMARY' 的值 '1-a' 重复"},
+		{errDupEntry.GenWithStackByArgs("1-a", "PRIMARY"), "en_US", "Duplicate entry '1-a' for key 'PRIMARY'"},
+		{errDupEntry.GenWithStackByArgs("1-a", "PRIMARY"), "ja_JP", "Duplicate entry '1-a' for key 'PRIMARY'"},
+		{errNoSuchTable.GenWithStackByArgs("test", "t"), "zh_CN", "表 'test.t' 不存在"},
+		{errNoSuchTable.GenWithStackByArgs("test", "t"), "ja_JP", "テーブル 'test.t' は存在しません"},
+		{errNoSuchTable.FastGenByArgs("te.st", "t's"), "ja", "テーブル 'te.st.t's' は存在しません"},
+		// The errors with custom messages aren't localized.
+		{errNoSuchTable.GenWithStack("no table %s", "t"), "zh_CN", "no table t"},
+		{ClassExecutor.NewStdErr(mysql.ErrDupEntry, mysql.Message("dup %s", nil)).GenWithStackByArgs("x"), "zh_CN", "dup x"},
+	}
+	for _, t := range tests {
+		e := errors.Cause(t.err).(*Error)
+		c.Assert(catalogue.Localize(e, t.locale), Equals, t.msg, Commentf("%s %s", t.err, t.locale))
+	}
+
+	c.Assert(catalogue.Load("ja", strings.NewReader(`{"x": "y"}`)), ErrorMatches, ".*invalid error code 'x'")
+	c.Assert(catalogue.Load("ja", strings.NewReader(`[]`)), NotNil)
+}
+
+func (s *testCatalogueSuite) TestToLocalizedSQLError(c *C) {
+	defer func(catalogue *Catalogue) { DefaultCatalogue = catalogue }(DefaultCatalogue)
+	DefaultCatalogue = NewCatalogue()
+	DefaultCatalogue.Add("zh", map[uint16]string{mysql.ErrNoSuchTable: "表 '%s.%s' 不存在"})
+	err := errors.Cause(ClassSchema.NewStd(mysql.ErrNoSuchTable).GenWithStackByArgs("test", "t")).(*Error)
+	sqlErr := ToLocalizedSQLError(err, "zh_TW")
+	c.Assert(sqlErr.Code, Equals, uint16(mysql.ErrNoSuchTable))
+	c.Assert(sqlErr.State, Equals, mysql.MySQLState[mysql.ErrNoSuchTable])
+	c.Assert(sqlErr.Message, Equals, "表 'test.t' 不存在")
+	c.Assert(ToSQLError(err).Message, Equals, "Table 'test.t' doesn't exist")
+}
+
+func (s *testCatalogueSuite) TestVerify(c *C) {
+	catalogue := NewCatalogue()
+	messages := make(map[uint16]string, len(mysql.MySQLErrName))
+	for code, msg := range mysql.MySQLErrName {
+		messages[code] = msg.Raw
+	}
+	catalogue.Add("en", messages)
+	missing, mismatched := catalogue.Verify("en")
+	c.Assert(missing, HasLen, 0)
+	c.Assert(mismatched, HasLen, 0)
+
+	catalogue.Add("en", map[uint16]string{
+		mysql.ErrDupEntry:    "%[2]s %[1]s 100%%",
+		mysql.ErrNoSuchTable: "%s",
+		mysql.ErrBadField:    "%[1]s %[3]s %[4]s",
+	})
+	delete(messages, mysql.ErrUnknown)
+	delete(messages, mysql.ErrSyntax)
+	other := NewCatalogue()
+	other.Add("zh", messages)
+	missing, mismatched = catalogue.Verify("en")
+	c.Assert(missing, HasLen, 0)
+	c.Assert(mismatched, DeepEquals, []uint16{mysql.ErrBadField, mysql.ErrNoSuchTable})
+	missing, _ = other.Verify("zh")
+	c.Assert(missing, DeepEquals, []uint16{mysql.ErrUnknown, mysql.ErrSyntax})
+	missing, _ = other.Verify("ja")
+	c.Assert(missing, HasLen, len(mysql.MySQLErrName))
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terror
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+)
+
+// ClassInfo describes a registered error class.
+type ClassInfo struct {
+	Class       ErrClass
+	Description string
+}
+
+// ErrorClasses returns the classes registered with RegisterErrorClass in the
+// order of their codes.
+func ErrorClasses() []ClassInfo {
+	classes := make([]ClassInfo, 0, len(errClass2Desc))
+	for class, desc := range errClass2Desc {
+		classes = append(classes, ClassInfo{Class: class, Description: desc})
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Class < classes[j].Class })
+	return classes
+}
+
+// ErrorInfo describes an error registered in a class.
+type ErrorInfo struct {
+	Class ErrClass
+	Code  ErrCode
+	// RFCCode is the code of the error like "parser:1064".
+	RFCCode errors.RFCErrorCode
+	// MessageTemplate is the message of the error as a printf template.
+	MessageTemplate string
+	// SQLState is the SQLSTATE sent to the client with the error.
+	SQLState string
+}
+
+// errorRegistry records the errors defined with New, NewStd and NewStdErr.
+type errorRegistry struct {
+	templates map[ErrClass]map[ErrCode]string
+	// conflicts describes the errors which are defined more than once with
+	// different templates.
+	conflicts []string
+}
+
+var registry = newErrorRegistry()
+
+func newErrorRegistry() *errorRegistry {
+	return &errorRegistry{templates: make(map[ErrClass]map[ErrCode]string)}
+}
+
+func (r *errorRegistry) add(class ErrClass, code ErrCode, template string) {
+	codes, ok := r.templates[class]
+	if !ok {
+		codes = make(map[ErrCode]string)
+		r.templates[class] = codes
+	}
+	if old, ok := codes[code]; ok {
+		if old != template {
+			r.conflicts = append(r.conflicts, fmt.Sprintf("error %s:%d is defined with the messages %q and %q", class, code, old, template))
+		}
+		return
+	}
+	codes[code] = template
+}
+
+func (r *errorRegistry) errors() []ErrorInfo {
+	var infos []ErrorInfo
+	for class, codes := range r.templates {
+		for code, template := range codes {
+			infos = append(infos, ErrorInfo{
+				Class:           class,
+				Code:            code,
+				RFCCode:         errors.RFCErrorCode(fmt.Sprintf("%s:%d", class, code)),
+				MessageTemplate: template,
+				SQLState:        mysql.SQLState(uint16(code)),
+			})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Class != infos[j].Class {
+			return infos[i].Class < infos[j].Class
+		}
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
+
+func (r *errorRegistry) check() []string {
+	var problems []string
+	for _, info := range r.errors() {
+		if _, ok := errClass2Desc[info.Class]; !ok {
+			problems = append(problems, fmt.Sprintf("error %s belongs to an unregistered class", info.RFCCode))
+		}
+		if info.MessageTemplate == "" {
+			problems = append(problems, fmt.Sprintf("error %s has no message", info.RFCCode))
+		}
+	}
+	return append(problems, r.conflicts...)
+}
+
+// RegisteredErrors returns the errors registered in the classes, ordered by
+// class and code. An error defined more than once in a class is returned
+// once, with the message it was first defined with.
+func RegisteredErrors() []ErrorInfo {
+	return registry.errors()
+}
+
+// CheckRegistry checks that the MySQL error tables are consistent, see
+// mysql.CheckErrorTables, and that every registered error has a message, a
+// registered class and isn't defined again with a different message. It is
+// meant to be called in tests after all the errors are defined.
+func CheckRegistry() error {
+	var problems []string
+	if err := mysql.CheckErrorTables(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, registry.check()...)
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.Errorf("inconsistent error registry:\n%s", strings.Join(problems, "\n"))
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terror
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+)
+
+var _ = Suite(&testRegistrySuite{})
+
+type testRegistrySuite struct{}
+
+func (s *testRegistrySuite) TestErrorClasses(c *C) {
+	classes := ErrorClasses()
+	c.Assert(len(classes), GreaterEqual, 27)
+	c.Assert(classes[0], Equals, ClassInfo{Class: ClassAutoid, Description: "autoid"})
+	for i := 1; i < len(classes); i++ {
+		c.Assert(classes[i-1].Class < classes[i].Class, IsTrue)
+	}
+	found := false
+	for _, class := range classes {
+		if class.Class == ClassParser {
+			c.Assert(class.Description, Equals, "parser")
+			found = true
+		}
+	}
+	c.Assert(found, IsTrue)
+}
+
+func (s *testRegistrySuite) TestRegisteredErrors(c *C) {
+	ClassKV.NewStd(mysql.ErrDupEntry)
+	infos := RegisteredErrors()
+	c.Assert(infos, Not(HasLen), 0)
+	var critical, dupKey *ErrorInfo
+	for i := range infos {
+		info := &infos[i]
+		switch info.RFCCode {
+		case "global:3":
+			critical = info
+		case "kv:1062":
+			dupKey = info
+		}
+	}
+	c.Assert(critical, NotNil)
+	c.Assert(*critical, Equals, ErrorInfo{
+		Class:           ClassGlobal,
+		Code:            CodeExecResultIsEmpty,
+		RFCCode:         "global:3",
+		MessageTemplate: "critical error %v",
+		SQLState:        mysql.DefaultMySQLState,
+	})
+	c.Assert(dupKey, NotNil)
+	c.Assert(dupKey.SQLState, Equals, "23000")
+}
+
+func (s *testRegistrySuite) TestCheckRegistry(c *C) {
+	r := newErrorRegistry()
+	r.add(ClassDDL, mysql.ErrNoDB, mysql.MySQLErrName[mysql.ErrNoDB].Raw)
+	r.add(ClassDDL, mysql.ErrNoDB, mysql.MySQLErrName[mysql.ErrNoDB].Raw)
+	r.add(ClassSchema, mysql.ErrNoDB, "no database")
+	c.Assert(r.check(), HasLen, 0)
+	c.Assert(r.errors(), HasLen, 2)
+
+	r.add(ClassDDL, mysql.ErrNoDB, "no db")
+	r.add(ClassDDL, 1, "")
+	r.add(ErrClass(1000), 1, "unknown class")
+	c.Assert(r.errors(), HasLen, 4)
+	c.Assert(r.check(), DeepEquals, []string{
+		"error ddl:1 has no message",
+		"error 1000:1 belongs to an unregistered class",
+		`error ddl:1046 is defined with the messages "No database selected" and "no db"`,
+	})
+
+	err := CheckRegistry()
+	if err != nil {
+		// The tests define some errors more than once with different messages.
+		c.Assert(errors.ErrorStack(err), Not(Matches), "(?s).*inconsistent error tables.*")
+	}
+}
@@ -160,7 +160,7 @@ func (ec ErrClass) NotEqualClass(err error) bool {
 	return !ec.EqualClass(err)
 }
 
-func (ec ErrClass) initError(code ErrCode) string {
+func (ec ErrClass) initError(code ErrCode, template string) string {
 	if frozen() {
 		panic("register error after initialized is prohibited")
 	}
@@ -173,6 +173,7 @@ func (ec ErrClass) initError(code ErrCode) string {
 	class := errClass2Desc[ec]
 	rfcCode := fmt.Sprintf("%s:%d", class, code)
 	rfcCode2errClass.Put(class, ec)
+	registry.add(ec, code, template)
 	return rfcCode
 }
 
@@ -184,7 +185,7 @@ func (ec ErrClass) initError(code ErrCode) string {
 //
 // Deprecated: use NewStd or NewStdErr instead.
 func (ec ErrClass) New(code ErrCode, message string) *Error {
-	rfcCode := ec.initError(code)
+	rfcCode := ec.initError(code, message)
 	err := errors.Normalize(message, errors.MySQLErrorCode(int(code)), errors.RFCCodeText(rfcCode))
 	return err
 }
@@ -192,7 +193,7 @@ func (ec ErrClass) New(code ErrCode, message string) *Error {
 // NewStdErr defines an *Error with an error code, an error
 // message and workaround to create standard error.
 func (ec ErrClass) NewStdErr(code ErrCode, message *mysql.ErrMessage) *Error {
-	rfcCode := ec.initError(code)
+	rfcCode := ec.initError(code, message.Raw)
 	err := errors.Normalize(message.Raw, errors.RedactArgs(message.RedactArgPos), errors.MySQLErrorCode(int(code)), errors.RFCCodeText(rfcCode))
 	return err
 }
@@ -18,13 +18,17 @@ import (
 	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/parser/ast"
 	"github.com/pingcap/parser/auth"
 	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/model"
 	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/opcode"
 	"github.com/pingcap/parser/terror"
 )
 
@@ -57,6 +61,18 @@ var (
 	ErrWarnDeprecatedIntegerDisplayWidth = terror.ClassParser.NewStdErr(mysql.ErrWarnDeprecatedSyntaxNoReplacement, mysql.Message("Integer display width is deprecated and will be removed in a future release.", nil))
 	// ErrWrongUsage returns for incorrect usages.
 	ErrWrongUsage = terror.ClassParser.NewStd(mysql.ErrWrongUsage)
+	// ErrInvalidVCPURange returns when the start of a VCPU range of a resource group is greater than its end.
+	ErrInvalidVCPURange = terror.ClassParser.NewStd(mysql.ErrInvalidVCPURange)
+	// ErrInvalidThreadPriority returns when the THREAD_PRIORITY of a resource group is out of the range of its type.
+	ErrInvalidThreadPriority = terror.ClassParser.NewStd(mysql.ErrInvalidThreadPriority)
+	// ErrInvalidUseOfForceOption returns when ALTER RESOURCE GROUP has FORCE without DISABLE.
+	ErrInvalidUseOfForceOption = terror.ClassParser.NewStd(mysql.ErrInvalidUseOfForceOption)
+	// ErrSpBadSQLState returns for malformed SQLSTATE value in SIGNAL or RESIGNAL.
+	ErrSpBadSQLState = terror.ClassParser.NewStd(mysql.ErrSpBadSQLstate)
+	// ErrDupSignalSet returns when a condition information item is set more than once.
+	ErrDupSignalSet = terror.ClassParser.NewStd(mysql.ErrDupSignalSet)
+	// ErrWrongValueForVar returns when a condition information item is set to a value out of its range.
+	ErrWrongValueForVar = terror.ClassParser.NewStd(mysql.ErrWrongValueForVar)
 	// SpecFieldPattern special result field pattern
 	SpecFieldPattern = regexp.MustCompile(`(\/\*!(M?[0-9]{5,6})?|\*\/)`)
 	specCodeStart    = regexp.MustCompile(`^\/\*!(M?[0-9]{5,6})?[ \t]*`)
@@ -74,6 +90,8 @@ type ParserConfig struct {
 	EnableStrictDoubleTypeCheck bool
 	SkipPositionRecording       bool
 	CharsetClient               string // CharsetClient indicates how to decode the original SQL.
+	EnableLiteralCharsetCheck   bool   // EnableLiteralCharsetCheck validates the literals with charset introducers and COLLATE clauses.
+	ConvertLiteralToUTF8        bool   // ConvertLiteralToUTF8 converts the literals with charset introducers to utf8mb4 strings.
 }
 
 // Parser represents a parser instance. Some temporary objects are stored in it to reduce object allocation during Parse function.
@@ -87,6 +105,8 @@ type Parser struct {
 
 	explicitCharset       bool
 	strictDoubleFieldType bool
+	literalCharsetCheck   bool
+	literalToUTF8         bool
 
 	// the following fields are used by yyParse to reduce allocation.
 	cache  []yySymType
@@ -135,6 +155,8 @@ func (parser *Parser) SetParserConfig(config ParserConfig) {
 	parser.SetStrictDoubleTypeCheck(config.EnableStrictDoubleTypeCheck)
 	parser.lexer.skipPositionRecording = config.SkipPositionRecording
 	parser.lexer.encoding = *charset.NewEncoding(config.CharsetClient)
+	parser.literalCharsetCheck = config.EnableLiteralCharsetCheck
+	parser.literalToUTF8 = config.ConvertLiteralToUTF8
 }
 
 // Parse parses a query string to raw ast.StmtNode.
@@ -208,6 +230,49 @@ func ParseErrorWith(errstr string, lineno int) error {
 	return fmt.Errorf("near '%-.80s' at line %d", errstr, lineno)
 }
 
+// PositionError is an error or a warning about a part of the SQL, like a
+// literal or an optimizer hint, with the position of the part.
+type PositionError struct {
+	// Line is the 1-based line of the part.
+	Line int
+	// Column is the 1-based column of the part, counted in characters.
+	Column int
+	// Offset is the byte offset of the part in the SQL.
+	Offset int
+	Err    error
+}
+
+// Error implements the error interface.
+func (e *PositionError) Error() string {
+	return fmt.Sprintf("%s (line %d column %d)", e.Err.Error(), e.Line, e.Column)
+}
+
+// Cause returns the error of the part.
+func (e *PositionError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the error of the part.
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// newPositionError returns a PositionError of err at the offset of src. The
+// offset is clamped to the length of src.
+func newPositionError(src string, offset int, err error) *PositionError {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	before := src[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return &PositionError{
+		Line:   strings.Count(before, "\n") + 1,
+		Column: utf8.RuneCountInString(before[lineStart:]) + 1,
+		Offset: offset,
+		Err:    err,
+	}
+}
+
 // The select statement is not at the end of the whole statement, if the last
 // field text was set from its offset to the end of the src string, update
 // the last field text.
@@ -237,9 +302,64 @@ func (parser *Parser) parseHint(input string) ([]*ast.TableOptimizerHint, []erro
 	if parser.hintParser == nil {
 		parser.hintParser = newHintParser()
 	}
+	parser.hintParser.recordPositions = !parser.lexer.skipPositionRecording
 	return parser.hintParser.parse(input, parser.lexer.GetSQLMode(), parser.lexer.lastHintPos)
 }
 
+// checkThreadPriority checks that the THREAD_PRIORITY of a resource group is
+// in the range of its type, which is [-20, 0] for SYSTEM and [0, 19] for USER.
+// The type of an altered group isn't known, so the priority is only checked
+// against the range of both types.
+func checkThreadPriority(name string, tp model.ResourceGroupType, priority int64) error {
+	min, max := int64(-20), int64(19)
+	switch tp {
+	case model.ResourceGroupTypeSystem:
+		max = 0
+	case model.ResourceGroupTypeUser:
+		min = 0
+	}
+	if priority >= min && priority <= max {
+		return nil
+	}
+	if tp == model.ResourceGroupTypeNone {
+		if priority < 0 {
+			tp, max = model.ResourceGroupTypeSystem, 0
+		} else {
+			tp, min = model.ResourceGroupTypeUser, 0
+		}
+	}
+	return ErrInvalidThreadPriority.GenWithStackByArgs(priority, tp.String(), name, min, max)
+}
+
+// checkSignalInfo checks the literal value of a condition information item in SIGNAL or RESIGNAL.
+// MYSQL_ERRNO must be in the range [1, 65535].
+func checkSignalInfo(item ast.ConditionInfoItem, value ast.ExprNode) error {
+	if item != ast.ConditionMySQLErrno {
+		return nil
+	}
+	switch v := value.(type) {
+	case *ast.UnaryOperationExpr:
+		if lit, ok := v.V.(ast.ValueExpr); ok && v.Op == opcode.Minus {
+			return ErrWrongValueForVar.GenWithStackByArgs(item.String(), fmt.Sprintf("-%v", lit.GetValue()))
+		}
+	case ast.ValueExpr:
+		switch n := v.GetValue().(type) {
+		case int64:
+			if n >= 1 && n <= math.MaxUint16 {
+				return nil
+			}
+		case uint64:
+			if n >= 1 && n <= math.MaxUint16 {
+				return nil
+			}
+		case nil:
+			return nil
+		}
+		return ErrWrongValueForVar.GenWithStackByArgs(item.String(), fmt.Sprintf("%v", v.GetValue()))
+	}
+	return nil
+}
+
 func toInt(l yyLexer, lval *yySymType, str string) int {
 	n, err := strconv.ParseUint(str, 10, 64)
 	if err != nil {